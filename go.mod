@@ -5,6 +5,7 @@ go 1.15
 require (
 	chainmaker.org/chainmaker-go/accesscontrol v0.0.0
 	chainmaker.org/chainmaker-go/blockchain v0.0.0
+	chainmaker.org/chainmaker-go/consensus v0.0.0
 	chainmaker.org/chainmaker-go/net v0.0.0
 	chainmaker.org/chainmaker-go/rpcserver v0.0.0
 	chainmaker.org/chainmaker-go/txpool v0.0.0
@@ -42,11 +43,13 @@ replace (
 	chainmaker.org/chainmaker-go/consensus => ./module/consensus
 	chainmaker.org/chainmaker-go/core => ./module/core
 	chainmaker.org/chainmaker-go/net => ./module/net
+	chainmaker.org/chainmaker-go/pb => ./module/pb
 	chainmaker.org/chainmaker-go/rpcserver => ./module/rpcserver
 	chainmaker.org/chainmaker-go/snapshot => ./module/snapshot
 	chainmaker.org/chainmaker-go/subscriber => ./module/subscriber
 	chainmaker.org/chainmaker-go/sync => ./module/sync
 	chainmaker.org/chainmaker-go/txpool => ./module/txpool
+	chainmaker.org/chainmaker-go/upgrade => ./module/upgrade
 	chainmaker.org/chainmaker-go/vm => ./module/vm
 	github.com/libp2p/go-libp2p-core => chainmaker.org/chainmaker/libp2p-core v1.0.0
 	github.com/spf13/afero => github.com/spf13/afero v1.5.1 //for go1.15 build
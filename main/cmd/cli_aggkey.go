@@ -0,0 +1,108 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"chainmaker.org/chainmaker-go/consensus/aggsig"
+	"chainmaker.org/chainmaker-go/consensus/governance"
+	"chainmaker.org/chainmaker-go/consensus/tbft"
+	"chainmaker.org/chainmaker/localconf/v2"
+	"github.com/spf13/cobra"
+)
+
+const flagNameOfAggKeyPath = "path"
+
+// AggKeyCMD generates and shows the BLS key which signs the aggregate commits of TBFT and QCs of chained-BFT.
+// ./chainmaker aggkey gen -c ../config/wx-org1/chainmaker.yml
+func AggKeyCMD() *cobra.Command {
+	var keyPath string
+	aggKeyCmd := &cobra.Command{
+		Use:   "aggkey",
+		Short: "Generate and show the consensus aggregation key",
+		Long: "Generate and show the BLS key which signs the aggregate commits of TBFT and QCs of chained-BFT. " +
+			"The key file is " + aggsig.KeyFile + " in the directory of the node key in config, or --path. " +
+			"The public key and proof of possession printed are registered in " + tbft.TBFTAggregateKeysKey +
+			" or " + governance.AggregateKeys + " of the consensus ext config, by the node id.",
+	}
+	aggKeyCmd.PersistentFlags().StringVar(&keyPath, flagNameOfAggKeyPath, "",
+		"the key file, instead of the one of the node key in config")
+
+	genCmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate the aggregation key",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			file, err := aggKeyFile(cmd, keyPath)
+			if err != nil {
+				return err
+			}
+			if _, err = os.Stat(file); err == nil {
+				return fmt.Errorf("key file %s exists", file)
+			}
+			sk, err := aggsig.GenerateKey()
+			if err != nil {
+				return err
+			}
+			if err = ioutil.WriteFile(file, []byte(hex.EncodeToString(sk.Bytes())), 0600); err != nil {
+				return err
+			}
+			fmt.Printf("key file: %s\n", file)
+			return printRegisteredKey(sk)
+		},
+	}
+	attachFlags(genCmd, []string{flagNameOfConfigFilepath})
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the public key and proof of possession of the aggregation key",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			file, err := aggKeyFile(cmd, keyPath)
+			if err != nil {
+				return err
+			}
+			sk, err := aggsig.LoadPrivateKey(file)
+			if err != nil {
+				return err
+			}
+			return printRegisteredKey(sk)
+		},
+	}
+	attachFlags(showCmd, []string{flagNameOfConfigFilepath})
+
+	aggKeyCmd.AddCommand(genCmd)
+	aggKeyCmd.AddCommand(showCmd)
+	return aggKeyCmd
+}
+
+// aggKeyFile returns the key file of --path, or the one in the directory of the node key in config
+func aggKeyFile(cmd *cobra.Command, keyPath string) (string, error) {
+	if keyPath != "" {
+		return keyPath, nil
+	}
+	initLocalConfig(cmd)
+	nodeKeyFile := localconf.ChainMakerConfig.NodeConfig.PrivKeyFile
+	if nodeKeyFile == "" {
+		return "", errors.New("no node key in config, the key file is required")
+	}
+	return filepath.Join(filepath.Dir(nodeKeyFile), aggsig.KeyFile), nil
+}
+
+func printRegisteredKey(sk *aggsig.PrivateKey) error {
+	data, err := json.MarshalIndent(aggsig.NewRegisteredKey(sk), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"chainmaker.org/chainmaker-go/blockchain"
+	"chainmaker.org/chainmaker/localconf/v2"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNameOfRollbackHeight  = "height"
+	flagNameOfRollbackChainId = "chain-id"
+	flagNameOfRollbackDryRun  = "dry-run"
+	flagNameOfRollbackYes     = "yes"
+)
+
+// RollbackCMD rolls back the ledger of a chain to a height, the node must be stopped.
+// ./chainmaker rollback -c ../config/wx-org1-solo/chainmaker.yml --chain-id chain1 --height 100 --dry-run
+func RollbackCMD() *cobra.Command {
+	var (
+		height  uint64
+		chainId string
+		dryRun  bool
+		yes     bool
+	)
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Rollback the ledger to a height",
+		Long: "Rollback the ledger of a chain to a height offline, the blocks, states, tx indexes and " +
+			"consensus wal entries above the height are removed. The node must be stopped.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !cmd.Flags().Changed(flagNameOfRollbackHeight) {
+				return errors.New("height is required")
+			}
+			initLocalConfig(cmd)
+			if chainId == "" {
+				chains := localconf.ChainMakerConfig.GetBlockChains()
+				if len(chains) != 1 {
+					return errors.New("chain-id is required when the node has more than one chain")
+				}
+				chainId = chains[0].ChainId
+			}
+
+			// always report what will be removed first
+			report, err := blockchain.RollbackLedger(chainId, height, true)
+			if err != nil {
+				return err
+			}
+			fmt.Print(report.String())
+			if dryRun {
+				return nil
+			}
+			if !yes && !confirm("rollback the ledger as above?") {
+				fmt.Println("rollback canceled")
+				return nil
+			}
+			if _, err = blockchain.RollbackLedger(chainId, height, false); err != nil {
+				return err
+			}
+			fmt.Printf("rollback chain %s to height %d success\n", chainId, height)
+			return nil
+		},
+	}
+	attachFlags(rollbackCmd, []string{flagNameOfConfigFilepath})
+	rollbackCmd.Flags().Uint64Var(&height, flagNameOfRollbackHeight, 0, "the height to rollback to")
+	rollbackCmd.Flags().StringVar(&chainId, flagNameOfRollbackChainId, "",
+		"the chain to rollback, can be omitted if the node has only one chain")
+	rollbackCmd.Flags().BoolVar(&dryRun, flagNameOfRollbackDryRun, false, "only report what will be removed")
+	rollbackCmd.Flags().BoolVarP(&yes, flagNameOfRollbackYes, "y", false, "rollback without confirmation")
+	return rollbackCmd
+}
+
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,530 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"chainmaker.org/chainmaker-go/consensus/safewal"
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
+	"chainmaker.org/chainmaker/common/v2/wal"
+	"chainmaker.org/chainmaker/localconf/v2"
+	chainedbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/chainedbft"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"github.com/gogo/protobuf/proto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagNameOfWalChainId   = "chain-id"
+	flagNameOfWalConsensus = "consensus"
+	flagNameOfWalDir       = "dir"
+	flagNameOfWalFrom      = "from"
+	flagNameOfWalTo        = "to"
+	flagNameOfWalDryRun    = "dry-run"
+	flagNameOfWalYes       = "yes"
+	flagNameOfWalForce     = "force"
+)
+
+// walKind is the wal of a consensus
+type walKind struct {
+	name string
+	dir  string // the wal dir under the store path of chain, see the consensus modules
+	// describe returns the height and the description of the data of an entry
+	describe func(data []byte) (uint64, string, error)
+	// keepFrom returns the first index needed by replay, of the data of the last entry
+	keepFrom func(data []byte) (uint64, error)
+}
+
+var walKinds = []*walKind{
+	{name: "tbft", dir: "tbftwal", describe: describeTBFTWalEntry, keepFrom: tbftWalKeepFrom},
+	{name: "hotstuff", dir: "hotstuff_wal", describe: describeHotstuffWalEntry, keepFrom: hotstuffWalKeepFrom},
+}
+
+// walOptions are the flags of wal commands to locate the wal
+type walOptions struct {
+	chainId   string
+	consensus string
+	dir       string
+}
+
+// WalCMD inspects, verifies, repairs and compacts the consensus wal of TBFT and chained-BFT offline.
+// ./chainmaker wal verify -c ../config/wx-org1/chainmaker.yml --chain-id chain1
+func WalCMD() *cobra.Command {
+	opts := &walOptions{}
+	walCmd := &cobra.Command{
+		Use:   "wal",
+		Short: "Inspect, verify, repair and compact the consensus wal",
+		Long: "Inspect, verify, repair and compact the consensus wal of TBFT and chained-BFT offline. " +
+			"The wal is located by the store path of chain in config, or by --dir. The node must be stopped. " +
+			"The entries are written with checksums, which the versions before can not read, " +
+			"so the wal must be removed before downgrading the node to them.",
+	}
+	walCmd.PersistentFlags().StringVar(&opts.chainId, flagNameOfWalChainId, "",
+		"the chain of wal, can be omitted if the node has only one chain")
+	walCmd.PersistentFlags().StringVar(&opts.consensus, flagNameOfWalConsensus, "",
+		"the consensus of wal, tbft or hotstuff, can be omitted if the chain has only one wal")
+	walCmd.PersistentFlags().StringVar(&opts.dir, flagNameOfWalDir, "",
+		"the wal dir, instead of the one of chain in config")
+	walCmd.AddCommand(walInspectCMD(opts))
+	walCmd.AddCommand(walVerifyCMD(opts))
+	walCmd.AddCommand(walDumpCMD(opts))
+	walCmd.AddCommand(walRepairCMD(opts))
+	walCmd.AddCommand(walCompactCMD(opts))
+	return walCmd
+}
+
+func walInspectCMD(opts *walOptions) *cobra.Command {
+	inspectCmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Show the summary of wal",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			kind, dir, err := opts.locate(cmd)
+			if err != nil {
+				return err
+			}
+			return walkWal(dir, func(walLog *wal.Log, firstIndex, lastIndex uint64) error {
+				fmt.Printf("wal: %s (%s)\n", dir, kind.name)
+				if lastIndex == 0 {
+					fmt.Println("no entry")
+					return nil
+				}
+				var checked, legacy int
+				var minHeight, maxHeight uint64
+				for i := firstIndex; i <= lastIndex; i++ {
+					entry, err := walLog.Read(i)
+					if err != nil {
+						return err
+					}
+					data, ok, err := safewal.Decode(entry)
+					if err != nil {
+						return fmt.Errorf("entry[%d] %s, run verify for details", i, err)
+					}
+					if ok {
+						checked++
+					} else {
+						legacy++
+					}
+					height, _, err := kind.describe(data)
+					if err != nil {
+						return fmt.Errorf("entry[%d] %s, run verify for details", i, err)
+					}
+					if i == firstIndex {
+						minHeight = height
+					}
+					maxHeight = height
+				}
+				fmt.Printf("entries: [%d, %d], %d with checksum, %d without checksum\n",
+					firstIndex, lastIndex, checked, legacy)
+				fmt.Printf("heights: [%d, %d]\n", minHeight, maxHeight)
+				keepFrom, err := walKeepFrom(walLog, kind, lastIndex)
+				if err != nil {
+					return err
+				}
+				if keepFrom > firstIndex {
+					fmt.Printf("compactable: %d entries before index %d\n", keepFrom-firstIndex, keepFrom)
+				}
+				return nil
+			})
+		},
+	}
+	attachFlags(inspectCmd, []string{flagNameOfConfigFilepath})
+	return inspectCmd
+}
+
+func walVerifyCMD(opts *walOptions) *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the checksums and contents of all the entries of wal",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			kind, dir, err := opts.locate(cmd)
+			if err != nil {
+				return err
+			}
+			return walkWal(dir, func(walLog *wal.Log, firstIndex, lastIndex uint64) error {
+				var corrupt int
+				var lastHeight uint64
+				for i := firstIndex; i <= lastIndex && lastIndex > 0; i++ {
+					entry, err := walLog.Read(i)
+					if err == nil {
+						var data []byte
+						if data, _, err = safewal.Decode(entry); err == nil {
+							var height uint64
+							if height, _, err = kind.describe(data); err == nil {
+								if height < lastHeight {
+									err = fmt.Errorf("height %d is lower than the height %d before", height, lastHeight)
+								}
+								lastHeight = height
+							}
+						}
+					}
+					if err != nil {
+						corrupt++
+						fmt.Printf("entry[%d]: %s\n", i, err)
+					}
+				}
+				if corrupt > 0 {
+					return fmt.Errorf("%d of the entries [%d, %d] in %s are corrupt", corrupt, firstIndex, lastIndex, dir)
+				}
+				fmt.Printf("%s is ok, entries: [%d, %d]\n", dir, firstIndex, lastIndex)
+				return nil
+			})
+		},
+	}
+	attachFlags(verifyCmd, []string{flagNameOfConfigFilepath})
+	return verifyCmd
+}
+
+func walDumpCMD(opts *walOptions) *cobra.Command {
+	var from, to uint64
+	dumpCmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Print the entries of wal",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			kind, dir, err := opts.locate(cmd)
+			if err != nil {
+				return err
+			}
+			return walkWal(dir, func(walLog *wal.Log, firstIndex, lastIndex uint64) error {
+				if from < firstIndex {
+					from = firstIndex
+				}
+				if to == 0 || to > lastIndex {
+					to = lastIndex
+				}
+				for i := from; i <= to && lastIndex > 0; i++ {
+					entry, err := walLog.Read(i)
+					if err != nil {
+						return err
+					}
+					data, checked, err := safewal.Decode(entry)
+					if err != nil {
+						fmt.Printf("[%d] %s\n", i, err)
+						continue
+					}
+					height, desc, err := kind.describe(data)
+					if err != nil {
+						fmt.Printf("[%d] invalid entry, %s\n", i, err)
+						continue
+					}
+					checksum := ""
+					if !checked {
+						checksum = " (no checksum)"
+					}
+					fmt.Printf("[%d] height %d %s%s\n", i, height, desc, checksum)
+				}
+				return nil
+			})
+		},
+	}
+	attachFlags(dumpCmd, []string{flagNameOfConfigFilepath})
+	dumpCmd.Flags().Uint64Var(&from, flagNameOfWalFrom, 0, "the first index to print, the first entry by default")
+	dumpCmd.Flags().Uint64Var(&to, flagNameOfWalTo, 0, "the last index to print, the last entry by default")
+	return dumpCmd
+}
+
+func walRepairCMD(opts *walOptions) *cobra.Command {
+	var (
+		force bool
+		yes   bool
+	)
+	repairCmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Truncate the corrupt tail of wal",
+		Long: "Truncate the corrupt tail of wal left by a torn write, which is done by the node on starting too. " +
+			"The complete entries failing the checksum at the end are truncated with --force only, the messages " +
+			"of them may have been sent, so the node may vote twice after the repair. " +
+			"The corrupt entries followed by valid ones are not repaired.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			_, dir, err := opts.locate(cmd)
+			if err != nil {
+				return err
+			}
+			var repairs []string
+			if force {
+				if !yes && !confirm("truncate the complete but corrupt entries, which may have been sent?") {
+					fmt.Println("repair canceled")
+					return nil
+				}
+				if repairs, err = safewal.ForceRepair(dir); err != nil {
+					return err
+				}
+			} else {
+				var walLog *safewal.Log
+				walLog, repairs, err = safewal.Open(dir)
+				if errors.Is(err, safewal.ErrCorruptTail) {
+					return fmt.Errorf("%w, run repair with --%s to truncate them", err, flagNameOfWalForce)
+				}
+				if err != nil {
+					return err
+				}
+				if err = walLog.Close(); err != nil {
+					return err
+				}
+			}
+			if len(repairs) == 0 {
+				fmt.Printf("%s has no corrupt tail\n", dir)
+			}
+			for _, repair := range repairs {
+				fmt.Printf("%s: %s\n", dir, repair)
+			}
+			return nil
+		},
+	}
+	attachFlags(repairCmd, []string{flagNameOfConfigFilepath})
+	repairCmd.Flags().BoolVar(&force, flagNameOfWalForce, false,
+		"truncate the complete but corrupt entries at the end too")
+	repairCmd.Flags().BoolVarP(&yes, flagNameOfWalYes, "y", false, "repair with --force without confirmation")
+	return repairCmd
+}
+
+func walCompactCMD(opts *walOptions) *cobra.Command {
+	var (
+		dryRun bool
+		yes    bool
+	)
+	compactCmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Remove the entries not needed by replay",
+		Long: "Remove the entries of wal before the first one needed by replay, which is the first entry of " +
+			"the last height for TBFT, and the snapshot index of the last entry for chained-BFT.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			kind, dir, err := opts.locate(cmd)
+			if err != nil {
+				return err
+			}
+			return walkWal(dir, func(walLog *wal.Log, firstIndex, lastIndex uint64) error {
+				if lastIndex == 0 {
+					fmt.Printf("%s has no entry\n", dir)
+					return nil
+				}
+				keepFrom, err := walKeepFrom(walLog, kind, lastIndex)
+				if err != nil {
+					return err
+				}
+				if keepFrom <= firstIndex || keepFrom > lastIndex {
+					fmt.Printf("%s has no entry to remove, entries: [%d, %d]\n", dir, firstIndex, lastIndex)
+					return nil
+				}
+				fmt.Printf("%s removes %d entries [%d, %d], keeps [%d, %d]\n",
+					dir, keepFrom-firstIndex, firstIndex, keepFrom-1, keepFrom, lastIndex)
+				if dryRun {
+					return nil
+				}
+				if !yes && !confirm("compact the wal as above?") {
+					fmt.Println("compact canceled")
+					return nil
+				}
+				if err = walLog.TruncateFront(keepFrom); err != nil {
+					return err
+				}
+				fmt.Printf("compact %s success\n", dir)
+				return nil
+			})
+		},
+	}
+	attachFlags(compactCmd, []string{flagNameOfConfigFilepath})
+	compactCmd.Flags().BoolVar(&dryRun, flagNameOfWalDryRun, false, "only report what will be removed")
+	compactCmd.Flags().BoolVarP(&yes, flagNameOfWalYes, "y", false, "compact without confirmation")
+	return compactCmd
+}
+
+// locate returns the kind and dir of wal by the flags
+func (opts *walOptions) locate(cmd *cobra.Command) (*walKind, string, error) {
+	var kind *walKind
+	for _, k := range walKinds {
+		if k.name == opts.consensus {
+			kind = k
+		}
+	}
+	if opts.consensus != "" && kind == nil {
+		return nil, "", fmt.Errorf("unknown consensus %s, tbft or hotstuff", opts.consensus)
+	}
+
+	if opts.dir != "" {
+		if kind == nil {
+			for _, k := range walKinds {
+				if filepath.Base(filepath.Clean(opts.dir)) == k.dir {
+					kind = k
+				}
+			}
+		}
+		if kind == nil {
+			return nil, "", errors.New("consensus is required for the wal dir")
+		}
+		return kind, opts.dir, checkWalDir(opts.dir)
+	}
+
+	initLocalConfig(cmd)
+	chainId := opts.chainId
+	if chainId == "" {
+		chains := localconf.ChainMakerConfig.GetBlockChains()
+		if len(chains) != 1 {
+			return nil, "", errors.New("chain-id is required when the node has more than one chain")
+		}
+		chainId = chains[0].ChainId
+	}
+	chainDir := path.Join(localconf.ChainMakerConfig.GetStorePath(), chainId)
+	if kind != nil {
+		dir := path.Join(chainDir, kind.dir)
+		return kind, dir, checkWalDir(dir)
+	}
+	var found []*walKind
+	for _, k := range walKinds {
+		if checkWalDir(path.Join(chainDir, k.dir)) == nil {
+			found = append(found, k)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, "", fmt.Errorf("no consensus wal found in %s", chainDir)
+	case 1:
+		return found[0], path.Join(chainDir, found[0].dir), nil
+	}
+	return nil, "", errors.New("consensus is required when the chain has more than one wal")
+}
+
+func checkWalDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a dir", dir)
+	}
+	return nil
+}
+
+// walkWal opens the wal in dir without repairing it, and calls f with the indexes of its entries
+func walkWal(dir string, f func(walLog *wal.Log, firstIndex, lastIndex uint64) error) error {
+	walLog, err := wal.Open(dir, nil)
+	if err == wal.ErrCorrupt {
+		return fmt.Errorf("%s has a corrupt tail, run repair to truncate it", dir)
+	}
+	if err != nil {
+		return err
+	}
+	defer walLog.Close()
+	firstIndex, err := walLog.FirstIndex()
+	if err != nil {
+		return err
+	}
+	lastIndex, err := walLog.LastIndex()
+	if err != nil {
+		return err
+	}
+	return f(walLog, firstIndex, lastIndex)
+}
+
+func walKeepFrom(walLog *wal.Log, kind *walKind, lastIndex uint64) (uint64, error) {
+	entry, err := walLog.Read(lastIndex)
+	if err != nil {
+		return 0, err
+	}
+	data, _, err := safewal.Decode(entry)
+	if err != nil {
+		return 0, fmt.Errorf("the last entry[%d] %s, run repair to truncate it", lastIndex, err)
+	}
+	return kind.keepFrom(data)
+}
+
+func describeTBFTWalEntry(data []byte) (uint64, string, error) {
+	entry := &tbftpb.WalEntry{}
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return 0, "", err
+	}
+	var desc string
+	switch entry.Type {
+	case tbftpb.WalEntryType_PROPOSAL_ENTRY:
+		proposal := &tbftpb.Proposal{}
+		if err := proto.Unmarshal(entry.Data, proposal); err != nil {
+			return 0, "", err
+		}
+		if proposal.Block == nil || proposal.Block.Header == nil {
+			return 0, "", errors.New("proposal without block")
+		}
+		desc = fmt.Sprintf("PROPOSAL %s(%d/%d) block %x txs %d", proposal.Voter, proposal.Height,
+			proposal.Round, proposal.Block.Header.BlockHash, len(proposal.Block.Txs))
+	case tbftpb.WalEntryType_VOTE_ENTRY:
+		vote := &tbftpb.Vote{}
+		if err := proto.Unmarshal(entry.Data, vote); err != nil {
+			return 0, "", err
+		}
+		ext := &tbftextpb.VoteExt{}
+		if err := proto.Unmarshal(entry.Data, ext); err != nil {
+			return 0, "", err
+		}
+		desc = fmt.Sprintf("%s %s(%d/%d) hash %x", vote.Type, vote.Voter, vote.Height, vote.Round, vote.Hash)
+		if len(ext.PartSetRoot) > 0 {
+			desc += fmt.Sprintf(" part set root %x", ext.PartSetRoot)
+		}
+	case tbftpb.WalEntryType_TIMEOUT_ENTRY:
+		timeout := &tbftpb.TimeoutInfo{}
+		if err := proto.Unmarshal(entry.Data, timeout); err != nil {
+			return 0, "", err
+		}
+		desc = fmt.Sprintf("TIMEOUT %s (%d/%d) duration %s", timeout.Step, timeout.Height, timeout.Round,
+			time.Duration(timeout.Duration)*time.Microsecond)
+	default:
+		return 0, "", fmt.Errorf("unknown entry type %s", entry.Type)
+	}
+	return entry.Height, desc, nil
+}
+
+func tbftWalKeepFrom(data []byte) (uint64, error) {
+	entry := &tbftpb.WalEntry{}
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return 0, err
+	}
+	return entry.HeightFirstIndex, nil
+}
+
+func describeHotstuffWalEntry(data []byte) (uint64, string, error) {
+	entry := &chainedbftpb.WalEntry{}
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return 0, "", err
+	}
+	if entry.Msg == nil || entry.Msg.Payload == nil {
+		return 0, "", errors.New("empty consensus msg")
+	}
+	switch entry.Msg.Payload.Type {
+	case chainedbftpb.MessageType_PROPOSAL_MESSAGE:
+		proposal := entry.Msg.Payload.GetProposalMsg()
+		if proposal == nil || proposal.ProposalData == nil {
+			return 0, "", errors.New("empty proposal msg")
+		}
+		p := proposal.ProposalData
+		var hash []byte
+		if p.Block != nil && p.Block.Header != nil {
+			hash = p.Block.Header.BlockHash
+		}
+		return p.Height, fmt.Sprintf("PROPOSAL proposer %s index %d epoch %d level %d block %x",
+			p.Proposer, p.ProposerIdx, p.EpochId, p.Level, hash), nil
+	case chainedbftpb.MessageType_VOTE_MESSAGE:
+		vote := entry.Msg.Payload.GetVoteMsg()
+		if vote == nil || vote.VoteData == nil {
+			return 0, "", errors.New("empty vote msg")
+		}
+		v := vote.VoteData
+		return v.Height, fmt.Sprintf("VOTE author %s index %d epoch %d level %d block %x new view %v",
+			v.Author, v.AuthorIdx, v.EpochId, v.Level, v.BlockId, v.NewView), nil
+	}
+	return 0, "", fmt.Errorf("unknown msg type %s", entry.Msg.Payload.Type)
+}
+
+func hotstuffWalKeepFrom(data []byte) (uint64, error) {
+	entry := &chainedbftpb.WalEntry{}
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return 0, err
+	}
+	return entry.LastSnapshotIndex, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chainmaker.org/chainmaker-go/consensus/safewal"
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
+	"chainmaker.org/chainmaker/common/v2/wal"
+	chainedbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/chainedbft"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func mustMarshalWal(t *testing.T, msg proto.Message) []byte {
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return data
+}
+
+func newTBFTWalVote(t *testing.T, height, heightFirstIndex uint64) []byte {
+	vote := &tbftpb.Vote{Type: tbftpb.VoteType_VOTE_PREVOTE, Voter: "node1", Height: height, Hash: []byte{1}}
+	data := append(mustMarshalWal(t, vote), mustMarshalWal(t, &tbftextpb.VoteExt{PartSetRoot: []byte{2}})...)
+	return mustMarshalWal(t, &tbftpb.WalEntry{
+		Height:           height,
+		Type:             tbftpb.WalEntryType_VOTE_ENTRY,
+		Data:             data,
+		HeightFirstIndex: heightFirstIndex,
+	})
+}
+
+// newTBFTWal writes the entries of heights 1 to 3 in wal, 2 entries of each height
+func newTBFTWal(t *testing.T) string {
+	root, err := ioutil.TempDir("", "cli_wal")
+	require.NoError(t, err)
+	dir := filepath.Join(root, "tbftwal")
+	walLog, _, err := safewal.Open(dir)
+	require.NoError(t, err)
+	for i := uint64(1); i <= 6; i++ {
+		height := (i + 1) / 2
+		require.NoError(t, walLog.Write(i, newTBFTWalVote(t, height, height*2-1)))
+	}
+	require.NoError(t, walLog.Close())
+	return dir
+}
+
+func runWalCMD(args ...string) error {
+	walCmd := WalCMD()
+	walCmd.SetArgs(args)
+	walCmd.SilenceUsage = true
+	return walCmd.Execute()
+}
+
+func walIndexes(t *testing.T, dir string) (uint64, uint64) {
+	walLog, err := wal.Open(dir, nil)
+	require.NoError(t, err)
+	defer walLog.Close()
+	firstIndex, err := walLog.FirstIndex()
+	require.NoError(t, err)
+	lastIndex, err := walLog.LastIndex()
+	require.NoError(t, err)
+	return firstIndex, lastIndex
+}
+
+func TestWalOptionsLocate(t *testing.T) {
+	dir := newTBFTWal(t)
+	defer os.RemoveAll(filepath.Dir(dir))
+
+	kind, located, err := (&walOptions{dir: dir}).locate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "tbft", kind.name)
+	require.Equal(t, dir, located)
+
+	kind, _, err = (&walOptions{dir: dir, consensus: "hotstuff"}).locate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "hotstuff", kind.name)
+
+	_, _, err = (&walOptions{dir: dir, consensus: "raft"}).locate(nil)
+	require.Error(t, err)
+	_, _, err = (&walOptions{dir: filepath.Dir(dir)}).locate(nil)
+	require.Error(t, err)
+	_, _, err = (&walOptions{dir: filepath.Join(dir, "none"), consensus: "tbft"}).locate(nil)
+	require.Error(t, err)
+}
+
+func TestDescribeWalEntry(t *testing.T) {
+	height, desc, err := describeTBFTWalEntry(newTBFTWalVote(t, 10, 19))
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), height)
+	require.Equal(t, "VOTE_PREVOTE node1(10/0) hash 01 part set root 02", desc)
+	keepFrom, err := tbftWalKeepFrom(newTBFTWalVote(t, 10, 19))
+	require.NoError(t, err)
+	require.Equal(t, uint64(19), keepFrom)
+	_, _, err = describeTBFTWalEntry(mustMarshalWal(t, &tbftpb.WalEntry{Type: tbftpb.WalEntryType_PROPOSAL_ENTRY}))
+	require.Error(t, err)
+
+	entry := mustMarshalWal(t, &chainedbftpb.WalEntry{
+		Msg: &chainedbftpb.ConsensusMsg{Payload: &chainedbftpb.ConsensusPayload{
+			Type: chainedbftpb.MessageType_VOTE_MESSAGE,
+			Data: &chainedbftpb.ConsensusPayload_VoteMsg{VoteMsg: &chainedbftpb.VoteMsg{
+				VoteData: &chainedbftpb.VoteData{Height: 10, Level: 12, Author: []byte("node1"), BlockId: []byte{1}},
+			}},
+		}},
+		LastSnapshotIndex: 19,
+	})
+	height, desc, err = describeHotstuffWalEntry(entry)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), height)
+	require.Equal(t, "VOTE author node1 index 0 epoch 0 level 12 block 01 new view false", desc)
+	keepFrom, err = hotstuffWalKeepFrom(entry)
+	require.NoError(t, err)
+	require.Equal(t, uint64(19), keepFrom)
+	_, _, err = describeHotstuffWalEntry(mustMarshalWal(t, &chainedbftpb.WalEntry{}))
+	require.Error(t, err)
+}
+
+func TestWalCMD(t *testing.T) {
+	dir := newTBFTWal(t)
+	defer os.RemoveAll(filepath.Dir(dir))
+
+	require.NoError(t, runWalCMD("inspect", "--dir", dir))
+	require.NoError(t, runWalCMD("verify", "--dir", dir))
+	require.NoError(t, runWalCMD("dump", "--dir", dir, "--from", "2", "--to", "3"))
+
+	// the entries before the last height are removed
+	require.NoError(t, runWalCMD("compact", "--dir", dir, "--dry-run"))
+	firstIndex, lastIndex := walIndexes(t, dir)
+	require.Equal(t, uint64(1), firstIndex)
+	require.NoError(t, runWalCMD("compact", "--dir", dir, "-y"))
+	firstIndex, lastIndex = walIndexes(t, dir)
+	require.Equal(t, uint64(5), firstIndex)
+	require.Equal(t, uint64(6), lastIndex)
+
+	// the complete but corrupt entry at the end is truncated with force only
+	walLog, err := wal.Open(dir, nil)
+	require.NoError(t, err)
+	entry := safewal.Encode(newTBFTWalVote(t, 4, 7))
+	entry[len(entry)-1]++
+	require.NoError(t, walLog.Write(7, entry))
+	require.NoError(t, walLog.Close())
+	require.Error(t, runWalCMD("verify", "--dir", dir))
+	err = runWalCMD("repair", "--dir", dir)
+	require.True(t, errors.Is(err, safewal.ErrCorruptTail))
+	_, lastIndex = walIndexes(t, dir)
+	require.Equal(t, uint64(7), lastIndex)
+	require.NoError(t, runWalCMD("repair", "--dir", dir, "--force", "-y"))
+	_, lastIndex = walIndexes(t, dir)
+	require.Equal(t, uint64(6), lastIndex)
+	require.NoError(t, runWalCMD("verify", "--dir", dir))
+}
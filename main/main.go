@@ -19,6 +19,9 @@ func main() {
 	mainCmd.AddCommand(cmd.StartCMD())
 	mainCmd.AddCommand(cmd.VersionCMD())
 	mainCmd.AddCommand(cmd.ConfigCMD())
+	mainCmd.AddCommand(cmd.RollbackCMD())
+	mainCmd.AddCommand(cmd.WalCMD())
+	mainCmd.AddCommand(cmd.AggKeyCMD())
 
 	err := mainCmd.Execute()
 	if err != nil {
@@ -9,6 +9,7 @@ SPDX-License-Identifier: Apache-2.0
 package blockchain
 
 import (
+	"chainmaker.org/chainmaker-go/core/statetree"
 	"chainmaker.org/chainmaker-go/subscriber"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
 	"chainmaker.org/chainmaker/logger/v2"
@@ -78,6 +79,9 @@ type Blockchain struct {
 
 	snapshotManager protocol.SnapshotManager
 
+	// authenticated world state
+	stateTree *statetree.StateTree
+
 	lastBlock *common.Block
 
 	chainConf protocol.ChainConf
@@ -115,3 +119,8 @@ func (bc *Blockchain) getConsensusType() consensus.ConsensusType {
 func (bc *Blockchain) GetAccessControl() protocol.AccessControlProvider {
 	return bc.ac
 }
+
+// GetStateTree returns the authenticated world state of the blockchain
+func (bc *Blockchain) GetStateTree() *statetree.StateTree {
+	return bc.stateTree
+}
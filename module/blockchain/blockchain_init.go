@@ -21,7 +21,9 @@ import (
 	"chainmaker.org/chainmaker-go/consensus"
 	"chainmaker.org/chainmaker-go/core"
 	"chainmaker.org/chainmaker-go/core/cache"
+	coreCommon "chainmaker.org/chainmaker-go/core/common"
 	providerConf "chainmaker.org/chainmaker-go/core/provider/conf"
+	"chainmaker.org/chainmaker-go/core/statetree"
 	"chainmaker.org/chainmaker-go/net"
 	"chainmaker.org/chainmaker-go/snapshot"
 	"chainmaker.org/chainmaker-go/subscriber"
@@ -320,7 +322,12 @@ func (bc *Blockchain) initCache() (err error) {
 	// cache the lasted config block
 	bc.ledgerCache = cache.NewLedgerCache(bc.chainId)
 	bc.ledgerCache.SetLastCommittedBlock(bc.lastBlock)
-	bc.proposalCache = cache.NewProposalCache(bc.chainConf, bc.ledgerCache)
+	// self-proposed blocks are persisted, to repeat the same block at the same height after restart
+	bc.proposalCache, err = cache.NewPersistentProposalCache(bc.chainConf, bc.ledgerCache,
+		bc.store.GetDBHandle(cache.ProposalDBName), bc.log)
+	if err != nil {
+		return fmt.Errorf("restore proposal cache failed, %s", err)
+	}
 	bc.log.Debugf("go last block: %+v", bc.lastBlock)
 	bc.initModules[moduleNameLedger] = struct{}{}
 	return nil
@@ -361,6 +368,8 @@ func (bc *Blockchain) initAC() (err error) {
 		bc.log.Errorf("new ac provider failed, %s", err.Error())
 		return
 	}
+	// watched after the ac provider, so the cached tx signatures are discarded after the members are updated
+	bc.chainConf.AddVmWatch(coreCommon.TxSigVerifierOf(bc.chainId))
 
 	switch bc.chainConf.ChainConfig().AuthType {
 	case protocol.PermissionedWithCert, protocol.Identity:
@@ -564,6 +573,22 @@ func (bc *Blockchain) initCore() (err error) {
 	} else {
 		bc.snapshotManager = snapshotFactory.NewSnapshotManager(bc.store)
 	}
+	// create state tree, it is maintained when the activation height of state root is configured
+	bc.stateTree, err = statetree.NewStateTree(bc.chainConf.ChainConfig().Crypto.Hash, bc.store,
+		bc.store.GetDBHandle(statetree.DBName), logger.GetLoggerByChain(logger.MODULE_CORE, bc.chainId))
+	if err != nil {
+		bc.log.Errorf("new state tree failed, %s", err.Error())
+		return err
+	}
+	if coreCommon.IsStateTreeMaintained(bc.chainConf) {
+		// loaded before consensus starts, so that the blocks can be proposed and verified at once
+		if err = bc.stateTree.Load(); err != nil {
+			bc.log.Errorf("load state tree failed, %s", err.Error())
+			return err
+		}
+		coreCommon.RestorePendingStateTrees(bc.stateTree, bc.proposalCache, bc.ledgerCache.GetLastCommittedBlock(),
+			bc.chainConf, bc.log)
+	}
 
 	// init coreEngine module
 	coreEngineConfig := &providerConf.CoreEngineConfig{
@@ -580,6 +605,7 @@ func (bc *Blockchain) initCore() (err error) {
 		VmMgr:           bc.vmMgr,
 		ProposalCache:   bc.proposalCache,
 		Subscriber:      bc.eventSubscriber,
+		StateTree:       bc.stateTree,
 	}
 
 	coreEngineFactory := core.Factory()
@@ -659,6 +685,7 @@ func (bc *Blockchain) initSync() (err error) {
 		bc.ledgerCache,
 		bc.coreEngine.GetBlockVerifier(),
 		bc.coreEngine.GetBlockCommitter(),
+		bc.chainConf,
 	)
 	bc.initModules[moduleNameSync] = struct{}{}
 	return
@@ -22,6 +22,7 @@ require (
 	chainmaker.org/chainmaker/utils/v2 v2.1.0
 	chainmaker.org/chainmaker/vm/v2 v2.1.1
 	github.com/fatih/color v1.13.0 // indirect
+	github.com/gogo/protobuf v1.3.2
 	github.com/hokaccha/go-prettyjson v0.0.0-20210113012101-fb4e108d2519 // indirect
 	github.com/mattn/go-colorable v0.1.11 // indirect
 	github.com/mitchellh/mapstructure v1.4.2
@@ -32,10 +33,12 @@ replace (
 	chainmaker.org/chainmaker-go/consensus => ../consensus
 	chainmaker.org/chainmaker-go/core => ../core
 	chainmaker.org/chainmaker-go/net => ../net
+	chainmaker.org/chainmaker-go/pb => ../pb
 	chainmaker.org/chainmaker-go/snapshot => ../snapshot
 	chainmaker.org/chainmaker-go/subscriber => ../subscriber
 	chainmaker.org/chainmaker-go/sync => ../sync
 	chainmaker.org/chainmaker-go/txpool => ../txpool
+	chainmaker.org/chainmaker-go/upgrade => ../upgrade
 	chainmaker.org/chainmaker-go/vm => ../vm
 	github.com/libp2p/go-libp2p-core => chainmaker.org/chainmaker/libp2p-core v1.0.0
 	google.golang.org/grpc v1.40.0 => google.golang.org/grpc v1.26.0
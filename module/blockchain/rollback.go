@@ -0,0 +1,438 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"chainmaker.org/chainmaker-go/consensus/safewal"
+	"chainmaker.org/chainmaker/common/v2/wal"
+	"chainmaker.org/chainmaker/localconf/v2"
+	"chainmaker.org/chainmaker/logger/v2"
+	chainedbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/chainedbft"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	storePb "chainmaker.org/chainmaker/pb-go/v2/store"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/store/v2"
+	"chainmaker.org/chainmaker/store/v2/conf"
+	"chainmaker.org/chainmaker/utils/v2"
+	"github.com/gogo/protobuf/proto"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// the wal dirs of consensus under store path of chain, see the consensus modules
+	tbftWalDir     = "tbftwal"
+	hotstuffWalDir = "hotstuff_wal"
+	raftWalDir     = "raftwal"
+	raftSnapDir    = "snap"
+
+	// suffixes of the dirs during and after rollback
+	rollbackTmpSuffix    = ".rollback"
+	rollbackBackupSuffix = ".bak"
+
+	storePathKey = "store_path"
+)
+
+// RollbackReport describes what a ledger rollback removes
+type RollbackReport struct {
+	ChainId      string
+	LastHeight   uint64
+	TargetHeight uint64
+	// BlockCount and TxCount are the number of blocks and txs above target height
+	BlockCount int
+	TxCount    int
+	// ConfigHeights are the heights of config blocks above target height, which are reverted
+	ConfigHeights []uint64
+	// WalActions describe the changes to consensus wal
+	WalActions []string
+	// StoreDirs are the ledger dirs of chain rebuilt, the origin dirs are kept with suffix rollbackBackupSuffix
+	StoreDirs []string
+}
+
+// String returns the report in lines
+func (r *RollbackReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "chain: %s\n", r.ChainId)
+	fmt.Fprintf(&sb, "height: %d => %d\n", r.LastHeight, r.TargetHeight)
+	fmt.Fprintf(&sb, "blocks to remove: %d, txs to remove: %d\n", r.BlockCount, r.TxCount)
+	if len(r.ConfigHeights) > 0 {
+		fmt.Fprintf(&sb, "config blocks to revert: %v\n", r.ConfigHeights)
+	}
+	for _, dir := range r.StoreDirs {
+		fmt.Fprintf(&sb, "ledger dir to rebuild: %s (backup: %s)\n", dir, dir+rollbackBackupSuffix)
+	}
+	for _, action := range r.WalActions {
+		fmt.Fprintf(&sb, "consensus wal: %s\n", action)
+	}
+	return sb.String()
+}
+
+// RollbackLedger rolls back the ledger of chain to height offline, the node must be stopped.
+// Blocks, states, tx indexes and history above height are dropped by rebuilding the ledger
+// from the blocks up to height, and the consensus wal entries above height are truncated.
+// With dryRun, nothing is changed and only the report is returned.
+func RollbackLedger(chainId string, height uint64, dryRun bool) (*RollbackReport, error) {
+	log := logger.GetLoggerByChain(logger.MODULE_STORAGE, chainId)
+	storeDirs, err := rollbackStoreDirs(chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := newRollbackStore(chainId, "", log)
+	if err != nil {
+		return nil, fmt.Errorf("open store failed, %s", err)
+	}
+	defer func() {
+		if src != nil {
+			src.Close()
+		}
+	}()
+
+	report, err := newRollbackReport(src, chainId, height)
+	if err != nil {
+		return nil, err
+	}
+	report.StoreDirs = storeDirs
+	chainDir := path.Join(localconf.ChainMakerConfig.GetStorePath(), chainId)
+	if report.WalActions, err = rollbackWal(chainDir, height, true); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	for _, dir := range storeDirs {
+		if err = checkNotExist(path.Dir(dir)+rollbackTmpSuffix, dir+rollbackBackupSuffix); err != nil {
+			return nil, err
+		}
+	}
+	if err = rebuildLedger(src, chainId, height, log); err != nil {
+		return nil, err
+	}
+	src.Close()
+	src = nil
+
+	// truncate the wal before moving it into the rebuilt chain dir
+	if _, err = rollbackWal(chainDir, height, false); err != nil {
+		return nil, err
+	}
+	for _, dir := range storeDirs {
+		// the rebuilt dir of chain is in the store path with tmp suffix
+		tmpRoot := path.Dir(dir) + rollbackTmpSuffix
+		tmpDir := path.Join(tmpRoot, chainId)
+		if dir == chainDir {
+			if err = moveWalDirs(dir, tmpDir); err != nil {
+				return nil, err
+			}
+		}
+		if err = os.Rename(dir, dir+rollbackBackupSuffix); err != nil {
+			return nil, err
+		}
+		if err = os.Rename(tmpDir, dir); err != nil {
+			return nil, err
+		}
+		if err = os.Remove(tmpRoot); err != nil {
+			log.Warnf("remove %s failed, %s", tmpRoot, err)
+		}
+	}
+	return report, nil
+}
+
+func newRollbackReport(src protocol.BlockchainStore, chainId string, height uint64) (*RollbackReport, error) {
+	lastBlock, err := src.GetLastBlock()
+	if err != nil {
+		return nil, fmt.Errorf("get last block failed, %s", err)
+	}
+	report := &RollbackReport{
+		ChainId:      chainId,
+		LastHeight:   lastBlock.Header.BlockHeight,
+		TargetHeight: height,
+	}
+	if height >= report.LastHeight {
+		return nil, fmt.Errorf("target height expect < %d, got %d", report.LastHeight, height)
+	}
+	for h := height + 1; h <= report.LastHeight; h++ {
+		block, err := src.GetBlock(h)
+		if err != nil {
+			return nil, fmt.Errorf("get block[%d] failed, %s", h, err)
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block[%d] not found", h)
+		}
+		report.BlockCount++
+		report.TxCount += len(block.Txs)
+		if utils.IsConfBlock(block) {
+			report.ConfigHeights = append(report.ConfigHeights, h)
+		}
+	}
+	return report, nil
+}
+
+// rebuildLedger writes the blocks up to height of src into a new store in the tmp dirs
+func rebuildLedger(src protocol.BlockchainStore, chainId string, height uint64, log protocol.Logger) error {
+	dst, err := newRollbackStore(chainId, rollbackTmpSuffix, log)
+	if err != nil {
+		return fmt.Errorf("create store failed, %s", err)
+	}
+	defer dst.Close()
+
+	for h := uint64(0); h <= height; h++ {
+		blockWithRWSets, err := src.GetBlockWithRWSets(h)
+		if err != nil {
+			return fmt.Errorf("get block[%d] failed, %s", h, err)
+		}
+		if blockWithRWSets == nil || blockWithRWSets.Block == nil {
+			return fmt.Errorf("block[%d] not found", h)
+		}
+		if h == 0 {
+			err = dst.InitGenesis(&storePb.BlockWithRWSet{
+				Block:          blockWithRWSets.Block,
+				TxRWSets:       blockWithRWSets.TxRWSets,
+				ContractEvents: blockWithRWSets.ContractEvents,
+			})
+		} else {
+			err = dst.PutBlock(blockWithRWSets.Block, blockWithRWSets.TxRWSets)
+		}
+		if err != nil {
+			return fmt.Errorf("put block[%d] failed, %s", h, err)
+		}
+		if h%1000 == 0 {
+			log.Infof("rollback rebuilt block[%d/%d]", h, height)
+		}
+	}
+	return nil
+}
+
+// newRollbackStore opens the store of chain, with suffix appended to all the store paths
+func newRollbackStore(chainId, suffix string, log protocol.Logger) (protocol.BlockchainStore, error) {
+	config := &conf.StorageConfig{}
+	if err := mapstructure.Decode(rollbackStorageConfig(suffix), config); err != nil {
+		return nil, err
+	}
+	p11Handle, err := localconf.ChainMakerConfig.GetP11Handle()
+	if err != nil {
+		return nil, err
+	}
+	var storeFactory store.Factory // nolint: typecheck
+	return storeFactory.NewStore(chainId, config, log, p11Handle)
+}
+
+// rollbackStorageConfig copies the storage config, with suffix appended to all the store paths
+func rollbackStorageConfig(suffix string) map[string]interface{} {
+	var copyConfig func(map[string]interface{}) map[string]interface{}
+	copyConfig = func(m map[string]interface{}) map[string]interface{} {
+		result := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			switch value := v.(type) {
+			case map[string]interface{}:
+				result[k] = copyConfig(value)
+			case string:
+				if k == storePathKey && suffix != "" {
+					value = path.Clean(value) + suffix
+				}
+				result[k] = value
+			default:
+				result[k] = v
+			}
+		}
+		return result
+	}
+	return copyConfig(localconf.ChainMakerConfig.StorageConfig)
+}
+
+// rollbackStoreDirs returns the dirs of chain in all the store paths, sql databases can't be rolled back
+func rollbackStoreDirs(chainId string) ([]string, error) {
+	var dirs []string
+	var walk func(map[string]interface{}) error
+	walk = func(m map[string]interface{}) error {
+		for k, v := range m {
+			switch value := v.(type) {
+			case map[string]interface{}:
+				if provider, ok := value["provider"].(string); ok && strings.EqualFold(provider, "sql") {
+					if disabled, _ := m["disable_"+strings.TrimSuffix(k, "_config")].(bool); !disabled {
+						return fmt.Errorf("%s with sql provider can't be rolled back", k)
+					}
+					continue
+				}
+				if err := walk(value); err != nil {
+					return err
+				}
+			case string:
+				if k == storePathKey && value != "" {
+					dirs = append(dirs, path.Join(value, chainId))
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(localconf.ChainMakerConfig.StorageConfig); err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err == nil {
+			result = append(result, dir)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no ledger dir of chain %s found", chainId)
+	}
+	return result, nil
+}
+
+// moveWalDirs moves the consensus wal dirs from chain dir src to dst
+func moveWalDirs(src, dst string) error {
+	for _, name := range []string{tbftWalDir, hotstuffWalDir, raftWalDir, raftSnapDir} {
+		if _, err := os.Stat(path.Join(src, name)); err != nil {
+			continue
+		}
+		if err := os.Rename(path.Join(src, name), path.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackWal truncates the consensus wal entries above height in chainDir, returns the actions.
+// With dryRun, the actions are only reported.
+func rollbackWal(chainDir string, height uint64, dryRun bool) ([]string, error) {
+	var actions []string
+	for _, w := range []struct {
+		name      string
+		getHeight func([]byte) (uint64, error)
+	}{{tbftWalDir, tbftWalEntryHeight}, {hotstuffWalDir, hotstuffWalEntryHeight}} {
+		dir := path.Join(chainDir, w.name)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		action, err := truncateWal(dir, height, w.getHeight, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("rollback wal %s failed, %s", dir, err)
+		}
+		actions = append(actions, action)
+	}
+
+	// the raft log can't be truncated below its commit index, so the raft state is removed
+	// and the raft cluster restarts from the rolled back ledger
+	for _, name := range []string{raftWalDir, raftSnapDir} {
+		dir := path.Join(chainDir, name)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		actions = append(actions, fmt.Sprintf("%s is removed, the raft state restarts from the ledger", dir))
+		if !dryRun {
+			if err := os.RemoveAll(dir); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return actions, nil
+}
+
+func truncateWal(dir string, height uint64, getHeight func([]byte) (uint64, error), dryRun bool) (string, error) {
+	walLog, err := wal.Open(dir, nil)
+	if err != nil {
+		return "", err
+	}
+	firstIndex, err := walLog.FirstIndex()
+	if err != nil {
+		walLog.Close()
+		return "", err
+	}
+	lastIndex, err := walLog.LastIndex()
+	if err != nil {
+		walLog.Close()
+		return "", err
+	}
+
+	// the first index of entries above height, entries are in the order of height
+	index := lastIndex + 1
+	for i := firstIndex; i <= lastIndex && lastIndex > 0; i++ {
+		entry, err := walLog.Read(i)
+		if err != nil {
+			walLog.Close()
+			return "", err
+		}
+		data, _, err := safewal.Decode(entry)
+		if err != nil {
+			walLog.Close()
+			return "", fmt.Errorf("read entry[%d] failed, %s", i, err)
+		}
+		h, err := getHeight(data)
+		if err != nil {
+			walLog.Close()
+			return "", fmt.Errorf("read entry[%d] failed, %s", i, err)
+		}
+		if h > height {
+			index = i
+			break
+		}
+	}
+
+	switch {
+	case lastIndex == 0 || index > lastIndex:
+		walLog.Close()
+		return fmt.Sprintf("%s has no entry above height %d", dir, height), nil
+	case index == firstIndex:
+		walLog.Close()
+		if !dryRun {
+			if err = os.RemoveAll(dir); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%s is removed, all the %d entries are above height %d",
+			dir, lastIndex-firstIndex+1, height), nil
+	default:
+		defer walLog.Close()
+		if !dryRun {
+			if err = walLog.TruncateBack(index - 1); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("%s truncates %d entries from index %d", dir, lastIndex-index+1, index), nil
+	}
+}
+
+func tbftWalEntryHeight(data []byte) (uint64, error) {
+	entry := &tbftpb.WalEntry{}
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return 0, err
+	}
+	return entry.Height, nil
+}
+
+func hotstuffWalEntryHeight(data []byte) (uint64, error) {
+	entry := &chainedbftpb.WalEntry{}
+	if err := proto.Unmarshal(data, entry); err != nil {
+		return 0, err
+	}
+	if entry.Msg == nil || entry.Msg.Payload == nil {
+		return 0, errors.New("empty consensus msg")
+	}
+	switch entry.Msg.Payload.Type {
+	case chainedbftpb.MessageType_PROPOSAL_MESSAGE:
+		return entry.Msg.Payload.GetProposalMsg().ProposalData.Height, nil
+	case chainedbftpb.MessageType_VOTE_MESSAGE:
+		return entry.Msg.Payload.GetVoteMsg().VoteData.Height, nil
+	}
+	return 0, fmt.Errorf("unknown msg type %s", entry.Msg.Payload.Type)
+}
+
+func checkNotExist(dirs ...string) error {
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err == nil {
+			return fmt.Errorf("%s exists, remove it before rollback", dir)
+		}
+	}
+	return nil
+}
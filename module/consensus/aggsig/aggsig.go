@@ -0,0 +1,266 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package aggsig is the BLS signatures on BLS12-381 which are aggregated into one signature, so that
+// a commit certificate of consensus is verified by one pairing check instead of a signature per validator.
+//
+// The signatures are points of G1 of 96 bytes and the public keys are points of G2 of 192 bytes, both in
+// the uncompressed form of go-ethereum bls12381. The signatures of the same message are aggregated by
+// adding them, and verified with the sum of the public keys of signers. A public key is registered with
+// a proof of possession, the signature of the key itself, which prevents the rogue keys that forge
+// an aggregate of other signers.
+package aggsig
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+const (
+	// PrivateKeySize is the size of private key in bytes
+	PrivateKeySize = 32
+	// PublicKeySize is the size of public key in bytes
+	PublicKeySize = 192
+	// SignatureSize is the size of signature in bytes
+	SignatureSize = 96
+
+	signDomain = "CHAINMAKER-BLS-SIG-BLS12381G1_XMD:SHA-256_SSWU_RO_"
+	popDomain  = "CHAINMAKER-BLS-POP-BLS12381G1_XMD:SHA-256_SSWU_RO_"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature is malformed or fails the verification
+	ErrInvalidSignature = errors.New("invalid aggregate signature")
+	// ErrInvalidKey is returned when a key is malformed
+	ErrInvalidKey = errors.New("invalid aggregate signature key")
+
+	// fieldModulus is the modulus of the base field of BLS12-381
+	fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6"+
+		"241eabfffeb153ffffb9feffffffffaaab", 16)
+)
+
+// PrivateKey is a BLS private key
+type PrivateKey struct {
+	x *big.Int
+}
+
+// PublicKey is a BLS public key
+type PublicKey struct {
+	p *bls.PointG2
+}
+
+// GenerateKey generates a random private key
+func GenerateKey() (*PrivateKey, error) {
+	order := bls.NewG1().Q()
+	for {
+		x, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, err
+		}
+		if x.Sign() > 0 {
+			return &PrivateKey{x: x}, nil
+		}
+	}
+}
+
+// PrivateKeyFromBytes parses the private key in bytes
+func PrivateKeyFromBytes(b []byte) (*PrivateKey, error) {
+	if len(b) != PrivateKeySize {
+		return nil, fmt.Errorf("%w: private key expect %d bytes, got %d", ErrInvalidKey, PrivateKeySize, len(b))
+	}
+	x := new(big.Int).SetBytes(b)
+	if x.Sign() == 0 || x.Cmp(bls.NewG1().Q()) >= 0 {
+		return nil, fmt.Errorf("%w: private key out of range", ErrInvalidKey)
+	}
+	return &PrivateKey{x: x}, nil
+}
+
+// LoadPrivateKey reads the private key in hex from file
+func LoadPrivateKey(file string) (*PrivateKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	return PrivateKeyFromBytes(b)
+}
+
+// Bytes returns the private key in bytes
+func (sk *PrivateKey) Bytes() []byte {
+	b := make([]byte, PrivateKeySize)
+	return sk.x.FillBytes(b)
+}
+
+// PublicKey returns the public key of sk
+func (sk *PrivateKey) PublicKey() *PublicKey {
+	g2 := bls.NewG2()
+	return &PublicKey{p: g2.MulScalar(g2.New(), g2.One(), sk.x)}
+}
+
+// Sign signs msg
+func (sk *PrivateKey) Sign(msg []byte) []byte {
+	return sk.sign(signDomain, msg)
+}
+
+// ProvePossession returns the proof of possession of sk, which is registered with the public key
+func (sk *PrivateKey) ProvePossession() []byte {
+	return sk.sign(popDomain, sk.PublicKey().Bytes())
+}
+
+func (sk *PrivateKey) sign(domain string, msg []byte) []byte {
+	g1 := bls.NewG1()
+	return g1.ToBytes(g1.MulScalar(g1.New(), hashToG1(g1, domain, msg), sk.x))
+}
+
+// PublicKeyFromBytes parses the public key in bytes
+func PublicKeyFromBytes(b []byte) (*PublicKey, error) {
+	if len(b) != PublicKeySize {
+		return nil, fmt.Errorf("%w: public key expect %d bytes, got %d", ErrInvalidKey, PublicKeySize, len(b))
+	}
+	g2 := bls.NewG2()
+	p, err := g2.FromBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	if g2.IsZero(p) || !g2.InCorrectSubgroup(p) {
+		return nil, fmt.Errorf("%w: public key not in G2", ErrInvalidKey)
+	}
+	return &PublicKey{p: p}, nil
+}
+
+// Bytes returns the public key in bytes
+func (pk *PublicKey) Bytes() []byte {
+	return bls.NewG2().ToBytes(pk.p)
+}
+
+// Equal returns true if pk and other are the same key
+func (pk *PublicKey) Equal(other *PublicKey) bool {
+	return other != nil && bls.NewG2().Equal(pk.p, other.p)
+}
+
+// Verify verifies the signature of msg
+func (pk *PublicKey) Verify(msg, sig []byte) error {
+	return verify(signDomain, pk.p, msg, sig)
+}
+
+// VerifyPossession verifies the proof of possession of pk
+func (pk *PublicKey) VerifyPossession(pop []byte) error {
+	return verify(popDomain, pk.p, pk.Bytes(), pop)
+}
+
+// Aggregate aggregates the signatures into one
+func Aggregate(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("%w: no signature to aggregate", ErrInvalidSignature)
+	}
+	g1 := bls.NewG1()
+	sum := g1.Zero()
+	for _, sig := range sigs {
+		p, err := signatureFromBytes(g1, sig)
+		if err != nil {
+			return nil, err
+		}
+		g1.Add(sum, sum, p)
+	}
+	return g1.ToBytes(sum), nil
+}
+
+// VerifyAggregate verifies the aggregate signature of msg signed by all of pks, with one pairing check
+func VerifyAggregate(pks []*PublicKey, msg, sig []byte) error {
+	if len(pks) == 0 {
+		return fmt.Errorf("%w: no signer", ErrInvalidSignature)
+	}
+	g2 := bls.NewG2()
+	sum := g2.Zero()
+	for _, pk := range pks {
+		g2.Add(sum, sum, pk.p)
+	}
+	return verify(signDomain, sum, msg, sig)
+}
+
+// verify checks e(sig, g2) == e(H(msg), pk)
+func verify(domain string, pk *bls.PointG2, msg, sig []byte) error {
+	g1 := bls.NewG1()
+	p, err := signatureFromBytes(g1, sig)
+	if err != nil {
+		return err
+	}
+	engine := bls.NewPairingEngine()
+	engine.AddPair(p, bls.NewG2().One())
+	engine.AddPairInv(hashToG1(g1, domain, msg), pk)
+	if !engine.Check() {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signatureFromBytes(g1 *bls.G1, sig []byte) (*bls.PointG1, error) {
+	if len(sig) != SignatureSize {
+		return nil, fmt.Errorf("%w: signature expect %d bytes, got %d", ErrInvalidSignature, SignatureSize, len(sig))
+	}
+	p, err := g1.FromBytes(sig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	if g1.IsZero(p) || !g1.InCorrectSubgroup(p) {
+		return nil, fmt.Errorf("%w: signature not in G1", ErrInvalidSignature)
+	}
+	return p, nil
+}
+
+// hashToG1 hashes msg to a point of G1, by mapping two field elements of expandMessage to the curve
+func hashToG1(g1 *bls.G1, domain string, msg []byte) *bls.PointG1 {
+	uniform := expandMessage(domain, msg, 128)
+	sum := g1.Zero()
+	for i := 0; i < 2; i++ {
+		u := new(big.Int).SetBytes(uniform[i*64 : (i+1)*64])
+		fe := make([]byte, 48)
+		p, err := g1.MapToCurve(u.Mod(u, fieldModulus).FillBytes(fe))
+		if err != nil {
+			// the field elements reduced by the modulus are always valid
+			panic(err)
+		}
+		g1.Add(sum, sum, p)
+	}
+	return sum
+}
+
+// expandMessage is expand_message_xmd of RFC 9380 with sha256, which returns size uniform bytes of msg
+func expandMessage(domain string, msg []byte, size int) []byte {
+	domainPrime := append([]byte(domain), byte(len(domain)))
+	h := sha256.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(size >> 8), byte(size), 0})
+	h.Write(domainPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, size)
+	bi := make([]byte, len(b0))
+	for i := 1; len(out) < size; i++ {
+		h.Reset()
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(domainPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:size]
+}
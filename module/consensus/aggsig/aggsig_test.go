@@ -0,0 +1,119 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package aggsig
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func generateKeys(t *testing.T, n int) ([]*PrivateKey, []*PublicKey) {
+	sks := make([]*PrivateKey, n)
+	pks := make([]*PublicKey, n)
+	for i := range sks {
+		var err error
+		sks[i], err = GenerateKey()
+		require.NoError(t, err)
+		pks[i] = sks[i].PublicKey()
+	}
+	return sks, pks
+}
+
+func TestSignVerify(t *testing.T) {
+	sks, pks := generateKeys(t, 2)
+	msg := []byte("commit block")
+	sig := sks[0].Sign(msg)
+	require.Len(t, sig, SignatureSize)
+	require.NoError(t, pks[0].Verify(msg, sig))
+	require.True(t, errors.Is(pks[0].Verify([]byte("other block"), sig), ErrInvalidSignature))
+	require.True(t, errors.Is(pks[1].Verify(msg, sig), ErrInvalidSignature))
+	require.True(t, errors.Is(pks[0].Verify(msg, sig[1:]), ErrInvalidSignature))
+	require.True(t, errors.Is(pks[0].Verify(msg, make([]byte, SignatureSize)), ErrInvalidSignature))
+
+	sk, err := PrivateKeyFromBytes(sks[0].Bytes())
+	require.NoError(t, err)
+	require.Equal(t, sig, sk.Sign(msg))
+	pk, err := PublicKeyFromBytes(pks[0].Bytes())
+	require.NoError(t, err)
+	require.True(t, pk.Equal(pks[0]))
+	require.False(t, pk.Equal(pks[1]))
+	_, err = PrivateKeyFromBytes(make([]byte, PrivateKeySize))
+	require.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = PublicKeyFromBytes(make([]byte, PublicKeySize))
+	require.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestVerifyAggregate(t *testing.T) {
+	sks, pks := generateKeys(t, 4)
+	msg := []byte("commit block")
+	sigs := make([][]byte, len(sks))
+	for i, sk := range sks {
+		sigs[i] = sk.Sign(msg)
+	}
+	aggregate, err := Aggregate(sigs[:3])
+	require.NoError(t, err)
+	require.NoError(t, VerifyAggregate(pks[:3], msg, aggregate))
+	require.True(t, errors.Is(VerifyAggregate(pks, msg, aggregate), ErrInvalidSignature))
+	require.True(t, errors.Is(VerifyAggregate(pks[1:], msg, aggregate), ErrInvalidSignature))
+	require.True(t, errors.Is(VerifyAggregate(pks[:3], []byte("other block"), aggregate), ErrInvalidSignature))
+	require.True(t, errors.Is(VerifyAggregate(nil, msg, aggregate), ErrInvalidSignature))
+
+	_, err = Aggregate(nil)
+	require.True(t, errors.Is(err, ErrInvalidSignature))
+	_, err = Aggregate([][]byte{sigs[0], []byte("invalid")})
+	require.True(t, errors.Is(err, ErrInvalidSignature))
+}
+
+func TestParseKeys(t *testing.T) {
+	sks, _ := generateKeys(t, 2)
+	registered := map[string]*RegisteredKey{
+		"node1": NewRegisteredKey(sks[0]),
+		"node2": NewRegisteredKey(sks[1]),
+	}
+	value, err := json.Marshal(registered)
+	require.NoError(t, err)
+	keys, err := ParseKeys(string(value))
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.True(t, keys["node1"].Equal(sks[0].PublicKey()))
+
+	// the proof of possession of another key
+	registered["node2"].Pop = registered["node1"].Pop
+	value, err = json.Marshal(registered)
+	require.NoError(t, err)
+	_, err = ParseKeys(string(value))
+	require.True(t, errors.Is(err, ErrInvalidKey))
+	// a signature is not a proof of possession
+	registered["node2"] = NewRegisteredKey(sks[1])
+	registered["node2"].Pop = hex.EncodeToString(sks[1].Sign(sks[1].PublicKey().Bytes()))
+	value, err = json.Marshal(registered)
+	require.NoError(t, err)
+	_, err = ParseKeys(string(value))
+	require.True(t, errors.Is(err, ErrInvalidKey))
+	// the key of another node
+	registered["node2"] = NewRegisteredKey(sks[0])
+	value, err = json.Marshal(registered)
+	require.NoError(t, err)
+	_, err = ParseKeys(string(value))
+	require.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = ParseKeys("{")
+	require.Error(t, err)
+}
+
+func TestBitmap(t *testing.T) {
+	b := NewBitmap(10)
+	require.Len(t, b, 2)
+	b.Set(0)
+	b.Set(9)
+	for i := 0; i < 20; i++ {
+		require.Equal(t, i == 0 || i == 9, b.Has(i), i)
+	}
+}
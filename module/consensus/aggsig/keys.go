@@ -0,0 +1,134 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package aggsig
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	// KeyFile is the file of the aggregation private key in hex, in the directory of the node key
+	KeyFile = "consensus.agg.key"
+
+	maxCachedKeySets = 16
+)
+
+// RegisteredKey is the public key of a node registered in chain config, with its proof of possession
+type RegisteredKey struct {
+	PublicKey string `json:"public_key"` // in hex
+	Pop       string `json:"pop"`        // in hex
+}
+
+// NewRegisteredKey returns the registered key of sk
+func NewRegisteredKey(sk *PrivateKey) *RegisteredKey {
+	return &RegisteredKey{
+		PublicKey: hex.EncodeToString(sk.PublicKey().Bytes()),
+		Pop:       hex.EncodeToString(sk.ProvePossession()),
+	}
+}
+
+// LoadNodeKey loads the aggregation key of the node with nodeKeyFile, nil if the node has no KeyFile
+func LoadNodeKey(nodeKeyFile string) (*PrivateKey, error) {
+	if nodeKeyFile == "" {
+		return nil, nil
+	}
+	key, err := LoadPrivateKey(filepath.Join(filepath.Dir(nodeKeyFile), KeyFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return key, err
+}
+
+var (
+	keySetsLock sync.Mutex
+	keySets     = make(map[string]map[string]*PublicKey) // config value => node id => key
+)
+
+// ParseKeys parses the json object of node id to RegisteredKey, and verifies the proofs of possession.
+// A public key registered for more than one node is rejected, otherwise a node registering the key and pop
+// of another validator counts toward the quorum whenever that validator signs.
+// The keys parsed are cached by value, since the keys of chain config are parsed for each block verified.
+func ParseKeys(value string) (map[string]*PublicKey, error) {
+	keySetsLock.Lock()
+	keys, ok := keySets[value]
+	keySetsLock.Unlock()
+	if ok {
+		return keys, nil
+	}
+
+	registered := make(map[string]*RegisteredKey)
+	if err := json.Unmarshal([]byte(value), &registered); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(registered))
+	for id := range registered {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	keys = make(map[string]*PublicKey, len(registered))
+	owners := make(map[string]string, len(registered)) // public key => node id
+	for _, id := range ids {
+		rk := registered[id]
+		if rk == nil {
+			return nil, fmt.Errorf("%w: no key of %s", ErrInvalidKey, id)
+		}
+		b, err := hex.DecodeString(rk.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: public key of %s, %s", ErrInvalidKey, id, err)
+		}
+		pk, err := PublicKeyFromBytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("public key of %s, %w", id, err)
+		}
+		// the key in the canonical form, since a point has several encodings
+		canonical := string(pk.Bytes())
+		if owner, ok := owners[canonical]; ok {
+			return nil, fmt.Errorf("%w: public key of %s is registered by %s too", ErrInvalidKey, id, owner)
+		}
+		owners[canonical] = id
+		pop, err := hex.DecodeString(rk.Pop)
+		if err != nil {
+			return nil, fmt.Errorf("%w: pop of %s, %s", ErrInvalidKey, id, err)
+		}
+		if err = pk.VerifyPossession(pop); err != nil {
+			return nil, fmt.Errorf("%w: pop of %s, %s", ErrInvalidKey, id, err)
+		}
+		keys[id] = pk
+	}
+
+	keySetsLock.Lock()
+	defer keySetsLock.Unlock()
+	if len(keySets) >= maxCachedKeySets {
+		keySets = make(map[string]map[string]*PublicKey)
+	}
+	keySets[value] = keys
+	return keys, nil
+}
+
+// Bitmap is the set of signers by their indexes in the validators
+type Bitmap []byte
+
+// NewBitmap returns an empty bitmap of n validators
+func NewBitmap(n int) Bitmap {
+	return make(Bitmap, (n+7)/8)
+}
+
+// Set adds the validator at index i
+func (b Bitmap) Set(i int) {
+	b[i/8] |= 1 << uint(i%8)
+}
+
+// Has returns true if the validator at index i is in the bitmap
+func (b Bitmap) Has(i int) bool {
+	return i/8 < len(b) && b[i/8]&(1<<uint(i%8)) != 0
+}
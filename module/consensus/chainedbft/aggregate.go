@@ -0,0 +1,334 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chainedbft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"chainmaker.org/chainmaker-go/consensus/aggsig"
+	"chainmaker.org/chainmaker-go/consensus/governance"
+	chainedbftextpb "chainmaker.org/chainmaker-go/pb/consensus/chainedbft"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	"chainmaker.org/chainmaker/common/v2/msgbus"
+	"chainmaker.org/chainmaker/localconf/v2"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	chainedbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/chainedbft"
+	"chainmaker.org/chainmaker/pb-go/v2/net"
+	"github.com/gogo/protobuf/proto"
+)
+
+// The QCs of committed blocks are certified by a BLS aggregate signature when
+// activation.HotStuffAggregateSignatures is active.
+//
+// A validator with an aggregation key registered in HotstuffAggregateKeys signs the QC message of the block
+// besides its vote, and broadcasts the signature to the other validators, since the votes are sent to the next
+// leader only. On commit, the signatures for the QC of a block reaching the quorum are aggregated into a
+// chainedbftextpb.AggregateQC, which is stored in the block with the QC without votes, so the block is verified
+// by one pairing check. The block carries the QC with votes as before if the signatures are not enough.
+//
+// The QC of the latest committed block is read from the block on start and on sync, so a QC without votes is
+// also verified by the AggregateQC of its block, which the nodes having committed the block have.
+//
+// The message type is out of the types defined in pb-go, so that the nodes before aggregation ignore it.
+const (
+	msgTypeQCSignature = chainedbftpb.MessageType(chainedbftextpb.MessageTypeExt_QC_SIGNATURE_MESSAGE)
+
+	// AggregateQCKey is the key in block additional data of chainedbftextpb.AggregateQC in protobuf
+	AggregateQCKey = "AggregateQC"
+
+	qcMessagePrefix    = "chainmaker-hotstuff-qc"
+	qcSignatureHeights = 10 // the signatures are kept for the heights above the committed one
+)
+
+// qcSignatureKey is the height and level of the votes signed
+type qcSignatureKey struct {
+	height uint64
+	level  uint64
+}
+
+// aggregateSignatures keeps the QC signatures of the uncommitted heights
+type aggregateSignatures struct {
+	key        *aggsig.PrivateKey                                         // nil if this node has no aggregation key
+	signatures map[qcSignatureKey]map[uint64]*chainedbftextpb.QCSignature // author index => signature
+}
+
+func newAggregateSignatures(key *aggsig.PrivateKey) *aggregateSignatures {
+	return &aggregateSignatures{
+		key:        key,
+		signatures: make(map[qcSignatureKey]map[uint64]*chainedbftextpb.QCSignature),
+	}
+}
+
+// prune drops the signatures of the heights committed
+func (a *aggregateSignatures) prune(height uint64) {
+	for key := range a.signatures {
+		if key.height <= height {
+			delete(a.signatures, key)
+		}
+	}
+}
+
+// loadAggregateKey loads the aggregation key of this node, nil if there is no key file
+func loadAggregateKey() (*aggsig.PrivateKey, error) {
+	return aggsig.LoadNodeKey(localconf.ChainMakerConfig.NodeConfig.PrivKeyFile)
+}
+
+// parseAggregateKeys parses the aggregation keys in consensus ext config, nil if they are not configured
+func parseAggregateKeys(chainConfig *config.ChainConfig) (map[string]*aggsig.PublicKey, error) {
+	var keys map[string]*aggsig.PublicKey
+	for _, kv := range chainConfig.Consensus.ExtConfig {
+		if kv.Key != governance.AggregateKeys {
+			continue
+		}
+		var err error
+		if keys, err = aggsig.ParseKeys(string(kv.Value)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", governance.AggregateKeys, err)
+		}
+	}
+	return keys, nil
+}
+
+// qcMessage returns the message signed for the QC of the block of blockId at height and level in epochId
+func qcMessage(chainId string, height, level, epochId uint64, blockId []byte) []byte {
+	msg := make([]byte, 0, len(qcMessagePrefix)+len(chainId)+len(blockId)+25)
+	msg = append(msg, qcMessagePrefix...)
+	msg = append(msg, byte(len(chainId)))
+	msg = append(msg, chainId...)
+	var buf [24]byte
+	binary.BigEndian.PutUint64(buf[:8], height)
+	binary.BigEndian.PutUint64(buf[8:16], level)
+	binary.BigEndian.PutUint64(buf[16:], epochId)
+	msg = append(msg, buf[:]...)
+	return append(msg, blockId...)
+}
+
+// isAggregateActive returns true if the QCs at height are certified by aggregate signatures
+func (cbi *ConsensusChainedBftImpl) isAggregateActive(height uint64) bool {
+	return activation.IsActive(cbi.chainConf.ChainConfig(), activation.HotStuffAggregateSignatures, height)
+}
+
+// sendQCSignature signs the QC of the block of proposal voted by this node,
+// and broadcasts the signature to the other validators
+func (cbi *ConsensusChainedBftImpl) sendQCSignature(proposal *chainedbftpb.ProposalData) {
+	key := cbi.aggregate.key
+	if key == nil || !cbi.isAggregateActive(proposal.Height) {
+		return
+	}
+	keys, err := parseAggregateKeys(cbi.chainConf.ChainConfig())
+	if err != nil {
+		cbi.logger.Errorf("service selfIndexInEpoch [%v] sendQCSignature: %s", cbi.selfIndexInEpoch, err)
+		return
+	}
+	if registered := keys[cbi.id]; !key.PublicKey().Equal(registered) {
+		cbi.logger.Warnf("service selfIndexInEpoch [%v] the aggregation key is not registered in %s",
+			cbi.selfIndexInEpoch, governance.AggregateKeys)
+		return
+	}
+
+	sig := &chainedbftextpb.QCSignature{
+		AuthorIdx: cbi.selfIndexInEpoch,
+		Author:    cbi.id,
+		Height:    proposal.Height,
+		Level:     proposal.Level,
+		EpochId:   cbi.smr.getEpochId(),
+		BlockId:   proposal.Block.Header.BlockHash,
+	}
+	sig.Signature = key.Sign(qcMessage(cbi.chainID, sig.Height, sig.Level, sig.EpochId, sig.BlockId))
+	cbi.addQCSignature(sig)
+
+	data, err := proto.Marshal(&chainedbftpb.ConsensusMsg{
+		Payload: &chainedbftpb.ConsensusPayload{Type: msgTypeQCSignature},
+	})
+	if err != nil {
+		cbi.logger.Errorf("marshal qc signature msg failed, err %v", err)
+		return
+	}
+	ext, err := proto.Marshal(&chainedbftextpb.ConsensusMsgExt{QcSignature: sig})
+	if err != nil {
+		cbi.logger.Errorf("marshal qc signature failed, err %v", err)
+		return
+	}
+	data = append(data, ext...)
+	for _, peer := range cbi.smr.peers() {
+		if peer.index == cbi.selfIndexInEpoch {
+			continue
+		}
+		go cbi.msgbus.Publish(msgbus.SendConsensusMsg, &net.NetMsg{
+			Payload: data,
+			Type:    net.NetMsg_CONSENSUS_MSG,
+			To:      peer.id,
+		})
+	}
+}
+
+// processQCSignature verifies the QC signature in the consensus msg of payload sent by a validator
+func (cbi *ConsensusChainedBftImpl) processQCSignature(payload []byte) {
+	ext := &chainedbftextpb.ConsensusMsgExt{}
+	if err := proto.Unmarshal(payload, ext); err != nil || ext.QcSignature == nil {
+		cbi.logger.Warnf("service selfIndexInEpoch [%v] received invalid qc signature msg, err %v",
+			cbi.selfIndexInEpoch, err)
+		return
+	}
+	sig := ext.QcSignature
+
+	cbi.mtx.Lock()
+	defer cbi.mtx.Unlock()
+	if !cbi.isAggregateActive(sig.Height) || sig.EpochId != cbi.smr.getEpochId() || sig.Level == 0 ||
+		sig.Height <= cbi.commitHeight || sig.Height > cbi.commitHeight+qcSignatureHeights {
+		return
+	}
+	if peer := cbi.smr.getPeerByIndex(sig.AuthorIdx); peer == nil || peer.id != sig.Author {
+		cbi.logger.Warnf("service selfIndexInEpoch [%v] received qc signature from invalid peer: %d",
+			cbi.selfIndexInEpoch, sig.AuthorIdx)
+		return
+	}
+	keys, err := parseAggregateKeys(cbi.chainConf.ChainConfig())
+	if err != nil {
+		return
+	}
+	pk, ok := keys[sig.Author]
+	if !ok {
+		return
+	}
+	msg := qcMessage(cbi.chainID, sig.Height, sig.Level, sig.EpochId, sig.BlockId)
+	if err = pk.Verify(msg, sig.Signature); err != nil {
+		cbi.logger.Warnf("service selfIndexInEpoch [%v] received qc signature of [%v] at [%d:%d], %v",
+			cbi.selfIndexInEpoch, sig.AuthorIdx, sig.Height, sig.Level, err)
+		return
+	}
+	cbi.addQCSignature(sig)
+}
+
+// addQCSignature adds the verified signature, only the first one of an author at a level is kept
+func (cbi *ConsensusChainedBftImpl) addQCSignature(sig *chainedbftextpb.QCSignature) {
+	key := qcSignatureKey{height: sig.Height, level: sig.Level}
+	signatures, ok := cbi.aggregate.signatures[key]
+	if !ok {
+		signatures = make(map[uint64]*chainedbftextpb.QCSignature)
+		cbi.aggregate.signatures[key] = signatures
+	}
+	if _, ok = signatures[sig.AuthorIdx]; !ok {
+		signatures[sig.AuthorIdx] = sig
+	}
+}
+
+// aggregateQCSignatures aggregates the QC signatures for the block of qc,
+// nil if the signers do not reach the quorum
+func (cbi *ConsensusChainedBftImpl) aggregateQCSignatures(qc *chainedbftpb.QuorumCert) *chainedbftextpb.AggregateQC {
+	if qc.NewView || qc.Level == 0 || !cbi.isAggregateActive(qc.Height) {
+		return nil
+	}
+	signatures := cbi.aggregate.signatures[qcSignatureKey{height: qc.Height, level: qc.Level}]
+	var maxIdx uint64
+	var matched []*chainedbftextpb.QCSignature
+	for _, sig := range signatures {
+		if sig.EpochId != qc.EpochId || !bytes.Equal(sig.BlockId, qc.BlockId) {
+			continue
+		}
+		matched = append(matched, sig)
+		if sig.AuthorIdx > maxIdx {
+			maxIdx = sig.AuthorIdx
+		}
+	}
+	if len(matched) < cbi.smr.min(qc.Height) {
+		cbi.logger.Infof("service selfIndexInEpoch [%v] qc signatures of %d validators at [%d:%d] "+
+			"are not enough to aggregate", cbi.selfIndexInEpoch, len(matched), qc.Height, qc.Level)
+		return nil
+	}
+	signers := aggsig.NewBitmap(int(maxIdx) + 1)
+	sigs := make([][]byte, 0, len(matched))
+	for _, sig := range matched {
+		signers.Set(int(sig.AuthorIdx))
+		sigs = append(sigs, sig.Signature)
+	}
+	aggregate, err := aggsig.Aggregate(sigs)
+	if err != nil {
+		cbi.logger.Errorf("service selfIndexInEpoch [%v] aggregate qc signatures at [%d:%d] failed, %v",
+			cbi.selfIndexInEpoch, qc.Height, qc.Level, err)
+		return nil
+	}
+	return &chainedbftextpb.AggregateQC{Signers: signers, Signature: aggregate}
+}
+
+// verifyQCByAggregate verifies the qc without votes by the aggregate qc of its block,
+// with the validators of the current epoch
+func (cbi *ConsensusChainedBftImpl) verifyQCByAggregate(qc *chainedbftpb.QuorumCert, block *common.Block) error {
+	validators := make(map[uint64]string)
+	for _, peer := range cbi.smr.peers() {
+		validators[peer.index] = peer.id
+	}
+	return verifyBlockAggregateQC(cbi.chainConf.ChainConfig(), qc, block, validators, cbi.smr.min(qc.Height))
+}
+
+// isAggregateCertified returns true if qc is a QC of block certified by an aggregate qc instead of votes
+func isAggregateCertified(qc *chainedbftpb.QuorumCert) bool {
+	return qc.Level > 0 && !qc.NewView && len(qc.Votes) == 0
+}
+
+// verifyBlockAggregateQC verifies the qc without votes by the aggregate qc in block,
+// with the validators of the epoch of qc by index
+func verifyBlockAggregateQC(chainConfig *config.ChainConfig, qc *chainedbftpb.QuorumCert, block *common.Block,
+	validators map[uint64]string, quorum int) error {
+	if block == nil || !bytes.Equal(block.GetHeader().GetBlockHash(), qc.BlockId) {
+		return fmt.Errorf("no block of qc without votes [%d:%d:%x]", qc.Height, qc.Level, qc.BlockId)
+	}
+	data, ok := block.GetAdditionalData().GetExtraData()[AggregateQCKey]
+	if !ok {
+		return fmt.Errorf("no votes or aggregate qc in qc [%d:%d:%x]", qc.Height, qc.Level, qc.BlockId)
+	}
+	if !activation.IsActive(chainConfig, activation.HotStuffAggregateSignatures, qc.Height) {
+		return fmt.Errorf("aggregate qc before the activation of %s", activation.HotStuffAggregateSignatures)
+	}
+	return verifyAggregateQC(chainConfig, qc, data, validators, quorum)
+}
+
+// verifyAggregateQC verifies the aggregate qc in data for qc, with the validators of the epoch of qc by index
+func verifyAggregateQC(chainConfig *config.ChainConfig, qc *chainedbftpb.QuorumCert, data []byte,
+	validators map[uint64]string, quorum int) error {
+	aggregate := &chainedbftextpb.AggregateQC{}
+	if err := proto.Unmarshal(data, aggregate); err != nil {
+		return fmt.Errorf("unmarshal aggregate qc failed, %s", err)
+	}
+	keys, err := parseAggregateKeys(chainConfig)
+	if err != nil {
+		return err
+	}
+
+	var maxIdx uint64
+	for idx := range validators {
+		if idx > maxIdx {
+			maxIdx = idx
+		}
+	}
+	signers := aggsig.Bitmap(aggregate.Signers)
+	if len(signers) > len(aggsig.NewBitmap(int(maxIdx)+1)) {
+		return fmt.Errorf("aggregate qc signers of %d bytes, expect max validator index %d", len(signers), maxIdx)
+	}
+	var pks []*aggsig.PublicKey
+	for i := 0; i < len(signers)*8; i++ {
+		if !signers.Has(i) {
+			continue
+		}
+		validator, ok := validators[uint64(i)]
+		if !ok {
+			return fmt.Errorf("aggregate qc signer %d is not a validator", i)
+		}
+		pk, ok := keys[validator]
+		if !ok {
+			return fmt.Errorf("aggregation key of %s is not registered", validator)
+		}
+		pks = append(pks, pk)
+	}
+	if len(pks) < quorum {
+		return fmt.Errorf("aggregate qc signers [%v] less than expected [%v]", len(pks), quorum)
+	}
+	msg := qcMessage(chainConfig.ChainId, qc.Height, qc.Level, qc.EpochId, qc.BlockId)
+	return aggsig.VerifyAggregate(pks, msg, aggregate.Signature)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chainedbft
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"chainmaker.org/chainmaker-go/consensus/aggsig"
+	"chainmaker.org/chainmaker-go/consensus/governance"
+	chainedbftextpb "chainmaker.org/chainmaker-go/pb/consensus/chainedbft"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	chainedbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/chainedbft"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAggregateQC(t *testing.T) {
+	validators := map[uint64]string{0: "node1", 1: "node2", 2: "node3", 3: "node4"}
+	sks := make(map[uint64]*aggsig.PrivateKey)
+	registered := make(map[string]*aggsig.RegisteredKey)
+	for idx, validator := range validators {
+		sk, err := aggsig.GenerateKey()
+		require.NoError(t, err)
+		sks[idx] = sk
+		registered[validator] = aggsig.NewRegisteredKey(sk)
+	}
+	keys, err := json.Marshal(registered)
+	require.NoError(t, err)
+	chainConfig := &configpb.ChainConfig{
+		ChainId: "chain1",
+		Consensus: &configpb.ConsensusConfig{
+			ExtConfig: []*configpb.ConfigKeyValue{{Key: governance.AggregateKeys, Value: string(keys)}},
+		},
+	}
+	qc := &chainedbftpb.QuorumCert{BlockId: []byte("block hash"), Height: 10, Level: 12, EpochId: 1}
+
+	makeAggregate := func(qc *chainedbftpb.QuorumCert, signers ...uint64) []byte {
+		bitmap := aggsig.NewBitmap(len(validators))
+		var sigs [][]byte
+		for _, idx := range signers {
+			bitmap.Set(int(idx))
+			sigs = append(sigs, sks[idx].Sign(qcMessage(chainConfig.ChainId, qc.Height, qc.Level, qc.EpochId,
+				qc.BlockId)))
+		}
+		sig, err := aggsig.Aggregate(sigs)
+		require.NoError(t, err)
+		data, err := proto.Marshal(&chainedbftextpb.AggregateQC{Signers: bitmap, Signature: sig})
+		require.NoError(t, err)
+		return data
+	}
+
+	require.NoError(t, verifyAggregateQC(chainConfig, qc, makeAggregate(qc, 0, 2, 3), validators, 3))
+	require.Error(t, verifyAggregateQC(chainConfig, qc, makeAggregate(qc, 0, 3), validators, 3))
+
+	// signed for another level
+	other := &chainedbftpb.QuorumCert{BlockId: qc.BlockId, Height: qc.Height, Level: qc.Level + 1, EpochId: 1}
+	err = verifyAggregateQC(chainConfig, qc, makeAggregate(other, 0, 1, 2), validators, 3)
+	require.True(t, errors.Is(err, aggsig.ErrInvalidSignature))
+
+	// a signer out of the validators
+	delete(validators, 1)
+	require.Error(t, verifyAggregateQC(chainConfig, qc, makeAggregate(qc, 0, 1, 2), validators, 3))
+	validators[1] = "node2"
+
+	// a signer without registered key
+	delete(registered, "node2")
+	keys, err = json.Marshal(registered)
+	require.NoError(t, err)
+	chainConfig.Consensus.ExtConfig[0].Value = string(keys)
+	require.Error(t, verifyAggregateQC(chainConfig, qc, makeAggregate(qc, 0, 1, 2), validators, 3))
+}
+
+func TestVerifyBlockAggregateQC(t *testing.T) {
+	qc := &chainedbftpb.QuorumCert{BlockId: []byte("block hash"), Height: 10, Level: 12}
+	require.True(t, isAggregateCertified(qc))
+	require.False(t, isAggregateCertified(&chainedbftpb.QuorumCert{Level: 12, NewView: true}))
+	require.False(t, isAggregateCertified(&chainedbftpb.QuorumCert{BlockId: qc.BlockId}))
+
+	chainConfig := &configpb.ChainConfig{ChainId: "chain1", Consensus: &configpb.ConsensusConfig{}}
+	block := &common.Block{
+		Header:         &common.BlockHeader{BlockHeight: 10, BlockHash: []byte("other hash")},
+		AdditionalData: &common.AdditionalData{ExtraData: map[string][]byte{AggregateQCKey: []byte("aggregate")}},
+	}
+	require.Error(t, verifyBlockAggregateQC(chainConfig, qc, nil, nil, 3))
+	require.Error(t, verifyBlockAggregateQC(chainConfig, qc, block, nil, 3))
+	block.Header.BlockHash = qc.BlockId
+	// the feature is not active
+	require.Error(t, verifyBlockAggregateQC(chainConfig, qc, block, nil, 3))
+	delete(block.AdditionalData.ExtraData, AggregateQCKey)
+	require.Error(t, verifyBlockAggregateQC(chainConfig, qc, block, nil, 3))
+}
+
+func TestAggregateSignaturesPrune(t *testing.T) {
+	a := newAggregateSignatures(nil)
+	for height := uint64(1); height <= 3; height++ {
+		a.signatures[qcSignatureKey{height: height, level: height}] = nil
+	}
+	a.prune(2)
+	require.Len(t, a.signatures, 1)
+	_, ok := a.signatures[qcSignatureKey{height: 3, level: 3}]
+	require.True(t, ok)
+}
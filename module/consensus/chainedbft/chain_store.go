@@ -181,7 +181,19 @@ func (cs *chainStore) commitBlock(block *common.Block) (lastCommitted *common.Bl
 		if qc = cs.blockPool.GetQCByID(string(blk.GetHeader().GetBlockHash())); qc == nil {
 			return lastCommitted, lastCommittedLevel, fmt.Errorf("commit block failed, get qc for block is nil")
 		}
-		if qcData, err = proto.Marshal(qc); err != nil {
+		// the block is certified by the aggregate qc instead of the votes if the signatures are enough
+		aggregate := cs.server.aggregateQCSignatures(qc)
+		storedQC := qc
+		if aggregate != nil {
+			storedQC = &chainedbftpb.QuorumCert{
+				BlockId: qc.BlockId,
+				Height:  qc.Height,
+				Level:   qc.Level,
+				NewView: qc.NewView,
+				EpochId: qc.EpochId,
+			}
+		}
+		if qcData, err = proto.Marshal(storedQC); err != nil {
 			return lastCommitted, lastCommittedLevel, fmt.Errorf("commit block failed, marshal qc at height [%v], err %v",
 				blk.GetHeader().GetBlockHeight(), err)
 		}
@@ -198,6 +210,12 @@ func (cs *chainStore) commitBlock(block *common.Block) (lastCommitted *common.Bl
 			cs.logger.Errorf("commit block failed, add qc to block err, %v", err)
 			return lastCommitted, lastCommittedLevel, err
 		}
+		if aggregate != nil {
+			if newBlock.AdditionalData.ExtraData[AggregateQCKey], err = proto.Marshal(aggregate); err != nil {
+				return lastCommitted, lastCommittedLevel, fmt.Errorf("commit block failed, marshal aggregate "+
+					"qc at height [%v], err %v", blk.GetHeader().GetBlockHeight(), err)
+			}
+		}
 		if err = cs.blockCommitter.AddBlock(newBlock); err == commonErrors.ErrBlockHadBeenCommited {
 			hadCommitBlock, getBlockErr := cs.blockChainStore.GetBlock(newBlock.GetHeader().GetBlockHeight())
 			if getBlockErr != nil {
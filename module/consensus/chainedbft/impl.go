@@ -19,9 +19,9 @@ import (
 	"chainmaker.org/chainmaker-go/consensus/chainedbft/types"
 	"chainmaker.org/chainmaker-go/consensus/chainedbft/utils"
 	"chainmaker.org/chainmaker-go/consensus/governance"
+	"chainmaker.org/chainmaker-go/consensus/safewal"
 	"chainmaker.org/chainmaker/chainconf/v2"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
-	"chainmaker.org/chainmaker/common/v2/wal"
 	"chainmaker.org/chainmaker/localconf/v2"
 	"chainmaker.org/chainmaker/logger/v2"
 	"chainmaker.org/chainmaker/pb-go/v2/common"
@@ -59,7 +59,7 @@ type ConsensusChainedBftImpl struct {
 	lastCommitWalIndex uint64
 
 	// wal info
-	wal              *wal.Log
+	wal              *safewal.Log
 	proposalWalIndex sync.Map
 	doneReplayWal    bool
 
@@ -69,6 +69,7 @@ type ConsensusChainedBftImpl struct {
 	msgPool    *message.MsgPool // manages all of consensus messages received for protocol
 	chainStore *chainStore      // Cache blocks, status information of QC,
 	// and the process of the commit blocks on the chain
+	aggregate *aggregateSignatures // The QC signatures to aggregate
 
 	timerService *timeservice.TimerService // Timer service
 
@@ -140,6 +141,11 @@ func New(chainID string, id string, singer protocol.SigningMember, ac protocol.A
 		return nil, err
 	}
 
+	aggregateKey, err := loadAggregateKey()
+	if err != nil {
+		return nil, fmt.Errorf("load aggregation key failed, %v", err)
+	}
+	service.aggregate = newAggregateSignatures(aggregateKey)
 	service.chainStore = chainStore
 	service.syncer = newSyncManager(service)
 	service.timerService = timeservice.NewTimerService(service.logger)
@@ -151,9 +157,13 @@ func New(chainID string, id string, singer protocol.SigningMember, ac protocol.A
 	epoch := service.nextEpoch
 	service.nextEpoch = nil
 	walDirPath := path.Join(localconf.ChainMakerConfig.GetStorePath(), chainID, WalDirSuffix)
-	if service.wal, err = wal.Open(walDirPath, nil); err != nil {
+	var repairs []string
+	if service.wal, repairs, err = safewal.Open(walDirPath); err != nil {
 		return nil, err
 	}
+	for _, repair := range repairs {
+		service.logger.Warnf("repair wal %s: %s", walDirPath, repair)
+	}
 	service.logger.Debugf("init epoch, epochID: %d, index: %d, createHeight: %d",
 		epoch.epochId, epoch.index, epoch.createHeight)
 	_ = chainconf.RegisterVerifier(chainID, consensus.ConsensusType_HOTSTUFF,
@@ -355,6 +365,10 @@ func (cbi *ConsensusChainedBftImpl) onReceivedMsg(msg *net.NetMsg) {
 			"from remote peer id [%v] add %v", cbi.selfIndexInEpoch, msg.Type, msg.To)
 		return
 	}
+	if consensusMsg.Payload.Type == msgTypeQCSignature {
+		cbi.processQCSignature(msg.Payload)
+		return
+	}
 	if err := message.ValidateMessageBasicInfo(consensusMsg.Payload); err != nil {
 		cbi.logger.Errorf("service selfIndexInEpoch [%v] failed to validate msg basic info, err %v",
 			cbi.selfIndexInEpoch, err)
@@ -476,6 +490,9 @@ func (cbi *ConsensusChainedBftImpl) VerifyBlockSignatures(block *common.Block) e
 		return fmt.Errorf("wrong qc block id [%v], expected [%v]",
 			qc.BlockId, BlockId)
 	}
+	if isAggregateCertified(qc) {
+		return cbi.verifyQCByAggregate(qc, block)
+	}
 	if newViewNum, votedBlockNum, err = cbi.countNumFromVotes(qc); err != nil {
 		return err
 	}
@@ -573,6 +590,14 @@ func VerifyBlockSignatures(chainConf protocol.ChainConf, ac protocol.AccessContr
 	} else {
 		return fmt.Errorf("validator invalid")
 	}
+	if isAggregateCertified(qc) {
+		validators := make(map[uint64]string, len(curValidators))
+		for _, v := range curValidators {
+			validators[v.Index] = v.NodeID
+		}
+		return verifyBlockAggregateQC(chainConf.ChainConfig(), qc, block, validators,
+			int(governanceContract.GetGovMembersValidatorMinCount()))
+	}
 
 	newViewNum, votedBlockNum, err := countNumFromVotes(qc, curValidators, ac)
 	if err != nil {
@@ -215,6 +215,9 @@ func (cbi *ConsensusChainedBftImpl) verifyJustifyQC(qc *chainedbftpb.QuorumCert)
 			"epoch id [%v],need [%v]", cbi.selfIndexInEpoch, qc.EpochId, cbi.smr.getEpochId())
 		return fmt.Errorf("invalid epoch id in qc")
 	}
+	if isAggregateCertified(qc) {
+		return cbi.verifyQCByAggregate(qc, cbi.chainStore.getBlockByHash(qc.BlockId))
+	}
 	newViewNum, votedBlockNum, err := cbi.countNumFromVotes(qc)
 	if err != nil {
 		return err
@@ -369,9 +372,16 @@ func (cbi *ConsensusChainedBftImpl) validateBlockPair(fromPeer uint64,
 	if !bytes.Equal(preQC.BlockId, blkHeader.PreBlockHash) {
 		return fmt.Errorf("preBlock id[%x] not equal preQC id[%x]", blkHeader.PreBlockHash, qc.BlockId)
 	}
-	if err := cbi.verifyJustifyQC(qc); err != nil {
+	// the qc of a committed block fetched is verified by the aggregate qc in the block
+	var verifyErr error
+	if isAggregateCertified(qc) {
+		verifyErr = cbi.verifyQCByAggregate(qc, blockPair.Block)
+	} else {
+		verifyErr = cbi.verifyJustifyQC(qc)
+	}
+	if verifyErr != nil {
 		return fmt.Errorf("server selfIndexInEpoch [%v] validate qc "+
-			"[%v:%v] failed, err %v", cbi.selfIndexInEpoch, qc.Height, qc.Level, err)
+			"[%v:%v] failed, err %v", cbi.selfIndexInEpoch, qc.Height, qc.Level, verifyErr)
 	}
 	if qc.Height != uint64(blkHeader.GetBlockHeight()) {
 		return fmt.Errorf("server selfIndexInEpoch [%v] mismatch block "+
@@ -537,6 +547,7 @@ func (cbi *ConsensusChainedBftImpl) generateVoteAndSend(proposal *chainedbftpb.P
 	cbi.logger.Debugf("service selfIndexInEpoch [%v] processProposal step6 send vote msg to next leader start",
 		cbi.selfIndexInEpoch)
 	cbi.sendVote2Next(proposal, vote)
+	cbi.sendQCSignature(proposal)
 	return nil
 }
 
@@ -948,6 +959,7 @@ func (cbi *ConsensusChainedBftImpl) commitBlocksByQC(qc *chainedbftpb.QuorumCert
 			cbi.smr.setLastCommittedBlock(lastCommittedBlock, lastCommitLevel)
 			cbi.logger.Debugf("on block sealed, blockHeight: %d", lastCommittedBlock.Header.BlockHeight)
 			cbi.msgPool.OnBlockSealed(uint64(lastCommittedBlock.Header.BlockHeight))
+			cbi.aggregate.prune(lastCommittedBlock.Header.BlockHeight)
 		}
 		if err != nil {
 			cbi.logger.Errorf("commit block to the chain failed, reason: %s", err)
@@ -977,6 +989,7 @@ func (cbi *ConsensusChainedBftImpl) processBlockCommitted(block *common.Block) {
 	cbi.logger.Debugf("processBlockCommitted step 2 update the last committed block info")
 	cbi.commitHeight = uint64(block.Header.BlockHeight)
 	cbi.msgPool.OnBlockSealed(uint64(block.Header.BlockHeight))
+	cbi.aggregate.prune(block.Header.BlockHeight)
 	cbi.smr.setLastCommittedBlock(block, cbi.chainStore.getCommitLevel())
 	cbi.updateWalIndexAndTruncFile(block.Header.BlockHeight)
 	// 4. create next epoch if meet the condition
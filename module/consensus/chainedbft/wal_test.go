@@ -13,6 +13,7 @@ import (
 
 	"chainmaker.org/chainmaker-go/consensus/chainedbft/liveness"
 	"chainmaker.org/chainmaker-go/consensus/chainedbft/message"
+	"chainmaker.org/chainmaker-go/consensus/safewal"
 	"chainmaker.org/chainmaker/common/v2/wal"
 	"chainmaker.org/chainmaker/logger/v2"
 	chainedbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/chainedbft"
@@ -75,7 +76,7 @@ func TestSaveWal(t *testing.T) {
 		msgPool: message.NewMsgPool(10, 10, 3),
 	}
 	dirPath := filepath.Join("./", "test_chain", WalDirSuffix)
-	walFile, err := wal.Open(dirPath, nil)
+	walFile, _, err := safewal.Open(dirPath)
 	defer os.RemoveAll(dirPath)
 	require.NoError(t, err)
 	cbi.wal = walFile
@@ -76,6 +76,7 @@ func (f Factory) NewConsensusEngine(
 			BlockCommitter: blockCommitter,
 			ChainConf:      chainConf,
 			MsgBus:         msgBus,
+			Store:          store,
 		}
 		return raft.New(config)
 	case consensuspb.ConsensusType_HOTSTUFF:
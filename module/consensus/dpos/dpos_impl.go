@@ -217,6 +217,14 @@ func (impl *DPoSImpl) VerifyConsensusArgs(block *common.Block, blockTxRwSet map[
 		"localConsensus: %v by seed: %x", consensusArgs, localConsensus, block.Header.PreBlockHash)
 }
 
+// GetValidatorWeights returns the voting weights of the validators of the current epoch by node id
+func (impl *DPoSImpl) GetValidatorWeights() (map[string]uint64, error) {
+	if !impl.isDPoSConsensus() {
+		return nil, nil
+	}
+	return GetValidatorWeightsFromLedger(impl.stateDB)
+}
+
 func (impl *DPoSImpl) GetValidators() ([]string, error) {
 	if !impl.isDPoSConsensus() {
 		return nil, nil
@@ -9,6 +9,7 @@ package dpos
 import (
 	"encoding/binary"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"sort"
 	"strings"
@@ -145,6 +146,53 @@ func GetNodeIDsFromValidators(store protocol.BlockchainStore, validators []strin
 	return nodeIDs, nil
 }
 
+// ValidatorWeightScale is the total voting weight shared by the validators of an epoch in proportion to their tokens
+const ValidatorWeightScale = 10000
+
+// GetValidatorWeightsFromLedger returns the voting weights of the validators of the latest epoch by node id.
+// The weights are in proportion to the tokens of validators, scaled to a total of about ValidatorWeightScale,
+// and at least 1 each.
+func GetValidatorWeightsFromLedger(store protocol.BlockchainStore) (map[string]uint64, error) {
+	epoch, err := GetLatestEpochInfo(store)
+	if err != nil {
+		return nil, err
+	}
+	nodeIDs, err := GetNodeIDsFromValidators(store, epoch.ProposerVector)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]*big.Int, 0, len(epoch.ProposerVector))
+	total := big.NewInt(0)
+	for _, address := range epoch.ProposerVector {
+		bz, err := store.ReadObject(syscontract.SystemContract_DPOS_STAKE.String(), dposmgr.ToValidatorKey(address))
+		if err != nil {
+			return nil, fmt.Errorf("read validator[%s] failed, reason: %s", address, err)
+		}
+		validator := syscontract.Validator{}
+		if err = proto.Unmarshal(bz, &validator); err != nil {
+			return nil, fmt.Errorf("unmarshal validator[%s] failed, reason: %s", address, err)
+		}
+		token, ok := big.NewInt(0).SetString(validator.Tokens, 10)
+		if !ok || token.Sign() < 0 {
+			return nil, fmt.Errorf("invalid tokens of validator[%s]: %s", address, validator.Tokens)
+		}
+		tokens = append(tokens, token)
+		total.Add(total, token)
+	}
+	weights := make(map[string]uint64, len(nodeIDs))
+	for i, nodeID := range nodeIDs {
+		weight := uint64(1)
+		if total.Sign() > 0 {
+			scaled := big.NewInt(0).Mul(tokens[i], big.NewInt(ValidatorWeightScale))
+			if scaled.Div(scaled, total).Uint64() > weight {
+				weight = scaled.Uint64()
+			}
+		}
+		weights[nodeID] = weight
+	}
+	return weights, nil
+}
+
 func GetChainConfig(store protocol.BlockchainStore) (*configPb.ChainConfig, error) {
 	var chainConfig configPb.ChainConfig
 	bytes, err := store.ReadObject(
@@ -0,0 +1,328 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package evidence is the equivocation evidence of TBFT validators. An evidence is a pair of
+// conflicting votes signed by the same validator, for different blocks or part sets of the same height,
+// round and vote type. It can be verified by anyone with the signatures of the votes only.
+//
+// The evidence detected or received by a node is kept in the pool of chain, and included in a
+// later block by the proposer as a tx to ContractName, which records it in state under Key.
+// The evidence recorded can be listed from state, for the DPoS or governance to act on.
+package evidence
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/common/v2/helper"
+	pbac "chainmaker.org/chainmaker/pb-go/v2/accesscontrol"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	// ContractName is the contract of evidence txs, which is executed by scheduler directly
+	ContractName = "TBFT_EVIDENCE"
+	// MethodRecord is the method of evidence txs
+	MethodRecord = "RECORD"
+	// ParamEvidence is the parameter of evidence txs, the evidence in protobuf
+	ParamEvidence = "EVIDENCE"
+
+	// keyPrefix is the prefix of the evidence keys in state of ContractName
+	keyPrefix = "e_"
+	// maxPending is the max number of evidence in pool, the later ones are dropped
+	maxPending = 1000
+)
+
+// Evidence is a pair of conflicting votes of a validator, with the part set roots they are signed with
+type Evidence struct {
+	VoteA *tbftpb.Vote `json:"vote_a"`
+	VoteB *tbftpb.Vote `json:"vote_b"`
+	RootA []byte       `json:"part_set_root_a,omitempty"`
+	RootB []byte       `json:"part_set_root_b,omitempty"`
+}
+
+// New creates the evidence of two conflicting votes with their part set roots, the votes are ordered
+// by hash and root, so that the same pair makes the same evidence wherever it is detected
+func New(voteA *tbftpb.Vote, rootA []byte, voteB *tbftpb.Vote, rootB []byte) *Evidence {
+	if compareVotes(voteA.Hash, rootA, voteB.Hash, rootB) > 0 {
+		voteA, rootA, voteB, rootB = voteB, rootB, voteA, rootA
+	}
+	return &Evidence{VoteA: voteA, VoteB: voteB, RootA: rootA, RootB: rootB}
+}
+
+func compareVotes(hashA, rootA, hashB, rootB []byte) int {
+	if c := bytes.Compare(hashA, hashB); c != 0 {
+		return c
+	}
+	return bytes.Compare(rootA, rootB)
+}
+
+// VoteSignBytes returns the message signed by a vote, the vote without endorsement in protobuf,
+// followed by the part set root in tbft.VoteExt if it is not empty
+func VoteSignBytes(vote *tbftpb.Vote, partSetRoot []byte) ([]byte, error) {
+	voteCopy := proto.Clone(vote).(*tbftpb.Vote)
+	voteCopy.Endorsement = nil
+	message, err := proto.Marshal(voteCopy)
+	if err != nil {
+		return nil, err
+	}
+	ext, err := proto.Marshal(&tbftextpb.VoteExt{PartSetRoot: partSetRoot})
+	if err != nil {
+		return nil, err
+	}
+	return append(message, ext...), nil
+}
+
+// Unmarshal parses the evidence in protobuf
+func Unmarshal(bz []byte) (*Evidence, error) {
+	msg := &tbftextpb.Evidence{}
+	if err := proto.Unmarshal(bz, msg); err != nil {
+		return nil, err
+	}
+	e := &Evidence{VoteA: &tbftpb.Vote{}, VoteB: &tbftpb.Vote{}, RootA: msg.PartSetRootA, RootB: msg.PartSetRootB}
+	if err := proto.Unmarshal(msg.VoteA, e.VoteA); err != nil {
+		return nil, err
+	}
+	if err := proto.Unmarshal(msg.VoteB, e.VoteB); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Marshal returns the evidence in protobuf
+func (e *Evidence) Marshal() ([]byte, error) {
+	voteA, err := proto.Marshal(e.VoteA)
+	if err != nil {
+		return nil, err
+	}
+	voteB, err := proto.Marshal(e.VoteB)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&tbftextpb.Evidence{
+		VoteA:        voteA,
+		VoteB:        voteB,
+		PartSetRootA: e.RootA,
+		PartSetRootB: e.RootB,
+	})
+}
+
+// Key returns the state key of evidence, only one evidence of a validator is recorded
+// for each height, round and vote type
+func (e *Evidence) Key() string {
+	return fmt.Sprintf("%s%s_%d_%d_%d", keyPrefix, e.VoteA.Voter, e.VoteA.Height, e.VoteA.Round, e.VoteA.Type)
+}
+
+// TxId returns the id of the tx recording evidence, which is derived from Key, so that
+// the txs of the same evidence proposed by different nodes are deduplicated as the same tx
+func (e *Evidence) TxId() string {
+	sum := sha256.Sum256([]byte(e.Key()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (e *Evidence) String() string {
+	return fmt.Sprintf("Evidence{%s-%s(%d/%d)-%x/%x}", e.VoteA.Type, e.VoteA.Voter, e.VoteA.Height,
+		e.VoteA.Round, e.VoteA.Hash, e.VoteB.Hash)
+}
+
+// Validate checks that the votes of evidence conflict with each other, signatures are not checked
+func (e *Evidence) Validate() error {
+	a, b := e.VoteA, e.VoteB
+	if a == nil || b == nil {
+		return errors.New("evidence without two votes")
+	}
+	if a.Voter != b.Voter || a.Height != b.Height || a.Round != b.Round || a.Type != b.Type {
+		return fmt.Errorf("votes %s(%d/%d/%s) and %s(%d/%d/%s) are not conflicting",
+			a.Voter, a.Height, a.Round, a.Type, b.Voter, b.Height, b.Round, b.Type)
+	}
+	if a.Type != tbftpb.VoteType_VOTE_PREVOTE && a.Type != tbftpb.VoteType_VOTE_PRECOMMIT {
+		return fmt.Errorf("invalid vote type %s", a.Type)
+	}
+	if compareVotes(a.Hash, e.RootA, b.Hash, e.RootB) >= 0 {
+		return fmt.Errorf("votes for the same hash %x and root %x or not ordered", a.Hash, e.RootA)
+	}
+	if a.Endorsement == nil || b.Endorsement == nil {
+		return errors.New("votes without signature")
+	}
+	return nil
+}
+
+// VerifySignatures checks that the votes of evidence are signed by a consensus node,
+// and that the node id of signer is the voter
+func (e *Evidence) VerifySignatures(ac protocol.AccessControlProvider, chainConfig *configpb.ChainConfig) error {
+	roots := [][]byte{e.RootA, e.RootB}
+	for i, vote := range []*tbftpb.Vote{e.VoteA, e.VoteB} {
+		message, err := VoteSignBytes(vote, roots[i])
+		if err != nil {
+			return err
+		}
+		principal, err := ac.CreatePrincipal(protocol.ResourceNameConsensusNode,
+			[]*common.EndorsementEntry{vote.Endorsement}, message)
+		if err != nil {
+			return err
+		}
+		result, err := ac.VerifyPrincipal(principal)
+		if err != nil {
+			return err
+		}
+		if !result {
+			return fmt.Errorf("invalid signature of vote %x", vote.Hash)
+		}
+		uid, err := NodeId(ac, chainConfig, vote.Endorsement.Signer)
+		if err != nil {
+			return err
+		}
+		if uid != vote.Voter {
+			return fmt.Errorf("vote %x is signed by %s, not by voter %s", vote.Hash, uid, vote.Voter)
+		}
+	}
+	return nil
+}
+
+// NodeId returns the node id of the signer of a vote, from the chain config and the signer only, so that
+// all nodes get the same id. It is the node id of the trust member with the same info, the peer id of
+// the public key for public key members, or the member id of certificates, which is the node id
+// in the certificate extension.
+func NodeId(ac protocol.AccessControlProvider, chainConfig *configpb.ChainConfig,
+	signer *pbac.Member) (string, error) {
+	if signer == nil {
+		return "", errors.New("vote without signer")
+	}
+	for _, m := range chainConfig.TrustMembers {
+		if m.MemberInfo == string(signer.MemberInfo) {
+			return m.NodeId, nil
+		}
+	}
+	if signer.MemberType == pbac.MemberType_PUBLIC_KEY {
+		pk, err := asym.PublicKeyFromPEM(signer.MemberInfo)
+		if err != nil {
+			return "", fmt.Errorf("parse public key of signer failed, %s", err)
+		}
+		return helper.CreateLibp2pPeerIdWithPublicKey(pk)
+	}
+	member, err := ac.NewMember(signer)
+	if err != nil {
+		return "", err
+	}
+	return member.GetMemberId(), nil
+}
+
+var pools sync.Map // chain id => *Pool
+
+// Of returns the evidence pool of chain
+func Of(chainId string) *Pool {
+	if p, ok := pools.Load(chainId); ok {
+		return p.(*Pool)
+	}
+	p, _ := pools.LoadOrStore(chainId, NewPool())
+	return p.(*Pool)
+}
+
+// Pool keeps the evidence verified but not recorded on chain yet
+type Pool struct {
+	mu      sync.Mutex
+	pending map[string]*Evidence // key => evidence
+	order   []string             // keys in the order added
+}
+
+// NewPool creates an evidence pool
+func NewPool() *Pool {
+	return &Pool{pending: make(map[string]*Evidence)}
+}
+
+// Add adds the evidence, returns false if it is known or the pool is full
+func (p *Pool) Add(e *Evidence) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := e.Key()
+	if _, ok := p.pending[key]; ok || len(p.pending) >= maxPending {
+		return false
+	}
+	p.pending[key] = e
+	p.order = append(p.order, key)
+	return true
+}
+
+// Pending returns the evidence in the order added
+func (p *Pool) Pending() []*Evidence {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	list := make([]*Evidence, 0, len(p.order))
+	for _, key := range p.order {
+		list = append(list, p.pending[key])
+	}
+	return list
+}
+
+// Remove removes the evidence, as it is recorded on chain
+func (p *Pool) Remove(e *Evidence) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := e.Key()
+	if _, ok := p.pending[key]; !ok {
+		return
+	}
+	delete(p.pending, key)
+	for i, k := range p.order {
+		if k == key {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// StateReader reads the state of contracts, which is implemented by protocol.BlockchainStore
+type StateReader interface {
+	SelectObject(contractName string, startKey []byte, limit []byte) (protocol.StateIterator, error)
+}
+
+// List returns the evidence recorded in state, ordered by voter, height, round and vote type
+func List(store StateReader) ([]*Evidence, error) {
+	iterRange := util.BytesPrefix([]byte(keyPrefix))
+	iter, err := store.SelectObject(ContractName, iterRange.Start, iterRange.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Release()
+	list := make([]*Evidence, 0)
+	for iter.Next() {
+		kv, err := iter.Value()
+		if err != nil {
+			return nil, err
+		}
+		e, err := Unmarshal(kv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid evidence %s: %s", kv.Key, err)
+		}
+		list = append(list, e)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i].VoteA, list[j].VoteA
+		if a.Voter != b.Voter {
+			return a.Voter < b.Voter
+		}
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		if a.Round != b.Round {
+			return a.Round < b.Round
+		}
+		return a.Type < b.Type
+	})
+	return list, nil
+}
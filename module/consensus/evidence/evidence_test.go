@@ -0,0 +1,104 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package evidence
+
+import (
+	"testing"
+
+	pbac "chainmaker.org/chainmaker/pb-go/v2/accesscontrol"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newVote(hash string) *tbftpb.Vote {
+	return &tbftpb.Vote{
+		Type:   tbftpb.VoteType_VOTE_PRECOMMIT,
+		Voter:  "node1",
+		Height: 10,
+		Round:  1,
+		Hash:   []byte(hash),
+		Endorsement: &common.EndorsementEntry{
+			Signer:    &pbac.Member{MemberType: pbac.MemberType_CERT, MemberInfo: []byte("cert1")},
+			Signature: []byte("sig-" + hash),
+		},
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	e := New(newVote("b"), nil, newVote("a"), nil)
+	require.NoError(t, e.Validate())
+	require.Equal(t, []byte("a"), e.VoteA.Hash)
+	require.Equal(t, New(newVote("a"), nil, newVote("b"), nil).TxId(), e.TxId())
+	require.Len(t, e.TxId(), 64)
+
+	bz, err := e.Marshal()
+	require.NoError(t, err)
+	parsed, err := Unmarshal(bz)
+	require.NoError(t, err)
+	require.NoError(t, parsed.Validate())
+	require.Equal(t, e.Key(), parsed.Key())
+
+	require.Error(t, New(newVote("a"), nil, newVote("a"), nil).Validate())
+	// the votes for the same block in different part sets conflict
+	e = New(newVote("a"), []byte("root2"), newVote("a"), []byte("root1"))
+	require.NoError(t, e.Validate())
+	require.Equal(t, []byte("root1"), e.RootA)
+	bz, err = e.Marshal()
+	require.NoError(t, err)
+	parsed, err = Unmarshal(bz)
+	require.NoError(t, err)
+	require.Equal(t, e.RootB, parsed.RootB)
+	other := newVote("b")
+	other.Round = 2
+	require.Error(t, New(newVote("a"), nil, other, nil).Validate())
+	other = newVote("b")
+	other.Endorsement = nil
+	require.Error(t, New(newVote("a"), nil, other, nil).Validate())
+}
+
+func TestPool(t *testing.T) {
+	p := NewPool()
+	e1 := New(newVote("a"), nil, newVote("b"), nil)
+	e2 := New(newVote("a"), nil, newVote("c"), nil) // the same voter, height, round and type
+	voteA, voteB := newVote("a"), newVote("b")
+	voteA.Height, voteB.Height = 11, 11
+	e3 := New(voteA, nil, voteB, nil)
+
+	require.True(t, p.Add(e1))
+	require.False(t, p.Add(e2))
+	require.True(t, p.Add(e3))
+	require.Equal(t, []*Evidence{e1, e3}, p.Pending())
+
+	p.Remove(e1)
+	require.Equal(t, []*Evidence{e3}, p.Pending())
+	require.True(t, p.Add(e2))
+}
+
+func TestVerifySignatures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	member := mock.NewMockMember(ctrl)
+	member.EXPECT().GetMemberId().Return("node2").AnyTimes()
+	ac := mock.NewMockAccessControlProvider(ctrl)
+	ac.EXPECT().CreatePrincipal(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	ac.EXPECT().VerifyPrincipal(gomock.Any()).Return(true, nil).AnyTimes()
+	ac.EXPECT().NewMember(gomock.Any()).Return(member, nil).AnyTimes()
+
+	// the votes of node1 signed by node2 are not evidence of node1
+	e := New(newVote("a"), nil, newVote("b"), nil)
+	require.Error(t, e.VerifySignatures(ac, &configpb.ChainConfig{}))
+
+	// the node id of signer is taken from the trust members first
+	chainConfig := &configpb.ChainConfig{
+		TrustMembers: []*configpb.TrustMemberConfig{{MemberInfo: "cert1", NodeId: "node1"}},
+	}
+	require.NoError(t, e.VerifySignatures(ac, chainConfig))
+}
@@ -4,6 +4,8 @@ go 1.15
 
 require (
 	chainmaker.org/chainmaker-go/accesscontrol v0.0.0
+	chainmaker.org/chainmaker-go/pb v0.0.0
+	chainmaker.org/chainmaker-go/upgrade v0.0.0
 	chainmaker.org/chainmaker/chainconf/v2 v2.1.1
 	chainmaker.org/chainmaker/common/v2 v2.1.0
 	chainmaker.org/chainmaker/localconf/v2 v2.1.0
@@ -13,11 +15,13 @@ require (
 	chainmaker.org/chainmaker/raftwal/v2 v2.1.0
 	chainmaker.org/chainmaker/utils/v2 v2.1.0
 	chainmaker.org/chainmaker/vm-native/v2 v2.1.1
+	github.com/ethereum/go-ethereum v1.10.3
 	github.com/go-sql-driver/mysql v1.6.0 // indirect
 	github.com/gogo/protobuf v1.3.2
 	github.com/golang/mock v1.6.0
 	github.com/pingcap/errors v0.11.5-0.20201126102027-b0a155152ca3 // indirect
 	github.com/pingcap/log v0.0.0-20201112100606-8f1e84a3abc8 // indirect
+	github.com/prometheus/client_golang v1.11.0
 	github.com/shirou/gopsutil v3.21.4-0.20210419000835-c7a38de76ee5+incompatible // indirect
 	github.com/spf13/viper v1.9.0
 	github.com/stretchr/testify v1.7.0
@@ -32,5 +36,7 @@ require (
 
 replace (
 	chainmaker.org/chainmaker-go/accesscontrol => ../accesscontrol
+	chainmaker.org/chainmaker-go/pb => ../pb
+	chainmaker.org/chainmaker-go/upgrade => ../upgrade
 	github.com/libp2p/go-libp2p-core => chainmaker.org/chainmaker/libp2p-core v1.0.0
 )
@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strconv"
 
+	"chainmaker.org/chainmaker-go/consensus/aggsig"
 	"chainmaker.org/chainmaker/logger/v2"
 	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
 	configPb "chainmaker.org/chainmaker/pb-go/v2/config"
@@ -40,6 +41,8 @@ const (
 	NodeProposeRound         = "NodeProposeRound"
 	RoundTimeoutMill         = "HotstuffRoundTimeoutMill"
 	RoundTimeoutIntervalMill = "HotstuffRoundTimeoutIntervalMill"
+	// AggregateKeys is the json object of node id to aggsig.RegisteredKey, the keys signing the aggregate QCs
+	AggregateKeys = "HotstuffAggregateKeys"
 
 	UnmarshalErrFmt        = "proto.Unmarshal err!err=%v"
 	CreateValidatorsErrFmt = "createValidators err!err=%v"
@@ -190,6 +193,10 @@ func checkChainConfig(chainConfig *configPb.ChainConfig,
 			if v < MinimumIntervalTimeOutMill {
 				return false, fmt.Errorf("set %s is too minimum, %d < %d", RoundTimeoutIntervalMill, v, MinimumIntervalTimeOutMill)
 			}
+		case AggregateKeys:
+			if _, err := aggsig.ParseKeys(string(oneConf.Value)); err != nil {
+				return false, fmt.Errorf("set %s err: %s", AggregateKeys, err)
+			}
 		}
 	}
 	return true, nil
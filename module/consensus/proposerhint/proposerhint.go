@@ -0,0 +1,38 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package proposerhint tells the block proposer whether this node is expected to propose a height,
+// before the consensus reaches it. The consensus engines which can predict their proposers register
+// a predictor by chain id, so that the blocks built ahead of time are only built by the node which
+// will propose them.
+package proposerhint
+
+import "sync"
+
+// Predictor returns whether this node is expected to propose the block at height in the first round
+type Predictor func(height uint64) bool
+
+var predictors sync.Map // chain id => Predictor
+
+// Register registers the predictor of the consensus of chain, replacing the former one
+func Register(chainId string, predictor Predictor) {
+	predictors.Store(chainId, predictor)
+}
+
+// Unregister removes the predictor of chain
+func Unregister(chainId string) {
+	predictors.Delete(chainId)
+}
+
+// IsProposer returns whether this node is expected to propose the block at height of chain,
+// false if the consensus of chain has not registered a predictor
+func IsProposer(chainId string, height uint64) bool {
+	predictor, ok := predictors.Load(chainId)
+	if !ok {
+		return false
+	}
+	return predictor.(Predictor)(height)
+}
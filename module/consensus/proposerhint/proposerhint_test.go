@@ -0,0 +1,25 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposerhint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsProposer(t *testing.T) {
+	require.False(t, IsProposer("chain1", 10))
+
+	Register("chain1", func(height uint64) bool { return height%2 == 0 })
+	require.True(t, IsProposer("chain1", 10))
+	require.False(t, IsProposer("chain1", 11))
+	require.False(t, IsProposer("chain2", 10))
+
+	Unregister("chain1")
+	require.False(t, IsProposer("chain1", 10))
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/thoas/go-funk"
 	"go.uber.org/zap"
 
+	"chainmaker.org/chainmaker-go/consensus/proposerhint"
 	"chainmaker.org/chainmaker/chainconf/v2"
 	commonErrors "chainmaker.org/chainmaker/common/v2/errors"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
@@ -84,7 +85,8 @@ type ConsensusRaftImpl struct {
 	msgbus        msgbus.MessageBus
 	closeC        chan struct{}
 	Id            uint64
-	peers         []uint64
+	peers         []uint64 // the voters in chain config
+	learners      []uint64 // the learners in chain config
 	isLeader      bool
 	node          etcdraft.Node
 	raftStorage   *etcdraft.MemoryStorage
@@ -99,15 +101,29 @@ type ConsensusRaftImpl struct {
 	appliedIndex  uint64
 	proposedIndex uint64
 	idToNodeId    sync.Map
+	// ticks since the last config change proposed, 0 if there is no config change in progress
+	confChangeTicks int
+	// ticks since the last check whether to transfer the leadership to a preferred leader
+	leaderCheckTicks int
+	// the number of snapshots being installed, during which no block is proposed
+	installingSnapshot int32
+	// the entries committed while installing snapshots, published after they are installed
+	pendingEntries []raftpb.Entry
+	// serializes the installations of snapshots, which share snapChunkC
+	snapInstallMu sync.Mutex
 
 	proposedBlockC chan *common.Block
 	verifyResultC  chan *consensus.VerifyResult
 	blockInfoC     chan *common.BlockInfo
 	confChangeC    chan raftpb.ConfChange
+	snapChunkC     chan raftpb.Message
+	snapChunkReqC  chan raftpb.Message
+	snapInstalledC chan struct{}
 	walSaveC       chan interface{}
 	wg             sync.WaitGroup
 	blockVerifier  protocol.BlockVerifier
 	blockCommitter protocol.BlockCommitter
+	store          protocol.BlockchainStore
 }
 
 // ConsensusRaftImplConfig contains initialization config for ConsensusRaftImpl
@@ -121,6 +137,7 @@ type ConsensusRaftImplConfig struct {
 	BlockCommitter protocol.BlockCommitter
 	ChainConf      protocol.ChainConf
 	MsgBus         msgbus.MessageBus
+	Store          protocol.BlockchainStore
 }
 
 // New creates a raft consensus instance
@@ -156,9 +173,13 @@ func New(config ConsensusRaftImplConfig) (*ConsensusRaftImpl, error) {
 	consensus.verifyResultC = make(chan *consensuspb.VerifyResult, DefaultChanCap)
 	consensus.blockInfoC = make(chan *common.BlockInfo, DefaultChanCap)
 	consensus.confChangeC = make(chan raftpb.ConfChange, DefaultChanCap)
+	consensus.snapChunkC = make(chan raftpb.Message, 1)
+	consensus.snapChunkReqC = make(chan raftpb.Message, snapChunkReqCap)
+	consensus.snapInstalledC = make(chan struct{}, 1)
 	consensus.walSaveC = make(chan interface{}, DefaultChanCap)
 	consensus.blockVerifier = config.BlockVerifier
 	consensus.blockCommitter = config.BlockCommitter
+	consensus.store = config.Store
 
 	consensus.logger.Infof("New ConsensusRaftImpl[%x]", consensus.Id)
 	instances.Store(consensus.Id, consensus)
@@ -182,11 +203,7 @@ func (consensus *ConsensusRaftImpl) Start() error {
 	walExist := wal.Exist(consensus.waldir)
 	consensus.wal = consensus.replayWAL()
 
-	var idToNodes map[uint64]string
-	consensus.peers, idToNodes = consensus.getPeersFromChainConf()
-	for id, node := range idToNodes {
-		consensus.idToNodeId.Store(id, node)
-	}
+	consensus.updatePeers()
 	c := &etcdraft.Config{
 		ID:              consensus.Id,
 		ElectionTick:    10,
@@ -218,9 +235,14 @@ func (consensus *ConsensusRaftImpl) Start() error {
 	consensus.wg.Add(1)
 	go consensus.AsyncWalSave()
 	go consensus.serve()
+	go consensus.serveSnapChunks()
 	consensus.msgbus.Register(msgbus.ProposedBlock, consensus)
 	consensus.msgbus.Register(msgbus.RecvConsensusMsg, consensus)
 	_ = chainconf.RegisterVerifier(consensus.chainID, consensuspb.ConsensusType_RAFT, consensus)
+	// the leader proposes every height until the leadership changes
+	proposerhint.Register(consensus.chainID, func(uint64) bool {
+		return consensus.node.Status().Lead == consensus.Id
+	})
 	isStarted.Store(consensus.Id, true)
 
 	return nil
@@ -245,6 +267,9 @@ func (consensus *ConsensusRaftImpl) OnMessage(message *msgbus.Message) {
 			if err := raftMsg.Unmarshal(msg.Payload); err != nil {
 				consensus.logger.Panicf("[%x] unmarshal message %v", consensus.Id, err)
 			}
+			if consensus.onSnapChunkMessage(raftMsg) {
+				return
+			}
 			consensus.logger.DebugDynamic(func() string {
 				return fmt.Sprintf("[%x] receive message %v", consensus.Id, describeMessage(raftMsg))
 			})
@@ -346,18 +371,26 @@ func (consensus *ConsensusRaftImpl) serve() {
 		consensus.msgbus.UnRegister(msgbus.RecvConsensusMsg, consensus)
 		isStarted.Delete(consensus.Id)
 		instances.Delete(consensus.Id)
+		proposerhint.Unregister(consensus.chainID)
 	}()
 
 	for {
 		select {
 		case <-ticker.C:
 			consensus.node.Tick()
+			consensus.maybeProposeConfChange()
+			consensus.maybeTransferToPreferredLeader()
 			consensus.logger.Debugf("[%x] status: %s", consensus.Id, consensus.node.Status())
 		case ready := <-consensus.node.Ready():
 			if exit := consensus.NodeReady(ready); exit {
 				consensus.logger.Debugf("exit consensus when process ready message")
 				return
 			}
+		case <-consensus.snapInstalledC:
+			if exit := consensus.publishPendingEntries(); exit {
+				consensus.logger.Debugf("exit consensus when publish pending entries")
+				return
+			}
 		case block := <-consensus.proposedBlockC:
 			consensus.ProposeBlock(block)
 		case cc := <-consensus.confChangeC:
@@ -375,9 +408,13 @@ func (consensus *ConsensusRaftImpl) NodeReady(ready etcdraft.Ready) (exit bool)
 	consensus.logger.DebugDynamic(func() string {
 		return fmt.Sprintf("[%x] receive from raft ready, %v", consensus.Id, describeReady(ready))
 	})
-	if !etcdraft.IsEmptySnap(ready.Snapshot) && ready.Snapshot.Metadata.Index <= consensus.appliedIndex {
-		consensus.logger.Fatalf("snapshot index: %v should > appliedIndex: %v",
-			ready.Snapshot.Metadata.Index, consensus.appliedIndex)
+	if !etcdraft.IsEmptySnap(ready.Snapshot) {
+		if ready.Snapshot.Metadata.Index <= consensus.appliedIndex {
+			consensus.logger.Fatalf("snapshot index: %v should > appliedIndex: %v",
+				ready.Snapshot.Metadata.Index, consensus.appliedIndex)
+		}
+		// the blocks of snapshot are installed in background, see publishSnapshot
+		atomic.AddInt32(&consensus.installingSnapshot, 1)
 	}
 	//异步WalSave
 	if consensus.asyncWalSave {
@@ -386,7 +423,26 @@ func (consensus *ConsensusRaftImpl) NodeReady(ready etcdraft.Ready) (exit bool)
 		consensus.processWalAndSnap(ready)
 	}
 
-	ok, configChanged := consensus.publishEntries(consensus.entriesToApply(ready.CommittedEntries))
+	if atomic.LoadInt32(&consensus.installingSnapshot) > 0 {
+		// the entries follow the blocks of snapshot, which are not committed yet
+		consensus.pendingEntries = append(consensus.pendingEntries, ready.CommittedEntries...)
+	} else if exit := consensus.applyEntries(ready.CommittedEntries); exit {
+		return true
+	}
+	if ready.SoftState != nil {
+		isLeader := atomic.LoadUint64(&ready.SoftState.Lead) == consensus.Id
+		if isLeader != consensus.isLeader {
+			consensus.confChangeTicks = 0
+		}
+		consensus.isLeader = isLeader
+	}
+	consensus.sendProposeState(consensus.isLeader)
+	return false
+}
+
+// applyEntries publishes the committed entries, it returns true if the node is deleted from consensus nodes
+func (consensus *ConsensusRaftImpl) applyEntries(ents []raftpb.Entry) (exit bool) {
+	ok, configChanged := consensus.publishEntries(consensus.entriesToApply(ents))
 	if !ok {
 		for len(consensus.walSaveC) != 0 {
 			time.Sleep(500 * time.Millisecond)
@@ -402,16 +458,29 @@ func (consensus *ConsensusRaftImpl) NodeReady(ready etcdraft.Ready) (exit bool)
 	} else {
 		consensus.maybeTriggerSnapshot(configChanged)
 	}
-	if ready.SoftState != nil {
-		consensus.isLeader = atomic.LoadUint64(&ready.SoftState.Lead) == consensus.Id
-	}
-	consensus.sendProposeState(consensus.isLeader)
 	return false
 }
 
+// publishPendingEntries publishes the entries committed while installing snapshots after the last one
+// is installed
+func (consensus *ConsensusRaftImpl) publishPendingEntries() (exit bool) {
+	if atomic.AddInt32(&consensus.installingSnapshot, -1) > 0 {
+		return false
+	}
+	ents := consensus.pendingEntries
+	consensus.pendingEntries = nil
+	consensus.logger.Infof("[%x] publish %d entries committed while installing snapshot", consensus.Id, len(ents))
+	return consensus.applyEntries(ents)
+}
+
 func (consensus *ConsensusRaftImpl) ProposeBlock(block *common.Block) {
 	consensus.Lock()
 	defer consensus.Unlock()
+	if atomic.LoadInt32(&consensus.installingSnapshot) > 0 {
+		consensus.logger.Debugf("[%x] drop proposed block while installing snapshot, height: %d",
+			consensus.Id, block.Header.BlockHeight)
+		return
+	}
 	if block.Header.BlockHeight <= consensus.proposedIndex {
 		consensus.logger.Debugf("[%x] got proposed block in wrong height:%d",
 			consensus.Id, block.Header.BlockHeight)
@@ -540,11 +609,8 @@ func (consensus *ConsensusRaftImpl) publishEntries(ents []raftpb.Entry) (ok bool
 				consensus.logger.Panicf("[%x] unmarshal config change error: %v", consensus.Id, err)
 			}
 			consensus.confState = *consensus.node.ApplyConfChange(cc)
-			var idToNodes map[uint64]string
-			consensus.peers, idToNodes = consensus.getPeersFromChainConf()
-			for id, node := range idToNodes {
-				consensus.idToNodeId.Store(id, node)
-			}
+			consensus.confChangeTicks = 0
+			consensus.updatePeers()
 			switch cc.Type {
 			// todo. may be check the delete node logic
 			case raftpb.ConfChangeRemoveNode:
@@ -559,44 +625,6 @@ func (consensus *ConsensusRaftImpl) publishEntries(ents []raftpb.Entry) (ok bool
 	return true, configChanged
 }
 
-func (consensus *ConsensusRaftImpl) publishSnapshot(snapshot raftpb.Snapshot) {
-	if etcdraft.IsEmptySnap(snapshot) {
-		return
-	}
-
-	consensus.logger.Infof("publishSnapshot metadata: %v", snapshot.Metadata)
-	consensus.confState = snapshot.Metadata.ConfState
-	consensus.snapshotIndex = snapshot.Metadata.Index
-	if snapshot.Metadata.Index > consensus.appliedIndex {
-		consensus.appliedIndex = snapshot.Metadata.Index
-	}
-
-	snapshotData := &SnapshotHeight{}
-	json.Unmarshal(snapshot.Data, snapshotData)
-
-	for {
-		// Loop until catch up to snapshotData.Height from Sync module
-		current, _ := consensus.ledgerCache.CurrentHeight()
-		consensus.logger.Debugf("publishSnapshot current height: %d, snapshot height: %d", current, snapshotData.Height)
-		if current >= snapshotData.Height {
-			break
-		}
-		time.Sleep(500 * time.Microsecond)
-	}
-}
-
-func (consensus *ConsensusRaftImpl) getSnapshot() ([]byte, error) {
-	height, err := consensus.ledgerCache.CurrentHeight()
-	if err != nil {
-		return nil, err
-	}
-	data, err := json.Marshal(SnapshotHeight{
-		Height: height,
-	})
-	consensus.logger.Infof("getSnapshot data: %s", data)
-	return data, err
-}
-
 func (consensus *ConsensusRaftImpl) maybeTriggerSnapshot(configChanged bool) {
 	if consensus.appliedIndex-consensus.snapshotIndex <= consensus.snapCount && !configChanged {
 		return
@@ -765,21 +793,36 @@ func (consensus *ConsensusRaftImpl) sendProposeState(isProposer bool) {
 func (consensus *ConsensusRaftImpl) Verify(
 	consensusType consensuspb.ConsensusType,
 	chainConfig *config.ChainConfig) error {
-	return nil
+	if err := checkLearners(chainConfig.Consensus); err != nil {
+		return err
+	}
+	return checkPreferredLeaders(chainConfig.Consensus)
 }
 
-func (consensus *ConsensusRaftImpl) getPeersFromChainConf() ([]uint64, map[uint64]string) {
+// getPeersFromChainConf returns the voters and learners in chain config, and the node ids of them
+func (consensus *ConsensusRaftImpl) getPeersFromChainConf() ([]uint64, []uint64, map[uint64]string) {
 	var (
 		peers      []uint64
+		learners   []uint64
 		idToNodeId = make(map[uint64]string)
 		builder    strings.Builder
 	)
 
+	consensusConfig := consensus.chainConf.ChainConfig().Consensus
+	learnerNodes, err := parseLearners(consensusConfig)
+	if err != nil {
+		consensus.logger.Errorf("[%x] parse learners failed, %v", consensus.Id, err)
+	}
 	fmt.Fprintf(&builder, "[")
-	for _, org := range consensus.chainConf.ChainConfig().Consensus.Nodes {
+	for _, org := range consensusConfig.Nodes {
 		for _, nodeId := range org.NodeId {
 			id := computeRaftIdFromNodeId(nodeId)
 			idToNodeId[id] = nodeId
+			if learnerNodes[nodeId] {
+				learners = append(learners, id)
+				fmt.Fprintf(&builder, "%s: %x(learner), ", nodeId, id)
+				continue
+			}
 			peers = append(peers, id)
 			fmt.Fprintf(&builder, "%s: %x, ", nodeId, id)
 		}
@@ -792,35 +835,59 @@ func (consensus *ConsensusRaftImpl) getPeersFromChainConf() ([]uint64, map[uint6
 	sort.Slice(peers, func(i, j int) bool {
 		return peers[i] < peers[j]
 	})
-	return peers, idToNodeId
+	sort.Slice(learners, func(i, j int) bool {
+		return learners[i] < learners[j]
+	})
+	return peers, learners, idToNodeId
+}
+
+// updatePeers updates the voters, learners and their node ids from chain config
+func (consensus *ConsensusRaftImpl) updatePeers() {
+	var idToNodes map[uint64]string
+	consensus.peers, consensus.learners, idToNodes = consensus.getPeersFromChainConf()
+	for id, node := range idToNodes {
+		consensus.idToNodeId.Store(id, node)
+	}
 }
 
 func (consensus *ConsensusRaftImpl) processConfigChange() bool {
-	peers, idToNodes := consensus.getPeersFromChainConf()
-	removed, added := computeUpdatedNodes(consensus.peers, peers)
-	consensus.logger.Debugf("[%x] processConfigChange removed: %v, added: %v", consensus.Id, removed, added)
-
-	if consensus.isLeader {
-		for _, node := range removed {
-			cc := raftpb.ConfChange{
-				Type:   raftpb.ConfChangeRemoveNode,
-				NodeID: node,
-			}
-			consensus.confChangeC <- cc
-		}
-		for _, node := range added {
-			cc := raftpb.ConfChange{
-				Type:   raftpb.ConfChangeAddNode,
-				NodeID: node,
-			}
-			consensus.confChangeC <- cc
-		}
-		consensus.peers = peers
-		for id, node := range idToNodes {
-			consensus.idToNodeId.Store(id, node)
+	peers, learners := consensus.peers, consensus.learners
+	consensus.updatePeers()
+	removed, added := computeUpdatedNodes(append(peers, learners...),
+		append(consensus.peers, consensus.learners...))
+	consensus.logger.Debugf("[%x] processConfigChange removed: %v, added: %v, learners: %v",
+		consensus.Id, removed, added, describeNodes(consensus.learners))
+
+	consensus.maybeProposeConfChange()
+	return len(removed) != 0 || len(added) != 0
+}
+
+// maybeProposeConfChange proposes the next config change towards the voters and learners of chain config
+// on leader. The config changes are proposed one at a time, since raft drops a config change proposed
+// before the last one is applied.
+func (consensus *ConsensusRaftImpl) maybeProposeConfChange() {
+	if !consensus.isLeader {
+		return
+	}
+	if consensus.confChangeTicks > 0 {
+		consensus.confChangeTicks++
+		if consensus.confChangeTicks <= confChangeTimeoutTicks {
+			return
 		}
 	}
-	return len(removed) != 0 || len(added) != 0
+
+	status := consensus.node.Status()
+	cc := nextConfChange(consensus.Id, consensus.peers, consensus.learners, consensus.confState,
+		func(id uint64) bool {
+			progress, ok := status.Progress[id]
+			return ok && progress.Match+learnerCatchUpEntries >= status.Commit
+		})
+	if cc == nil {
+		consensus.confChangeTicks = 0
+		return
+	}
+	consensus.confChangeTicks = 1
+	consensus.confChangeC <- *cc
 }
 
 // VerifyBlockSignatures verifies whether the signatures in block
@@ -0,0 +1,193 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	etcdraft "go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/tracker"
+)
+
+// The leadership is transferred by the admin with the TRANSFER_RAFT_LEADERSHIP query of rpc server, or by
+// the leader itself to a preferred leader. The preferred leaders are in RAFT_preferred_leaders, and a leader
+// not in them transfers the leadership to a caught up one periodically, so the leadership moves back to them
+// after failover.
+const (
+	// RaftPreferredLeadersKey is the key in consensus ext config of the preferred leaders, a json array of
+	// node ids which must be the voters
+	RaftPreferredLeadersKey = "RAFT_preferred_leaders"
+
+	// preferredLeaderCheckTicks is the ticks between the checks of leader whether to transfer to a preferred leader
+	preferredLeaderCheckTicks = 30
+)
+
+var (
+	// ErrNotLeader is returned when transferring the leadership on a follower
+	ErrNotLeader = errors.New("not the raft leader")
+	// ErrNoTransferee is returned when there is no follower to transfer the leadership to
+	ErrNoTransferee = errors.New("no follower to transfer the leadership to")
+)
+
+// parsePreferredLeaders parses the preferred leaders in consensus ext config
+func parsePreferredLeaders(config *config.ConsensusConfig) (map[string]bool, error) {
+	leaders := make(map[string]bool)
+	for _, kv := range config.ExtConfig {
+		if kv.Key != RaftPreferredLeadersKey {
+			continue
+		}
+		var nodeIds []string
+		if err := json.Unmarshal([]byte(kv.Value), &nodeIds); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", RaftPreferredLeadersKey, err)
+		}
+		for _, nodeId := range nodeIds {
+			leaders[nodeId] = true
+		}
+	}
+	return leaders, nil
+}
+
+// checkPreferredLeaders checks that the preferred leaders are consensus nodes and not learners
+func checkPreferredLeaders(config *config.ConsensusConfig) error {
+	leaders, err := parsePreferredLeaders(config)
+	if err != nil {
+		return err
+	}
+	learners, err := parseLearners(config)
+	if err != nil {
+		return err
+	}
+	nodes := make(map[string]bool)
+	for _, org := range config.Nodes {
+		for _, nodeId := range org.NodeId {
+			nodes[nodeId] = true
+		}
+	}
+	for nodeId := range leaders {
+		if !nodes[nodeId] || learners[nodeId] {
+			return fmt.Errorf("invalid %s: %s is not a voter", RaftPreferredLeadersKey, nodeId)
+		}
+	}
+	return nil
+}
+
+// bestTransferee returns the follower to transfer the leadership to in the status of leader, 0 if there is none.
+// It is the voter recently active with the most entries replicated, and the preferred leaders are chosen
+// over the others. If onlyPreferred, it is a preferred leader which has replicated all entries of leader.
+func bestTransferee(status etcdraft.Status, preferred func(id uint64) bool, onlyPreferred bool) uint64 {
+	ids := make([]uint64, 0, len(status.Progress))
+	for id := range status.Progress {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	var best uint64
+	var bestProgress tracker.Progress
+	for _, id := range ids {
+		progress := status.Progress[id]
+		if id == status.ID || progress.IsLearner || !progress.RecentActive {
+			continue
+		}
+		if onlyPreferred && (!preferred(id) || progress.Match < status.Progress[status.ID].Match) {
+			continue
+		}
+		if best == 0 || progress.Match > bestProgress.Match ||
+			progress.Match == bestProgress.Match && preferred(id) && !preferred(best) {
+			best, bestProgress = id, progress
+		}
+	}
+	return best
+}
+
+// transferLeadership transfers the leadership to the node of nodeId, or the best follower if nodeId is empty.
+// It returns the node id of the transferee, and the transfer completes asynchronously.
+func (consensus *ConsensusRaftImpl) transferLeadership(nodeId string) (string, error) {
+	status := consensus.node.Status()
+	if status.Lead != consensus.Id {
+		return "", ErrNotLeader
+	}
+
+	var transferee uint64
+	if nodeId == "" {
+		preferred, err := parsePreferredLeaders(consensus.chainConf.ChainConfig().Consensus)
+		if err != nil {
+			return "", err
+		}
+		transferee = bestTransferee(status, func(id uint64) bool {
+			return preferred[consensus.nodeIdOf(id)]
+		}, false)
+		if transferee == 0 {
+			return "", ErrNoTransferee
+		}
+	} else {
+		transferee = computeRaftIdFromNodeId(nodeId)
+		if _, ok := status.Config.Voters.IDs()[transferee]; !ok || transferee == consensus.Id {
+			return "", fmt.Errorf("%s is not a follower to transfer the leadership to", nodeId)
+		}
+	}
+
+	consensus.logger.Infof("[%x] transfer leadership to %s(%x)", consensus.Id,
+		consensus.nodeIdOf(transferee), transferee)
+	consensus.node.TransferLeadership(context.Background(), consensus.Id, transferee)
+	return consensus.nodeIdOf(transferee), nil
+}
+
+// maybeTransferToPreferredLeader transfers the leadership to a caught up preferred leader periodically,
+// if the leader is not a preferred one and there is no config change in progress.
+func (consensus *ConsensusRaftImpl) maybeTransferToPreferredLeader() {
+	if !consensus.isLeader || consensus.confChangeTicks > 0 {
+		consensus.leaderCheckTicks = 0
+		return
+	}
+	consensus.leaderCheckTicks++
+	if consensus.leaderCheckTicks < preferredLeaderCheckTicks {
+		return
+	}
+	consensus.leaderCheckTicks = 0
+
+	preferred, err := parsePreferredLeaders(consensus.chainConf.ChainConfig().Consensus)
+	if err != nil || len(preferred) == 0 || preferred[consensus.nodeIdOf(consensus.Id)] {
+		return
+	}
+	status := consensus.node.Status()
+	if status.LeadTransferee != 0 {
+		return
+	}
+	transferee := bestTransferee(status, func(id uint64) bool {
+		return preferred[consensus.nodeIdOf(id)]
+	}, true)
+	if transferee == 0 {
+		return
+	}
+	consensus.logger.Infof("[%x] transfer leadership to preferred leader %s(%x)", consensus.Id,
+		consensus.nodeIdOf(transferee), transferee)
+	consensus.node.TransferLeadership(context.Background(), consensus.Id, transferee)
+}
+
+// TransferLeadership transfers the leadership of the raft instance of chain to the node of to, or the best
+// follower if to is empty, and returns the node id of the transferee. It is to drain the leader for
+// maintenance without waiting for an election timeout.
+func TransferLeadership(chainId, to string) (string, error) {
+	var instance *ConsensusRaftImpl
+	rangeStarted(func(consensus *ConsensusRaftImpl) bool {
+		if consensus.chainID == chainId {
+			instance = consensus
+		}
+		return instance == nil
+	})
+	if instance == nil {
+		return "", fmt.Errorf("no raft instance of chain %s", chainId)
+	}
+	return instance.transferLeadership(to)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package raft
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	"github.com/stretchr/testify/require"
+	etcdraft "go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/tracker"
+)
+
+func TestBestTransferee(t *testing.T) {
+	status := etcdraft.Status{
+		BasicStatus: etcdraft.BasicStatus{ID: 1},
+		Progress: map[uint64]tracker.Progress{
+			1: {Match: 100, RecentActive: true},
+			2: {Match: 90, RecentActive: true},
+			3: {Match: 100, RecentActive: true},
+			4: {Match: 100, RecentActive: true},
+			5: {Match: 100, RecentActive: true, IsLearner: true},
+			6: {Match: 100},
+		},
+	}
+	preferred := func(id uint64) bool { return id == 2 || id == 4 }
+	require.Equal(t, uint64(4), bestTransferee(status, preferred, false))
+	require.Equal(t, uint64(4), bestTransferee(status, preferred, true))
+	require.Equal(t, uint64(3), bestTransferee(status, func(uint64) bool { return false }, false))
+
+	// the preferred leaders have not caught up
+	p := status.Progress[4]
+	p.Match = 99
+	status.Progress[4] = p
+	require.Equal(t, uint64(3), bestTransferee(status, preferred, false))
+	require.Equal(t, uint64(0), bestTransferee(status, preferred, true))
+}
+
+func TestCheckPreferredLeaders(t *testing.T) {
+	consensusConfig := func(leaders string) *config.ConsensusConfig {
+		return &config.ConsensusConfig{
+			Nodes: []*config.OrgConfig{
+				{OrgId: "org1", NodeId: []string{"node1", "node2"}},
+				{OrgId: "org2", NodeId: []string{"node3"}},
+			},
+			ExtConfig: []*config.ConfigKeyValue{
+				{Key: RaftLearnersKey, Value: `["node3"]`},
+				{Key: RaftPreferredLeadersKey, Value: leaders},
+			},
+		}
+	}
+	require.NoError(t, checkPreferredLeaders(consensusConfig(`["node1","node2"]`)))
+	require.Error(t, checkPreferredLeaders(consensusConfig(`["node3"]`)))
+	require.Error(t, checkPreferredLeaders(consensusConfig(`["node4"]`)))
+	require.Error(t, checkPreferredLeaders(consensusConfig(`node1`)))
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package raft
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// The raft members follow the consensus nodes of chain config. The nodes in RAFT_learners are learners, which
+// receive the replicated log but do not vote, and the others are voters. The leader changes the raft config
+// one node at a time towards chain config: it removes the nodes not in chain config, adds the new nodes as
+// learners, and promotes a learner to voter once it has caught up with the commit index, so a new node does
+// not change the quorum before it can vote in time. Promoting a learner is to remove it from RAFT_learners.
+const (
+	// RaftLearnersKey is the key in consensus ext config of the learners, a json array of node ids
+	// which must be the consensus nodes
+	RaftLearnersKey = "RAFT_learners"
+
+	// learnerCatchUpEntries is the max entries a learner lags behind the commit index to be promoted
+	learnerCatchUpEntries = uint64(10)
+	// confChangeTimeoutTicks is the ticks to wait for a proposed config change to apply before proposing again
+	confChangeTimeoutTicks = 10
+)
+
+// parseLearners parses the learners in consensus ext config
+func parseLearners(config *config.ConsensusConfig) (map[string]bool, error) {
+	learners := make(map[string]bool)
+	for _, kv := range config.ExtConfig {
+		if kv.Key != RaftLearnersKey {
+			continue
+		}
+		var nodeIds []string
+		if err := json.Unmarshal([]byte(kv.Value), &nodeIds); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", RaftLearnersKey, err)
+		}
+		for _, nodeId := range nodeIds {
+			learners[nodeId] = true
+		}
+	}
+	return learners, nil
+}
+
+// checkLearners checks that the learners are consensus nodes, and there are voters besides them
+func checkLearners(config *config.ConsensusConfig) error {
+	learners, err := parseLearners(config)
+	if err != nil {
+		return err
+	}
+	nodes := make(map[string]bool)
+	for _, org := range config.Nodes {
+		for _, nodeId := range org.NodeId {
+			nodes[nodeId] = true
+		}
+	}
+	for nodeId := range learners {
+		if !nodes[nodeId] {
+			return fmt.Errorf("invalid %s: learner %s is not a consensus node", RaftLearnersKey, nodeId)
+		}
+	}
+	if len(learners) >= len(nodes) {
+		return fmt.Errorf("invalid %s: no voter", RaftLearnersKey)
+	}
+	return nil
+}
+
+// nextConfChange returns the next config change from the raft config of state to the voters and learners,
+// nil if there is none or the next one must wait. It is called on leader self, which is never demoted,
+// and is removed after the others. caughtUp returns whether a learner is caught up to be promoted.
+func nextConfChange(self uint64, voters, learners []uint64, state raftpb.ConfState,
+	caughtUp func(id uint64) bool) *raftpb.ConfChange {
+	isVoter, isLearner := toSet(voters), toSet(learners)
+	current := toSet(state.Voters)
+	for _, id := range state.Learners {
+		current[id] = false
+	}
+
+	removeSelf := false
+	for _, id := range sortedIds(current) {
+		if isVoter[id] || isLearner[id] {
+			continue
+		}
+		if id != self {
+			return &raftpb.ConfChange{Type: raftpb.ConfChangeRemoveNode, NodeID: id}
+		}
+		removeSelf = true
+	}
+	if removeSelf {
+		return &raftpb.ConfChange{Type: raftpb.ConfChangeRemoveNode, NodeID: self}
+	}
+
+	for _, id := range learners {
+		if voter, ok := current[id]; (!ok || voter) && id != self {
+			return &raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: id}
+		}
+	}
+	for _, id := range voters {
+		if _, ok := current[id]; !ok {
+			return &raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: id}
+		}
+	}
+	for _, id := range voters {
+		if voter := current[id]; !voter && caughtUp(id) {
+			return &raftpb.ConfChange{Type: raftpb.ConfChangeAddNode, NodeID: id}
+		}
+	}
+	return nil
+}
+
+func toSet(ids []uint64) map[uint64]bool {
+	set := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func sortedIds(set map[uint64]bool) []uint64 {
+	ids := make([]uint64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package raft
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestNextConfChange(t *testing.T) {
+	caughtUp := func(id uint64) bool { return id != 5 }
+	tests := []struct {
+		name     string
+		voters   []uint64
+		learners []uint64
+		state    raftpb.ConfState
+		want     *raftpb.ConfChange
+	}{
+		{
+			name:   "no change",
+			voters: []uint64{1, 2, 3},
+			state:  raftpb.ConfState{Voters: []uint64{1, 2, 3}},
+		},
+		{
+			name:   "remove others before self",
+			voters: []uint64{2},
+			state:  raftpb.ConfState{Voters: []uint64{1, 2, 3}},
+			want:   &raftpb.ConfChange{Type: raftpb.ConfChangeRemoveNode, NodeID: 3},
+		},
+		{
+			name:   "remove self",
+			voters: []uint64{2},
+			state:  raftpb.ConfState{Voters: []uint64{1, 2}},
+			want:   &raftpb.ConfChange{Type: raftpb.ConfChangeRemoveNode, NodeID: 1},
+		},
+		{
+			name:     "add learner",
+			voters:   []uint64{1, 2, 3},
+			learners: []uint64{4},
+			state:    raftpb.ConfState{Voters: []uint64{1, 2, 3}},
+			want:     &raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 4},
+		},
+		{
+			name:     "demote voter",
+			voters:   []uint64{1, 2},
+			learners: []uint64{3},
+			state:    raftpb.ConfState{Voters: []uint64{1, 2, 3}},
+			want:     &raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 3},
+		},
+		{
+			name:     "never demote self",
+			voters:   []uint64{2, 3},
+			learners: []uint64{1},
+			state:    raftpb.ConfState{Voters: []uint64{1, 2, 3}},
+		},
+		{
+			name:   "add new voter as learner",
+			voters: []uint64{1, 2, 3, 4},
+			state:  raftpb.ConfState{Voters: []uint64{1, 2, 3}},
+			want:   &raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 4},
+		},
+		{
+			name:   "promote caught up learner",
+			voters: []uint64{1, 2, 3, 4},
+			state:  raftpb.ConfState{Voters: []uint64{1, 2, 3}, Learners: []uint64{4}},
+			want:   &raftpb.ConfChange{Type: raftpb.ConfChangeAddNode, NodeID: 4},
+		},
+		{
+			name:   "wait for learner to catch up",
+			voters: []uint64{1, 2, 3, 5},
+			state:  raftpb.ConfState{Voters: []uint64{1, 2, 3}, Learners: []uint64{5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, nextConfChange(1, tt.voters, tt.learners, tt.state, caughtUp))
+		})
+	}
+}
+
+func TestCheckLearners(t *testing.T) {
+	consensusConfig := func(learners string) *config.ConsensusConfig {
+		return &config.ConsensusConfig{
+			Nodes: []*config.OrgConfig{
+				{OrgId: "org1", NodeId: []string{"node1", "node2"}},
+				{OrgId: "org2", NodeId: []string{"node3"}},
+			},
+			ExtConfig: []*config.ConfigKeyValue{{Key: RaftLearnersKey, Value: learners}},
+		}
+	}
+	learners, err := parseLearners(consensusConfig(`["node2","node3"]`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"node2": true, "node3": true}, learners)
+
+	require.NoError(t, checkLearners(consensusConfig(`[]`)))
+	require.NoError(t, checkLearners(consensusConfig(`["node2","node3"]`)))
+	require.Error(t, checkLearners(consensusConfig(`["node1","node2","node3"]`)))
+	require.Error(t, checkLearners(consensusConfig(`["node4"]`)))
+	require.Error(t, checkLearners(consensusConfig(`node2`)))
+}
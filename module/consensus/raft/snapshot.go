@@ -0,0 +1,237 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package raft
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	raftextpb "chainmaker.org/chainmaker-go/pb/consensus/raft"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"github.com/gogo/protobuf/proto"
+	etcdraft "go.etcd.io/etcd/raft/v3"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+// A raft snapshot carries a SnapshotManifest instead of the blocks. The follower installing a snapshot
+// requests the blocks after its height from the leader in chunks, and commits them until it reaches the
+// height of manifest, during which it does not propose blocks. The blocks are fetched in background, so the
+// raft messages are still sent and the entries still persisted during the installation, the entries
+// committed meanwhile are published after it, see publishPendingEntries. The chunks are raft messages of the
+// types of raftextpb.MessageTypeExt, so they are sent and received as other raft messages.
+const (
+	// msgSnapChunkRequest requests the blocks from Index
+	msgSnapChunkRequest = raftpb.MessageType(raftextpb.MessageTypeExt_MSG_SNAP_CHUNK_REQUEST)
+	// msgSnapChunk responds a msgSnapChunkRequest with the blocks in Entries, Reject if there is none
+	msgSnapChunk = raftpb.MessageType(raftextpb.MessageTypeExt_MSG_SNAP_CHUNK)
+
+	// snapChunkMaxBlocks and snapChunkMaxBytes are the max blocks and size of blocks in a chunk
+	snapChunkMaxBlocks = 100
+	snapChunkMaxBytes  = 4 * 1024 * 1024
+	// snapChunkTimeout is the time to wait for a chunk before requesting it again
+	snapChunkTimeout = 5 * time.Second
+	// snapChunkReqCap is the max chunk requests waiting to be served, the others are dropped
+	snapChunkReqCap = 8
+)
+
+var errSnapChunkTimeout = errors.New("snapshot chunk timeout")
+
+// getSnapshot returns the manifest of the committed blocks as the snapshot data
+func (consensus *ConsensusRaftImpl) getSnapshot() ([]byte, error) {
+	block := consensus.ledgerCache.GetLastCommittedBlock()
+	if block == nil {
+		return nil, errors.New("no committed block")
+	}
+	data, err := json.Marshal(SnapshotManifest{
+		Height: block.Header.BlockHeight,
+		Hash:   block.Header.BlockHash,
+	})
+	consensus.logger.Infof("getSnapshot data: %s", data)
+	return data, err
+}
+
+// publishSnapshot applies the metadata of snapshot, and installs its blocks in background. It returns
+// without waiting for the blocks, the installation is signalled by snapInstalledC.
+func (consensus *ConsensusRaftImpl) publishSnapshot(snapshot raftpb.Snapshot) {
+	if etcdraft.IsEmptySnap(snapshot) {
+		return
+	}
+
+	consensus.logger.Infof("publishSnapshot metadata: %v", snapshot.Metadata)
+	consensus.confState = snapshot.Metadata.ConfState
+	consensus.snapshotIndex = snapshot.Metadata.Index
+	if snapshot.Metadata.Index > consensus.appliedIndex {
+		consensus.appliedIndex = snapshot.Metadata.Index
+	}
+
+	manifest := &SnapshotManifest{}
+	if err := json.Unmarshal(snapshot.Data, manifest); err != nil {
+		consensus.logger.Fatalf("[%x] unmarshal snapshot data error: %v", consensus.Id, err)
+	}
+	go consensus.installSnapshot(manifest)
+}
+
+// installSnapshot fetches and commits the blocks up to the height of manifest, and signals snapInstalledC.
+// The blocks may also be committed by the sync module meanwhile.
+func (consensus *ConsensusRaftImpl) installSnapshot(manifest *SnapshotManifest) {
+	consensus.snapInstallMu.Lock()
+	defer consensus.snapInstallMu.Unlock()
+	for {
+		select {
+		case <-consensus.closeC:
+			return
+		default:
+		}
+		current, err := consensus.ledgerCache.CurrentHeight()
+		if err != nil {
+			consensus.logger.Fatalf("[%x] get current height error: %v", consensus.Id, err)
+		}
+		if current >= manifest.Height {
+			break
+		}
+		consensus.logger.Infof("[%x] install snapshot current height: %d, snapshot height: %d",
+			consensus.Id, current, manifest.Height)
+
+		blocks, err := consensus.fetchSnapChunk(current + 1)
+		if err != nil {
+			consensus.logger.Warnf("[%x] fetch snapshot chunk from height %d error: %v", consensus.Id, current+1, err)
+			continue
+		}
+		for _, block := range blocks {
+			if block.Header.BlockHeight > manifest.Height {
+				break
+			}
+			consensus.commitBlock(block)
+		}
+	}
+
+	block := consensus.ledgerCache.GetLastCommittedBlock()
+	if len(manifest.Hash) != 0 && block.Header.BlockHeight == manifest.Height &&
+		!bytes.Equal(block.Header.BlockHash, manifest.Hash) {
+		consensus.logger.Fatalf("[%x] block %d-%x differs from the snapshot hash %x", consensus.Id,
+			block.Header.BlockHeight, block.Header.BlockHash, manifest.Hash)
+	}
+	consensus.logger.Infof("[%x] snapshot installed at height %d", consensus.Id, manifest.Height)
+	select {
+	case consensus.snapInstalledC <- struct{}{}:
+	case <-consensus.closeC:
+	}
+}
+
+// fetchSnapChunk requests the blocks from height of the leader, and waits for the chunk in response
+func (consensus *ConsensusRaftImpl) fetchSnapChunk(height uint64) ([]*common.Block, error) {
+	lead := consensus.node.Status().Lead
+	if lead == 0 || lead == consensus.Id {
+		time.Sleep(snapChunkTimeout)
+		return nil, errors.New("no leader to fetch from")
+	}
+	consensus.sendMessages([]raftpb.Message{{
+		Type:  msgSnapChunkRequest,
+		To:    lead,
+		From:  consensus.Id,
+		Index: height,
+	}})
+
+	timer := time.NewTimer(snapChunkTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case chunk := <-consensus.snapChunkC:
+			if chunk.From != lead || chunk.Index != height {
+				continue // the response of an earlier request
+			}
+			if chunk.Reject {
+				return nil, fmt.Errorf("rejected by %x", lead)
+			}
+			blocks := make([]*common.Block, 0, len(chunk.Entries))
+			for i, entry := range chunk.Entries {
+				block := new(common.Block)
+				if err := proto.Unmarshal(entry.Data, block); err != nil {
+					return nil, err
+				}
+				if block.Header.BlockHeight != height+uint64(i) {
+					return nil, fmt.Errorf("unexpected block %d in chunk", block.Header.BlockHeight)
+				}
+				blocks = append(blocks, block)
+			}
+			return blocks, nil
+		case <-timer.C:
+			return nil, errSnapChunkTimeout
+		case <-consensus.closeC:
+			return nil, errors.New("consensus closed")
+		}
+	}
+}
+
+// onSnapChunkMessage handles the snapshot chunk messages, it returns false for the other messages
+func (consensus *ConsensusRaftImpl) onSnapChunkMessage(msg raftpb.Message) bool {
+	switch msg.Type {
+	case msgSnapChunkRequest:
+		// the blocks are read by serveSnapChunks, not on the msgbus goroutine
+		select {
+		case consensus.snapChunkReqC <- msg:
+		default:
+			consensus.logger.Debugf("[%x] drop snapshot chunk request from %x", consensus.Id, msg.From)
+		}
+	case msgSnapChunk:
+		select {
+		case consensus.snapChunkC <- msg:
+		default:
+			consensus.logger.Debugf("[%x] drop snapshot chunk from %x", consensus.Id, msg.From)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// serveSnapChunks responds the snapshot chunk requests until consensus is closed
+func (consensus *ConsensusRaftImpl) serveSnapChunks() {
+	for {
+		select {
+		case req := <-consensus.snapChunkReqC:
+			consensus.sendMessages([]raftpb.Message{consensus.snapChunk(req.From, req.Index)})
+		case <-consensus.closeC:
+			return
+		}
+	}
+}
+
+// snapChunk returns the chunk of the committed blocks from height to the node of to. The blocks in a chunk
+// are at most snapChunkMaxBytes, except a chunk of a single block larger than it.
+func (consensus *ConsensusRaftImpl) snapChunk(to, height uint64) raftpb.Message {
+	chunk := raftpb.Message{
+		Type:  msgSnapChunk,
+		To:    to,
+		From:  consensus.Id,
+		Index: height,
+	}
+	current, err := consensus.ledgerCache.CurrentHeight()
+	if err != nil || height > current {
+		chunk.Reject = true
+		return chunk
+	}
+	size := 0
+	for h := height; h <= current && len(chunk.Entries) < snapChunkMaxBlocks; h++ {
+		block, err := consensus.store.GetBlock(h)
+		if err != nil || block == nil {
+			consensus.logger.Errorf("[%x] get block %d for snapshot chunk error: %v", consensus.Id, h, err)
+			break
+		}
+		data := mustMarshal(block)
+		if len(chunk.Entries) > 0 && size+len(data) > snapChunkMaxBytes {
+			break
+		}
+		size += len(data)
+		chunk.Entries = append(chunk.Entries, raftpb.Entry{Index: h, Data: data})
+	}
+	chunk.Reject = len(chunk.Entries) == 0
+	return chunk
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package raft
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/logger/v2"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/v3/raftpb"
+)
+
+func TestSnapChunk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ledgerCache := mock.NewMockLedgerCache(ctrl)
+	ledgerCache.EXPECT().CurrentHeight().Return(uint64(150), nil).AnyTimes()
+	store := mock.NewMockBlockchainStore(ctrl)
+	store.EXPECT().GetBlock(gomock.Any()).DoAndReturn(func(height uint64) (*common.Block, error) {
+		return &common.Block{Header: &common.BlockHeader{BlockHeight: height}}, nil
+	}).AnyTimes()
+	consensus := &ConsensusRaftImpl{
+		logger:      logger.GetLoggerByChain(logger.MODULE_CONSENSUS, "chain1"),
+		Id:          1,
+		ledgerCache: ledgerCache,
+		store:       store,
+	}
+
+	chunk := consensus.snapChunk(2, 10)
+	require.Equal(t, msgSnapChunk, chunk.Type)
+	require.Equal(t, uint64(2), chunk.To)
+	require.Equal(t, uint64(10), chunk.Index)
+	require.False(t, chunk.Reject)
+	require.Len(t, chunk.Entries, snapChunkMaxBlocks)
+	for i, entry := range chunk.Entries {
+		block := new(common.Block)
+		mustUnmarshal(entry.Data, block)
+		require.Equal(t, uint64(10+i), block.Header.BlockHeight)
+	}
+
+	require.Len(t, consensus.snapChunk(2, 100).Entries, 51)
+	require.True(t, consensus.snapChunk(2, 151).Reject)
+}
+
+func TestSnapChunkMaxBytes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ledgerCache := mock.NewMockLedgerCache(ctrl)
+	ledgerCache.EXPECT().CurrentHeight().Return(uint64(150), nil).AnyTimes()
+	store := mock.NewMockBlockchainStore(ctrl)
+	store.EXPECT().GetBlock(gomock.Any()).DoAndReturn(func(height uint64) (*common.Block, error) {
+		// the blocks above 100 are larger than a chunk
+		size := snapChunkMaxBytes / 3
+		if height > 100 {
+			size = snapChunkMaxBytes
+		}
+		return &common.Block{Header: &common.BlockHeader{BlockHeight: height, Signature: make([]byte, size)}}, nil
+	}).AnyTimes()
+	consensus := &ConsensusRaftImpl{
+		logger:      logger.GetLoggerByChain(logger.MODULE_CONSENSUS, "chain1"),
+		Id:          1,
+		ledgerCache: ledgerCache,
+		store:       store,
+	}
+
+	chunk := consensus.snapChunk(2, 10)
+	require.Len(t, chunk.Entries, 2)
+	size := 0
+	for _, entry := range chunk.Entries {
+		size += len(entry.Data)
+	}
+	require.LessOrEqual(t, size, snapChunkMaxBytes)
+
+	// a block larger than a chunk is sent alone
+	chunk = consensus.snapChunk(2, 101)
+	require.Len(t, chunk.Entries, 1)
+	require.False(t, chunk.Reject)
+}
+
+func TestSnapChunkRequestQueued(t *testing.T) {
+	// no store is read on the msgbus goroutine
+	consensus := &ConsensusRaftImpl{
+		logger:        logger.GetLoggerByChain(logger.MODULE_CONSENSUS, "chain1"),
+		Id:            1,
+		snapChunkReqC: make(chan raftpb.Message, 1),
+	}
+
+	require.True(t, consensus.onSnapChunkMessage(raftpb.Message{Type: msgSnapChunkRequest, From: 2, Index: 10}))
+	// dropped when the queue is full, the follower requests again on timeout
+	require.True(t, consensus.onSnapChunkMessage(raftpb.Message{Type: msgSnapChunkRequest, From: 3, Index: 10}))
+	require.Len(t, consensus.snapChunkReqC, 1)
+	req := <-consensus.snapChunkReqC
+	require.Equal(t, uint64(2), req.From)
+	require.False(t, consensus.onSnapChunkMessage(raftpb.Message{Type: raftpb.MsgHeartbeat}))
+}
+
+func TestPublishSnapshotInBackground(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	block := &common.Block{Header: &common.BlockHeader{BlockHeight: 10, BlockHash: []byte("hash10")}}
+	ledgerCache := mock.NewMockLedgerCache(ctrl)
+	ledgerCache.EXPECT().CurrentHeight().Return(uint64(10), nil).AnyTimes()
+	ledgerCache.EXPECT().GetLastCommittedBlock().Return(block).AnyTimes()
+	consensus := &ConsensusRaftImpl{
+		logger:         logger.GetLoggerByChain(logger.MODULE_CONSENSUS, "chain1"),
+		Id:             1,
+		ledgerCache:    ledgerCache,
+		closeC:         make(chan struct{}),
+		snapInstalledC: make(chan struct{}, 1),
+	}
+	data, err := json.Marshal(SnapshotManifest{Height: 10, Hash: []byte("hash10")})
+	require.NoError(t, err)
+
+	// the lock held by an earlier installation delays the installation, not publishSnapshot
+	consensus.snapInstallMu.Lock()
+	consensus.publishSnapshot(raftpb.Snapshot{Data: data, Metadata: raftpb.SnapshotMetadata{Index: 5, Term: 1}})
+	require.Equal(t, uint64(5), consensus.appliedIndex)
+	require.Len(t, consensus.snapInstalledC, 0)
+	consensus.snapInstallMu.Unlock()
+
+	select {
+	case <-consensus.snapInstalledC:
+	case <-time.After(time.Second):
+		t.Fatal("snapshot is not installed")
+	}
+}
+
+func TestPublishPendingEntries(t *testing.T) {
+	consensus := &ConsensusRaftImpl{
+		logger:             logger.GetLoggerByChain(logger.MODULE_CONSENSUS, "chain1"),
+		Id:                 1,
+		appliedIndex:       5,
+		snapshotIndex:      5,
+		snapCount:          defaultSnapCount,
+		installingSnapshot: 2,
+		pendingEntries:     []raftpb.Entry{{Index: 6, Term: 1}, {Index: 7, Term: 1}},
+	}
+
+	// published after the last snapshot is installed
+	require.False(t, consensus.publishPendingEntries())
+	require.Len(t, consensus.pendingEntries, 2)
+	require.Equal(t, uint64(5), consensus.appliedIndex)
+
+	require.False(t, consensus.publishPendingEntries())
+	require.Empty(t, consensus.pendingEntries)
+	require.Equal(t, uint64(7), consensus.appliedIndex)
+	require.Equal(t, int32(0), consensus.installingSnapshot)
+}
@@ -11,6 +11,14 @@ type SnapshotHeight struct {
 	Height uint64
 }
 
+// SnapshotManifest is the data of raft snapshot, which describes the blocks from genesis to Height.
+// The blocks are not carried in the snapshot, a follower installing it fetches the blocks it lacks
+// in chunks from the leader. It is compatible with SnapshotHeight of the earlier snapshots.
+type SnapshotManifest struct {
+	Height uint64
+	Hash   []byte // hash of the block at Height, empty in the earlier snapshots
+}
+
 // AdditionalData contains consensus specified data to be store in block
 type AdditionalData struct {
 	Signature []byte
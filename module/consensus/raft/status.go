@@ -0,0 +1,158 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package raft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// raftStatus is the status of a raft instance served by StatusHandler
+type raftStatus struct {
+	Id       string   `json:"id"`
+	NodeId   string   `json:"node_id"`
+	Leader   string   `json:"leader"`
+	State    string   `json:"state"`
+	Term     uint64   `json:"term"`
+	Commit   uint64   `json:"commit"`
+	Applied  uint64   `json:"applied"`
+	Voters   []string `json:"voters"`   // the voters in raft config
+	Learners []string `json:"learners"` // the learners in raft config
+	// the learners in chain config, which are promoted by removing them from it
+	ConfigLearners   []string                 `json:"config_learners"`
+	PreferredLeaders []string                 `json:"preferred_leaders"`
+	LeadTransferee   string                   `json:"lead_transferee,omitempty"` // the leadership transferring to
+	Progress         map[string]*peerProgress `json:"progress,omitempty"`        // only on leader
+}
+
+// peerProgress is the replication progress of a peer known by leader
+type peerProgress struct {
+	Match   uint64 `json:"match"`
+	Next    uint64 `json:"next"`
+	State   string `json:"state"`
+	Learner bool   `json:"learner"`
+}
+
+// StatusHandler returns the http handler serving the status of the raft instance of chain in json,
+// at "?chain_id=<chain id>", and the chain ids of the raft instances without chain_id.
+func StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chainId := r.URL.Query().Get("chain_id")
+		var result interface{}
+		if chainId == "" {
+			chainIds := make([]string, 0)
+			rangeStarted(func(consensus *ConsensusRaftImpl) bool {
+				chainIds = append(chainIds, consensus.chainID)
+				return true
+			})
+			sort.Strings(chainIds)
+			result = chainIds
+		} else {
+			rangeStarted(func(consensus *ConsensusRaftImpl) bool {
+				if consensus.chainID == chainId {
+					result = consensus.status()
+				}
+				return result == nil
+			})
+			if result == nil {
+				http.Error(w, "no raft instance of chain "+chainId, http.StatusNotFound)
+				return
+			}
+		}
+		bz, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(bz)
+	})
+}
+
+// rangeStarted calls f on the started raft instances until it returns false
+func rangeStarted(f func(consensus *ConsensusRaftImpl) bool) {
+	instances.Range(func(key, value interface{}) bool {
+		if started, ok := isStarted.Load(key); !ok || !started.(bool) {
+			return true
+		}
+		return f(value.(*ConsensusRaftImpl))
+	})
+}
+
+// status returns the current status of raft node
+func (consensus *ConsensusRaftImpl) status() *raftStatus {
+	st := consensus.node.Status()
+	status := &raftStatus{
+		Id:       fmt.Sprintf("%x", consensus.Id),
+		NodeId:   consensus.nodeIdOf(consensus.Id),
+		State:    st.RaftState.String(),
+		Term:     st.Term,
+		Commit:   st.Commit,
+		Applied:  st.Applied,
+		Voters:   make([]string, 0),
+		Learners: make([]string, 0),
+	}
+	if st.Lead != 0 {
+		status.Leader = consensus.nodeIdOf(st.Lead)
+	}
+	if st.LeadTransferee != 0 {
+		status.LeadTransferee = consensus.nodeIdOf(st.LeadTransferee)
+	}
+	voters, learners := make(map[uint64]bool), make(map[uint64]bool)
+	for id := range st.Config.Voters.IDs() {
+		voters[id] = true
+	}
+	for id := range st.Config.Learners {
+		learners[id] = true
+	}
+	for _, id := range sortedIds(voters) {
+		status.Voters = append(status.Voters, consensus.nodeIdOf(id))
+	}
+	for _, id := range sortedIds(learners) {
+		status.Learners = append(status.Learners, consensus.nodeIdOf(id))
+	}
+
+	consensusConfig := consensus.chainConf.ChainConfig().Consensus
+	if configLearners, err := parseLearners(consensusConfig); err == nil {
+		status.ConfigLearners = sortedNodeIds(configLearners)
+	}
+	if preferredLeaders, err := parsePreferredLeaders(consensusConfig); err == nil {
+		status.PreferredLeaders = sortedNodeIds(preferredLeaders)
+	}
+
+	if len(st.Progress) != 0 {
+		status.Progress = make(map[string]*peerProgress, len(st.Progress))
+		for id, progress := range st.Progress {
+			status.Progress[consensus.nodeIdOf(id)] = &peerProgress{
+				Match:   progress.Match,
+				Next:    progress.Next,
+				State:   progress.State.String(),
+				Learner: progress.IsLearner,
+			}
+		}
+	}
+	return status
+}
+
+// nodeIdOf returns the node id of raft id, or the raft id in hex if it is unknown
+func (consensus *ConsensusRaftImpl) nodeIdOf(id uint64) string {
+	if nodeId, ok := consensus.idToNodeId.Load(id); ok {
+		return nodeId.(string)
+	}
+	return fmt.Sprintf("%x", id)
+}
+
+func sortedNodeIds(set map[string]bool) []string {
+	nodeIds := make([]string, 0, len(set))
+	for nodeId := range set {
+		nodeIds = append(nodeIds, nodeId)
+	}
+	sort.Strings(nodeIds)
+	return nodeIds
+}
@@ -0,0 +1,270 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package safewal is the consensus wal with a checksum of each entry.
+//
+// An entry is written as magic | crc32c(data) | data. The entries written before checksums have no magic,
+// they are read as they are, so the wals of former versions are still readable.
+//
+// A write torn by a power loss leaves a corrupt tail in the last segment of wal, which makes the wal unable
+// to open, or the entries at the end left empty by the zeros of a file extended before its data was written.
+// Open repairs both by truncating the tail, the entries truncated are the ones not written completely,
+// so they were not synced and the messages of them were not sent.
+//
+// An entry written completely but failing the checksum may have been synced, and its messages sent, so
+// truncating it may make the node vote twice. Open fails on it instead, it is checked by the wal verify
+// command and truncated by the wal repair command with --force, by the operator only.
+// The corrupt entries followed by valid ones are not repaired, they are reported by Read.
+//
+// The versions before the checksums can not read the entries with magic, so a node can not be downgraded
+// to them with its wal, which must be removed before the downgrade.
+package safewal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"chainmaker.org/chainmaker/common/v2/wal"
+)
+
+var (
+	// ErrChecksum is returned when an entry fails the checksum
+	ErrChecksum = errors.New("wal entry checksum mismatch")
+	// ErrCorruptTail is returned by Open when the entries at the end are complete but fail the checksum
+	ErrCorruptTail = errors.New("wal has complete but corrupt entries at the end")
+
+	magic      = []byte{0xff, 'c', 'r', 'c'} // not the first byte of a protobuf message with small field numbers
+	crcTable   = crc32.MakeTable(crc32.Castagnoli)
+	headerSize = len(magic) + crc32.Size
+)
+
+// Log is a wal of entries with checksums
+type Log struct {
+	log *wal.Log
+}
+
+// Open opens the wal in dir and repairs the tail torn by a write, returns the repairs done.
+// ErrCorruptTail is returned if the entries at the end are complete but corrupt.
+func Open(dir string) (*Log, []string, error) {
+	return open(dir, false)
+}
+
+// ForceRepair truncates the corrupt entries at the end of wal in dir, including the complete ones,
+// returns the repairs done. The messages of the complete entries truncated may have been sent.
+func ForceRepair(dir string) ([]string, error) {
+	l, repairs, err := open(dir, true)
+	if err != nil {
+		return nil, err
+	}
+	return repairs, l.Close()
+}
+
+func open(dir string, force bool) (*Log, []string, error) {
+	var repairs []string
+	walLog, err := wal.Open(dir, nil)
+	if err == wal.ErrCorrupt {
+		var repair string
+		if repair, err = repairLastSegment(dir); err != nil {
+			return nil, nil, fmt.Errorf("repair wal %s failed, %s", dir, err)
+		}
+		repairs = append(repairs, repair)
+		walLog, err = wal.Open(dir, nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := &Log{log: walLog}
+	repair, err := l.truncateCorruptTail(force)
+	if err != nil {
+		_ = walLog.Close()
+		return nil, nil, fmt.Errorf("repair wal %s failed, %w", dir, err)
+	}
+	if repair != "" {
+		repairs = append(repairs, repair)
+	}
+	return l, repairs, nil
+}
+
+// Encode returns the entry of data with checksum
+func Encode(data []byte) []byte {
+	entry := make([]byte, headerSize, headerSize+len(data))
+	copy(entry, magic)
+	binary.BigEndian.PutUint32(entry[len(magic):], crc32.Checksum(data, crcTable))
+	return append(entry, data...)
+}
+
+// Decode returns the data of entry, checked is false if the entry has no checksum
+func Decode(entry []byte) (data []byte, checked bool, err error) {
+	// the entries are never empty, an empty one is the zeros of a torn write
+	if len(entry) == 0 {
+		return nil, false, ErrChecksum
+	}
+	if !bytes.HasPrefix(entry, magic) {
+		return entry, false, nil
+	}
+	if len(entry) < headerSize {
+		return nil, true, ErrChecksum
+	}
+	data = entry[headerSize:]
+	if binary.BigEndian.Uint32(entry[len(magic):]) != crc32.Checksum(data, crcTable) {
+		return nil, true, ErrChecksum
+	}
+	return data, true, nil
+}
+
+// Write writes data with checksum at index
+func (l *Log) Write(index uint64, data []byte) error {
+	return l.log.Write(index, Encode(data))
+}
+
+// Read reads the data at index, ErrChecksum is returned if it is corrupt
+func (l *Log) Read(index uint64) ([]byte, error) {
+	entry, err := l.log.Read(index)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := Decode(entry)
+	if err != nil {
+		return nil, fmt.Errorf("read entry[%d] failed, %w", index, err)
+	}
+	return data, nil
+}
+
+// ReadEntry reads the data at index, checked is false if the entry has no checksum
+func (l *Log) ReadEntry(index uint64) (data []byte, checked bool, err error) {
+	entry, err := l.log.Read(index)
+	if err != nil {
+		return nil, false, err
+	}
+	return Decode(entry)
+}
+
+// FirstIndex returns the index of the first entry, 0 if there is no entry
+func (l *Log) FirstIndex() (uint64, error) {
+	return l.log.FirstIndex()
+}
+
+// LastIndex returns the index of the last entry, 0 if there is no entry
+func (l *Log) LastIndex() (uint64, error) {
+	return l.log.LastIndex()
+}
+
+// TruncateFront removes the entries before index
+func (l *Log) TruncateFront(index uint64) error {
+	return l.log.TruncateFront(index)
+}
+
+// TruncateBack removes the entries after index
+func (l *Log) TruncateBack(index uint64) error {
+	return l.log.TruncateBack(index)
+}
+
+// Sync syncs the entries written to disk
+func (l *Log) Sync() error {
+	return l.log.Sync()
+}
+
+// Close closes the wal
+func (l *Log) Close() error {
+	return l.log.Close()
+}
+
+// truncateCorruptTail truncates the empty entries at the end of wal left by a torn write,
+// and the complete entries failing the checksum there if force
+func (l *Log) truncateCorruptTail(force bool) (string, error) {
+	firstIndex, err := l.log.FirstIndex()
+	if err != nil {
+		return "", err
+	}
+	lastIndex, err := l.log.LastIndex()
+	if err != nil || lastIndex == 0 {
+		return "", err
+	}
+	index := lastIndex
+	for ; index >= firstIndex; index-- {
+		entry, err := l.log.Read(index)
+		if err != nil {
+			return "", err
+		}
+		if len(entry) == 0 {
+			continue
+		}
+		if _, _, err = Decode(entry); err == nil {
+			break
+		}
+		if !errors.Is(err, ErrChecksum) {
+			return "", err
+		}
+		if !force {
+			return "", fmt.Errorf("%w, entry[%d] in [%d, %d], run wal verify for details",
+				ErrCorruptTail, index, firstIndex, lastIndex)
+		}
+	}
+	switch {
+	case index == lastIndex:
+		return "", nil
+	case index < firstIndex:
+		return "", fmt.Errorf("all the entries [%d, %d] are corrupt", firstIndex, lastIndex)
+	}
+	if err = l.log.TruncateBack(index); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("truncate %d corrupt entries from index %d", lastIndex-index, index+1), nil
+}
+
+// repairLastSegment truncates the last segment file of wal to its last complete entry.
+// The segment files are named by the index of their first entry, with the entries in the binary format
+// of wal, which is uvarint(len(entry)) | entry.
+func repairLastSegment(dir string) (string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var last string
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || len(name) != 20 {
+			continue
+		}
+		if _, err = strconv.ParseUint(name, 10, 64); err == nil && name > last {
+			last = name
+		}
+	}
+	if last == "" {
+		return "", errors.New("no segment found")
+	}
+
+	segment := filepath.Join(dir, last)
+	data, err := ioutil.ReadFile(segment)
+	if err != nil {
+		return "", err
+	}
+	var pos, count int
+	for pos < len(data) {
+		size, n := binary.Uvarint(data[pos:])
+		if n <= 0 || uint64(len(data)-pos-n) < size {
+			break
+		}
+		pos += n + int(size)
+		count++
+	}
+	if pos == len(data) {
+		return "", fmt.Errorf("segment %s is not corrupt at the end", segment)
+	}
+	if err = os.Truncate(segment, int64(pos)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("truncate segment %s from %d bytes to %d bytes of %d entries",
+		segment, len(data), pos, count), nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package safewal
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chainmaker.org/chainmaker/common/v2/wal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	data := []byte("consensus msg")
+	entry := Encode(data)
+	decoded, checked, err := Decode(entry)
+	require.NoError(t, err)
+	require.True(t, checked)
+	require.Equal(t, data, decoded)
+
+	// the entries without checksum
+	decoded, checked, err = Decode(data)
+	require.NoError(t, err)
+	require.False(t, checked)
+	require.Equal(t, data, decoded)
+
+	entry[len(entry)-1]++
+	_, _, err = Decode(entry)
+	require.True(t, errors.Is(err, ErrChecksum))
+	_, _, err = Decode(entry[:headerSize-1])
+	require.True(t, errors.Is(err, ErrChecksum))
+	_, _, err = Decode(nil)
+	require.True(t, errors.Is(err, ErrChecksum))
+}
+
+func writeEntries(t *testing.T, dir string, from, to uint64) {
+	l, repairs, err := Open(dir)
+	require.NoError(t, err)
+	require.Empty(t, repairs)
+	for i := from; i <= to; i++ {
+		require.NoError(t, l.Write(i, []byte(fmt.Sprintf("entry %d", i))))
+	}
+	require.NoError(t, l.Close())
+}
+
+func TestOpenTornSegment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "safewal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeEntries(t, dir, 1, 10)
+
+	// a torn write of the 11th entry
+	segment := filepath.Join(dir, fmt.Sprintf("%020d", 1))
+	f, err := os.OpenFile(segment, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 0xff, 'c'})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = wal.Open(dir, nil)
+	require.Equal(t, wal.ErrCorrupt, err)
+
+	l, repairs, err := Open(dir)
+	require.NoError(t, err)
+	require.Len(t, repairs, 1)
+	lastIndex, err := l.LastIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), lastIndex)
+	data, err := l.Read(10)
+	require.NoError(t, err)
+	require.Equal(t, []byte("entry 10"), data)
+	require.NoError(t, l.Write(11, []byte("entry 11")))
+	require.NoError(t, l.Close())
+}
+
+func TestOpenCorruptTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "safewal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	writeEntries(t, dir, 1, 10)
+
+	// the empty entries of a torn write
+	walLog, err := wal.Open(dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, walLog.Write(11, make([]byte, 0)))
+	require.NoError(t, walLog.Write(12, make([]byte, 0)))
+	require.NoError(t, walLog.Close())
+
+	l, repairs, err := Open(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"truncate 2 corrupt entries from index 11"}, repairs)
+	lastIndex, err := l.LastIndex()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), lastIndex)
+	require.NoError(t, l.Close())
+
+	// the entries written completely but corrupt are truncated by force only
+	walLog, err = wal.Open(dir, nil)
+	require.NoError(t, err)
+	entry := Encode([]byte("entry 11"))
+	entry[len(entry)-1]++
+	require.NoError(t, walLog.Write(11, entry))
+	require.NoError(t, walLog.Write(12, make([]byte, 0)))
+	require.NoError(t, walLog.Close())
+
+	_, _, err = Open(dir)
+	require.True(t, errors.Is(err, ErrCorruptTail))
+	repairs, err = ForceRepair(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"truncate 2 corrupt entries from index 11"}, repairs)
+	writeEntries(t, dir, 11, 11)
+
+	// the corrupt entries followed by valid ones are not repaired
+	walLog, err = wal.Open(dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, walLog.TruncateBack(10))
+	require.NoError(t, walLog.Write(11, entry))
+	require.NoError(t, walLog.Write(12, Encode([]byte("entry 12"))))
+	require.NoError(t, walLog.Close())
+	l, repairs, err = Open(dir)
+	require.NoError(t, err)
+	require.Empty(t, repairs)
+	_, err = l.Read(11)
+	require.True(t, errors.Is(err, ErrChecksum))
+	require.NoError(t, l.Close())
+}
@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"chainmaker.org/chainmaker-go/consensus/proposerhint"
 	"chainmaker.org/chainmaker/pb-go/v2/common"
 	consensuspb "chainmaker.org/chainmaker/pb-go/v2/consensus"
 
@@ -60,6 +61,8 @@ func (consensus *ConsensusSoloImpl) Start() error {
 	consensus.msgbus.Register(msgbus.ProposedBlock, consensus)
 	consensus.msgbus.Register(msgbus.VerifyResult, consensus)
 	go consensus.procProposerStatus()
+	// the only node proposes every height
+	proposerhint.Register(consensus.chainID, func(uint64) bool { return true })
 
 	clog.Infof("ConsensusSoloImpl %s started", consensus.id)
 	return nil
@@ -68,6 +71,7 @@ func (consensus *ConsensusSoloImpl) Start() error {
 // Stop implements the Stop method of ConsensusEngine interface.
 // TODO: implement Stop method
 func (consensus *ConsensusSoloImpl) Stop() error {
+	proposerhint.Unregister(consensus.chainID)
 	clog.Infof("ConsensusSoloImpl %s stoped", consensus.id)
 	return nil
 }
@@ -169,7 +173,7 @@ func (consensus *ConsensusSoloImpl) handleVerifyResult(message *msgbus.Message)
 		clog.Errorf("unmatch block hash %s", verifyResult.VerifiedBlock.Header.BlockHash)
 		return
 	}
-	if verifyResult.Code == consensuspb.VerifyResult_FAIL {
+	if verifyResult.Code != consensuspb.VerifyResult_SUCCESS {
 		clog.Errorf("block verified failed")
 		consensus.verifyingBlock = nil
 		return
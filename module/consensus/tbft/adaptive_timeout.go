@@ -0,0 +1,134 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+)
+
+const (
+	// TBFTAdaptiveTimeoutKey is the key in consensus ext config to enable adaptive timeouts, "true" or "false".
+	// The propose, prevote and precommit timeouts are tuned by the step durations of recent heights,
+	// within [TBFT_adaptive_timeout_min, TBFT_adaptive_timeout_max].
+	TBFTAdaptiveTimeoutKey = "TBFT_adaptive_timeout"
+	// TBFTAdaptiveTimeoutMinKey is the key in consensus ext config of the lower bound of adaptive timeouts
+	TBFTAdaptiveTimeoutMinKey = "TBFT_adaptive_timeout_min"
+	// TBFTAdaptiveTimeoutMaxKey is the key in consensus ext config of the upper bound of adaptive timeouts,
+	// the fixed timeout of each step by default
+	TBFTAdaptiveTimeoutMaxKey = "TBFT_adaptive_timeout_max"
+
+	defaultAdaptiveTimeoutMin = 1 * time.Second
+	adaptiveTimeoutWindow     = 50 // the number of recent durations kept for each step
+	adaptiveTimeoutMinSamples = 10 // the fixed timeouts are used until there are enough durations
+	adaptiveTimeoutMultiplier = 3  // the timeout is the median duration times it
+)
+
+// adaptiveTimeoutConfig is the config of adaptive timeouts
+type adaptiveTimeoutConfig struct {
+	enabled bool
+	min     time.Duration
+	max     time.Duration // 0 for the fixed timeout of each step
+}
+
+// parseAdaptiveTimeoutConfig parses the adaptive timeout config in consensus ext config
+func parseAdaptiveTimeoutConfig(config *config.ConsensusConfig) (*adaptiveTimeoutConfig, error) {
+	c := &adaptiveTimeoutConfig{min: defaultAdaptiveTimeoutMin}
+	var err error
+	for _, kv := range config.ExtConfig {
+		switch kv.Key {
+		case TBFTAdaptiveTimeoutKey:
+			c.enabled, err = strconv.ParseBool(string(kv.Value))
+		case TBFTAdaptiveTimeoutMinKey:
+			c.min, err = time.ParseDuration(string(kv.Value))
+		case TBFTAdaptiveTimeoutMaxKey:
+			c.max, err = time.ParseDuration(string(kv.Value))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", kv.Key, err)
+		}
+	}
+	if c.min <= 0 || c.max < 0 || (c.max > 0 && c.max < c.min) {
+		return nil, fmt.Errorf("invalid adaptive timeout bounds [%v, %v]", c.min, c.max)
+	}
+	return c, nil
+}
+
+// adaptiveTimeout tunes the timeouts of steps by the durations observed in the rounds committing blocks.
+//
+// The duration of a step is the time from entering it to the quorum of votes (or the proposal) which ends it,
+// it is decided by the fastest validators making up the quorum, so a single slow validator does not
+// lengthen it unless it is needed for the quorum. The timeout is a multiple of the median duration,
+// which is not moved by the delays of a minority of rounds, such as the ones of a slow or Byzantine proposer,
+// and the bounds cap the timeouts whatever the durations are.
+type adaptiveTimeout struct {
+	config    *adaptiveTimeoutConfig
+	durations map[tbftpb.Step][]time.Duration // step => recent durations
+}
+
+func newAdaptiveTimeout() *adaptiveTimeout {
+	return &adaptiveTimeout{
+		config:    &adaptiveTimeoutConfig{min: defaultAdaptiveTimeoutMin},
+		durations: make(map[tbftpb.Step][]time.Duration),
+	}
+}
+
+// updateConfig sets the config, the durations observed are kept
+func (a *adaptiveTimeout) updateConfig(config *adaptiveTimeoutConfig) {
+	a.config = config
+}
+
+// observe records the step durations of a round which commits a block
+func (a *adaptiveTimeout) observe(r *roundMetrics) {
+	a.add(tbftpb.Step_PROPOSE, r.enterProposalTime, r.enterPrevoteTime)
+	a.add(tbftpb.Step_PREVOTE, r.enterPrevoteTime, r.enterPrecommitTime)
+	a.add(tbftpb.Step_PRECOMMIT, r.enterPrecommitTime, r.enterCommitTime)
+}
+
+func (a *adaptiveTimeout) add(step tbftpb.Step, start, end time.Time) {
+	// the step is skipped if it is not entered, e.g. the node catches up with the votes of others
+	if start.IsZero() || end.IsZero() || !end.After(start) {
+		return
+	}
+	durations := append(a.durations[step], end.Sub(start))
+	if len(durations) > adaptiveTimeoutWindow {
+		durations = durations[len(durations)-adaptiveTimeoutWindow:]
+	}
+	a.durations[step] = durations
+}
+
+// timeout returns the timeout of step at round, fixed is the timeout configured for the step,
+// which is returned if adaptive timeouts are disabled or there are not enough durations
+func (a *adaptiveTimeout) timeout(step tbftpb.Step, round int32, fixed time.Duration,
+	delta time.Duration) time.Duration {
+	durations := a.durations[step]
+	if !a.config.enabled || len(durations) < adaptiveTimeoutMinSamples {
+		return fixed + delta*time.Duration(round)
+	}
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	timeout := sorted[len(sorted)/2] * adaptiveTimeoutMultiplier
+
+	max := a.config.max
+	if max == 0 {
+		max = fixed
+	}
+	if timeout > max {
+		timeout = max
+	}
+	if timeout < a.config.min {
+		timeout = a.config.min
+	}
+	// the timeout still grows by round, so that the rounds can always be long enough to reach consensus
+	return timeout + delta*time.Duration(round)
+}
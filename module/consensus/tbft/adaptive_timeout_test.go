@@ -0,0 +1,85 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"testing"
+	"time"
+
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"github.com/stretchr/testify/require"
+)
+
+func newStepRound(propose, prevote, precommit time.Duration) *roundMetrics {
+	r := newRoundMetrics(0)
+	r.enterProposalTime = time.Now()
+	r.enterPrevoteTime = r.enterProposalTime.Add(propose)
+	r.enterPrecommitTime = r.enterPrevoteTime.Add(prevote)
+	r.enterCommitTime = r.enterPrecommitTime.Add(precommit)
+	return r
+}
+
+func TestParseAdaptiveTimeoutConfig(t *testing.T) {
+	config := &configpb.ConsensusConfig{}
+	c, err := parseAdaptiveTimeoutConfig(config)
+	require.NoError(t, err)
+	require.False(t, c.enabled)
+	require.Equal(t, defaultAdaptiveTimeoutMin, c.min)
+
+	config.ExtConfig = []*configpb.ConfigKeyValue{
+		{Key: TBFTAdaptiveTimeoutKey, Value: "true"},
+		{Key: TBFTAdaptiveTimeoutMinKey, Value: "200ms"},
+		{Key: TBFTAdaptiveTimeoutMaxKey, Value: "10s"},
+	}
+	c, err = parseAdaptiveTimeoutConfig(config)
+	require.NoError(t, err)
+	require.Equal(t, &adaptiveTimeoutConfig{enabled: true, min: 200 * time.Millisecond, max: 10 * time.Second}, c)
+
+	config.ExtConfig[2].Value = "100ms"
+	_, err = parseAdaptiveTimeoutConfig(config)
+	require.Error(t, err)
+	config.ExtConfig[0].Value = "yes"
+	_, err = parseAdaptiveTimeoutConfig(config)
+	require.Error(t, err)
+}
+
+func TestAdaptiveTimeout(t *testing.T) {
+	a := newAdaptiveTimeout()
+	fixed, delta := 30*time.Second, time.Second
+	for i := 0; i < adaptiveTimeoutMinSamples; i++ {
+		a.observe(newStepRound(time.Second, 500*time.Millisecond, 100*time.Millisecond))
+	}
+	// disabled
+	require.Equal(t, fixed+delta, a.timeout(tbftpb.Step_PROPOSE, 1, fixed, delta))
+
+	a.updateConfig(&adaptiveTimeoutConfig{enabled: true, min: 400 * time.Millisecond})
+	require.Equal(t, 3*time.Second, a.timeout(tbftpb.Step_PROPOSE, 0, fixed, delta))
+	require.Equal(t, 3*time.Second+2*delta, a.timeout(tbftpb.Step_PROPOSE, 2, fixed, delta))
+	require.Equal(t, 1500*time.Millisecond, a.timeout(tbftpb.Step_PREVOTE, 0, fixed, delta))
+	// bounded by min
+	require.Equal(t, 400*time.Millisecond, a.timeout(tbftpb.Step_PRECOMMIT, 0, fixed, delta))
+
+	// the rounds of a slow proposer do not move the median
+	for i := 0; i < adaptiveTimeoutMinSamples/2-1; i++ {
+		a.observe(newStepRound(25*time.Second, 500*time.Millisecond, 100*time.Millisecond))
+	}
+	require.Equal(t, 3*time.Second, a.timeout(tbftpb.Step_PROPOSE, 0, fixed, delta))
+
+	// bounded by max, which is the fixed timeout by default
+	for i := 0; i < adaptiveTimeoutWindow; i++ {
+		a.observe(newStepRound(20*time.Second, 500*time.Millisecond, 100*time.Millisecond))
+	}
+	require.Equal(t, fixed, a.timeout(tbftpb.Step_PROPOSE, 0, fixed, delta))
+	a.updateConfig(&adaptiveTimeoutConfig{enabled: true, min: time.Second, max: 10 * time.Second})
+	require.Equal(t, 10*time.Second, a.timeout(tbftpb.Step_PROPOSE, 0, fixed, delta))
+
+	// the steps not entered are skipped
+	r := newRoundMetrics(1)
+	a.observe(r)
+	require.Len(t, a.durations[tbftpb.Step_PROPOSE], adaptiveTimeoutWindow)
+}
@@ -0,0 +1,273 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"chainmaker.org/chainmaker-go/consensus/aggsig"
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	"chainmaker.org/chainmaker/common/v2/msgbus"
+	"chainmaker.org/chainmaker/localconf/v2"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	netpb "chainmaker.org/chainmaker/pb-go/v2/net"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+)
+
+// The commits are certified by a BLS aggregate signature when activation.TBFTAggregateSignatures is active.
+//
+// A validator with an aggregation key registered in TBFT_aggregate_keys signs the commit message of the block
+// besides its precommit, and sends the signature to the other validators. On commit, the signatures for the
+// block reaching the quorum are aggregated into a tbftextpb.AggregateCommit, which is stored in the block instead of
+// the precommits, so the block is verified by one pairing check. The block carries the precommits as before
+// if the signatures are not enough at that time, such as the validators without aggregation keys.
+//
+// The message type is out of the types defined in pb-go, so that the nodes before aggregation ignore it.
+const (
+	msgTypeCommitSignature = tbftpb.TBFTMsgType(tbftextpb.TBFTMsgTypeExt_MSG_COMMIT_SIGNATURE)
+
+	// TBFTAggregateKeysKey is the key in consensus ext config of the aggregation keys of validators,
+	// a json object of node id to aggsig.RegisteredKey
+	TBFTAggregateKeysKey = "TBFT_aggregate_keys"
+	// TBFTAggregateCommitKey is the key in block additional data of tbftextpb.AggregateCommit in protobuf
+	TBFTAggregateCommitKey = "TBFTAggregateCommit"
+
+	commitMessagePrefix = "chainmaker-tbft-commit"
+)
+
+// aggregateSignatures keeps the commit signatures of the current height
+type aggregateSignatures struct {
+	key        *aggsig.PrivateKey // nil if this node has no aggregation key
+	keys       map[string]*aggsig.PublicKey
+	signatures map[int32]map[string]*tbftextpb.CommitSignature // round => voter => signature
+}
+
+func newAggregateSignatures(key *aggsig.PrivateKey) *aggregateSignatures {
+	return &aggregateSignatures{
+		key:        key,
+		signatures: make(map[int32]map[string]*tbftextpb.CommitSignature),
+	}
+}
+
+// reset drops the signatures, it is called at a new height
+func (a *aggregateSignatures) reset() {
+	a.signatures = make(map[int32]map[string]*tbftextpb.CommitSignature)
+}
+
+// loadAggregateKey loads the aggregation key of this node, nil if there is no key file
+func loadAggregateKey() (*aggsig.PrivateKey, error) {
+	return aggsig.LoadNodeKey(localconf.ChainMakerConfig.NodeConfig.PrivKeyFile)
+}
+
+// parseAggregateKeys parses the aggregation keys in consensus ext config, nil if they are not configured
+func parseAggregateKeys(config *config.ConsensusConfig) (map[string]*aggsig.PublicKey, error) {
+	var keys map[string]*aggsig.PublicKey
+	for _, kv := range config.ExtConfig {
+		if kv.Key != TBFTAggregateKeysKey {
+			continue
+		}
+		var err error
+		if keys, err = aggsig.ParseKeys(string(kv.Value)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", TBFTAggregateKeysKey, err)
+		}
+	}
+	return keys, nil
+}
+
+// commitMessage returns the message signed for the commit of the block of hash at height and round
+func commitMessage(chainId string, height uint64, round int32, hash []byte) []byte {
+	msg := make([]byte, 0, len(commitMessagePrefix)+len(chainId)+len(hash)+16)
+	msg = append(msg, commitMessagePrefix...)
+	msg = append(msg, byte(len(chainId)))
+	msg = append(msg, chainId...)
+	var buf [12]byte
+	binary.BigEndian.PutUint64(buf[:8], height)
+	binary.BigEndian.PutUint32(buf[8:], uint32(round))
+	msg = append(msg, buf[:]...)
+	return append(msg, hash...)
+}
+
+// isAggregateActive returns true if the commits at height are certified by aggregate signatures
+func (consensus *ConsensusTBFTImpl) isAggregateActive(height uint64) bool {
+	return activation.IsActive(consensus.chainConf.ChainConfig(), activation.TBFTAggregateSignatures, height)
+}
+
+// sendCommitSignature signs the commit of the block of hash precommitted by this node,
+// and sends the signature to the other validators
+func (consensus *ConsensusTBFTImpl) sendCommitSignature(hash []byte) {
+	key := consensus.aggregate.key
+	if key == nil || !consensus.isAggregateActive(consensus.Height) {
+		return
+	}
+	if registered := consensus.aggregate.keys[consensus.Id]; !key.PublicKey().Equal(registered) {
+		consensus.logger.Warnf("[%s](%d/%d/%s) the aggregation key is not registered in %s",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, TBFTAggregateKeysKey)
+		return
+	}
+
+	sig := &tbftextpb.CommitSignature{
+		Voter:  consensus.Id,
+		Height: consensus.Height,
+		Round:  consensus.Round,
+		Hash:   hash,
+	}
+	sig.Signature = key.Sign(commitMessage(consensus.chainID, sig.Height, sig.Round, hash))
+	consensus.addCommitSignature(sig)
+
+	payload := mustMarshal(&tbftpb.TBFTMsg{
+		Type: msgTypeCommitSignature,
+		Msg:  mustMarshal(sig),
+	})
+	for _, validator := range consensus.validatorSet.List() {
+		if validator == consensus.Id {
+			continue
+		}
+		consensus.msgbus.Publish(msgbus.SendConsensusMsg, &netpb.NetMsg{
+			Payload: payload,
+			Type:    netpb.NetMsg_CONSENSUS_MSG,
+			To:      validator,
+		})
+	}
+}
+
+// procCommitSignature verifies the commit signature sent by a validator
+func (consensus *ConsensusTBFTImpl) procCommitSignature(msg *tbftpb.TBFTMsg) {
+	sig := &tbftextpb.CommitSignature{}
+	if err := proto.Unmarshal(msg.Msg, sig); err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid commit signature, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
+		return
+	}
+	if sig.Height != consensus.Height || isNilHash(sig.Hash) || !consensus.isAggregateActive(sig.Height) ||
+		!consensus.validatorSet.HasValidator(sig.Voter) {
+		return
+	}
+	pk, ok := consensus.aggregate.keys[sig.Voter]
+	if !ok {
+		return
+	}
+	if err := pk.Verify(commitMessage(consensus.chainID, sig.Height, sig.Round, sig.Hash), sig.Signature); err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive commit signature of %s(%d/%d), %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, sig.Voter, sig.Height, sig.Round, err)
+		return
+	}
+	consensus.addCommitSignature(sig)
+}
+
+// addCommitSignature adds the verified signature, only the first one of a voter in a round is kept
+func (consensus *ConsensusTBFTImpl) addCommitSignature(sig *tbftextpb.CommitSignature) {
+	signatures, ok := consensus.aggregate.signatures[sig.Round]
+	if !ok {
+		signatures = make(map[string]*tbftextpb.CommitSignature)
+		consensus.aggregate.signatures[sig.Round] = signatures
+	}
+	if _, ok = signatures[sig.Voter]; !ok {
+		signatures[sig.Voter] = sig
+	}
+}
+
+// aggregateCommit aggregates the commit signatures for the block of hash at round,
+// nil if the signers do not reach the quorum
+func (consensus *ConsensusTBFTImpl) aggregateCommit(round int32, hash []byte) *tbftextpb.AggregateCommit {
+	if !consensus.isAggregateActive(consensus.Height) {
+		return nil
+	}
+	signatures := consensus.aggregate.signatures[round]
+	validators := consensus.validatorSet.List()
+	signers := aggsig.NewBitmap(len(validators))
+	var sigs [][]byte
+	var weight uint64
+	for i, validator := range validators {
+		sig, ok := signatures[validator]
+		if !ok || !bytes.Equal(sig.Hash, hash) {
+			continue
+		}
+		signers.Set(i)
+		sigs = append(sigs, sig.Signature)
+		weight += consensus.validatorSet.WeightOf(validator)
+	}
+	if weight < consensus.validatorSet.Quorum() {
+		consensus.logger.Infof("[%s](%d/%d/%s) commit signatures of %d validators are not enough to aggregate",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, len(sigs))
+		return nil
+	}
+	aggregate, err := aggsig.Aggregate(sigs)
+	if err != nil {
+		consensus.logger.Errorf("[%s](%d/%d/%s) aggregate commit signatures failed, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
+		return nil
+	}
+	return &tbftextpb.AggregateCommit{
+		Round:     round,
+		Hash:      hash,
+		Signers:   signers,
+		Signature: aggregate,
+	}
+}
+
+// addCommitSignatures adds the commit certificate to the proposal block, the aggregate commit if possible,
+// otherwise the precommits in voteSet
+func (consensus *ConsensusTBFTImpl) addCommitSignatures(voteSet *VoteSet) {
+	block := consensus.Proposal.Block
+	if block.AdditionalData == nil {
+		block.AdditionalData = &common.AdditionalData{
+			ExtraData: make(map[string][]byte),
+		}
+	}
+	if commit := consensus.aggregateCommit(voteSet.Round, block.Header.BlockHash); commit != nil {
+		block.AdditionalData.ExtraData[TBFTAggregateCommitKey] = mustMarshal(commit)
+		return
+	}
+	block.AdditionalData.ExtraData[protocol.TBFTAddtionalDataKey] = voteSet.Marshal()
+}
+
+// verifyAggregateCommit verifies the aggregate commit of block in data, with the validators of block height
+func verifyAggregateCommit(chainConfig *config.ChainConfig, validatorSet *validatorSet, block *common.Block,
+	data []byte) error {
+	commit := &tbftextpb.AggregateCommit{}
+	if err := proto.Unmarshal(data, commit); err != nil {
+		return fmt.Errorf("unmarshal aggregate commit failed, %s", err)
+	}
+	if !bytes.Equal(commit.Hash, block.Header.BlockHash) {
+		return fmt.Errorf("unmatch aggregate commit: %x to block hash: %x", commit.Hash, block.Header.BlockHash)
+	}
+	keys, err := parseAggregateKeys(chainConfig.Consensus)
+	if err != nil {
+		return err
+	}
+
+	validators := validatorSet.List()
+	signers := aggsig.Bitmap(commit.Signers)
+	if len(signers) != len(aggsig.NewBitmap(len(validators))) {
+		return fmt.Errorf("aggregate commit signers of %d bytes, expect %d validators",
+			len(signers), len(validators))
+	}
+	var pks []*aggsig.PublicKey
+	var weight uint64
+	for i, validator := range validators {
+		if !signers.Has(i) {
+			continue
+		}
+		pk, ok := keys[validator]
+		if !ok {
+			return fmt.Errorf("aggregation key of %s is not registered", validator)
+		}
+		pks = append(pks, pk)
+		weight += validatorSet.WeightOf(validator)
+	}
+	if weight < validatorSet.Quorum() {
+		return fmt.Errorf("aggregate commit weight %d, expect >= %d", weight, validatorSet.Quorum())
+	}
+	msg := commitMessage(chainConfig.ChainId, block.Header.BlockHeight, commit.Round, commit.Hash)
+	return aggsig.VerifyAggregate(pks, msg, commit.Signature)
+}
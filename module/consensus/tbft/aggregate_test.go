@@ -0,0 +1,76 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"chainmaker.org/chainmaker-go/consensus/aggsig"
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAggregateCommit(t *testing.T) {
+	validators := []string{org1NodeId, org2NodeId, org3NodeId, org4NodeId}
+	sks := make(map[string]*aggsig.PrivateKey)
+	registered := make(map[string]*aggsig.RegisteredKey)
+	for _, validator := range validators {
+		sk, err := aggsig.GenerateKey()
+		require.NoError(t, err)
+		sks[validator] = sk
+		registered[validator] = aggsig.NewRegisteredKey(sk)
+	}
+	keys, err := json.Marshal(registered)
+	require.NoError(t, err)
+	chainConfig := &configpb.ChainConfig{
+		ChainId: chainId,
+		Consensus: &configpb.ConsensusConfig{
+			ExtConfig: []*configpb.ConfigKeyValue{{Key: TBFTAggregateKeysKey, Value: string(keys)}},
+		},
+	}
+	validatorSet := newValidatorSet(cmLogger, validators, 1)
+	block := &common.Block{Header: &common.BlockHeader{BlockHeight: 10, BlockHash: []byte("block hash")}}
+
+	makeCommit := func(round int32, signers ...int) []byte {
+		bitmap := aggsig.NewBitmap(len(validators))
+		var sigs [][]byte
+		for _, i := range signers {
+			bitmap.Set(i)
+			msg := commitMessage(chainId, block.Header.BlockHeight, round, block.Header.BlockHash)
+			sigs = append(sigs, sks[validatorSet.List()[i]].Sign(msg))
+		}
+		sig, err := aggsig.Aggregate(sigs)
+		require.NoError(t, err)
+		return mustMarshal(&tbftextpb.AggregateCommit{
+			Round:     round,
+			Hash:      block.Header.BlockHash,
+			Signers:   bitmap,
+			Signature: sig,
+		})
+	}
+
+	require.NoError(t, verifyAggregateCommit(chainConfig, validatorSet, block, makeCommit(1, 0, 2, 3)))
+	require.Error(t, verifyAggregateCommit(chainConfig, validatorSet, block, makeCommit(1, 0, 3)))
+
+	// signed for another round
+	commit := &tbftextpb.AggregateCommit{}
+	mustUnmarshal(makeCommit(1, 0, 1, 2), commit)
+	commit.Round = 2
+	err = verifyAggregateCommit(chainConfig, validatorSet, block, mustMarshal(commit))
+	require.True(t, errors.Is(err, aggsig.ErrInvalidSignature))
+
+	// a signer without registered key
+	delete(registered, validatorSet.List()[1])
+	keys, err = json.Marshal(registered)
+	require.NoError(t, err)
+	chainConfig.Consensus.ExtConfig[0].Value = string(keys)
+	require.Error(t, verifyAggregateCommit(chainConfig, validatorSet, block, makeCommit(1, 0, 1, 2)))
+}
@@ -0,0 +1,354 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	"chainmaker.org/chainmaker/common/v2/msgbus"
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	netpb "chainmaker.org/chainmaker/pb-go/v2/net"
+	"github.com/gogo/protobuf/proto"
+)
+
+// The proposals larger than a part are sent in parts when activation.TBFTBlockParts is active.
+//
+// The proposer splits its signed proposal into parts of TBFT_block_part_size bytes, which are committed by
+// the merkle root of them, and signs the part set header with a vote of voteTypeBlockParts. A node having all
+// the parts of the proposal of a round, the proposer or a validator which received them, sends the header and
+// the parts to a peer missing the proposal once, and only the header again while the peer still misses it.
+// The peer requests the parts it is missing from the sender of the header. A part is verified with the root
+// on receiving, and the complete parts are handled as the proposal sent as a whole.
+//
+// The prevotes and precommits for a proposal in parts sign its root besides the block hash, see
+// tbftextpb.VoteExt. The votes for the same block in different part sets are counted apart,
+// so the quorum of votes certifies the parts as well as the block.
+const (
+	msgTypeBlockPartsHeader  = tbftpb.TBFTMsgType(tbftextpb.TBFTMsgTypeExt_MSG_BLOCK_PARTS_HEADER)
+	msgTypeBlockPart         = tbftpb.TBFTMsgType(tbftextpb.TBFTMsgTypeExt_MSG_BLOCK_PART)
+	msgTypeBlockPartsRequest = tbftpb.TBFTMsgType(tbftextpb.TBFTMsgTypeExt_MSG_BLOCK_PARTS_REQUEST)
+
+	// voteTypeBlockParts is the type of the vote of proposer signing a part set header
+	voteTypeBlockParts = tbftpb.VoteType(tbftextpb.VoteTypeExt_VOTE_BLOCK_PARTS)
+
+	// TBFTBlockPartSizeKey is the key in consensus ext config of the size of block parts in bytes
+	TBFTBlockPartSizeKey = "TBFT_block_part_size"
+
+	defaultBlockPartSize = 64 * 1024
+	minBlockPartSize     = 1024
+	maxBlockParts        = 4096 // the proposals of more parts are sent as a whole
+	maxPartSetsPerRound  = 4    // more than one part set of a round only if the proposer equivocates
+)
+
+// receivedBlockParts is a part set received from the proposer or the other validators
+type receivedBlockParts struct {
+	vote  *tbftpb.Vote // the vote of proposer for the hash of the part set header
+	parts *PartSet
+}
+
+// blockParts keeps the part sets of the current height
+type blockParts struct {
+	partSize int
+	own      *PartSet                                 // the parts of the proposal of this node
+	ownVote  *tbftpb.Vote                             // the vote of this node for the hash of the header of own
+	received map[int32]map[string]*receivedBlockParts // round => root in hex => part set
+}
+
+func newBlockParts() *blockParts {
+	return &blockParts{
+		partSize: defaultBlockPartSize,
+		received: make(map[int32]map[string]*receivedBlockParts),
+	}
+}
+
+// reset drops the part sets, it is called at a new height
+func (b *blockParts) reset() {
+	b.own = nil
+	b.ownVote = nil
+	b.received = make(map[int32]map[string]*receivedBlockParts)
+}
+
+// get returns the part set of root at round, nil if it is unknown
+func (b *blockParts) get(round int32, root []byte) *PartSet {
+	if b.ownVote != nil && b.ownVote.Round == round && bytes.Equal(b.own.Header().Root, root) {
+		return b.own
+	}
+	if rp, ok := b.received[round][hex.EncodeToString(root)]; ok {
+		return rp.parts
+	}
+	return nil
+}
+
+// parseBlockPartSize parses the block part size in consensus ext config
+func parseBlockPartSize(config *config.ConsensusConfig) (int, error) {
+	size := defaultBlockPartSize
+	for _, kv := range config.ExtConfig {
+		if kv.Key != TBFTBlockPartSizeKey {
+			continue
+		}
+		var err error
+		size, err = strconv.Atoi(string(kv.Value))
+		if err != nil || size < minBlockPartSize {
+			return 0, fmt.Errorf("invalid %s: %s, at least %d", kv.Key, kv.Value, minBlockPartSize)
+		}
+	}
+	return size, nil
+}
+
+// isBlockPartsActive returns true if the proposals at height are sent in parts
+func (consensus *ConsensusTBFTImpl) isBlockPartsActive(height uint64) bool {
+	return activation.IsActive(consensus.chainConf.ChainConfig(), activation.TBFTBlockParts, height)
+}
+
+// makeBlockParts splits the proposal of this node into parts if it is larger than a part,
+// otherwise the proposal is sent as a whole
+func (consensus *ConsensusTBFTImpl) makeBlockParts(proposal *Proposal) {
+	if !consensus.isBlockPartsActive(proposal.Height) {
+		return
+	}
+	data := mustMarshal(proposal.ToProto())
+	if len(data) <= consensus.blockParts.partSize {
+		return
+	}
+	parts := NewPartSetFromData(data, consensus.blockParts.partSize)
+	if parts.Header().Total > maxBlockParts {
+		consensus.logger.Warnf("[%s](%d/%d/%s) proposal of %d bytes is too large for parts, send it as a whole",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, len(data))
+		return
+	}
+	vote := NewVote(voteTypeBlockParts, consensus.Id, proposal.Height, proposal.Round, parts.Header().Hash())
+	if err := consensus.signVote(vote); err != nil {
+		return
+	}
+	consensus.blockParts.own = parts
+	consensus.blockParts.ownVote = vote.ToProto()
+	proposal.PartSetRoot = parts.Header().Root
+	consensus.logger.Infof("[%s](%d/%d/%s) split proposal of %d bytes into parts %s",
+		consensus.Id, consensus.Height, consensus.Round, consensus.Step, len(data), parts.Header())
+}
+
+// proposalBlockParts returns the complete parts of the proposal at height and round, the parts of this node
+// or the ones the proposal is received in, nil if the proposal is unknown or sent as a whole
+func (consensus *ConsensusTBFTImpl) proposalBlockParts(height uint64, round int32) (*tbftpb.Vote, *PartSet) {
+	if vote := consensus.blockParts.ownVote; vote != nil && vote.Height == height && vote.Round == round {
+		return vote, consensus.blockParts.own
+	}
+	if consensus.Height != height || consensus.Round != round {
+		return nil, nil
+	}
+	proposal := consensus.Proposal
+	if proposal == nil {
+		proposal = consensus.VerifingProposal
+	}
+	if proposal == nil || proposal.Round != round || len(proposal.PartSetRoot) == 0 {
+		return nil, nil
+	}
+	rp, ok := consensus.blockParts.received[round][hex.EncodeToString(proposal.PartSetRoot)]
+	if !ok || !rp.parts.IsComplete() {
+		return nil, nil
+	}
+	return rp.vote, rp.parts
+}
+
+// procBlockPartsHeader handles the header of parts from the proposer or a validator having the parts,
+// it requests the parts missing from the sender if the header is received again
+func (consensus *ConsensusTBFTImpl) procBlockPartsHeader(msg *tbftpb.TBFTMsg) {
+	headerMsg := new(tbftextpb.BlockPartsHeader)
+	vote := new(tbftpb.Vote)
+	err := proto.Unmarshal(msg.Msg, headerMsg)
+	if err == nil {
+		err = proto.Unmarshal(headerMsg.Vote, vote)
+	}
+	if err != nil || headerMsg.Header == nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid block parts header, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
+		return
+	}
+	header := NewPartSetHeaderFromProto(headerMsg.Header)
+	if vote.Type != voteTypeBlockParts || !bytes.Equal(vote.Hash, header.Hash()) ||
+		header.Total == 0 || header.Total > maxBlockParts {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid block parts header %s of %s from %s",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, header, vote.Voter, headerMsg.From)
+		return
+	}
+	if !consensus.isBlockPartsActive(vote.Height) || !consensus.canReceiveProposal(vote.Height, vote.Round) ||
+		consensus.Proposal != nil || consensus.VerifingProposal != nil {
+		return
+	}
+	proposer, _ := consensus.validatorSet.GetProposer(vote.Height, vote.Round)
+	if proposer != vote.Voter {
+		consensus.logger.Infof("[%s](%d/%d/%s) proposer: %s, receive block parts header from incorrect proposer: %s",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, proposer, vote.Voter)
+		return
+	}
+
+	key := hex.EncodeToString(header.Root)
+	received := consensus.blockParts.received[vote.Round]
+	if rp, ok := received[key]; ok {
+		if !rp.parts.IsComplete() {
+			consensus.requestBlockParts(rp, headerMsg.From)
+		}
+		return
+	}
+	if len(received) >= maxPartSetsPerRound {
+		consensus.logger.Warnf("[%s](%d/%d/%s) too many block parts headers of %s, ignore %s",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, vote.Voter, header)
+		return
+	}
+	if err = consensus.verifyVote(vote, nil); err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive block parts header %s, verifyVote failed, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, header, err)
+		return
+	}
+	if received == nil {
+		received = make(map[string]*receivedBlockParts)
+		consensus.blockParts.received[vote.Round] = received
+	}
+	received[key] = &receivedBlockParts{vote: vote, parts: NewPartSetFromHeader(header)}
+	consensus.logger.Debugf("[%s](%d/%d/%s) receive block parts header %s of %s from %s",
+		consensus.Id, consensus.Height, consensus.Round, consensus.Step, header, vote.Voter, headerMsg.From)
+}
+
+// requestBlockParts requests the parts missing from the validator sending the header, or from the proposer
+// if the sender is unknown
+func (consensus *ConsensusTBFTImpl) requestBlockParts(rp *receivedBlockParts, from string) {
+	if from == consensus.Id || !consensus.validatorSet.HasValidator(from) {
+		from = rp.vote.Voter
+	}
+	missing := rp.parts.Missing()
+	consensus.logger.Debugf("[%s](%d/%d/%s) request %d parts of %s from %s",
+		consensus.Id, consensus.Height, consensus.Round, consensus.Step, len(missing), rp.parts.Header(), from)
+	consensus.sendBlockPartsMsg(from, msgTypeBlockPartsRequest, &tbftextpb.BlockPartsRequest{
+		From:    consensus.Id,
+		Height:  rp.vote.Height,
+		Round:   rp.vote.Round,
+		Root:    rp.parts.Header().Root,
+		Missing: missing,
+	})
+}
+
+// procBlockPart adds the part to the part set of its header,
+// and handles the proposal in parts when the parts are complete
+func (consensus *ConsensusTBFTImpl) procBlockPart(msg *tbftpb.TBFTMsg) {
+	partMsg := new(tbftextpb.BlockPart)
+	if err := proto.Unmarshal(msg.Msg, partMsg); err != nil || partMsg.Part == nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid block part, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
+		return
+	}
+	if partMsg.Height != consensus.Height {
+		return
+	}
+	rp, ok := consensus.blockParts.received[partMsg.Round][hex.EncodeToString(partMsg.Root)]
+	if !ok {
+		consensus.logger.Debugf("[%s](%d/%d/%s) receive block part (%d/%d/%x) without header",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, partMsg.Height, partMsg.Round, partMsg.Root)
+		return
+	}
+	added, err := rp.parts.AddPart(NewPartFromProto(partMsg.Part))
+	if err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid block part of %s, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, rp.parts.Header(), err)
+		return
+	}
+	if !added || !rp.parts.IsComplete() {
+		return
+	}
+
+	vote := rp.vote
+	proposal := new(tbftpb.Proposal)
+	if err = proto.Unmarshal(rp.parts.GetData(), proposal); err != nil ||
+		proposal.Block == nil || proposal.Block.Header == nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid proposal in parts %s from %s, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, rp.parts.Header(), vote.Voter, err)
+		return
+	}
+	if proposal.Voter != vote.Voter || proposal.Height != vote.Height || proposal.Round != vote.Round {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive proposal %s(%d/%d) unmatched with its parts header %s(%d/%d)",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step,
+			proposal.Voter, proposal.Height, proposal.Round, vote.Voter, vote.Height, vote.Round)
+		return
+	}
+	consensus.logger.Infof("[%s](%d/%d/%s) receive proposal %s(%d/%d) in parts %s",
+		consensus.Id, consensus.Height, consensus.Round, consensus.Step,
+		proposal.Voter, proposal.Height, proposal.Round, rp.parts.Header())
+	consensus.procProposal(proposal, rp.parts.Header().Root)
+}
+
+// procBlockPartsRequest sends the parts requested by a validator, from the parts of this node
+// or the ones received
+func (consensus *ConsensusTBFTImpl) procBlockPartsRequest(msg *tbftpb.TBFTMsg) {
+	req := new(tbftextpb.BlockPartsRequest)
+	if err := proto.Unmarshal(msg.Msg, req); err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid block parts request, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
+		return
+	}
+	if req.Height != consensus.Height || !consensus.validatorSet.HasValidator(req.From) {
+		return
+	}
+	parts := consensus.blockParts.get(req.Round, req.Root)
+	if parts == nil || len(req.Missing) > int(parts.Header().Total) {
+		return
+	}
+	for _, index := range req.Missing {
+		if part := parts.GetPart(index); part != nil {
+			consensus.sendBlockPart(req.From, req.Height, req.Round, parts.Header().Root, part)
+		}
+	}
+}
+
+// sendBlockPart sends a part to the peer
+func (consensus *ConsensusTBFTImpl) sendBlockPart(to string, height uint64, round int32, root []byte, part *Part) {
+	consensus.sendBlockPartsMsg(to, msgTypeBlockPart, &tbftextpb.BlockPart{
+		Height: height,
+		Round:  round,
+		Root:   root,
+		Part:   part.ToProto(),
+	})
+}
+
+// sendBlockPartsMsg sends the block parts message to the peer
+func (consensus *ConsensusTBFTImpl) sendBlockPartsMsg(to string, msgType tbftpb.TBFTMsgType, m proto.Message) {
+	msg := &tbftpb.TBFTMsg{
+		Type: msgType,
+		Msg:  mustMarshal(m),
+	}
+	consensus.msgbus.Publish(msgbus.SendConsensusMsg, &netpb.NetMsg{
+		Payload: mustMarshal(msg),
+		Type:    netpb.NetMsg_CONSENSUS_MSG,
+		To:      to,
+	})
+}
+
+// sendBlockParts sends the header of parts signed by vote, and all the parts if they are not sent to the peer
+// yet, the peer requests the parts it is missing when it receives the header again
+func (pcs *PeerStateService) sendBlockParts(vote *tbftpb.Vote, parts *PartSet) {
+	header := parts.Header()
+	pcs.logger.Infof("[%s](%d/%d/%s) sendBlockParts %s(%d/%d) %s to %v",
+		pcs.tbftImpl.Id, pcs.tbftImpl.Height, pcs.tbftImpl.Round, pcs.tbftImpl.Step,
+		vote.Voter, vote.Height, vote.Round, header, pcs.Id)
+
+	pcs.tbftImpl.sendBlockPartsMsg(pcs.Id, msgTypeBlockPartsHeader, &tbftextpb.BlockPartsHeader{
+		Header: header.ToProto(),
+		Vote:   mustMarshal(vote),
+		From:   pcs.tbftImpl.Id,
+	})
+	if bytes.Equal(pcs.sentBlockPartsRoot, header.Root) {
+		return
+	}
+	pcs.sentBlockPartsRoot = header.Root
+	for i := uint32(0); i < header.Total; i++ {
+		pcs.tbftImpl.sendBlockPart(pcs.Id, vote.Height, vote.Round, header.Root, parts.GetPart(i))
+	}
+}
@@ -15,6 +15,10 @@ import (
 	"sync"
 	"time"
 
+	"chainmaker.org/chainmaker-go/consensus/evidence"
+	"chainmaker.org/chainmaker-go/consensus/proposerhint"
+	"chainmaker.org/chainmaker-go/consensus/safewal"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
 	"chainmaker.org/chainmaker/chainconf/v2"
 	"chainmaker.org/chainmaker/common/v2/crypto/asym"
 	"chainmaker.org/chainmaker/common/v2/helper"
@@ -88,7 +92,7 @@ type ConsensusTBFTImpl struct {
 	closeC             chan struct{}
 	internalMsgCCloseC chan struct{}
 	waldir             string
-	wal                *wal.Log
+	wal                *safewal.Log
 	heightFirstIndex   uint64
 
 	validatorSet *validatorSet
@@ -107,9 +111,13 @@ type ConsensusTBFTImpl struct {
 
 	TimeoutPropose      time.Duration
 	TimeoutProposeDelta time.Duration
+	adaptiveTimeout     *adaptiveTimeout
+	blockParts          *blockParts
+	aggregate           *aggregateSignatures
 
 	// time metrics
-	metrics *heightMetrics
+	metrics     *heightMetrics
+	promMetrics *promMetrics
 }
 
 // ConsensusTBFTImplConfig contains initialization config for ConsensusTBFTImpl
@@ -148,10 +156,14 @@ func New(config ConsensusTBFTImplConfig) (*ConsensusTBFTImpl, error) {
 		consensus.dpos = config.Dpos
 	}
 	consensus.waldir = path.Join(localconf.ChainMakerConfig.GetStorePath(), consensus.chainID, walDir)
-	consensus.wal, err = wal.Open(consensus.waldir, nil)
+	var repairs []string
+	consensus.wal, repairs, err = safewal.Open(consensus.waldir)
 	if err != nil {
 		return nil, err
 	}
+	for _, repair := range repairs {
+		consensus.logger.Warnf("[%s] repair wal %s: %s", consensus.Id, consensus.waldir, repair)
+	}
 	consensus.heightFirstIndex = 0
 
 	consensus.proposedBlockC = make(chan *consensuspb.ProposalBlock, defaultChanCap)
@@ -168,6 +180,14 @@ func New(config ConsensusTBFTImplConfig) (*ConsensusTBFTImpl, error) {
 	consensus.ConsensusState = NewConsensusState(consensus.logger, consensus.Id)
 	consensus.consensusStateCache = newConsensusStateCache(defaultConsensusStateCacheSize)
 	consensus.timeScheduler = newTimeSheduler(consensus.logger, config.Id)
+	consensus.adaptiveTimeout = newAdaptiveTimeout()
+	consensus.blockParts = newBlockParts()
+	aggregateKey, err := loadAggregateKey()
+	if err != nil {
+		return nil, err
+	}
+	consensus.aggregate = newAggregateSignatures(aggregateKey)
+	consensus.promMetrics = newPromMetrics(consensus.chainID)
 	consensus.gossip = newGossipService(consensus.logger, consensus)
 
 	return consensus, nil
@@ -193,6 +213,13 @@ func (consensus *ConsensusTBFTImpl) Start() error {
 
 	consensus.gossip.start()
 	go consensus.handle()
+	instances.Store(consensus.chainID, consensus)
+	// the proposer of the first round, by the validator set of the current height
+	proposerhint.Register(consensus.chainID, func(height uint64) bool {
+		consensus.RLock()
+		defer consensus.RUnlock()
+		return consensus.isProposer(height, 0)
+	})
 	return nil
 }
 
@@ -215,6 +242,8 @@ func (consensus *ConsensusTBFTImpl) Stop() error {
 	}
 	consensus.gossip.stop()
 	close(consensus.closeC)
+	instances.Delete(consensus.chainID)
+	proposerhint.Unregister(consensus.chainID)
 	return nil
 }
 
@@ -280,11 +309,14 @@ func (consensus *ConsensusTBFTImpl) Verify(consensusType consensuspb.ConsensusTy
 	}
 	config := chainConfig.Consensus
 	_, _, _, _, err := consensus.extractConsensusConfig(config)
-	return err
+	if err != nil {
+		return err
+	}
+	return activation.CheckConfig(chainConfig)
 }
 
-func (consensus *ConsensusTBFTImpl) updateChainConfig() (addedValidators []string, removedValidators []string,
-	err error) {
+func (consensus *ConsensusTBFTImpl) updateChainConfig(height uint64) (addedValidators []string,
+	removedValidators []string, err error) {
 	consensus.logger.Debugf("[%s](%d/%d/%v) update chain config",
 		consensus.Id, consensus.Height, consensus.Round, consensus.Step)
 
@@ -302,6 +334,17 @@ func (consensus *ConsensusTBFTImpl) updateChainConfig() (addedValidators []strin
 
 	consensus.TimeoutPropose = timeoutPropose
 	consensus.TimeoutProposeDelta = timeoutProposeDelta
+	adaptiveTimeoutConfig, err := parseAdaptiveTimeoutConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	consensus.adaptiveTimeout.updateConfig(adaptiveTimeoutConfig)
+	if consensus.blockParts.partSize, err = parseBlockPartSize(config); err != nil {
+		return nil, nil, err
+	}
+	if consensus.aggregate.keys, err = parseAggregateKeys(config); err != nil {
+		return nil, nil, err
+	}
 	if consensus.chainConf.ChainConfig().Consensus.Type == consensuspb.ConsensusType_DPOS {
 		consensus.logger.Debugf("enter dpos to get proposers ...")
 		if validators, err = consensus.dpos.GetValidators(); err != nil {
@@ -314,7 +357,32 @@ func (consensus *ConsensusTBFTImpl) updateChainConfig() (addedValidators []strin
 			consensus.logger.Errorf("update Proposer per Blocks failed err: %s", err)
 		}
 	}
-	return consensus.validatorSet.updateValidators(validators)
+	weights, err := consensus.getValidatorWeights(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	addedValidators, removedValidators, err = consensus.validatorSet.updateValidators(validators)
+	if err != nil {
+		return nil, nil, err
+	}
+	consensus.validatorSet.updateWeights(weights)
+	return addedValidators, removedValidators, nil
+}
+
+// getValidatorWeights returns the voting weights of validators at height, nil if the votes are equal
+func (consensus *ConsensusTBFTImpl) getValidatorWeights(height uint64) (map[string]uint64, error) {
+	chainConfig := consensus.chainConf.ChainConfig()
+	if !activation.IsActive(chainConfig, activation.TBFTWeightedVoting, height) {
+		return nil, nil
+	}
+	if chainConfig.Consensus.Type != consensuspb.ConsensusType_DPOS {
+		return GetValidatorWeightsFromConfig(chainConfig)
+	}
+	provider, ok := consensus.dpos.(validatorWeightsProvider)
+	if !ok {
+		return nil, fmt.Errorf("dpos does not provide validator weights")
+	}
+	return provider.GetValidatorWeights()
 }
 
 func (consensus *ConsensusTBFTImpl) extractConsensusConfig(config *config.ConsensusConfig) (validators []string,
@@ -338,12 +406,21 @@ func (consensus *ConsensusTBFTImpl) extractConsensusConfig(config *config.Consen
 			timeoutProposeDelta, err = consensus.extractProposeTimeoutDelta(string(v.Value))
 		case protocol.TBFT_blocks_per_proposer:
 			tbftBlocksPerProposer, err = consensus.extractBlocksPerProposer(string(v.Value))
+		case TBFTValidatorWeightsKey:
+			_, err = parseValidatorWeights(string(v.Value))
 		}
 
 		if err != nil {
 			return
 		}
 	}
+	if _, err = parseAdaptiveTimeoutConfig(config); err != nil {
+		return
+	}
+	if _, err = parseBlockPartSize(config); err != nil {
+		return
+	}
+	_, err = parseAggregateKeys(config)
 
 	return
 }
@@ -473,6 +550,7 @@ func (consensus *ConsensusTBFTImpl) handleProposedBlock(proposedBlock *consensus
 		return
 	}
 	consensus.Proposal = proposal
+	consensus.makeBlockParts(proposal)
 
 	if !replayMode {
 		consensus.saveWalEntry(consensus.Proposal)
@@ -502,7 +580,7 @@ func (consensus *ConsensusTBFTImpl) handleVerifyResult(verifyResult *consensuspb
 
 	if consensus.Height == height &&
 		consensus.Round == consensus.VerifingProposal.Round &&
-		verifyResult.Code == consensuspb.VerifyResult_FAIL {
+		verifyResult.Code != consensuspb.VerifyResult_SUCCESS {
 		consensus.logger.Warnf("[%s](%d/%d/%s) %x receive verify result (%d/%x) %v failed",
 			consensus.Id, consensus.Height, consensus.Round, consensus.Step, consensus.VerifingProposal.Block.Header.BlockHash,
 			height, hash, verifyResult.Code,
@@ -524,13 +602,7 @@ func (consensus *ConsensusTBFTImpl) handleVerifyResult(verifyResult *consensuspb
 					consensus.saveWalEntry(consensus.Proposal)
 				}
 
-				qc := mustMarshal(voteSet.ToProto())
-				if consensus.Proposal.Block.AdditionalData == nil {
-					consensus.Proposal.Block.AdditionalData = &common.AdditionalData{
-						ExtraData: make(map[string][]byte),
-					}
-				}
-				consensus.Proposal.Block.AdditionalData.ExtraData[protocol.TBFTAddtionalDataKey] = qc
+				consensus.addCommitSignatures(voteSet)
 				// Commit block to core engine
 				consensus.commitBlock(consensus.Proposal.Block)
 				return
@@ -584,7 +656,11 @@ func (consensus *ConsensusTBFTImpl) handleBlockHeight(height uint64) {
 func (consensus *ConsensusTBFTImpl) procPropose(msg *tbftpb.TBFTMsg) {
 	proposalProto := new(tbftpb.Proposal)
 	mustUnmarshal(msg.Msg, proposalProto)
+	consensus.procProposal(proposalProto, nil)
+}
 
+// procProposal handles the proposal received as a whole or in parts of partSetRoot
+func (consensus *ConsensusTBFTImpl) procProposal(proposalProto *tbftpb.Proposal, partSetRoot []byte) {
 	consensus.logger.Debugf("[%s](%d/%d/%s) receive proposal from %s(%d/%d) (%d/%x/%d)",
 		consensus.Id, consensus.Height, consensus.Round, consensus.Step,
 		proposalProto.Voter, proposalProto.Height, proposalProto.Round,
@@ -605,6 +681,7 @@ func (consensus *ConsensusTBFTImpl) procPropose(msg *tbftpb.TBFTMsg) {
 			consensus.Id, consensus.Height, consensus.Round, consensus.Step)
 		return
 	}
+	proposal.PartSetRoot = partSetRoot
 
 	height := proposal.Block.Header.BlockHeight
 	hash := proposal.Block.Header.BlockHash
@@ -669,8 +746,12 @@ func (consensus *ConsensusTBFTImpl) canReceiveProposal(height uint64, round int3
 }
 
 func (consensus *ConsensusTBFTImpl) procPrevote(msg *tbftpb.TBFTMsg) {
-	prevote := new(tbftpb.Vote)
-	mustUnmarshal(msg.Msg, prevote)
+	prevote, partSetRoot, err := unmarshalVote(msg.Msg)
+	if err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid prevote, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
+		return
+	}
 
 	consensus.logger.Debugf("[%s](%d/%d/%s) receive prevote %s(%d/%d/%x)",
 		consensus.Id, consensus.Height, consensus.Round, consensus.Step,
@@ -688,7 +769,7 @@ func (consensus *ConsensusTBFTImpl) procPrevote(msg *tbftpb.TBFTMsg) {
 	}
 
 	if prevote.Voter != consensus.Id {
-		err := consensus.verifyVote(prevote)
+		err := consensus.verifyVote(prevote, partSetRoot)
 		if err != nil {
 			consensus.logger.Errorf("[%s](%d/%d/%s) receive prevote %s(%d/%d/%x), verifyVote failed: %v",
 				consensus.Id, consensus.Height, consensus.Round, consensus.Step,
@@ -699,7 +780,8 @@ func (consensus *ConsensusTBFTImpl) procPrevote(msg *tbftpb.TBFTMsg) {
 	}
 
 	vote := NewVoteFromProto(prevote)
-	err := consensus.addVote(vote, false)
+	vote.PartSetRoot = partSetRoot
+	err = consensus.addVote(vote, false)
 	if err != nil {
 		consensus.logger.Errorf("[%s](%d/%d/%s) addVote %s(%d/%d/%s) failed, %v",
 			consensus.Id, consensus.Height, consensus.Round, consensus.Step,
@@ -710,8 +792,12 @@ func (consensus *ConsensusTBFTImpl) procPrevote(msg *tbftpb.TBFTMsg) {
 }
 
 func (consensus *ConsensusTBFTImpl) procPrecommit(msg *tbftpb.TBFTMsg) {
-	precommit := new(tbftpb.Vote)
-	mustUnmarshal(msg.Msg, precommit)
+	precommit, partSetRoot, err := unmarshalVote(msg.Msg)
+	if err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid precommit, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
+		return
+	}
 
 	consensus.logger.Debugf("[%s](%d/%d/%s) receive precommit %s(%d/%d)",
 		consensus.Id, consensus.Height, consensus.Round, consensus.Step,
@@ -729,7 +815,7 @@ func (consensus *ConsensusTBFTImpl) procPrecommit(msg *tbftpb.TBFTMsg) {
 	}
 
 	if precommit.Voter != consensus.Id {
-		err := consensus.verifyVote(precommit)
+		err := consensus.verifyVote(precommit, partSetRoot)
 		if err != nil {
 			consensus.logger.Errorf("[%s](%d/%d/%s) receive precommit %s(%d/%d/%x), verifyVote failed, %v",
 				consensus.Id, consensus.Height, consensus.Round, consensus.Step,
@@ -740,7 +826,8 @@ func (consensus *ConsensusTBFTImpl) procPrecommit(msg *tbftpb.TBFTMsg) {
 	}
 
 	vote := NewVoteFromProto(precommit)
-	err := consensus.addVote(vote, false)
+	vote.PartSetRoot = partSetRoot
+	err = consensus.addVote(vote, false)
 	if err != nil {
 		consensus.logger.Errorf("[%s](%d/%d/%s) addVote %s(%d/%d/%s) failed, %v",
 			consensus.Id, consensus.Height, consensus.Round, consensus.Step,
@@ -764,6 +851,16 @@ func (consensus *ConsensusTBFTImpl) handleConsensusMsg(msg *tbftpb.TBFTMsg) {
 	case tbftpb.TBFTMsgType_MSG_STATE:
 		// Async is ok
 		go consensus.gossip.onRecvState(msg)
+	case msgTypeEvidence:
+		consensus.procEvidence(msg)
+	case msgTypeBlockPartsHeader:
+		consensus.procBlockPartsHeader(msg)
+	case msgTypeBlockPart:
+		consensus.procBlockPart(msg)
+	case msgTypeBlockPartsRequest:
+		consensus.procBlockPartsRequest(msg)
+	case msgTypeCommitSignature:
+		consensus.procCommitSignature(msg)
 	}
 }
 
@@ -807,23 +904,18 @@ func (consensus *ConsensusTBFTImpl) commitBlock(block *common.Block) {
 
 // ProposeTimeout returns timeout to wait for proposing at `round`
 func (consensus *ConsensusTBFTImpl) ProposeTimeout(round int32) time.Duration {
-	return time.Duration(
-		consensus.TimeoutPropose.Nanoseconds()+consensus.TimeoutProposeDelta.Nanoseconds()*int64(round),
-	) * time.Nanosecond
+	return consensus.adaptiveTimeout.timeout(tbftpb.Step_PROPOSE, round,
+		consensus.TimeoutPropose, consensus.TimeoutProposeDelta)
 }
 
 // PrevoteTimeout returns timeout to wait for prevoting at `round`
 func (consensus *ConsensusTBFTImpl) PrevoteTimeout(round int32) time.Duration {
-	return time.Duration(
-		TimeoutPrevote.Nanoseconds()+TimeoutPrevoteDelta.Nanoseconds()*int64(round),
-	) * time.Nanosecond
+	return consensus.adaptiveTimeout.timeout(tbftpb.Step_PREVOTE, round, TimeoutPrevote, TimeoutPrevoteDelta)
 }
 
 // PrecommitTimeout returns timeout to wait for precommiting at `round`
 func (consensus *ConsensusTBFTImpl) PrecommitTimeout(round int32) time.Duration {
-	return time.Duration(
-		TimeoutPrecommit.Nanoseconds()+TimeoutPrecommitDelta.Nanoseconds()*int64(round),
-	) * time.Nanosecond
+	return consensus.adaptiveTimeout.timeout(tbftpb.Step_PRECOMMIT, round, TimeoutPrecommit, TimeoutPrecommitDelta)
 }
 
 // CommitTimeout returns timeout to wait for precommiting at `round`
@@ -846,6 +938,9 @@ func (consensus *ConsensusTBFTImpl) addVote(vote *Vote, replayMode bool) error {
 	if !added || err != nil {
 		consensus.logger.Infof("[%s](%d/%d/%s) addVote %v, added: %v, err: %v",
 			consensus.Id, consensus.Height, consensus.Round, consensus.Step, vote, added, err)
+		if err != nil && !replayMode {
+			consensus.checkConflictingVote(err)
+		}
 		return err
 	}
 
@@ -965,7 +1060,7 @@ func (consensus *ConsensusTBFTImpl) enterNewHeight(height uint64, replayMode boo
 			consensus.Id, consensus.Height, consensus.Round, consensus.Step, height)
 		return
 	}
-	addedValidators, removedValidators, err := consensus.updateChainConfig()
+	addedValidators, removedValidators, err := consensus.updateChainConfig(height)
 	if err != nil {
 		consensus.logger.Errorf("[%s](%v/%v/%v) update chain config failed: %v",
 			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
@@ -1009,8 +1104,14 @@ func (consensus *ConsensusTBFTImpl) enterNewHeight(height uint64, replayMode boo
 	consensus.Step = tbftpb.Step_NEW_HEIGHT
 	consensus.heightRoundVoteSet = newHeightRoundVoteSet(
 		consensus.logger, consensus.Height, consensus.Round, consensus.validatorSet)
+	consensus.blockParts.reset()
+	consensus.aggregate.reset()
+	consensus.promMetrics.observeHeight(consensus.metrics)
 	consensus.metrics = newHeightMetrics(consensus.Height)
 	consensus.metrics.SetEnterNewHeightTime()
+	for _, feature := range activation.ActivatedAt(consensus.chainConf.ChainConfig(), height) {
+		consensus.logger.Infof("[%s](%d) feature %s activated", consensus.Id, height, feature)
+	}
 	consensus.enterNewRound(height, 0)
 }
 
@@ -1095,8 +1196,10 @@ func (consensus *ConsensusTBFTImpl) enterPrevote(height uint64, round int32) {
 	consensus.sendProposeState(false)
 
 	var hash = nilHash
+	var partSetRoot []byte
 	if consensus.Proposal != nil {
 		hash = consensus.Proposal.Block.Header.BlockHash
+		partSetRoot = consensus.Proposal.PartSetRoot
 	}
 
 	//Simulate a node which send an invalid(hash=NIL) Prevote
@@ -1124,6 +1227,9 @@ func (consensus *ConsensusTBFTImpl) enterPrevote(height uint64, round int32) {
 			localconf.ChainMakerConfig.DebugConfig.IsPrevoteOldHeight, consensus.Height-1)
 		prevote = NewVote(tbftpb.VoteType_VOTE_PREVOTE, consensus.Id, consensus.Height-1, consensus.Round, hash)
 	}
+	if !isNilHash(hash) {
+		prevote.PartSetRoot = partSetRoot
+	}
 	err := consensus.signVote(prevote)
 	if err != nil {
 		consensus.logger.Errorf("enter Prevote sign Vote error: %s", err)
@@ -1179,11 +1285,18 @@ func (consensus *ConsensusTBFTImpl) enterPrecommit(height uint64, round int32) {
 			localconf.ChainMakerConfig.DebugConfig.IsPrecommitOldHeight, consensus.Height-1)
 		precommit = NewVote(tbftpb.VoteType_VOTE_PRECOMMIT, consensus.Id, consensus.Height-1, consensus.Round, hash)
 	}
+	// the precommit is for the part set of the prevotes reaching majority
+	if !isNilHash(hash) {
+		precommit.PartSetRoot = voteSet.maj23Root
+	}
 	err := consensus.signVote(precommit)
 	if err != nil {
 		consensus.logger.Errorf("enter Precommit sign Vote error: %s", err)
 	}
 	precommitProto := createPrecommitMsg(precommit)
+	if !isNilHash(hash) {
+		consensus.sendCommitSignature(hash)
+	}
 
 	//Simulate a node which delay when Precommit
 	if localconf.ChainMakerConfig.DebugConfig.IsPrecommitDelay {
@@ -1231,14 +1344,9 @@ func (consensus *ConsensusTBFTImpl) enterCommit(height uint64, round int32) {
 			panic(fmt.Errorf("[%s] block match failed, unmatch precommit hash: %x with proposal hash: %x",
 				consensus.Id, hash, consensus.Proposal.Block.Header.BlockHash))
 		}
+		consensus.adaptiveTimeout.observe(consensus.metrics.getRoundMertrics(round))
 
-		qc := mustMarshal(voteSet.ToProto())
-		if consensus.Proposal.Block.AdditionalData == nil {
-			consensus.Proposal.Block.AdditionalData = &common.AdditionalData{
-				ExtraData: make(map[string][]byte),
-			}
-		}
-		consensus.Proposal.Block.AdditionalData.ExtraData[protocol.TBFTAddtionalDataKey] = qc
+		consensus.addCommitSignatures(voteSet)
 
 		// Commit block to core engine
 		consensus.commitBlock(consensus.Proposal.Block)
@@ -1315,7 +1423,10 @@ func (consensus *ConsensusTBFTImpl) signProposal(proposal *Proposal) error {
 }
 
 func (consensus *ConsensusTBFTImpl) signVote(vote *Vote) error {
-	voteBytes := mustMarshal(vote.ToProto())
+	voteBytes, err := evidence.VoteSignBytes(vote.ToProto(), vote.PartSetRoot)
+	if err != nil {
+		return err
+	}
 	sig, err := consensus.singer.Sign(consensus.chainConf.ChainConfig().Crypto.Hash, voteBytes)
 	if err != nil {
 		consensus.logger.Errorf("[%s](%d/%d/%v) sign vote %s(%d/%d)-%x failed: %v",
@@ -1370,14 +1481,11 @@ func (consensus *ConsensusTBFTImpl) verifyProposal(proposal *tbftpb.Proposal) er
 	return nil
 }
 
-func (consensus *ConsensusTBFTImpl) verifyVote(voteProto *tbftpb.Vote) error {
-	voteProtoCopy := proto.Clone(voteProto)
-	vote, ok := voteProtoCopy.(*tbftpb.Vote)
-	if !ok {
-		return fmt.Errorf("interface to *tbftpb.Vote failed")
+func (consensus *ConsensusTBFTImpl) verifyVote(voteProto *tbftpb.Vote, partSetRoot []byte) error {
+	message, err := evidence.VoteSignBytes(voteProto, partSetRoot)
+	if err != nil {
+		return err
 	}
-	vote.Endorsement = nil
-	message := mustMarshal(vote)
 
 	principal, err := consensus.ac.CreatePrincipal(
 		protocol.ResourceNameConsensusNode,
@@ -1472,7 +1580,7 @@ func (consensus *ConsensusTBFTImpl) saveWalEntry(entry interface{}) {
 		data = mustMarshal(m.ToProto())
 	case *Vote:
 		walType = tbftpb.WalEntryType_VOTE_ENTRY
-		data = mustMarshal(m.ToProto())
+		data = m.Marshal()
 	case timeoutInfo:
 		walType = tbftpb.WalEntryType_TIMEOUT_ENTRY
 		data = mustMarshal(m.ToProto())
@@ -1496,11 +1604,17 @@ func (consensus *ConsensusTBFTImpl) saveWalEntry(entry interface{}) {
 	}
 
 	log := mustMarshal(&walEntry)
+	start := time.Now()
 	err = consensus.wal.Write(lastIndex+1, log)
 	if err != nil {
 		consensus.logger.Fatalf("[%s](%d/%d/%s) save wal type: %s write error: %v",
 			consensus.Id, consensus.Height, consensus.Round, consensus.Step, walType, err)
 	}
+	persistDuration := time.Since(start)
+	if consensus.metrics != nil {
+		consensus.metrics.AppendPersistStateDuration(consensus.Round, walType.String(), persistDuration)
+	}
+	consensus.promMetrics.observeDuration(stepLabelPersist, persistDuration)
 	consensus.logger.Debugf("[%s](%d/%d/%s) save wal type: %s data length: %v",
 		consensus.Id, consensus.Height, consensus.Round, consensus.Step, walType, len(data))
 }
@@ -1590,10 +1704,13 @@ func (consensus *ConsensusTBFTImpl) replayWal() error {
 				consensus.handleVerifyResult(verifyResult, true)
 			}
 		case tbftpb.WalEntryType_VOTE_ENTRY:
-			voteProto := new(tbftpb.Vote)
-			mustUnmarshal(entry.Data, voteProto)
+			voteProto, partSetRoot, err := unmarshalVote(entry.Data)
+			if err != nil {
+				panic(err)
+			}
 			vote := NewVoteFromProto(voteProto)
-			err := consensus.addVote(vote, true)
+			vote.PartSetRoot = partSetRoot
+			err = consensus.addVote(vote, true)
 			if err != nil {
 				errMsg := fmt.Sprintf("[%s](%d/%d/%s) addVote %s(%d/%d) failed, %v",
 					consensus.Id, consensus.Height, consensus.Round, consensus.Step,
@@ -0,0 +1,236 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+var instances sync.Map // chain id => *ConsensusTBFTImpl, the started instances
+
+// debugState is the state of a TBFT instance served by DebugHandler.
+// It has the block hashes and voters only, the blocks are not served on the monitor port.
+type debugState struct {
+	Id               string              `json:"id"`
+	Height           uint64              `json:"height"`
+	Round            int32               `json:"round"`
+	Step             string              `json:"step"`
+	Validators       []string            `json:"validators"`
+	Timeouts         map[string]string   `json:"timeouts"` // timeouts of the current round
+	Metrics          json.RawMessage     `json:"metrics"`  // round metrics of the current height
+	Proposal         *proposalDebugState `json:"proposal"`
+	VerifingProposal *proposalDebugState `json:"verifing_proposal"`
+	LockedRound      int32               `json:"locked_round"`
+	LockedProposal   *proposalDebugState `json:"locked_proposal"`
+	ValidRound       int32               `json:"valid_round"`
+	ValidProposal    *proposalDebugState `json:"valid_proposal"`
+	Rounds           []*roundDebugState  `json:"rounds"` // vote sets of the rounds of the current height
+	Peers            []*peerDebugState   `json:"peers"`
+}
+
+// proposalDebugState is a proposal without its block
+type proposalDebugState struct {
+	Voter     string `json:"voter"`
+	Height    uint64 `json:"height"`
+	Round     int32  `json:"round"`
+	PolRound  int32  `json:"pol_round"`
+	BlockHash string `json:"block_hash"`
+}
+
+// roundDebugState is the vote sets of a round
+type roundDebugState struct {
+	Round      int32              `json:"round"`
+	Prevotes   *voteSetDebugState `json:"prevotes"`
+	Precommits *voteSetDebugState `json:"precommits"`
+}
+
+// voteSetDebugState is the voters of a vote set by the block hash voted for, "" for nil votes
+type voteSetDebugState struct {
+	Maj23  string              `json:"maj23"`
+	Voters map[string][]string `json:"voters"`
+}
+
+// peerDebugState is the state of a peer known by gossip
+type peerDebugState struct {
+	Id         string   `json:"id"`
+	Height     uint64   `json:"height"`
+	Round      int32    `json:"round"`
+	Step       string   `json:"step"`
+	Prevotes   []string `json:"prevotes"`   // voters of the prevotes of peer in its round
+	Precommits []string `json:"precommits"` // voters of the precommits of peer in its round
+}
+
+// DebugHandler returns the http handler serving the state of the TBFT instance of chain in json,
+// at "?chain_id=<chain id>", and the chain ids of the TBFT instances without chain_id.
+// It is to diagnose the stuck rounds without debug logs.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chainId := r.URL.Query().Get("chain_id")
+		var result interface{}
+		if chainId == "" {
+			chainIds := make([]string, 0)
+			instances.Range(func(key, _ interface{}) bool {
+				chainIds = append(chainIds, key.(string))
+				return true
+			})
+			sort.Strings(chainIds)
+			result = chainIds
+		} else {
+			consensus, ok := instances.Load(chainId)
+			if !ok {
+				http.Error(w, "no TBFT instance of chain "+chainId, http.StatusNotFound)
+				return
+			}
+			result = consensus.(*ConsensusTBFTImpl).debugState()
+		}
+		bz, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(bz)
+	})
+}
+
+// debugState returns the current state of consensus and its peers
+func (consensus *ConsensusTBFTImpl) debugState() *debugState {
+	consensus.RLock()
+	state := &debugState{
+		Id:         consensus.Id,
+		Height:     consensus.Height,
+		Round:      consensus.Round,
+		Step:       consensus.Step.String(),
+		Validators: consensus.validatorSet.List(),
+		Timeouts: map[string]string{
+			stepLabelPropose:   consensus.ProposeTimeout(consensus.Round).String(),
+			stepLabelPrevote:   consensus.PrevoteTimeout(consensus.Round).String(),
+			stepLabelPrecommit: consensus.PrecommitTimeout(consensus.Round).String(),
+		},
+		Proposal:         debugProposal(consensus.Proposal),
+		VerifingProposal: debugProposal(consensus.VerifingProposal),
+		LockedRound:      consensus.LockedRound,
+		LockedProposal:   debugProposal(consensus.LockedProposal),
+		ValidRound:       consensus.ValidRound,
+		ValidProposal:    debugProposal(consensus.ValidProposal),
+		Rounds:           debugRounds(consensus.heightRoundVoteSet),
+	}
+	if consensus.metrics != nil {
+		state.Metrics = json.RawMessage(consensus.metrics.String())
+	}
+	consensus.RUnlock()
+
+	state.Peers = consensus.gossip.debugPeerStates()
+	return state
+}
+
+func debugProposal(proposal *Proposal) *proposalDebugState {
+	if proposal == nil {
+		return nil
+	}
+	state := &proposalDebugState{
+		Voter:    proposal.Voter,
+		Height:   proposal.Height,
+		Round:    proposal.Round,
+		PolRound: proposal.PolRound,
+	}
+	if proposal.Block != nil && proposal.Block.Header != nil {
+		state.BlockHash = hex.EncodeToString(proposal.Block.Header.BlockHash)
+	}
+	return state
+}
+
+// debugRounds returns the vote sets of hvs sorted by round
+func debugRounds(hvs *heightRoundVoteSet) []*roundDebugState {
+	if hvs == nil {
+		return nil
+	}
+	rounds := make([]*roundDebugState, 0, len(hvs.RoundVoteSets))
+	for round, rvs := range hvs.RoundVoteSets {
+		if rvs == nil {
+			continue
+		}
+		rounds = append(rounds, &roundDebugState{
+			Round:      round,
+			Prevotes:   debugVoteSet(rvs.Prevotes),
+			Precommits: debugVoteSet(rvs.Precommits),
+		})
+	}
+	sort.Slice(rounds, func(i, j int) bool {
+		return rounds[i].Round < rounds[j].Round
+	})
+	return rounds
+}
+
+func debugVoteSet(voteSet *VoteSet) *voteSetDebugState {
+	if voteSet == nil {
+		return nil
+	}
+	state := &voteSetDebugState{
+		Maj23:  hex.EncodeToString(voteSet.Maj23),
+		Voters: make(map[string][]string),
+	}
+	for voter, vote := range voteSet.Votes {
+		hash := hex.EncodeToString(vote.Hash)
+		state.Voters[hash] = append(state.Voters[hash], voter)
+	}
+	for _, voters := range state.Voters {
+		sort.Strings(voters)
+	}
+	return state
+}
+
+// debugPeerStates returns the states of peers sorted by id
+func (g *gossipService) debugPeerStates() []*peerDebugState {
+	g.Lock()
+	peers := make([]*PeerStateService, 0, len(g.peerStates))
+	for _, pss := range g.peerStates {
+		peers = append(peers, pss)
+	}
+	g.Unlock()
+
+	states := make([]*peerDebugState, 0, len(peers))
+	for _, pss := range peers {
+		states = append(states, pss.debugState())
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Id < states[j].Id
+	})
+	return states
+}
+
+func (pcs *PeerStateService) debugState() *peerDebugState {
+	pcs.Lock()
+	defer pcs.Unlock()
+	state := &peerDebugState{
+		Id:     pcs.Id,
+		Height: pcs.Height,
+		Round:  pcs.Round,
+		Step:   pcs.Step.String(),
+	}
+	if pcs.RoundVoteSet != nil {
+		state.Prevotes = debugVoters(pcs.RoundVoteSet.Prevotes)
+		state.Precommits = debugVoters(pcs.RoundVoteSet.Precommits)
+	}
+	return state
+}
+
+func debugVoters(voteSet *VoteSet) []string {
+	if voteSet == nil {
+		return nil
+	}
+	voters := make([]string, 0, len(voteSet.Votes))
+	for voter := range voteSet.Votes {
+		voters = append(voters, voter)
+	}
+	sort.Strings(voters)
+	return voters
+}
@@ -0,0 +1,68 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugHandler(t *testing.T) {
+	handler := DebugHandler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/tbft", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var chainIds []string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &chainIds))
+	require.Empty(t, chainIds)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/tbft?chain_id=chain1", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestPromMetricsDisabled(t *testing.T) {
+	// the metrics are nil when monitor is disabled, observing is a no-op
+	var m *promMetrics
+	h := newHeightMetrics(1)
+	h.SetEnterNewRoundTime(0)
+	h.SetEnterProposalTime(0)
+	m.observeHeight(h)
+	m.observeDuration(stepLabelPersist, 0)
+}
+
+func TestDebugProposalAndVoteSet(t *testing.T) {
+	block := &common.Block{
+		Header: &common.BlockHeader{BlockHeight: 10, BlockHash: []byte{0x01, 0x02}},
+		Txs:    []*common.Transaction{{Payload: &common.Payload{TxId: "tx1"}}},
+	}
+	proposal := NewProposal("node1", 10, 1, -1, block)
+	bz, err := json.Marshal(debugProposal(proposal))
+	require.NoError(t, err)
+	require.NotContains(t, string(bz), "tx1")
+	require.Equal(t, "0102", debugProposal(proposal).BlockHash)
+	require.Nil(t, debugProposal(nil))
+
+	voteSet := &VoteSet{
+		Maj23: []byte{0x01, 0x02},
+		Votes: map[string]*Vote{
+			"node2": {Voter: "node2", Hash: []byte{0x01, 0x02}},
+			"node1": {Voter: "node1", Hash: []byte{0x01, 0x02}},
+			"node3": {Voter: "node3"},
+		},
+	}
+	state := debugVoteSet(voteSet)
+	require.Equal(t, "0102", state.Maj23)
+	require.Equal(t, []string{"node1", "node2"}, state.Voters["0102"])
+	require.Equal(t, []string{"node3"}, state.Voters[""])
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"errors"
+
+	"chainmaker.org/chainmaker-go/consensus/evidence"
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	"chainmaker.org/chainmaker/common/v2/msgbus"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	netpb "chainmaker.org/chainmaker/pb-go/v2/net"
+)
+
+// msgTypeEvidence is the message type of equivocation evidence, which is out of the types of pb-go,
+// so that the nodes before evidence ignore it
+const msgTypeEvidence = tbftpb.TBFTMsgType(tbftextpb.TBFTMsgTypeExt_MSG_EVIDENCE)
+
+// isEvidenceActive returns true if the evidence of votes at height is collected
+func (consensus *ConsensusTBFTImpl) isEvidenceActive(height uint64) bool {
+	return activation.IsActive(consensus.chainConf.ChainConfig(), activation.TBFTEvidence, height)
+}
+
+// checkConflictingVote makes evidence of the conflicting votes returned by addVote,
+// and gossips it to validators if it is new
+func (consensus *ConsensusTBFTImpl) checkConflictingVote(err error) {
+	var conflict *ConflictingVoteError
+	if !errors.As(err, &conflict) || !consensus.isEvidenceActive(conflict.New.Height) {
+		return
+	}
+	e := evidence.New(conflict.Existing.ToProto(), conflict.Existing.PartSetRoot,
+		conflict.New.ToProto(), conflict.New.PartSetRoot)
+	if err = e.Validate(); err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) invalid evidence of conflicting votes %v and %v, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, conflict.Existing, conflict.New, err)
+		return
+	}
+	consensus.addEvidence(e)
+}
+
+// procEvidence verifies the evidence gossiped by other validators
+func (consensus *ConsensusTBFTImpl) procEvidence(msg *tbftpb.TBFTMsg) {
+	e, err := evidence.Unmarshal(msg.Msg)
+	if err == nil {
+		err = e.Validate()
+	}
+	if err != nil {
+		consensus.logger.Warnf("[%s](%d/%d/%s) receive invalid evidence, %v",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, err)
+		return
+	}
+	if !consensus.isEvidenceActive(e.VoteA.Height) || !consensus.validatorSet.HasValidator(e.VoteA.Voter) {
+		consensus.logger.Debugf("[%s](%d/%d/%s) ignore evidence %s",
+			consensus.Id, consensus.Height, consensus.Round, consensus.Step, e)
+		return
+	}
+	roots := [][]byte{e.RootA, e.RootB}
+	for i, vote := range []*tbftpb.Vote{e.VoteA, e.VoteB} {
+		if err = consensus.verifyVote(vote, roots[i]); err != nil {
+			consensus.logger.Warnf("[%s](%d/%d/%s) receive evidence %s, verifyVote failed, %v",
+				consensus.Id, consensus.Height, consensus.Round, consensus.Step, e, err)
+			return
+		}
+	}
+	consensus.addEvidence(e)
+}
+
+// addEvidence adds the verified evidence to pool, and gossips it to validators if it is new
+func (consensus *ConsensusTBFTImpl) addEvidence(e *evidence.Evidence) {
+	if !evidence.Of(consensus.chainID).Add(e) {
+		return
+	}
+	consensus.logger.Warnf("[%s](%d/%d/%s) detect equivocation of validator %s, %s",
+		consensus.Id, consensus.Height, consensus.Round, consensus.Step, e.VoteA.Voter, e)
+
+	msg, err := createEvidenceMsg(e)
+	if err != nil {
+		consensus.logger.Errorf("[%s] marshal evidence %s failed, %v", consensus.Id, e, err)
+		return
+	}
+	payload := mustMarshal(msg)
+	for _, validator := range consensus.validatorSet.List() {
+		if validator == consensus.Id {
+			continue
+		}
+		consensus.msgbus.Publish(msgbus.SendConsensusMsg, &netpb.NetMsg{
+			Payload: payload,
+			Type:    netpb.NetMsg_CONSENSUS_MSG,
+			To:      validator,
+		})
+	}
+}
@@ -9,6 +9,20 @@ package tbft
 import (
 	"encoding/json"
 	"time"
+
+	"chainmaker.org/chainmaker/common/v2/monitor"
+	"chainmaker.org/chainmaker/localconf/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// the step labels of the step duration metrics
+const (
+	stepLabelNewRound  = "new_round"
+	stepLabelPropose   = "propose"
+	stepLabelPrevote   = "prevote"
+	stepLabelPrecommit = "precommit"
+	stepLabelCommit    = "commit"
+	stepLabelPersist   = "persist"
 )
 
 type roundMetrics struct {
@@ -162,3 +176,65 @@ func (h *heightMetrics) AppendPersistStateDuration(round int32, step string, d t
 	r := h.getRoundMertrics(round)
 	r.AppendPersistStateDuration(step, d)
 }
+
+// promMetrics exports the round metrics of heights to Prometheus, it is nil if monitor is disabled
+type promMetrics struct {
+	chainId            string
+	metricRounds       *prometheus.HistogramVec // rounds taken by a height
+	metricStepDuration *prometheus.HistogramVec // durations of steps, and of persisting wal entries
+}
+
+func newPromMetrics(chainId string) *promMetrics {
+	if !localconf.ChainMakerConfig.MonitorConfig.Enabled {
+		return nil
+	}
+	return &promMetrics{
+		chainId: chainId,
+		metricRounds: monitor.NewHistogramVec(monitor.SUBSYSTEM_CONSENSUS, "metric_tbft_rounds",
+			"rounds taken by a height", []float64{1, 2, 3, 5, 10}, "chainId"),
+		metricStepDuration: monitor.NewHistogramVec(monitor.SUBSYSTEM_CONSENSUS, "metric_tbft_step_duration",
+			"duration of TBFT steps in seconds", []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+			"chainId", "step"),
+	}
+}
+
+// observeHeight exports the metrics of a height when the next height is entered
+func (m *promMetrics) observeHeight(h *heightMetrics) {
+	if m == nil || h == nil || len(h.rounds) == 0 {
+		return
+	}
+	var committed *roundMetrics
+	maxRound := int32(0)
+	for round, r := range h.rounds {
+		m.observeStep(stepLabelNewRound, r.enterNewRoundTime, r.enterProposalTime)
+		m.observeStep(stepLabelPropose, r.enterProposalTime, r.enterPrevoteTime)
+		m.observeStep(stepLabelPrevote, r.enterPrevoteTime, r.enterPrecommitTime)
+		m.observeStep(stepLabelPrecommit, r.enterPrecommitTime, r.enterCommitTime)
+		if round > maxRound {
+			maxRound = round
+		}
+		// the rounds before the committed one enter commit with nil
+		if !r.enterCommitTime.IsZero() && (committed == nil || round > committed.round) {
+			committed = r
+		}
+	}
+	m.metricRounds.WithLabelValues(m.chainId).Observe(float64(maxRound + 1))
+	if committed != nil {
+		m.observeStep(stepLabelCommit, committed.enterCommitTime, time.Now())
+	}
+}
+
+// observeStep exports the duration of step, the steps not entered are skipped
+func (m *promMetrics) observeStep(step string, start, end time.Time) {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return
+	}
+	m.observeDuration(step, end.Sub(start))
+}
+
+func (m *promMetrics) observeDuration(step string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.metricStepDuration.WithLabelValues(m.chainId, step).Observe(d.Seconds())
+}
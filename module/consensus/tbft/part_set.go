@@ -0,0 +1,246 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
+)
+
+var (
+	ErrPartSetInvalidProof = errors.New("invalid proof of part")
+	ErrPartSetUnexpected   = errors.New("unexpected part")
+)
+
+// PartSetHeader is the commitment of a part set, the number of parts and the merkle root of them
+type PartSetHeader struct {
+	Total uint32
+	Root  []byte
+}
+
+// NewPartSetHeaderFromProto creates a PartSetHeader from pb
+func NewPartSetHeaderFromProto(h *tbftextpb.PartSetHeader) PartSetHeader {
+	return PartSetHeader{Total: h.Total, Root: h.Root}
+}
+
+// ToProto serializes the PartSetHeader
+func (h PartSetHeader) ToProto() *tbftextpb.PartSetHeader {
+	return &tbftextpb.PartSetHeader{Total: h.Total, Root: h.Root}
+}
+
+// Hash returns the hash of header, which is signed by the proposer of the parts
+func (h PartSetHeader) Hash() []byte {
+	data := make([]byte, 4, 4+len(h.Root))
+	binary.BigEndian.PutUint32(data, h.Total)
+	sum := sha256.Sum256(append(data, h.Root...))
+	return sum[:]
+}
+
+func (h PartSetHeader) String() string {
+	return fmt.Sprintf("%d:%x", h.Total, h.Root)
+}
+
+// Part is a part of data with its merkle proof, the hashes of siblings from leaf to root
+type Part struct {
+	Index uint32
+	Bytes []byte
+	Proof [][]byte
+}
+
+// NewPartFromProto creates a Part from pb
+func NewPartFromProto(p *tbftextpb.Part) *Part {
+	return &Part{Index: p.Index, Bytes: p.Bytes, Proof: p.Proof}
+}
+
+// ToProto serializes the Part
+func (p *Part) ToProto() *tbftextpb.Part {
+	return &tbftextpb.Part{Index: p.Index, Bytes: p.Bytes, Proof: p.Proof}
+}
+
+// PartSet is the data split into fixed-size parts, which are sent and verified one by one
+type PartSet struct {
+	header PartSetHeader
+	parts  []*Part
+	count  uint32
+	size   int
+}
+
+// NewPartSetFromData splits data into parts of partSize bytes, the last part may be shorter
+func NewPartSetFromData(data []byte, partSize int) *PartSet {
+	total := (len(data) + partSize - 1) / partSize
+	if total == 0 {
+		total = 1
+	}
+	parts := make([]*Part, total)
+	leaves := make([][]byte, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * partSize
+		if end > len(data) {
+			end = len(data)
+		}
+		parts[i] = &Part{Index: uint32(i), Bytes: data[i*partSize : end]}
+		leaves[i] = leafHash(parts[i].Bytes)
+	}
+	root, proofs := merkleProofs(leaves)
+	for i := range parts {
+		parts[i].Proof = proofs[i]
+	}
+	return &PartSet{
+		header: PartSetHeader{Total: uint32(total), Root: root},
+		parts:  parts,
+		count:  uint32(total),
+		size:   len(data),
+	}
+}
+
+// NewPartSetFromHeader creates an empty part set to be filled with the parts committed by header
+func NewPartSetFromHeader(header PartSetHeader) *PartSet {
+	return &PartSet{
+		header: header,
+		parts:  make([]*Part, header.Total),
+	}
+}
+
+// Header returns the commitment of part set
+func (ps *PartSet) Header() PartSetHeader {
+	return ps.header
+}
+
+// AddPart verifies part with the root and adds it, returns false if it is added already
+func (ps *PartSet) AddPart(part *Part) (bool, error) {
+	if part.Index >= ps.header.Total {
+		return false, fmt.Errorf("%w: index %d of %d parts", ErrPartSetUnexpected, part.Index, ps.header.Total)
+	}
+	if ps.parts[part.Index] != nil {
+		return false, nil
+	}
+	root := rootFromProof(part.Index, ps.header.Total, leafHash(part.Bytes), part.Proof)
+	if root == nil || !bytes.Equal(root, ps.header.Root) {
+		return false, fmt.Errorf("%w: index %d", ErrPartSetInvalidProof, part.Index)
+	}
+	ps.parts[part.Index] = part
+	ps.count++
+	ps.size += len(part.Bytes)
+	return true, nil
+}
+
+// GetPart returns the part at index, nil if it is not received
+func (ps *PartSet) GetPart(index uint32) *Part {
+	if index >= ps.header.Total {
+		return nil
+	}
+	return ps.parts[index]
+}
+
+// IsComplete returns true if all the parts are received
+func (ps *PartSet) IsComplete() bool {
+	return ps.count == ps.header.Total
+}
+
+// Missing returns the indexes of the parts not received
+func (ps *PartSet) Missing() []uint32 {
+	missing := make([]uint32, 0, ps.header.Total-ps.count)
+	for i, part := range ps.parts {
+		if part == nil {
+			missing = append(missing, uint32(i))
+		}
+	}
+	return missing
+}
+
+// Size returns the size of the parts received
+func (ps *PartSet) Size() int {
+	return ps.size
+}
+
+// GetData returns the data joined by parts, nil if it is not complete
+func (ps *PartSet) GetData() []byte {
+	if !ps.IsComplete() {
+		return nil
+	}
+	data := make([]byte, 0, ps.size)
+	for _, part := range ps.parts {
+		data = append(data, part.Bytes...)
+	}
+	return data
+}
+
+// The merkle tree is the one of RFC 6962, the leaves and inner nodes are hashed with different prefixes,
+// and the tree is split at the largest power of 2 less than the number of leaves.
+
+func leafHash(data []byte) []byte {
+	sum := sha256.Sum256(append([]byte{0}, data...))
+	return sum[:]
+}
+
+func innerHash(left, right []byte) []byte {
+	data := make([]byte, 0, 1+len(left)+len(right))
+	data = append(data, 1)
+	data = append(data, left...)
+	data = append(data, right...)
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// splitPoint returns the largest power of 2 less than n, n > 1
+func splitPoint(n uint32) uint32 {
+	return 1 << (bits.Len32(n-1) - 1)
+}
+
+// merkleProofs returns the root of leaves and the proof of each leaf
+func merkleProofs(leaves [][]byte) ([]byte, [][][]byte) {
+	if len(leaves) == 1 {
+		return leaves[0], [][][]byte{nil}
+	}
+	k := splitPoint(uint32(len(leaves)))
+	leftRoot, leftProofs := merkleProofs(leaves[:k])
+	rightRoot, rightProofs := merkleProofs(leaves[k:])
+	for i := range leftProofs {
+		leftProofs[i] = append(leftProofs[i], rightRoot)
+	}
+	for i := range rightProofs {
+		rightProofs[i] = append(rightProofs[i], leftRoot)
+	}
+	return innerHash(leftRoot, rightRoot), append(leftProofs, rightProofs...)
+}
+
+// rootFromProof returns the root computed with the leaf at index of total leaves and its proof,
+// nil if the proof does not match the position
+func rootFromProof(index, total uint32, leaf []byte, proof [][]byte) []byte {
+	if total == 0 || index >= total {
+		return nil
+	}
+	if total == 1 {
+		if len(proof) != 0 {
+			return nil
+		}
+		return leaf
+	}
+	if len(proof) == 0 {
+		return nil
+	}
+	k := splitPoint(total)
+	sibling := proof[len(proof)-1]
+	if index < k {
+		left := rootFromProof(index, k, leaf, proof[:len(proof)-1])
+		if left == nil {
+			return nil
+		}
+		return innerHash(left, sibling)
+	}
+	right := rootFromProof(index-k, total-k, leaf, proof[:len(proof)-1])
+	if right == nil {
+		return nil
+	}
+	return innerHash(sibling, right)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tbft
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartSet(t *testing.T) {
+	for _, size := range []int{0, 1, 9, 10, 11, 35, 64, 100} {
+		data := bytes.Repeat([]byte{1, 2, 3}, size)[:size]
+		ps := NewPartSetFromData(data, 10)
+		require.True(t, ps.IsComplete())
+		require.Equal(t, data, ps.GetData())
+
+		received := NewPartSetFromHeader(NewPartSetHeaderFromProto(ps.Header().ToProto()))
+		require.False(t, received.IsComplete())
+		require.Nil(t, received.GetData())
+		// add parts in reverse order
+		for i := int(ps.Header().Total) - 1; i >= 0; i-- {
+			added, err := received.AddPart(NewPartFromProto(ps.GetPart(uint32(i)).ToProto()))
+			require.NoError(t, err)
+			require.True(t, added)
+			added, err = received.AddPart(ps.GetPart(uint32(i)))
+			require.NoError(t, err)
+			require.False(t, added)
+		}
+		require.True(t, received.IsComplete())
+		require.Empty(t, received.Missing())
+		require.Equal(t, len(data), received.Size())
+		require.Equal(t, data, received.GetData())
+	}
+}
+
+func TestPartSetInvalidPart(t *testing.T) {
+	ps := NewPartSetFromData(bytes.Repeat([]byte{1}, 55), 10)
+	received := NewPartSetFromHeader(ps.Header())
+	require.Equal(t, []uint32{0, 1, 2, 3, 4, 5}, received.Missing())
+
+	part := ps.GetPart(2)
+	_, err := received.AddPart(&Part{Index: 2, Bytes: []byte("tampered"), Proof: part.Proof})
+	require.True(t, errors.Is(err, ErrPartSetInvalidProof))
+	_, err = received.AddPart(&Part{Index: 3, Bytes: part.Bytes, Proof: part.Proof})
+	require.True(t, errors.Is(err, ErrPartSetInvalidProof))
+	_, err = received.AddPart(&Part{Index: 2, Bytes: part.Bytes, Proof: part.Proof[1:]})
+	require.True(t, errors.Is(err, ErrPartSetInvalidProof))
+	_, err = received.AddPart(&Part{Index: 6, Bytes: part.Bytes, Proof: part.Proof})
+	require.True(t, errors.Is(err, ErrPartSetUnexpected))
+
+	// parts of another part set
+	other := NewPartSetFromData(bytes.Repeat([]byte{2}, 55), 10)
+	_, err = received.AddPart(other.GetPart(2))
+	require.True(t, errors.Is(err, ErrPartSetInvalidProof))
+
+	added, err := received.AddPart(part)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, []uint32{0, 1, 3, 4, 5}, received.Missing())
+	require.Nil(t, received.GetPart(0))
+	require.Equal(t, part, received.GetPart(2))
+
+	require.NotEqual(t, ps.Header().Hash(), other.Header().Hash())
+	require.NotEqual(t, ps.Header().Hash(), PartSetHeader{Total: 5, Root: ps.Header().Root}.Hash())
+}
+
+func TestParseBlockPartSize(t *testing.T) {
+	config := &configpb.ConsensusConfig{}
+	size, err := parseBlockPartSize(config)
+	require.NoError(t, err)
+	require.Equal(t, defaultBlockPartSize, size)
+
+	config.ExtConfig = []*configpb.ConfigKeyValue{{Key: TBFTBlockPartSizeKey, Value: "1048576"}}
+	size, err = parseBlockPartSize(config)
+	require.NoError(t, err)
+	require.Equal(t, 1048576, size)
+
+	config.ExtConfig[0].Value = "100"
+	_, err = parseBlockPartSize(config)
+	require.Error(t, err)
+	config.ExtConfig[0].Value = "1m"
+	_, err = parseBlockPartSize(config)
+	require.Error(t, err)
+}
+
+func TestVotePartSetRoot(t *testing.T) {
+	vote := NewVote(tbftpb.VoteType_VOTE_PREVOTE, "node1", 10, 0, []byte("hash"))
+	vote.PartSetRoot = []byte("root1")
+	voteProto, root, err := unmarshalVote(vote.Marshal())
+	require.NoError(t, err)
+	require.Equal(t, vote.ToProto(), voteProto)
+	require.Equal(t, vote.PartSetRoot, root)
+
+	// the vote without root is the vote of pb-go
+	vote.PartSetRoot = nil
+	require.Equal(t, mustMarshal(vote.ToProto()), vote.Marshal())
+	_, root, err = unmarshalVote(vote.Marshal())
+	require.NoError(t, err)
+	require.Empty(t, root)
+}
+
+func TestVoteSetPartSetRoot(t *testing.T) {
+	validators := newValidatorSet(cmLogger, []string{"node1", "node2", "node3", "node4"}, DefaultBlocksPerProposer)
+	voteSet := NewVoteSet(cmLogger, tbftpb.VoteType_VOTE_PRECOMMIT, 10, 0, validators)
+	newVote := func(voter string, root string) *Vote {
+		vote := NewVote(tbftpb.VoteType_VOTE_PRECOMMIT, voter, 10, 0, []byte("hash"))
+		vote.PartSetRoot = []byte(root)
+		return vote
+	}
+
+	// the votes for the same block in different part sets are counted apart
+	for _, vote := range []*Vote{newVote("node1", "root1"), newVote("node2", "root1"), newVote("node3", "root2")} {
+		added, err := voteSet.AddVote(vote)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	require.False(t, voteSet.HasTwoThirdsMajority())
+	_, err := voteSet.AddVote(newVote("node3", "root1"))
+	var conflict *ConflictingVoteError
+	require.True(t, errors.As(err, &conflict))
+
+	added, err := voteSet.AddVote(newVote("node4", "root1"))
+	require.NoError(t, err)
+	require.True(t, added)
+	require.True(t, voteSet.HasTwoThirdsMajority())
+	require.Equal(t, []byte("root1"), voteSet.maj23Root)
+	require.Len(t, voteSet.majorityVotes().Votes, 3)
+
+	// the roots are kept in the vote set in protobuf
+	parsed, err := unmarshalVoteSet(cmLogger, voteSet.Marshal(), validators)
+	require.NoError(t, err)
+	require.Equal(t, []byte("root1"), parsed.maj23Root)
+	require.Equal(t, []byte("root2"), parsed.Votes["node3"].PartSetRoot)
+}
@@ -37,6 +37,8 @@ type PeerStateService struct {
 	ValidProposal    *Proposal // valid proposal
 	RoundVoteSet     *roundVoteSet
 
+	sentBlockPartsRoot []byte // the root of the block parts sent to peer
+
 	stateC   chan *tbftpb.GossipState
 	tbftImpl *ConsensusTBFTImpl
 	msgbus   msgbus.MessageBus
@@ -209,11 +211,16 @@ func (pcs *PeerStateService) sendStateOfRound() {
 }
 
 func (pcs *PeerStateService) sendProposalOfRound(height uint64, round int32) {
+	if pcs.VerifingProposal != nil || pcs.Step < tbftpb.Step_PROPOSE {
+		return
+	}
+	// Send proposal in parts (the proposer or the validators having the parts can send them)
+	if vote, parts := pcs.tbftImpl.proposalBlockParts(height, round); vote != nil {
+		pcs.sendBlockParts(vote, parts)
+		return
+	}
 	// Send proposal (only proposer can send proposal)
-	if pcs.tbftImpl.isProposer(height, round) &&
-		pcs.tbftImpl.Proposal != nil &&
-		pcs.VerifingProposal == nil &&
-		pcs.Step >= tbftpb.Step_PROPOSE {
+	if pcs.tbftImpl.isProposer(height, round) && pcs.tbftImpl.Proposal != nil {
 		pcs.logger.Debugf("[%s] sendProposalOfRound: [%d,%d]",
 			pcs.Id, pcs.tbftImpl.Proposal.Height, pcs.tbftImpl.Proposal.Round)
 		pcs.sendProposal(pcs.tbftImpl.Proposal)
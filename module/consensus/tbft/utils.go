@@ -8,18 +8,17 @@ package tbft
 
 import (
 	"bytes"
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
 
 	"chainmaker.org/chainmaker-go/consensus/dpos"
+	"chainmaker.org/chainmaker-go/consensus/evidence"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
 	"chainmaker.org/chainmaker/logger/v2"
 	"chainmaker.org/chainmaker/pb-go/v2/common"
 	"chainmaker.org/chainmaker/pb-go/v2/config"
 	"chainmaker.org/chainmaker/pb-go/v2/consensus"
-	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
 	"chainmaker.org/chainmaker/protocol/v2"
-
-	"github.com/gogo/protobuf/proto"
 )
 
 func GetValidatorList(chainConfig *config.ChainConfig, store protocol.BlockchainStore) (validators []string,
@@ -55,6 +54,81 @@ func GetValidatorListFromLedger(store protocol.BlockchainStore) (validators []st
 	return nodeIDs, nil
 }
 
+const (
+	// TBFTValidatorWeightsKey is the key in consensus ext config of the voting weights of validators,
+	// a json object of node id or org id to weight, the weight of an org applies to each of its nodes.
+	// The validators not listed have weight 1. It takes effect from the activation height of
+	// activation.TBFTWeightedVoting.
+	TBFTValidatorWeightsKey = "TBFT_validator_weights"
+
+	maxValidatorWeight = 1000000 // max weight of a validator in config
+)
+
+// validatorWeightsProvider provides the voting weights of validators by node id, implemented by DPoS
+type validatorWeightsProvider interface {
+	GetValidatorWeights() (map[string]uint64, error)
+}
+
+// parseValidatorWeights parses the value of TBFTValidatorWeightsKey
+func parseValidatorWeights(value string) (map[string]uint64, error) {
+	weights := make(map[string]uint64)
+	if err := json.Unmarshal([]byte(value), &weights); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", TBFTValidatorWeightsKey, err)
+	}
+	for id, weight := range weights {
+		if weight == 0 || weight > maxValidatorWeight {
+			return nil, fmt.Errorf("invalid %s: weight of %s expect in [1, %d], got %d",
+				TBFTValidatorWeightsKey, id, maxValidatorWeight, weight)
+		}
+	}
+	return weights, nil
+}
+
+// GetValidatorWeightsFromConfig returns the voting weights of the consensus nodes in chain config by node id,
+// nil if the weights are not configured
+func GetValidatorWeightsFromConfig(chainConfig *config.ChainConfig) (map[string]uint64, error) {
+	var weights map[string]uint64
+	for _, kv := range chainConfig.Consensus.ExtConfig {
+		if kv.Key != TBFTValidatorWeightsKey {
+			continue
+		}
+		var err error
+		if weights, err = parseValidatorWeights(string(kv.Value)); err != nil {
+			return nil, err
+		}
+	}
+	if weights == nil {
+		return nil, nil
+	}
+	nodeWeights := make(map[string]uint64)
+	for _, node := range chainConfig.Consensus.Nodes {
+		for _, nodeId := range node.NodeId {
+			weight, ok := weights[nodeId]
+			if !ok {
+				weight, ok = weights[node.OrgId]
+			}
+			if !ok {
+				weight = 1
+			}
+			nodeWeights[nodeId] = weight
+		}
+	}
+	return nodeWeights, nil
+}
+
+// GetValidatorWeights returns the voting weights of validators for the block at height by node id,
+// nil if the votes are equal
+func GetValidatorWeights(chainConfig *config.ChainConfig, store protocol.BlockchainStore,
+	height uint64) (map[string]uint64, error) {
+	if !activation.IsActive(chainConfig, activation.TBFTWeightedVoting, height) {
+		return nil, nil
+	}
+	if chainConfig.Consensus.Type == consensus.ConsensusType_DPOS {
+		return dpos.GetValidatorWeightsFromLedger(store)
+	}
+	return GetValidatorWeightsFromConfig(chainConfig)
+}
+
 // VerifyBlockSignatures verifies whether the signatures in block
 // is qulified with the consensus algorithm. It should return nil
 // error when verify successfully, and return corresponding error
@@ -65,16 +139,6 @@ func VerifyBlockSignatures(chainConf protocol.ChainConf,
 	if block == nil || block.Header == nil || block.AdditionalData == nil || block.AdditionalData.ExtraData == nil {
 		return fmt.Errorf("invalid block")
 	}
-	blockVoteSet, ok := block.AdditionalData.ExtraData[protocol.TBFTAddtionalDataKey]
-	if !ok {
-		return fmt.Errorf("block.AdditionalData.ExtraData[TBFTAddtionalDataKey] not exist")
-	}
-
-	voteSetProto := new(tbftpb.VoteSet)
-	if err := proto.Unmarshal(blockVoteSet, voteSetProto); err != nil {
-		return err
-	}
-
 	height := block.Header.BlockHeight
 	chainConfig, err := chainConf.GetChainConfigFromFuture(height)
 	if err != nil {
@@ -86,9 +150,39 @@ func VerifyBlockSignatures(chainConf protocol.ChainConf,
 		return err
 	}
 
+	weights, err := GetValidatorWeights(chainConfig, store, height)
+	if err != nil {
+		return err
+	}
+
 	logger := logger.GetLoggerByChain(logger.MODULE_CONSENSUS, chainConfig.ChainId)
 	validatorSet := newValidatorSet(logger, validators, DefaultBlocksPerProposer)
-	voteSet := NewVoteSetFromProto(logger, voteSetProto, validatorSet)
+	validatorSet.updateWeights(weights)
+
+	// the block certified by an aggregate commit carries no precommits
+	if commit, ok := block.AdditionalData.ExtraData[TBFTAggregateCommitKey]; ok {
+		if !activation.IsActive(chainConfig, activation.TBFTAggregateSignatures, height) {
+			return fmt.Errorf("aggregate commit before the activation of %s", activation.TBFTAggregateSignatures)
+		}
+		if err = verifyAggregateCommit(chainConfig, validatorSet, block, commit); err != nil {
+			clog.Infof("verify block signatures block(%d-%x) error: %v",
+				block.Header.BlockHeight, block.Header.BlockHash, err)
+			return err
+		}
+		clog.Debugf("VerifyBlockSignatures block (%d-%x) by aggregate commit success",
+			block.Header.BlockHeight, block.Header.BlockHash)
+		return nil
+	}
+
+	blockVoteSet, ok := block.AdditionalData.ExtraData[protocol.TBFTAddtionalDataKey]
+	if !ok {
+		return fmt.Errorf("block.AdditionalData.ExtraData[TBFTAddtionalDataKey] not exist")
+	}
+
+	voteSet, err := unmarshalVoteSet(logger, blockVoteSet, validatorSet)
+	if err != nil {
+		return err
+	}
 	hash, ok := voteSet.twoThirdsMajority()
 	if !ok {
 		return fmt.Errorf("voteSet without majority")
@@ -98,18 +192,14 @@ func VerifyBlockSignatures(chainConf protocol.ChainConf,
 		return fmt.Errorf("unmatch QC: %x to block hash: %v", hash, block.Header.BlockHash)
 	}
 
-	hashStr := base64.StdEncoding.EncodeToString(hash)
-	blockVotes := voteSet.VotesByBlock[hashStr]
+	blockVotes := voteSet.majorityVotes()
 	// blockVotes should contain valid vote only, otherwise the block is invalid
 	for _, v := range blockVotes.Votes {
 		voteProto := v.ToProto()
-		voteProtoCopy := proto.Clone(voteProto)
-		vote, ok := voteProtoCopy.(*tbftpb.Vote)
-		if !ok {
-			return fmt.Errorf("interface transfer to *tbftpb.Vote failed")
+		message, err := evidence.VoteSignBytes(voteProto, v.PartSetRoot)
+		if err != nil {
+			return err
 		}
-		vote.Endorsement = nil
-		message := mustMarshal(vote)
 
 		principal, err := ac.CreatePrincipal(
 			protocol.ResourceNameConsensusNode,
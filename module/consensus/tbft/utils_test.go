@@ -377,3 +377,30 @@ func TestVerifyBlockSignaturesFourNodeFail(t *testing.T) {
 		t.Errorf("VerifyBlockSignatures() error = %v, but expecte error", err)
 	}
 }
+
+func TestGetValidatorWeightsFromConfig(t *testing.T) {
+	chainConfig := &configpb.ChainConfig{
+		Consensus: &configpb.ConsensusConfig{
+			Type: consensuspb.ConsensusType_TBFT,
+			Nodes: []*configpb.OrgConfig{
+				{OrgId: org1Id, NodeId: []string{org1NodeId}},
+				{OrgId: org2Id, NodeId: []string{org2NodeId}},
+				{OrgId: org3Id, NodeId: []string{org3NodeId}},
+			},
+		},
+	}
+	weights, err := GetValidatorWeightsFromConfig(chainConfig)
+	require.NoError(t, err)
+	require.Nil(t, weights)
+
+	chainConfig.Consensus.ExtConfig = []*configpb.ConfigKeyValue{
+		{Key: TBFTValidatorWeightsKey, Value: `{"` + org1Id + `": 3, "` + org2NodeId + `": 2}`},
+	}
+	weights, err = GetValidatorWeightsFromConfig(chainConfig)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{org1NodeId: 3, org2NodeId: 2, org3NodeId: 1}, weights)
+
+	chainConfig.Consensus.ExtConfig[0].Value = `{"` + org1Id + `": 0}`
+	_, err = GetValidatorWeightsFromConfig(chainConfig)
+	require.Error(t, err)
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package tbft
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"sort"
@@ -24,6 +26,8 @@ type validatorSet struct {
 	logger            *logger.CMLogger
 	Validators        []string
 	blocksPerProposer uint64
+	// voting weights of validators, nil means every validator has weight 1
+	weights map[string]uint64
 }
 
 func newValidatorSet(logger *logger.CMLogger, validators []string, blocksPerProposer uint64) *validatorSet {
@@ -72,6 +76,20 @@ func (valSet *validatorSet) Size() int32 {
 	return int32(len(valSet.Validators))
 }
 
+// List holds the lock and returns a copy of validators
+func (valSet *validatorSet) List() []string {
+	if valSet == nil {
+		return nil
+	}
+
+	valSet.Lock()
+	defer valSet.Unlock()
+
+	validators := make([]string, len(valSet.Validators))
+	copy(validators, valSet.Validators)
+	return validators
+}
+
 // HasValidator holds the lock and return whether validator is in
 // the validatorSet
 func (valSet *validatorSet) HasValidator(validator string) bool {
@@ -94,10 +112,15 @@ func (valSet *validatorSet) hasValidator(validator string) bool {
 	return false
 }
 
+// GetProposer returns the proposer of round at height. Without weights the validators take turns,
+// otherwise the proposer is sampled in proportion to weight.
 func (valSet *validatorSet) GetProposer(height uint64, round int32) (validator string, err error) {
 	if valSet.isNilOrEmpty() {
 		return "", ErrInvalidIndex
 	}
+	if proposer, ok := valSet.getWeightedProposer((height+1)/valSet.blocksPerProposer, round); ok {
+		return proposer, nil
+	}
 
 	heightOffset := int32((height + 1) / valSet.blocksPerProposer)
 	roundOffset := round % valSet.Size()
@@ -148,6 +171,101 @@ func (valSet *validatorSet) updateValidators(validators []string) (addedValidato
 	return
 }
 
+// getWeightedProposer samples validators by weight without replacement, seeded by heightOffset.
+// The proposer of round 0 is chosen in proportion to weight, and each later round tries
+// a validator not tried in the rounds before. Returns false if the validators are not weighted.
+func (valSet *validatorSet) getWeightedProposer(heightOffset uint64, round int32) (string, bool) {
+	valSet.Lock()
+	defer valSet.Unlock()
+	if valSet.weights == nil || len(valSet.Validators) == 0 {
+		return "", false
+	}
+
+	candidates := make([]string, len(valSet.Validators))
+	copy(candidates, valSet.Validators)
+	total := valSet.totalWeight()
+	turns := int(round)%len(candidates) + 1
+	var proposer string
+	var seed [16]byte
+	binary.BigEndian.PutUint64(seed[:8], heightOffset)
+	for i := 0; i < turns; i++ {
+		binary.BigEndian.PutUint64(seed[8:], uint64(i))
+		sum := sha256.Sum256(seed[:])
+		point := binary.BigEndian.Uint64(sum[:8]) % total
+		for j, candidate := range candidates {
+			weight := valSet.weightOf(candidate)
+			if point < weight {
+				proposer = candidate
+				candidates = append(candidates[:j], candidates[j+1:]...)
+				total -= weight
+				break
+			}
+			point -= weight
+		}
+	}
+	return proposer, true
+}
+
+// updateWeights sets the voting weights of validators, nil or empty means equal weights
+func (valSet *validatorSet) updateWeights(weights map[string]uint64) {
+	valSet.Lock()
+	defer valSet.Unlock()
+
+	if len(weights) == 0 {
+		weights = nil
+	}
+	valSet.weights = weights
+	if weights != nil {
+		valSet.logger.Infof("update validator weights: %v", weights)
+	}
+}
+
+// TotalWeight returns the sum of weights of validators
+func (valSet *validatorSet) TotalWeight() uint64 {
+	if valSet == nil {
+		return 0
+	}
+	valSet.Lock()
+	defer valSet.Unlock()
+	return valSet.totalWeight()
+}
+
+// WeightOf returns the weight of validator, 0 if it's not a validator
+func (valSet *validatorSet) WeightOf(validator string) uint64 {
+	if valSet == nil {
+		return 0
+	}
+	valSet.Lock()
+	defer valSet.Unlock()
+	if !valSet.hasValidator(validator) {
+		return 0
+	}
+	return valSet.weightOf(validator)
+}
+
+// Quorum returns the weight of more than 2/3 of validators
+func (valSet *validatorSet) Quorum() uint64 {
+	return valSet.TotalWeight()*2/3 + 1
+}
+
+func (valSet *validatorSet) totalWeight() uint64 {
+	var total uint64
+	for _, val := range valSet.Validators {
+		total += valSet.weightOf(val)
+	}
+	return total
+}
+
+func (valSet *validatorSet) weightOf(validator string) uint64 {
+	if valSet.weights == nil {
+		return 1
+	}
+	if weight := valSet.weights[validator]; weight > 0 {
+		return weight
+	}
+	return 1
+}
+
 func (valSet *validatorSet) updateBlocksPerProposer(blocks uint64) error {
 	valSet.Lock()
 	defer valSet.Unlock()
@@ -7,8 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package tbft
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidatorSetUpdateValidators(t *testing.T) {
@@ -95,3 +99,77 @@ func TestValidatorSetUpdateValidators(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatorSetWeights(t *testing.T) {
+	valSet := newValidatorSet(cmLogger, []string{"node1", "node2", "node3", "node4"}, DefaultBlocksPerProposer)
+	require.EqualValues(t, 4, valSet.TotalWeight())
+	require.EqualValues(t, 3, valSet.Quorum())
+
+	valSet.updateWeights(map[string]uint64{"node1": 7, "node2": 1, "node3": 1})
+	require.EqualValues(t, 10, valSet.TotalWeight())
+	require.EqualValues(t, 7, valSet.Quorum())
+	require.EqualValues(t, 7, valSet.WeightOf("node1"))
+	require.EqualValues(t, 1, valSet.WeightOf("node4"))
+	require.EqualValues(t, 0, valSet.WeightOf("node5"))
+
+	// each round of a height tries a different validator
+	for height := uint64(1); height < 10; height++ {
+		proposers := make(map[string]struct{})
+		for round := int32(0); round < 4; round++ {
+			proposer, err := valSet.GetProposer(height, round)
+			require.NoError(t, err)
+			proposers[proposer] = struct{}{}
+		}
+		require.Len(t, proposers, 4)
+	}
+
+	// the heavy validator proposes most of the heights
+	count := 0
+	for height := uint64(1); height <= 1000; height++ {
+		if proposer, _ := valSet.GetProposer(height, 0); proposer == "node1" {
+			count++
+		}
+	}
+	require.True(t, count > 600 && count < 800, "node1 proposed %d of 1000", count)
+}
+
+func TestVoteSetWeightedMajority(t *testing.T) {
+	valSet := newValidatorSet(cmLogger, []string{"node1", "node2", "node3", "node4"}, DefaultBlocksPerProposer)
+	valSet.updateWeights(map[string]uint64{"node1": 7, "node2": 1, "node3": 1})
+	hash := []byte("hash")
+
+	voteSet := NewVoteSet(cmLogger, tbftpb.VoteType_VOTE_PRECOMMIT, 1, 0, valSet)
+	for _, voter := range []string{"node2", "node3", "node4"} {
+		added, err := voteSet.AddVote(NewVote(tbftpb.VoteType_VOTE_PRECOMMIT, voter, 1, 0, hash))
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	// 3 of 4 validators but only 3 of 10 weights
+	require.False(t, voteSet.HasTwoThirdsMajority())
+
+	added, err := voteSet.AddVote(NewVote(tbftpb.VoteType_VOTE_PRECOMMIT, "node1", 1, 0, hash))
+	require.NoError(t, err)
+	require.True(t, added)
+	require.True(t, voteSet.HasTwoThirdsMajority())
+	require.EqualValues(t, 10, voteSet.Sum)
+}
+
+func TestVoteSetConflictingVote(t *testing.T) {
+	valSet := newValidatorSet(cmLogger, []string{"node1", "node2", "node3", "node4"}, DefaultBlocksPerProposer)
+	voteSet := NewVoteSet(cmLogger, tbftpb.VoteType_VOTE_PREVOTE, 1, 0, valSet)
+
+	added, err := voteSet.AddVote(NewVote(tbftpb.VoteType_VOTE_PREVOTE, "node1", 1, 0, []byte("hash1")))
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = voteSet.AddVote(NewVote(tbftpb.VoteType_VOTE_PREVOTE, "node1", 1, 0, []byte("hash1")))
+	require.NoError(t, err)
+	require.False(t, added)
+
+	added, err = voteSet.AddVote(NewVote(tbftpb.VoteType_VOTE_PREVOTE, "node1", 1, 0, []byte("hash2")))
+	require.False(t, added)
+	require.True(t, errors.Is(err, ErrVoteForDifferentHash))
+	var conflict *ConflictingVoteError
+	require.True(t, errors.As(err, &conflict))
+	require.Equal(t, []byte("hash1"), conflict.Existing.Hash)
+	require.Equal(t, []byte("hash2"), conflict.New.Hash)
+}
@@ -13,11 +13,14 @@ import (
 	"fmt"
 	"strings"
 
+	"chainmaker.org/chainmaker-go/consensus/evidence"
+	tbftextpb "chainmaker.org/chainmaker-go/pb/consensus/tbft"
 	"chainmaker.org/chainmaker/pb-go/v2/common"
 
 	"chainmaker.org/chainmaker/logger/v2"
 
 	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"github.com/gogo/protobuf/proto"
 )
 
 //const (
@@ -31,6 +34,22 @@ var (
 	ErrVoteForDifferentHash = errors.New("vote for different hash")
 )
 
+// ConflictingVoteError is returned when a voter votes for different hashes or part sets in the VoteSet,
+// the two votes are the evidence of its equivocation
+type ConflictingVoteError struct {
+	Existing *Vote
+	New      *Vote
+}
+
+func (e *ConflictingVoteError) Error() string {
+	return fmt.Sprintf("%s existing: %v, new: %v", ErrVoteForDifferentHash, e.Existing.Hash, e.New.Hash)
+}
+
+// Unwrap returns ErrVoteForDifferentHash
+func (e *ConflictingVoteError) Unwrap() error {
+	return ErrVoteForDifferentHash
+}
+
 // Proposal represent a proposal to be vote for consensus
 type Proposal struct {
 	Voter       string
@@ -39,6 +58,7 @@ type Proposal struct {
 	PolRound    int32
 	Block       *common.Block
 	Endorsement *common.EndorsementEntry
+	PartSetRoot []byte // the root of the parts the proposal is sent in, nil if it is sent as a whole
 }
 
 // NewProposal create a new Proposal instance
@@ -92,6 +112,7 @@ type Vote struct {
 	Round       int32
 	Hash        []byte
 	Endorsement *common.EndorsementEntry
+	PartSetRoot []byte // the part set root of the proposal voted for, see tbftextpb.VoteExt
 }
 
 // NewVote create a new Vote instance
@@ -135,14 +156,44 @@ func (v *Vote) ToProto() *tbftpb.Vote {
 	}
 }
 
+// Marshal returns the vote in protobuf with its part set root, see tbftextpb.VoteExt
+func (v *Vote) Marshal() []byte {
+	return append(mustMarshal(v.ToProto()), mustMarshal(&tbftextpb.VoteExt{PartSetRoot: v.PartSetRoot})...)
+}
+
+// unmarshalVote parses the vote in protobuf with its part set root
+func unmarshalVote(data []byte) (*tbftpb.Vote, []byte, error) {
+	vote := new(tbftpb.Vote)
+	if err := proto.Unmarshal(data, vote); err != nil {
+		return nil, nil, err
+	}
+	ext := new(tbftextpb.VoteExt)
+	if err := proto.Unmarshal(data, ext); err != nil {
+		return nil, nil, err
+	}
+	return vote, ext.PartSetRoot, nil
+}
+
+// voteKey returns the key of the votes for the same hash and part set root in VoteSet.VotesByBlock
+func voteKey(hash, partSetRoot []byte) string {
+	key := base64.StdEncoding.EncodeToString(hash)
+	if len(partSetRoot) == 0 {
+		return key
+	}
+	return key + "/" + base64.StdEncoding.EncodeToString(partSetRoot)
+}
+
 func (v *Vote) String() string {
+	if len(v.PartSetRoot) > 0 {
+		return fmt.Sprintf("Vote{%s-%s(%d/%d)-%x/%x}", v.Type, v.Voter, v.Height, v.Round, v.Hash, v.PartSetRoot)
+	}
 	return fmt.Sprintf("Vote{%s-%s(%d/%d)-%x}", v.Type, v.Voter, v.Height, v.Round, v.Hash)
 }
 
 // BlockVotes traces the vote from different voter
 type BlockVotes struct {
 	Votes map[string]*Vote
-	Sum   uint64
+	Sum   uint64 // sum of the weights of voters
 }
 
 // NewBlockVotes creates a new BlockVotes instance
@@ -181,9 +232,9 @@ func (bv *BlockVotes) ToProto() *tbftpb.BlockVotes {
 	return bvProto
 }
 
-func (bv *BlockVotes) addVote(vote *Vote) {
+func (bv *BlockVotes) addVote(vote *Vote, weight uint64) {
 	bv.Votes[vote.Voter] = vote
-	bv.Sum++
+	bv.Sum += weight
 }
 
 // VoteSet wraps tbftpb.VoteSet and validatorSet
@@ -192,11 +243,13 @@ type VoteSet struct {
 	Type         tbftpb.VoteType
 	Height       uint64
 	Round        int32
-	Sum          uint64
+	Sum          uint64 // sum of the weights of voters
 	Maj23        []byte
 	Votes        map[string]*Vote
 	VotesByBlock map[string]*BlockVotes
 	validators   *validatorSet
+
+	maj23Root []byte // the part set root of the votes reaching majority
 }
 
 // NewVoteSet creates a new VoteSet instance
@@ -255,6 +308,38 @@ func (vs *VoteSet) ToProto() *tbftpb.VoteSet {
 	return vsProto
 }
 
+// Marshal returns the VoteSet in protobuf with the part set roots of votes, see tbftextpb.VoteSetExt
+func (vs *VoteSet) Marshal() []byte {
+	ext := &tbftextpb.VoteSetExt{PartSetRoots: make(map[string][]byte)}
+	for voter, v := range vs.Votes {
+		if len(v.PartSetRoot) > 0 {
+			ext.PartSetRoots[voter] = v.PartSetRoot
+		}
+	}
+	return append(mustMarshal(vs.ToProto()), mustMarshal(ext)...)
+}
+
+// unmarshalVoteSet parses the VoteSet in protobuf with the part set roots of votes
+func unmarshalVoteSet(logger *logger.CMLogger, data []byte, validators *validatorSet) (*VoteSet, error) {
+	vsProto := new(tbftpb.VoteSet)
+	if err := proto.Unmarshal(data, vsProto); err != nil {
+		return nil, err
+	}
+	ext := new(tbftextpb.VoteSetExt)
+	if err := proto.Unmarshal(data, ext); err != nil {
+		return nil, err
+	}
+	vs := NewVoteSet(logger, vsProto.Type, vsProto.Height, vsProto.Round, validators)
+	for _, v := range vsProto.Votes {
+		vote := NewVoteFromProto(v)
+		vote.PartSetRoot = ext.PartSetRoots[v.Voter]
+		if added, err := vs.AddVote(vote); !added || err != nil {
+			logger.Errorf("validators: %s, vote: %s", validators, vote)
+		}
+	}
+	return vs, nil
+}
+
 func (vs *VoteSet) String() string {
 	if vs == nil {
 		return ""
@@ -311,27 +396,27 @@ func (vs *VoteSet) AddVote(vote *Vote) (added bool, err error) {
 	}
 
 	if v, ok := vs.Votes[vote.Voter]; ok {
-		if bytes.Equal(vote.Hash, v.Hash) {
+		if bytes.Equal(vote.Hash, v.Hash) && bytes.Equal(vote.PartSetRoot, v.PartSetRoot) {
 			return false, nil
 		}
-		return false, fmt.Errorf("%w existing: %v, new: %v",
-			ErrVoteForDifferentHash, v.Hash, vote.Hash)
+		return false, &ConflictingVoteError{Existing: v, New: vote}
 	}
 
+	weight := vs.validators.WeightOf(vote.Voter)
 	vs.Votes[vote.Voter] = vote
-	vs.Sum++
+	vs.Sum += weight
 
-	hashStr := base64.StdEncoding.EncodeToString(vote.Hash)
-	votesByBlock, ok := vs.VotesByBlock[hashStr]
+	key := voteKey(vote.Hash, vote.PartSetRoot)
+	votesByBlock, ok := vs.VotesByBlock[key]
 	if !ok {
 		votesByBlock = NewBlockVotes()
-		vs.VotesByBlock[hashStr] = votesByBlock
+		vs.VotesByBlock[key] = votesByBlock
 	}
 
 	oldSum := votesByBlock.Sum
-	quorum := uint64(vs.validators.Size()*2/3 + 1)
+	quorum := vs.validators.Quorum()
 
-	votesByBlock.addVote(vote)
+	votesByBlock.addVote(vote, weight)
 	vs.logger.Debugf("VoteSet(%s/%d/%d) AddVote %s(%s/%d/%d/%x) "+
 		"oldSum: %d, quorum: %d, sum: %d",
 		vs.Type, vs.Height, vs.Round, vote.Voter, vote.Type, vote.Height, vote.Round,
@@ -341,6 +426,7 @@ func (vs *VoteSet) AddVote(vote *Vote) (added bool, err error) {
 		vs.logger.Infof("VoteSet(%s/%d/%d) AddVote reach majority %x",
 			vs.Type, vs.Height, vs.Round, vote.Hash)
 		vs.Maj23 = vote.Hash
+		vs.maj23Root = vote.PartSetRoot
 
 		for k, v := range votesByBlock.Votes {
 			vs.Votes[k] = v
@@ -365,6 +451,14 @@ func (vs *VoteSet) twoThirdsMajority() (hash []byte, ok bool) {
 	return nil, false
 }
 
+// majorityVotes returns the votes reaching majority, nil if there is no majority
+func (vs *VoteSet) majorityVotes() *BlockVotes {
+	if vs == nil || vs.Maj23 == nil {
+		return nil
+	}
+	return vs.VotesByBlock[voteKey(vs.Maj23, vs.maj23Root)]
+}
+
 // HasTwoThirdsMajority shoule used when the mutex has been lock
 func (vs *VoteSet) HasTwoThirdsMajority() (majority bool) {
 	if vs == nil {
@@ -380,9 +474,13 @@ func (vs *VoteSet) hasTwoThirdsAny() bool {
 	}
 
 	ret := true
-	leftSum := uint64(vs.validators.Size()) - vs.Sum
+	var leftSum uint64
+	if total := vs.validators.TotalWeight(); total > vs.Sum {
+		leftSum = total - vs.Sum
+	}
+	quorum := vs.validators.Quorum()
 	for _, v := range vs.VotesByBlock {
-		if (v.Sum + leftSum) >= uint64(vs.validators.Size()*2/3+1) {
+		if (v.Sum + leftSum) >= quorum {
 			ret = false
 			break
 		}
@@ -398,7 +496,7 @@ func (vs *VoteSet) hasTwoThirdsNoMajority() bool {
 		return false
 	}
 
-	return vs.Sum >= uint64(vs.validators.Size()*2/3+1)
+	return vs.Sum >= vs.validators.Quorum()
 }
 
 type roundVoteSet struct {
@@ -569,8 +667,7 @@ func createProposalMsg(proposal *Proposal) *tbftpb.TBFTMsg {
 }
 
 func createPrevoteMsg(prevote *Vote) *tbftpb.TBFTMsg {
-	prevoteProto := prevote.ToProto()
-	data := mustMarshal(prevoteProto)
+	data := prevote.Marshal()
 
 	tbftMsg := &tbftpb.TBFTMsg{
 		Type: tbftpb.TBFTMsgType_MSG_PREVOTE,
@@ -581,8 +678,7 @@ func createPrevoteMsg(prevote *Vote) *tbftpb.TBFTMsg {
 }
 
 func createPrecommitMsg(precommit *Vote) *tbftpb.TBFTMsg {
-	precommitProto := precommit.ToProto()
-	data := mustMarshal(precommitProto)
+	data := precommit.Marshal()
 
 	tbftMsg := &tbftpb.TBFTMsg{
 		Type: tbftpb.TBFTMsgType_MSG_PRECOMMIT,
@@ -591,3 +687,17 @@ func createPrecommitMsg(precommit *Vote) *tbftpb.TBFTMsg {
 
 	return tbftMsg
 }
+
+func createEvidenceMsg(e *evidence.Evidence) (*tbftpb.TBFTMsg, error) {
+	data, err := e.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	tbftMsg := &tbftpb.TBFTMsg{
+		Type: msgTypeEvidence,
+		Msg:  data,
+	}
+
+	return tbftMsg, nil
+}
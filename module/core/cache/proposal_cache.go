@@ -27,6 +27,7 @@ type ProposalCache struct {
 	rwMu              sync.RWMutex
 	chainConf         protocol.ChainConf
 	ledgerCache       protocol.LedgerCache
+	store             *proposalStore // persists self-proposed blocks, nil if not persistent
 }
 
 // blockProposal is a struct cached in ProposalCache.
@@ -50,10 +51,72 @@ func NewProposalCache(chainConf protocol.ChainConf, ledgerCache protocol.LedgerC
 	return pc
 }
 
+// NewPersistentProposalCache get a ProposalCache which persists self-proposed blocks into db in background,
+// and restores the blocks above the committed height from db.
+func NewPersistentProposalCache(chainConf protocol.ChainConf, ledgerCache protocol.LedgerCache,
+	db protocol.DBHandle, log protocol.Logger) (protocol.ProposalCache, error) {
+	store, err := newProposalStore(db, log)
+	if err != nil {
+		return nil, err
+	}
+	pc := &ProposalCache{
+		lastProposedBlock: make(map[uint64]map[string]*blockProposal),
+		chainConf:         chainConf,
+		ledgerCache:       ledgerCache,
+		store:             store,
+	}
+	if err = pc.restore(); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// restore loads the persisted self-proposed blocks, the committed ones are removed
+func (pc *ProposalCache) restore() error {
+	blocks, err := pc.store.load()
+	if err != nil {
+		return err
+	}
+	currentHeight, err := pc.ledgerCache.CurrentHeight()
+	if err != nil {
+		return err
+	}
+	for _, blockWithRWSet := range blocks {
+		block := blockWithRWSet.Block
+		if block.Header.BlockHeight <= currentHeight {
+			pc.store.delete(block)
+			continue
+		}
+		rwSetMap := make(map[string]*commonpb.TxRWSet, len(blockWithRWSet.TxRWSets))
+		for _, rwSet := range blockWithRWSet.TxRWSets {
+			rwSetMap[rwSet.TxId] = rwSet
+		}
+		contractEventMap := make(map[string][]*commonpb.ContractEvent, len(block.Txs))
+		for _, tx := range block.Txs {
+			contractEventMap[tx.Payload.TxId] = nil
+		}
+		for _, event := range blockWithRWSet.ContractEvents {
+			contractEventMap[event.TxId] = append(contractEventMap[event.TxId], event)
+		}
+		if _, ok := pc.lastProposedBlock[block.Header.BlockHeight]; !ok {
+			pc.lastProposedBlock[block.Header.BlockHeight] = make(map[string]*blockProposal)
+		}
+		// not proposed in this round yet, the proposer repeats it when proposing at the height
+		pc.lastProposedBlock[block.Header.BlockHeight][string(utils.CalcBlockFingerPrint(block))] = &blockProposal{
+			block:                block,
+			rwSetMap:             rwSetMap,
+			contractEventInfoMap: contractEventMap,
+			isSelfProposed:       true,
+		}
+	}
+	return nil
+}
+
 // ClearProposedBlockAt clear proposed blocks with height.
 func (pc *ProposalCache) ClearProposedBlockAt(height uint64) {
 	pc.rwMu.Lock()
 	defer pc.rwMu.Unlock()
+	pc.unpersist(pc.lastProposedBlock[height])
 	delete(pc.lastProposedBlock, height)
 }
 
@@ -132,6 +195,10 @@ func (pc *ProposalCache) SetProposedBlock(b *commonpb.Block, rwSetMap map[string
 	}
 	pc.rwMu.Lock()
 	defer pc.rwMu.Unlock()
+	// persist in background, so that the same block is repeated after restart
+	if selfPropose && pc.store != nil {
+		pc.store.put(b, rwSetMap, contractEventMap)
+	}
 	if _, ok := pc.lastProposedBlock[height]; !ok {
 		pc.lastProposedBlock[height] = make(map[string]*blockProposal)
 	}
@@ -145,6 +212,9 @@ func (pc *ProposalCache) ClearTheBlock(block *commonpb.Block) {
 
 	if proposedBlocks, ok := pc.lastProposedBlock[block.Header.BlockHeight]; ok {
 		fingerPrint := utils.CalcBlockFingerPrint(block)
+		if proposedBlock, ok := proposedBlocks[string(fingerPrint)]; ok {
+			pc.unpersist(map[string]*blockProposal{string(fingerPrint): proposedBlock})
+		}
 		delete(proposedBlocks, string(fingerPrint))
 	}
 }
@@ -223,7 +293,9 @@ func (pc *ProposalCache) KeepProposedBlock(hash []byte, height uint64) []*common
 			if !bytes.Equal(hash, proposedBlock.block.Header.BlockHash) {
 				// remove blocks except this block
 				blocks = append(blocks, proposedBlock.block)
-				delete(proposedBlocks, string(utils.CalcBlockFingerPrint(proposedBlock.block)))
+				fingerPrint := string(utils.CalcBlockFingerPrint(proposedBlock.block))
+				pc.unpersist(map[string]*blockProposal{fingerPrint: proposedBlock})
+				delete(proposedBlocks, fingerPrint)
 			}
 		}
 	}
@@ -238,6 +310,7 @@ func (pc *ProposalCache) DiscardAboveHeight(baseHeight uint64) []*commonpb.Block
 		if height <= baseHeight {
 			continue
 		}
+		pc.unpersist(blks)
 		delete(pc.lastProposedBlock, height)
 		for _, blkInfo := range blks {
 			delBlocks = append(delBlocks, blkInfo.block)
@@ -246,6 +319,21 @@ func (pc *ProposalCache) DiscardAboveHeight(baseHeight uint64) []*commonpb.Block
 	return delBlocks
 }
 
+// unpersist removes the self-proposed blocks of proposals from store in background.
+// It is best effort, the blocks left are removed after committed when restored.
+func (pc *ProposalCache) unpersist(proposals map[string]*blockProposal) {
+	if pc.store == nil {
+		return
+	}
+	blocks := make([]*commonpb.Block, 0, len(proposals))
+	for _, proposal := range proposals {
+		if proposal.isSelfProposed {
+			blocks = append(blocks, proposal.block)
+		}
+	}
+	pc.store.delete(blocks...)
+}
+
 // getHashType return hash type claimed in this chain.
 func (pc *ProposalCache) getHashType() string { //nolint: unused
 	if pc.chainConf == nil || pc.chainConf.ChainConfig() == nil {
@@ -0,0 +1,198 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cache
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	storePb "chainmaker.org/chainmaker/pb-go/v2/store"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// ProposalDBName is the name of the db handle in store for self-proposed blocks
+	ProposalDBName = "proposal"
+
+	proposalKeyPrefix = "proposal_cache/"
+)
+
+// proposalIndexKey is the key of the keys of all persisted proposals
+var proposalIndexKey = []byte(proposalKeyPrefix + "index")
+
+// proposalOp is a pending write of proposalStore, to put the block or to delete it if block is nil
+type proposalOp struct {
+	key   string
+	block *storePb.BlockWithRWSet
+}
+
+// proposalStore persists the self-proposed blocks with their rwsets, so that a proposer
+// can repeat the same block at the same height after restart.
+//
+// The blocks are written by a background goroutine, so the consensus path never waits for the db.
+// The writes queued meanwhile are applied in one batch with one update of the index. A block which is
+// not written yet when the node stops is lost, the proposer proposes a new block at that height as usual.
+type proposalStore struct {
+	db  protocol.DBHandle
+	log protocol.Logger
+
+	mu      sync.Mutex
+	keys    map[string]struct{} // keys of the persisted and pending proposals
+	pending []*proposalOp
+	notifyC chan struct{}
+
+	writeMu sync.Mutex // serializes the writes of the background goroutine and flush
+}
+
+func newProposalStore(db protocol.DBHandle, log protocol.Logger) (*proposalStore, error) {
+	ps := &proposalStore{
+		db:      db,
+		log:     log,
+		keys:    make(map[string]struct{}),
+		notifyC: make(chan struct{}, 1),
+	}
+	data, err := db.Get(proposalIndexKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		var keys []string
+		if err = json.Unmarshal(data, &keys); err != nil {
+			return nil, fmt.Errorf("unmarshal proposal index failed, %s", err)
+		}
+		for _, key := range keys {
+			ps.keys[key] = struct{}{}
+		}
+	}
+	go ps.loop()
+	return ps, nil
+}
+
+func proposalKey(block *commonpb.Block) string {
+	return fmt.Sprintf("%s%d/%s", proposalKeyPrefix, block.Header.BlockHeight,
+		hex.EncodeToString(utils.CalcBlockFingerPrint(block)))
+}
+
+// put queues the block with rwsets and contract events of its txs to persist,
+// the block persisted before is ignored
+func (ps *proposalStore) put(block *commonpb.Block, rwSetMap map[string]*commonpb.TxRWSet,
+	contractEventMap map[string][]*commonpb.ContractEvent) {
+	key := proposalKey(block)
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if _, ok := ps.keys[key]; ok {
+		return
+	}
+	// the additional data is left out, as it is written by consensus while the block is marshaled in background
+	blockWithRWSet := &storePb.BlockWithRWSet{
+		Block: &commonpb.Block{Header: block.Header, Dag: block.Dag, Txs: block.Txs},
+	}
+	for _, tx := range block.Txs {
+		if rwSet, ok := rwSetMap[tx.Payload.TxId]; ok {
+			blockWithRWSet.TxRWSets = append(blockWithRWSet.TxRWSets, rwSet)
+		}
+		blockWithRWSet.ContractEvents = append(blockWithRWSet.ContractEvents, contractEventMap[tx.Payload.TxId]...)
+	}
+	ps.keys[key] = struct{}{}
+	ps.enqueue(&proposalOp{key: key, block: blockWithRWSet})
+}
+
+// delete queues the blocks to remove, the blocks not persisted are ignored
+func (ps *proposalStore) delete(blocks ...*commonpb.Block) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, block := range blocks {
+		key := proposalKey(block)
+		if _, ok := ps.keys[key]; !ok {
+			continue
+		}
+		delete(ps.keys, key)
+		ps.enqueue(&proposalOp{key: key})
+	}
+}
+
+// enqueue adds op to the pending writes and wakes up the background goroutine, ps.mu is held
+func (ps *proposalStore) enqueue(op *proposalOp) {
+	ps.pending = append(ps.pending, op)
+	select {
+	case ps.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+func (ps *proposalStore) loop() {
+	for range ps.notifyC {
+		if err := ps.flush(); err != nil {
+			ps.log.Warnf("persist proposed blocks failed, %s", err)
+		}
+	}
+}
+
+// flush writes the pending writes to db, with the index of the keys at that time
+func (ps *proposalStore) flush() error {
+	ps.writeMu.Lock()
+	defer ps.writeMu.Unlock()
+
+	ps.mu.Lock()
+	pending := ps.pending
+	ps.pending = nil
+	keys := make([]string, 0, len(ps.keys))
+	for key := range ps.keys {
+		keys = append(keys, key)
+	}
+	ps.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	for _, op := range pending {
+		if op.block == nil {
+			if err := ps.db.Delete([]byte(op.key)); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := proto.Marshal(op.block)
+		if err != nil {
+			return err
+		}
+		if err = ps.db.Put([]byte(op.key), data); err != nil {
+			return err
+		}
+	}
+	// the index is written after the blocks, so that it never refers to a missing block
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	return ps.db.Put(proposalIndexKey, data)
+}
+
+// load returns all the persisted blocks, it is called before any write
+func (ps *proposalStore) load() ([]*storePb.BlockWithRWSet, error) {
+	blocks := make([]*storePb.BlockWithRWSet, 0, len(ps.keys))
+	for key := range ps.keys {
+		data, err := ps.db.Get([]byte(key))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		blockWithRWSet := &storePb.BlockWithRWSet{}
+		if err = proto.Unmarshal(data, blockWithRWSet); err != nil {
+			return nil, fmt.Errorf("unmarshal proposal %s failed, %s", key, err)
+		}
+		blocks = append(blocks, blockWithRWSet)
+	}
+	return blocks, nil
+}
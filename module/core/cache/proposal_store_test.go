@@ -0,0 +1,110 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/logger/v2"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// testDB keeps the values of a db handle in memory, it is written by the background goroutine of proposalStore
+type testDB struct {
+	mu  sync.Mutex
+	kvs map[string][]byte
+}
+
+func (db *testDB) has(key string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	_, ok := db.kvs[key]
+	return ok
+}
+
+func (db *testDB) handle(ctrl *gomock.Controller) *mock.MockDBHandle {
+	handle := mock.NewMockDBHandle(ctrl)
+	handle.EXPECT().Get(gomock.Any()).DoAndReturn(func(key []byte) ([]byte, error) {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		return db.kvs[string(key)], nil
+	}).AnyTimes()
+	handle.EXPECT().Put(gomock.Any(), gomock.Any()).DoAndReturn(func(key, value []byte) error {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		db.kvs[string(key)] = value
+		return nil
+	}).AnyTimes()
+	handle.EXPECT().Delete(gomock.Any()).DoAndReturn(func(key []byte) error {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		delete(db.kvs, string(key))
+		return nil
+	}).AnyTimes()
+	return handle
+}
+
+func newProposalTestBlock(height uint64) (*commonpb.Block, map[string]*commonpb.TxRWSet) {
+	block := CreateNewTestBlock(height)
+	block.Txs[0].Payload.TxId = "tx1"
+	rwSetMap := map[string]*commonpb.TxRWSet{
+		"tx1": {TxId: "tx1", TxWrites: []*commonpb.TxWrite{{Key: []byte("k"), Value: []byte("v")}}},
+	}
+	return block, rwSetMap
+}
+
+func TestPersistentProposalCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log := logger.GetLoggerByChain(logger.MODULE_CORE, "Chain1")
+	db := &testDB{kvs: make(map[string][]byte)}
+
+	ledgerCache := NewLedgerCache("Chain1")
+	ledgerCache.SetLastCommittedBlock(CreateNewTestBlock(1))
+	cache, err := NewPersistentProposalCache(nil, ledgerCache, db.handle(ctrl), log)
+	require.NoError(t, err)
+
+	// the self-proposed blocks are persisted in background, the others are not
+	block2, rwSetMap2 := newProposalTestBlock(2)
+	block3, rwSetMap3 := newProposalTestBlock(3)
+	block4, rwSetMap4 := newProposalTestBlock(4)
+	require.NoError(t, cache.SetProposedBlock(block2, rwSetMap2, nil, true))
+	require.NoError(t, cache.SetProposedBlock(block3, rwSetMap3, nil, true))
+	require.NoError(t, cache.SetProposedBlock(block4, rwSetMap4, nil, false))
+	require.Eventually(t, func() bool {
+		return db.has(proposalKey(block2)) && db.has(proposalKey(block3))
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, cache.(*ProposalCache).store.flush())
+	require.False(t, db.has(proposalKey(block4)))
+
+	// after restart, the blocks above the committed height are restored, the committed ones are removed
+	ledgerCache.SetLastCommittedBlock(CreateNewTestBlock(2))
+	cache, err = NewPersistentProposalCache(nil, ledgerCache, db.handle(ctrl), log)
+	require.NoError(t, err)
+	require.Nil(t, cache.GetSelfProposedBlockAt(2))
+	restored := cache.GetSelfProposedBlockAt(3)
+	require.NotNil(t, restored)
+	require.Equal(t, block3.Header.BlockHeight, restored.Header.BlockHeight)
+	_, rwSetMap, _ := cache.GetProposedBlock(block3)
+	require.Equal(t, rwSetMap3["tx1"].TxWrites, rwSetMap["tx1"].TxWrites)
+	require.Nil(t, cache.GetSelfProposedBlockAt(4))
+	require.NoError(t, cache.(*ProposalCache).store.flush())
+	require.False(t, db.has(proposalKey(block2)))
+
+	// the cleared block is removed
+	cache.ClearTheBlock(block3)
+	require.NoError(t, cache.(*ProposalCache).store.flush())
+	require.False(t, db.has(proposalKey(block3)))
+	cache, err = NewPersistentProposalCache(nil, ledgerCache, db.handle(ctrl), log)
+	require.NoError(t, err)
+	require.Nil(t, cache.GetSelfProposedBlockAt(3))
+}
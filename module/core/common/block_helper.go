@@ -16,6 +16,8 @@ import (
 
 	"chainmaker.org/chainmaker-go/core/common/scheduler"
 	"chainmaker.org/chainmaker-go/core/provider/conf"
+	"chainmaker.org/chainmaker-go/core/statetree"
+	"chainmaker.org/chainmaker-go/core/txtimeline"
 	"chainmaker.org/chainmaker-go/subscriber"
 	"chainmaker.org/chainmaker/common/v2/crypto/hash"
 	commonErrors "chainmaker.org/chainmaker/common/v2/errors"
@@ -31,7 +33,10 @@ import (
 )
 
 const (
-	DEFAULTDURATION = 1000 // default proposal duration, millis seconds
+	DEFAULTDURATION    = 1000 // default proposal duration, millis seconds
+	maxRescheduleTimes = 3    // max times to reschedule a block which exceeds the block limit
+
+	blockTimestampSkewWindow = 60 // seconds, the clock skew of blocks older than it is not observed
 )
 
 type BlockBuilderConf struct {
@@ -45,6 +50,7 @@ type BlockBuilderConf struct {
 	ChainConf       protocol.ChainConf // chain config
 	Log             protocol.Logger
 	StoreHelper     conf.StoreHelper
+	StateTree       *statetree.StateTree // authenticated world state
 }
 
 type BlockBuilder struct {
@@ -58,6 +64,7 @@ type BlockBuilder struct {
 	chainConf       protocol.ChainConf // chain config
 	log             protocol.Logger
 	storeHelper     conf.StoreHelper
+	stateTree       *statetree.StateTree // authenticated world state
 }
 
 func NewBlockBuilder(conf *BlockBuilderConf) *BlockBuilder {
@@ -72,6 +79,7 @@ func NewBlockBuilder(conf *BlockBuilderConf) *BlockBuilder {
 		chainConf:       conf.ChainConf,
 		log:             conf.Log,
 		storeHelper:     conf.StoreHelper,
+		stateTree:       conf.StateTree,
 	}
 
 	return creatorBlock
@@ -122,6 +130,12 @@ func (bb *BlockBuilder) GenerateNewBlock(proposingHeight uint64, preHash []byte,
 		return nil, timeLasts, fmt.Errorf("schedule block(%d,%x) error %s",
 			block.Header.BlockHeight, block.Header.BlockHash, err)
 	}
+	// the gas used and results of txs are known after scheduling, reschedule if block limit exceeded
+	block, snapshot, txBatch, txRWSetMap, contractEventMap, err = bb.scheduleWithinLimit(
+		lastBlock, block, snapshot, txBatch, txRWSetMap, contractEventMap, isConfigBlock)
+	if err != nil {
+		return nil, timeLasts, err
+	}
 
 	vmLasts := utils.CurrentTimeMillisSeconds() - vmStartTick
 	timeLasts = append(timeLasts, ssLasts, vmLasts)
@@ -144,6 +158,15 @@ func (bb *BlockBuilder) GenerateNewBlock(proposingHeight uint64, preHash []byte,
 		return nil, timeLasts, fmt.Errorf("finalizeBlock block(%d,%s) error %s",
 			block.Header.BlockHeight, hex.EncodeToString(block.Header.BlockHash), err)
 	}
+	// commit state root into header, and keep the state tree for the blocks proposed on it
+	stateVersion, err := FinalizeStateRoot(bb.stateTree, lastBlock, block, txRWSetMap, bb.chainConf)
+	if err != nil {
+		return nil, timeLasts, fmt.Errorf("finalizeBlock block(%d) state root error %s",
+			block.Header.BlockHeight, err)
+	}
+	if stateVersion != nil {
+		bb.stateTree.AddPending(block, stateVersion)
+	}
 
 	finalizeLasts := utils.CurrentTimeMillisSeconds() - finalizeStartTick
 	timeLasts = append(timeLasts, finalizeLasts)
@@ -165,10 +188,67 @@ func (bb *BlockBuilder) GenerateNewBlock(proposingHeight uint64, preHash []byte,
 		return block, timeLasts, err
 	}
 	bb.proposalCache.SetProposedAt(block.Header.BlockHeight)
+	txtimeline.Of(bb.chainId).RecordTxs(block.Txs, txtimeline.Proposed, block.Header.BlockHeight, "")
 
 	return block, timeLasts, nil
 }
 
+// scheduleWithinLimit checks the scheduled block against the block size and gas limit.
+// If exceeded, the txs out of limit are put back to txpool and the rest are rescheduled on a new snapshot.
+// The returned tx batch is the batch of the last scheduling. On error, it returns a *TxsHandledError
+// with the txs put back to or removed from txpool already.
+func (bb *BlockBuilder) scheduleWithinLimit(lastBlock, block *commonpb.Block, snapshot protocol.Snapshot,
+	txBatch []*commonpb.Transaction, txRWSetMap map[string]*commonpb.TxRWSet,
+	contractEventMap map[string][]*commonpb.ContractEvent, isConfigBlock bool) (*commonpb.Block, protocol.Snapshot,
+	[]*commonpb.Transaction, map[string]*commonpb.TxRWSet, map[string][]*commonpb.ContractEvent, error) {
+	limit := GetBlockLimit(bb.chainConf)
+	var err error
+	var handledTxs []*commonpb.Transaction
+	for i := 0; i < maxRescheduleTimes; i++ {
+		fitTxs, restTxs, oversizeTxs := limit.Split(block.Txs)
+		if len(restTxs) == 0 && len(oversizeTxs) == 0 {
+			return block, snapshot, txBatch, txRWSetMap, contractEventMap, nil
+		}
+		bb.log.Warnf("block(%d) exceeds limit(bytes:%d,gas:%d), keep %d txs, retry %d txs, remove %d txs",
+			block.Header.BlockHeight, limit.MaxBytes, limit.MaxGas, len(fitTxs), len(restTxs), len(oversizeTxs))
+		for _, tx := range txBatch {
+			// txs not scheduled in time should be put back to txpool too
+			if _, ok := txRWSetMap[tx.Payload.TxId]; !ok {
+				restTxs = append(restTxs, tx)
+			}
+		}
+		bb.txPool.RetryAndRemoveTxs(restTxs, oversizeTxs)
+		handledTxs = append(append(handledTxs, restTxs...), oversizeTxs...)
+		txtimeline.Of(bb.chainId).RecordTxs(oversizeTxs, txtimeline.Dropped, block.Header.BlockHeight,
+			"exceeds block limit")
+		txBatch = fitTxs
+		if sqlErr := bb.storeHelper.RollBack(block, snapshot.GetBlockchainStore()); sqlErr != nil {
+			bb.log.Errorf("block [%d] rollback sql failed: %s", block.Header.BlockHeight, sqlErr)
+		}
+
+		// clear the results of the previous execution before rescheduling
+		for _, tx := range fitTxs {
+			tx.Result = nil
+		}
+		block, err = initNewBlock(lastBlock, bb.identity, bb.chainId, bb.chainConf, isConfigBlock)
+		if err != nil {
+			return nil, nil, nil, nil, nil, &TxsHandledError{Err: err, Txs: handledTxs}
+		}
+		snapshot = bb.snapshotManager.NewSnapshot(lastBlock, block)
+		bb.storeHelper.BeginDbTransaction(snapshot.GetBlockchainStore(), block.GetTxKey())
+		txRWSetMap, contractEventMap, err = bb.txScheduler.Schedule(block, fitTxs, snapshot)
+		if err != nil {
+			return nil, nil, nil, nil, nil, &TxsHandledError{Txs: handledTxs,
+				Err: fmt.Errorf("reschedule block(%d) error %s", block.Header.BlockHeight, err)}
+		}
+	}
+	if err = checkBlockLimit(block, limit); err != nil {
+		return nil, nil, nil, nil, nil, &TxsHandledError{Txs: handledTxs,
+			Err: fmt.Errorf("reschedule block(%d) error %s", block.Header.BlockHeight, err)}
+	}
+	return block, snapshot, txBatch, txRWSetMap, contractEventMap, nil
+}
+
 func (bb *BlockBuilder) findLastBlockFromCache(proposingHeight uint64, preHash []byte,
 	currentHeight uint64) *commonpb.Block {
 	var lastBlock *commonpb.Block
@@ -195,6 +275,7 @@ func initNewBlock(
 	if utils.IsConfBlock(lastBlock) {
 		preConfHeight = lastBlock.Header.BlockHeight
 	}
+	timestampRule := GetBlockTimestampRule(chainConf, lastBlock.Header.BlockHeight+1)
 
 	block := &commonpb.Block{
 		Header: &commonpb.BlockHeader{
@@ -207,7 +288,7 @@ func initNewBlock(
 			DagHash:        nil,
 			RwSetRoot:      nil,
 			TxRoot:         nil,
-			BlockTimestamp: utils.CurrentTimeSeconds(),
+			BlockTimestamp: timestampRule.Next(lastBlock, utils.CurrentTimeSeconds()),
 			Proposer:       proposer,
 			ConsensusArgs:  nil,
 			TxCount:        0,
@@ -419,11 +500,6 @@ func CheckBlockDigests(block *commonpb.Block, txHashes [][]byte, hashType string
 		log.Error(err)
 		return err
 	}
-	// verify read write set, check if simulate result is equal with rwset in block header
-	if err := IsRWSetHashValid(block, hashType); err != nil {
-		log.Error(err)
-		return err
-	}
 	return nil
 }
 
@@ -452,6 +528,7 @@ type VerifierBlockConf struct {
 	ProposalCache   protocol.ProposalCache // proposal cache
 	StoreHelper     conf.StoreHelper
 	TxScheduler     protocol.TxScheduler
+	StateTree       *statetree.StateTree // authenticated world state
 }
 
 type VerifierBlock struct {
@@ -466,6 +543,9 @@ type VerifierBlock struct {
 	blockchainStore protocol.BlockchainStore
 	proposalCache   protocol.ProposalCache // proposal cache
 	storeHelper     conf.StoreHelper
+	stateTree       *statetree.StateTree // authenticated world state
+
+	metricBlockTimestampSkew *prometheus.HistogramVec // metric clock skew of block proposers
 }
 
 func NewVerifierBlock(conf *VerifierBlockConf) *VerifierBlock {
@@ -481,6 +561,7 @@ func NewVerifierBlock(conf *VerifierBlockConf) *VerifierBlock {
 		proposalCache:   conf.ProposalCache,
 		storeHelper:     conf.StoreHelper,
 		txScheduler:     conf.TxScheduler,
+		stateTree:       conf.StateTree,
 	}
 	var schedulerFactory scheduler.TxSchedulerFactory
 	verifyBlock.txScheduler = schedulerFactory.NewTxScheduler(
@@ -488,6 +569,11 @@ func NewVerifierBlock(conf *VerifierBlockConf) *VerifierBlock {
 		verifyBlock.chainConf,
 		conf.StoreHelper,
 	)
+	if localconf.ChainMakerConfig.MonitorConfig.Enabled {
+		verifyBlock.metricBlockTimestampSkew = monitor.NewHistogramVec(monitor.SUBSYSTEM_CORE_VERIFIER,
+			"metric_block_timestamp_skew", "block timestamp minus local time in seconds",
+			[]float64{-10, -5, -2, -1, 0, 1, 2, 5, 10, 30}, "chainId", "proposer")
+	}
 	return verifyBlock
 }
 
@@ -531,14 +617,25 @@ func (vb *VerifierBlock) ValidateBlock(
 	sigLasts := utils.CurrentTimeMillisSeconds() - startSigTick
 	timeLasts = append(timeLasts, sigLasts)
 
+	vb.observeBlockTimestampSkew(block)
+
 	err := CheckVacuumBlock(block, vb.chainConf.ChainConfig().Consensus.Type)
 	if err != nil {
 		return nil, nil, timeLasts, err
 	}
+	// verify if txs exceed the block size and gas limit
+	if err = IsBlockLimitValid(block, vb.chainConf); err != nil {
+		return nil, nil, timeLasts, err
+	}
 	// we must new a snapshot for the vacant block,
 	// otherwise the subsequent snapshot can not link to the previous snapshot.
 	snapshot := vb.snapshotManager.NewSnapshot(lastBlock, block)
 	if len(block.Txs) == 0 {
+		if IsStateRootEnabled(vb.chainConf, block.Header.BlockHeight) {
+			if err = vb.verifyStateRoot(lastBlock, block, nil); err != nil {
+				return nil, nil, timeLasts, err
+			}
+		}
 		return nil, nil, timeLasts, nil
 	}
 	// verify if txs are duplicate in this block
@@ -606,21 +703,61 @@ func (vb *VerifierBlock) ValidateBlock(
 	if err != nil {
 		return txRWSetMap, contractEventMap, timeLasts, err
 	}
+	// verify read write set, check if simulate result and state root are equal with rwset in block header
+	if err = vb.verifyStateRoot(lastBlock, block, txRWSetMap); err != nil {
+		return txRWSetMap, contractEventMap, timeLasts, err
+	}
 	rootsLast := utils.CurrentTimeMillisSeconds() - startRootsTick
 	timeLasts = append(timeLasts, rootsLast)
+	txtimeline.Of(block.Header.ChainId).RecordTxs(block.Txs, txtimeline.Verified, block.Header.BlockHeight, "")
 
 	return txRWSetMap, contractEventMap, timeLasts, nil
 }
 
+// observeBlockTimestampSkew, to observe the clock skew of the proposer by the block timestamp,
+// the timestamp rule is checked by CheckPreBlock
+func (vb *VerifierBlock) observeBlockTimestampSkew(block *commonpb.Block) {
+	now := utils.CurrentTimeSeconds()
+	skew := block.Header.BlockTimestamp - now
+	// the blocks older than the window are history being synced, not a clock skew
+	if vb.metricBlockTimestampSkew != nil && skew > -blockTimestampSkewWindow {
+		proposer := block.Header.Proposer.GetOrgId()
+		if member, err := vb.ac.NewMember(block.Header.Proposer); err == nil {
+			proposer = member.GetMemberId()
+		}
+		vb.metricBlockTimestampSkew.WithLabelValues(vb.chainConf.ChainConfig().ChainId, proposer).
+			Observe(float64(skew))
+	}
+}
+
+// verifyStateRoot, to check if rwset root in block header is valid, with the state root if enabled,
+// and keep the state tree of block for the blocks proposed on it
+func (vb *VerifierBlock) verifyStateRoot(lastBlock, block *commonpb.Block,
+	txRWSetMap map[string]*commonpb.TxRWSet) error {
+	stateVersion, err := IsStateRootValid(vb.stateTree, lastBlock, block, txRWSetMap, vb.chainConf)
+	if err != nil {
+		vb.log.Error(err)
+		return err
+	}
+	if stateVersion != nil {
+		vb.stateTree.AddPending(block, stateVersion)
+	}
+	return nil
+}
+
 //nolint: staticcheck
 func CheckPreBlock(block *commonpb.Block, lastBlock *commonpb.Block,
-	err error, lastBlockHash []byte, proposedHeight uint64) error {
+	err error, lastBlockHash []byte, proposedHeight uint64, chainConf protocol.ChainConf) error {
 
 	if err = IsHeightValid(block, proposedHeight); err != nil {
 		return err
 	}
 	// check if this block pre hash is equal with last block hash
-	return IsPreHashValid(block, lastBlockHash)
+	if err = IsPreHashValid(block, lastBlockHash); err != nil {
+		return err
+	}
+	// check if this block timestamp follows the timestamp rule
+	return CheckBlockTimestamp(chainConf, block, lastBlock)
 }
 
 // BlockCommitterImpl implements BlockCommitter interface.
@@ -661,6 +798,7 @@ type BlockCommitterConfig struct {
 	Subscriber      *subscriber.EventSubscriber
 	Verifier        protocol.BlockVerifier
 	StoreHelper     conf.StoreHelper
+	StateTree       *statetree.StateTree // authenticated world state
 }
 
 func NewBlockCommitter(config BlockCommitterConfig, log protocol.Logger) (protocol.BlockCommitter, error) {
@@ -723,6 +861,7 @@ func NewBlockCommitter(config BlockCommitterConfig, log protocol.Logger) (protoc
 		MetricBlockCounter:    blockchain.metricBlockCounter,
 		MetricBlockSize:       blockchain.metricBlockSize,
 		MetricTxCounter:       blockchain.metricTxCounter,
+		StateTree:             config.StateTree,
 	}
 	blockchain.commonCommit = NewCommitBlock(cbConf)
 
@@ -0,0 +1,178 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// BlockGasLimitKey is the key in consensus ext config,
+	// which limits the cumulative gas used by the txs of one block, 0 means no limit
+	BlockGasLimitKey = "block_gas_limit"
+)
+
+// GetConsensusExtConfig returns the value of key in the ext config of consensus config
+func GetConsensusExtConfig(chainConf protocol.ChainConf, key string) (string, bool) {
+	if chainConf == nil || chainConf.ChainConfig() == nil || chainConf.ChainConfig().Consensus == nil {
+		return "", false
+	}
+	for _, kv := range chainConf.ChainConfig().Consensus.ExtConfig {
+		if kv.Key == key {
+			return string(kv.Value), true
+		}
+	}
+	return "", false
+}
+
+// BlockLimit limits the total size and execution cost of the txs in a block, zero means no limit
+type BlockLimit struct {
+	MaxBytes uint64 // max total bytes of txs, with results
+	MaxGas   uint64 // max cumulative gas used by txs
+}
+
+// GetBlockLimit reads the block limit from chain config,
+// the byte limit comes from block.block_size(MB) and the gas limit from consensus ext config
+func GetBlockLimit(chainConf protocol.ChainConf) *BlockLimit {
+	limit := &BlockLimit{}
+	if chainConf == nil || chainConf.ChainConfig() == nil {
+		return limit
+	}
+	if chainConf.ChainConfig().Block != nil {
+		limit.MaxBytes = uint64(chainConf.ChainConfig().Block.BlockSize) * 1024 * 1024
+	}
+	if value, ok := GetConsensusExtConfig(chainConf, BlockGasLimitKey); ok {
+		if gas, err := strconv.ParseUint(value, 10, 64); err == nil {
+			limit.MaxGas = gas
+		}
+	}
+	return limit
+}
+
+// IsUnlimited returns true if neither size nor gas is limited
+func (l *BlockLimit) IsUnlimited() bool {
+	return l.MaxBytes == 0 && l.MaxGas == 0
+}
+
+// TxSize returns the encoded size of tx in bytes
+func TxSize(tx *commonpb.Transaction) uint64 {
+	return uint64(proto.Size(tx))
+}
+
+// TxGasUsed returns the gas used by tx, 0 if tx has not been executed
+func TxGasUsed(tx *commonpb.Transaction) uint64 {
+	if tx.Result == nil || tx.Result.ContractResult == nil {
+		return 0
+	}
+	return tx.Result.ContractResult.GasUsed
+}
+
+// Split splits txs into the longest prefix which fits in the limit, the rest txs,
+// and the txs which exceed the limit alone and can never be packed into a block.
+func (l *BlockLimit) Split(txs []*commonpb.Transaction) (
+	fit []*commonpb.Transaction, rest []*commonpb.Transaction, oversize []*commonpb.Transaction) {
+	if l.IsUnlimited() {
+		return txs, nil, nil
+	}
+	var totalBytes, totalGas uint64
+	fit = make([]*commonpb.Transaction, 0, len(txs))
+	for i, tx := range txs {
+		size, gas := TxSize(tx), TxGasUsed(tx)
+		if l.exceed(size, gas) {
+			oversize = append(oversize, tx)
+			continue
+		}
+		if l.exceed(totalBytes+size, totalGas+gas) {
+			for _, r := range txs[i:] {
+				if l.exceed(TxSize(r), TxGasUsed(r)) {
+					oversize = append(oversize, r)
+				} else {
+					rest = append(rest, r)
+				}
+			}
+			break
+		}
+		totalBytes += size
+		totalGas += gas
+		fit = append(fit, tx)
+	}
+	return fit, rest, oversize
+}
+
+func (l *BlockLimit) exceed(bytes, gas uint64) bool {
+	return (l.MaxBytes > 0 && bytes > l.MaxBytes) || (l.MaxGas > 0 && gas > l.MaxGas)
+}
+
+// IsBlockLimitValid, to check if the txs of block fit in the block size and gas limit,
+// the limit is not checked before the activation height of activation.BlockLimit
+func IsBlockLimitValid(block *commonpb.Block, chainConf protocol.ChainConf) error {
+	if chainConf == nil || !activation.IsActive(chainConf.ChainConfig(), activation.BlockLimit,
+		block.Header.BlockHeight) {
+		return nil
+	}
+	return checkBlockLimit(block, GetBlockLimit(chainConf))
+}
+
+func checkBlockLimit(block *commonpb.Block, limit *BlockLimit) error {
+	if limit.IsUnlimited() {
+		return nil
+	}
+	var totalBytes, totalGas uint64
+	for _, tx := range block.Txs {
+		totalBytes += TxSize(tx)
+		totalGas += TxGasUsed(tx)
+	}
+	if limit.MaxBytes > 0 && totalBytes > limit.MaxBytes {
+		return fmt.Errorf("block size expect <= %d, got %d", limit.MaxBytes, totalBytes)
+	}
+	if limit.MaxGas > 0 && totalGas > limit.MaxGas {
+		return fmt.Errorf("block gas expect <= %d, got %d", limit.MaxGas, totalGas)
+	}
+	return nil
+}
+
+// TxsHandledError is the error of generating a block, after some txs of the batch have been put back to
+// or removed from txpool already
+type TxsHandledError struct {
+	Err error
+	Txs []*commonpb.Transaction // the txs put back to or removed from txpool
+}
+
+func (e *TxsHandledError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TxsHandledError) Unwrap() error {
+	return e.Err
+}
+
+// TxsToRetry returns the txs of batch to put back to txpool after generating a block failed with err,
+// without the txs handled already
+func TxsToRetry(batch []*commonpb.Transaction, err error) []*commonpb.Transaction {
+	var handledErr *TxsHandledError
+	if !errors.As(err, &handledErr) || len(handledErr.Txs) == 0 {
+		return batch
+	}
+	handled := make(map[string]bool, len(handledErr.Txs))
+	for _, tx := range handledErr.Txs {
+		handled[tx.Payload.TxId] = true
+	}
+	txs := make([]*commonpb.Transaction, 0, len(batch))
+	for _, tx := range batch {
+		if !handled[tx.Payload.TxId] {
+			txs = append(txs, tx)
+		}
+	}
+	return txs
+}
@@ -0,0 +1,68 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newLimitTestTx(txId string, gasUsed uint64) *commonpb.Transaction {
+	return &commonpb.Transaction{
+		Payload: &commonpb.Payload{TxId: txId},
+		Result: &commonpb.Result{
+			ContractResult: &commonpb.ContractResult{GasUsed: gasUsed},
+		},
+	}
+}
+
+func TestBlockLimitSplit(t *testing.T) {
+	txs := []*commonpb.Transaction{
+		newLimitTestTx("tx1", 10),
+		newLimitTestTx("tx2", 10),
+		newLimitTestTx("tx3", 100),
+		newLimitTestTx("tx4", 10),
+	}
+
+	limit := &BlockLimit{}
+	fit, rest, oversize := limit.Split(txs)
+	require.Equal(t, txs, fit)
+	require.Nil(t, rest)
+	require.Nil(t, oversize)
+
+	limit = &BlockLimit{MaxGas: 25}
+	fit, rest, oversize = limit.Split(txs)
+	require.Equal(t, txs[:2], fit)
+	require.Equal(t, []*commonpb.Transaction{txs[3]}, rest)
+	require.Equal(t, []*commonpb.Transaction{txs[2]}, oversize)
+
+	limit = &BlockLimit{MaxBytes: TxSize(txs[0])}
+	fit, rest, oversize = limit.Split(txs)
+	require.Equal(t, txs[:1], fit)
+	require.Equal(t, 3, len(rest))
+	require.Equal(t, 0, len(oversize))
+}
+
+func TestTxsToRetry(t *testing.T) {
+	txs := []*commonpb.Transaction{
+		newLimitTestTx("tx1", 10),
+		newLimitTestTx("tx2", 10),
+		newLimitTestTx("tx3", 100),
+	}
+	err := errors.New("schedule error")
+	require.Equal(t, txs, TxsToRetry(txs, err))
+	require.Equal(t, txs, TxsToRetry(txs, &TxsHandledError{Err: err}))
+
+	// the txs put back or removed during rescheduling are not put back again
+	handledErr := fmt.Errorf("generate block: %w", &TxsHandledError{Err: err, Txs: txs[1:]})
+	require.Equal(t, txs[:1], TxsToRetry(txs, handledErr))
+	require.True(t, errors.Is(handledErr, err))
+}
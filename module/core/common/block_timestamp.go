@@ -0,0 +1,102 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	consensusextpb "chainmaker.org/chainmaker-go/pb/consensus"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	consensuspb "chainmaker.org/chainmaker/pb-go/v2/consensus"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+const (
+	// BlockTimestampMonotonicKey is the key in consensus ext config, "true" requires the timestamp
+	// of a block strictly after the timestamp of its parent. As timestamps are in seconds,
+	// it limits the chain to one block per second on average.
+	BlockTimestampMonotonicKey = "block_timestamp_monotonic"
+	// BlockTimestampMaxDriftKey is the key in consensus ext config, the max seconds a block timestamp
+	// can be ahead of the local clock, 0 means no limit
+	BlockTimestampMaxDriftKey = "block_timestamp_max_drift"
+)
+
+// ErrInvalidBlockTimestamp is returned when a block timestamp breaks the timestamp rule
+var ErrInvalidBlockTimestamp = errors.New("invalid block timestamp")
+
+// InvalidBlockTimestampCode is the verify result code of a block rejected by the timestamp rule
+const InvalidBlockTimestampCode = consensuspb.VerifyResult_Code(
+	consensusextpb.VerifyResultCodeExt_INVALID_BLOCK_TIMESTAMP)
+
+// VerifyResultCode returns the verify result code of a block verified with err
+func VerifyResultCode(err error) consensuspb.VerifyResult_Code {
+	switch {
+	case err == nil:
+		return consensuspb.VerifyResult_SUCCESS
+	case errors.Is(err, ErrInvalidBlockTimestamp):
+		return InvalidBlockTimestampCode
+	default:
+		return consensuspb.VerifyResult_FAIL
+	}
+}
+
+// CheckBlockTimestamp, to check the timestamp of block against its parent lastBlock and the local clock,
+// with the block timestamp rule of chain config
+func CheckBlockTimestamp(chainConf protocol.ChainConf, block, lastBlock *commonpb.Block) error {
+	return GetBlockTimestampRule(chainConf, block.Header.BlockHeight).Check(block, lastBlock, utils.CurrentTimeSeconds())
+}
+
+// BlockTimestampRule is the rule of block timestamps, the zero value accepts any timestamp
+type BlockTimestampRule struct {
+	Monotonic bool  // timestamp must be after the parent's
+	MaxDrift  int64 // max seconds ahead of the local clock, 0 means no limit
+}
+
+// GetBlockTimestampRule reads the block timestamp rule of the block at height from consensus ext config,
+// the rule accepts any timestamp before the activation height of activation.BlockTimestampRule
+func GetBlockTimestampRule(chainConf protocol.ChainConf, height uint64) *BlockTimestampRule {
+	rule := &BlockTimestampRule{}
+	if chainConf == nil || !activation.IsActive(chainConf.ChainConfig(), activation.BlockTimestampRule, height) {
+		return rule
+	}
+	if value, ok := GetConsensusExtConfig(chainConf, BlockTimestampMonotonicKey); ok {
+		rule.Monotonic, _ = strconv.ParseBool(value)
+	}
+	if value, ok := GetConsensusExtConfig(chainConf, BlockTimestampMaxDriftKey); ok {
+		if drift, err := strconv.ParseInt(value, 10, 64); err == nil && drift > 0 {
+			rule.MaxDrift = drift
+		}
+	}
+	return rule
+}
+
+// Next returns the timestamp of the block proposed on lastBlock at local time now
+func (r *BlockTimestampRule) Next(lastBlock *commonpb.Block, now int64) int64 {
+	if r.Monotonic && lastBlock != nil && now <= lastBlock.Header.BlockTimestamp {
+		return lastBlock.Header.BlockTimestamp + 1
+	}
+	return now
+}
+
+// Check checks the timestamp of block against its parent lastBlock and the local time now.
+// Returns an error wrapping ErrInvalidBlockTimestamp if the rule is broken.
+func (r *BlockTimestampRule) Check(block, lastBlock *commonpb.Block, now int64) error {
+	timestamp := block.Header.BlockTimestamp
+	if r.Monotonic && lastBlock != nil && timestamp <= lastBlock.Header.BlockTimestamp {
+		return fmt.Errorf("%w, block[%d] timestamp %d expect > parent timestamp %d",
+			ErrInvalidBlockTimestamp, block.Header.BlockHeight, timestamp, lastBlock.Header.BlockTimestamp)
+	}
+	if r.MaxDrift > 0 && timestamp > now+r.MaxDrift {
+		return fmt.Errorf("%w, block[%d] timestamp %d is %ds ahead of local time %d, expect <= %ds",
+			ErrInvalidBlockTimestamp, block.Header.BlockHeight, timestamp, timestamp-now, now, r.MaxDrift)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"testing"
+
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	consensuspb "chainmaker.org/chainmaker/pb-go/v2/consensus"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTimestampTestBlock(height uint64, timestamp int64) *commonpb.Block {
+	return &commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: height, BlockTimestamp: timestamp}}
+}
+
+func TestBlockTimestampRule(t *testing.T) {
+	parent := newTimestampTestBlock(1, 100)
+
+	// the zero rule accepts any timestamp
+	rule := &BlockTimestampRule{}
+	require.NoError(t, rule.Check(newTimestampTestBlock(2, 100), parent, 100))
+	require.NoError(t, rule.Check(newTimestampTestBlock(2, 1000), parent, 100))
+	require.Equal(t, int64(100), rule.Next(parent, 100))
+
+	rule = &BlockTimestampRule{Monotonic: true, MaxDrift: 5}
+	require.NoError(t, rule.Check(newTimestampTestBlock(2, 101), parent, 100))
+	require.NoError(t, rule.Check(newTimestampTestBlock(2, 105), parent, 100))
+	err := rule.Check(newTimestampTestBlock(2, 100), parent, 100)
+	require.True(t, errors.Is(err, ErrInvalidBlockTimestamp))
+	err = rule.Check(newTimestampTestBlock(2, 106), parent, 100)
+	require.True(t, errors.Is(err, ErrInvalidBlockTimestamp))
+
+	// the next timestamp is after the parent even if the local clock is behind
+	require.Equal(t, int64(101), rule.Next(parent, 100))
+	require.Equal(t, int64(101), rule.Next(parent, 99))
+	require.Equal(t, int64(120), rule.Next(parent, 120))
+	require.NoError(t, rule.Check(newTimestampTestBlock(2, rule.Next(parent, 100)), parent, 100))
+}
+
+func TestCheckPreBlockTimestamp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	chainConf := mock.NewMockChainConf(ctrl)
+	chainConf.EXPECT().ChainConfig().Return(&configpb.ChainConfig{
+		Consensus: &configpb.ConsensusConfig{ExtConfig: []*configpb.ConfigKeyValue{
+			{Key: BlockTimestampMonotonicKey, Value: "true"},
+		}},
+	}).AnyTimes()
+
+	parent := newTimestampTestBlock(1, 100)
+	parent.Header.BlockHash = []byte("parent")
+	block := newTimestampTestBlock(2, 101)
+	block.Header.PreBlockHash = parent.Header.BlockHash
+	err := CheckPreBlock(block, parent, nil, parent.Header.BlockHash, 1, chainConf)
+	require.NoError(t, err)
+	require.Equal(t, consensuspb.VerifyResult_SUCCESS, VerifyResultCode(err))
+
+	block.Header.BlockTimestamp = 100
+	err = CheckPreBlock(block, parent, nil, parent.Header.BlockHash, 1, chainConf)
+	require.True(t, errors.Is(err, ErrInvalidBlockTimestamp))
+	require.Equal(t, InvalidBlockTimestampCode, VerifyResultCode(err))
+	require.NotEqual(t, consensuspb.VerifyResult_FAIL, InvalidBlockTimestampCode)
+
+	// other failures are reported as FAIL
+	err = CheckPreBlock(block, parent, nil, []byte("other"), 1, chainConf)
+	require.Error(t, err)
+	require.Equal(t, consensuspb.VerifyResult_FAIL, VerifyResultCode(err))
+}
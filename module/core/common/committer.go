@@ -8,6 +8,8 @@ package common
 import (
 	"fmt"
 
+	"chainmaker.org/chainmaker-go/core/statetree"
+	"chainmaker.org/chainmaker-go/core/txtimeline"
 	"chainmaker.org/chainmaker/chainconf/v2"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
 	"chainmaker.org/chainmaker/localconf/v2"
@@ -29,6 +31,7 @@ type CommitBlock struct {
 	metricBlockCounter    *prometheus.CounterVec   // metric block counter
 	metricTxCounter       *prometheus.CounterVec   // metric transaction counter
 	metricBlockCommitTime *prometheus.HistogramVec // metric block commit time
+	stateTree             *statetree.StateTree     // authenticated world state
 }
 
 type CommitBlockConf struct {
@@ -43,6 +46,7 @@ type CommitBlockConf struct {
 	MetricBlockCounter    *prometheus.CounterVec   // metric block counter
 	MetricTxCounter       *prometheus.CounterVec   // metric transaction counter
 	MetricBlockCommitTime *prometheus.HistogramVec // metric block commit time
+	StateTree             *statetree.StateTree     // authenticated world state
 }
 
 func NewCommitBlock(cbConf *CommitBlockConf) *CommitBlock {
@@ -53,6 +57,7 @@ func NewCommitBlock(cbConf *CommitBlockConf) *CommitBlock {
 		ledgerCache:     cbConf.LedgerCache,
 		chainConf:       cbConf.ChainConf,
 		msgBus:          cbConf.MsgBus,
+		stateTree:       cbConf.StateTree,
 	}
 	if localconf.ChainMakerConfig.MonitorConfig.Enabled {
 		commitBlock.metricBlockSize = cbConf.MetricBlockSize
@@ -81,6 +86,11 @@ func (cb *CommitBlock) CommitBlock(
 		panic(err)
 	}
 	dbLasts = utils.CurrentTimeMillisSeconds() - startDBTick
+	if cb.stateTree != nil && IsStateTreeMaintained(cb.chainConf) {
+		cb.stateTree.Commit(block, rwSet)
+	}
+	recordCommittedTxs(block)
+	pruneEvidence(block, cb.log)
 
 	// clear snapshot
 	startSnapshotTick := utils.CurrentTimeMillisSeconds()
@@ -179,3 +189,15 @@ func rearrangeContractEvent(block *commonpb.Block,
 	}
 	return conEvent
 }
+
+// recordCommittedTxs records the committed event of txs in block, with the result code of failed txs
+func recordCommittedTxs(block *commonpb.Block) {
+	timeline := txtimeline.Of(block.Header.ChainId)
+	for _, tx := range block.Txs {
+		var reason string
+		if tx.Result != nil && tx.Result.Code != commonpb.TxStatusCode_SUCCESS {
+			reason = tx.Result.Code.String()
+		}
+		timeline.Record(tx.Payload.TxId, txtimeline.Committed, block.Header.BlockHeight, reason)
+	}
+}
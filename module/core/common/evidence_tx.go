@@ -0,0 +1,127 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"chainmaker.org/chainmaker-go/consensus/evidence"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+// maxEvidenceTxs is the max number of evidence txs in a block
+const maxEvidenceTxs = 10
+
+// AddEvidenceTxs puts the txs recording the pending evidence of chain before batch, within the block tx capacity.
+// The evidence recorded already is removed from pool, and the txs in batch are not repeated.
+func AddEvidenceTxs(batch []*commonpb.Transaction, height uint64, chainConf protocol.ChainConf,
+	store protocol.BlockchainStore, identity protocol.SigningMember, log protocol.Logger) []*commonpb.Transaction {
+	chainConfig := chainConf.ChainConfig()
+	if !activation.IsActive(chainConfig, activation.TBFTEvidence, height) {
+		return batch
+	}
+	limit := int(chainConfig.Block.BlockTxCapacity) - len(batch)
+	if limit > maxEvidenceTxs {
+		limit = maxEvidenceTxs
+	}
+	if limit <= 0 {
+		return batch
+	}
+	inBatch := make(map[string]struct{}, len(batch))
+	for _, tx := range batch {
+		inBatch[tx.Payload.TxId] = struct{}{}
+	}
+
+	pool := evidence.Of(chainConfig.ChainId)
+	txs := make([]*commonpb.Transaction, 0, limit)
+	for _, e := range pool.Pending() {
+		if len(txs) >= limit {
+			break
+		}
+		txId := e.TxId()
+		if _, ok := inBatch[txId]; ok {
+			continue
+		}
+		exist, err := store.TxExists(txId)
+		if err != nil {
+			log.Warnf("check evidence tx[%s] exists failed, %s", txId, err)
+			continue
+		}
+		if exist {
+			pool.Remove(e)
+			continue
+		}
+		tx, err := newEvidenceTx(e, chainConfig.ChainId, chainConfig.Crypto.Hash, identity)
+		if err != nil {
+			log.Errorf("create evidence tx of %s failed, %s", e, err)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	if len(txs) == 0 {
+		return batch
+	}
+	log.Infof("propose %d evidence txs in block[%d]", len(txs), height)
+	return append(txs, batch...)
+}
+
+// pruneEvidence removes the evidence recorded by the evidence txs of the committed block from pool,
+// whichever node proposed the block
+func pruneEvidence(block *commonpb.Block, log protocol.Logger) {
+	pool := evidence.Of(block.Header.ChainId)
+	for _, tx := range block.Txs {
+		if tx.Payload.ContractName != evidence.ContractName {
+			continue
+		}
+		for _, kv := range tx.Payload.Parameters {
+			if kv.Key != evidence.ParamEvidence {
+				continue
+			}
+			e, err := evidence.Unmarshal(kv.Value)
+			if err != nil {
+				log.Warnf("invalid evidence in tx[%s] of block[%d], %s", tx.Payload.TxId, block.Header.BlockHeight, err)
+				continue
+			}
+			pool.Remove(e)
+		}
+	}
+}
+
+// newEvidenceTx creates the tx recording evidence, signed by identity
+func newEvidenceTx(e *evidence.Evidence, chainId string, hashType string,
+	identity protocol.SigningMember) (*commonpb.Transaction, error) {
+	value, err := e.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	payload := &commonpb.Payload{
+		ChainId:      chainId,
+		TxType:       commonpb.TxType_INVOKE_CONTRACT,
+		TxId:         e.TxId(),
+		Timestamp:    utils.CurrentTimeSeconds(),
+		ContractName: evidence.ContractName,
+		Method:       evidence.MethodRecord,
+		Parameters:   []*commonpb.KeyValuePair{{Key: evidence.ParamEvidence, Value: value}},
+	}
+	payloadBytes, err := payload.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := identity.Sign(hashType, payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	member, err := identity.GetMember()
+	if err != nil {
+		return nil, err
+	}
+	return &commonpb.Transaction{
+		Payload: payload,
+		Sender:  &commonpb.EndorsementEntry{Signer: member, Signature: sig},
+	}, nil
+}
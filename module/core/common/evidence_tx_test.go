@@ -0,0 +1,54 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker-go/consensus/evidence"
+	"chainmaker.org/chainmaker/logger/v2"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	tbftpb "chainmaker.org/chainmaker/pb-go/v2/consensus/tbft"
+	"github.com/stretchr/testify/require"
+)
+
+func newEvidenceTestVote(height uint64, hash string) *tbftpb.Vote {
+	return &tbftpb.Vote{
+		Type:        tbftpb.VoteType_VOTE_PREVOTE,
+		Voter:       "node1",
+		Height:      height,
+		Hash:        []byte(hash),
+		Endorsement: &commonpb.EndorsementEntry{Signature: []byte("sig-" + hash)},
+	}
+}
+
+func TestPruneEvidence(t *testing.T) {
+	log := logger.GetLoggerByChain(logger.MODULE_CORE, "chain_prune_evidence")
+	pool := evidence.Of("chain_prune_evidence")
+	recorded := evidence.New(newEvidenceTestVote(10, "a"), nil, newEvidenceTestVote(10, "b"), nil)
+	pending := evidence.New(newEvidenceTestVote(11, "a"), nil, newEvidenceTestVote(11, "b"), nil)
+	require.True(t, pool.Add(recorded))
+	require.True(t, pool.Add(pending))
+
+	value, err := recorded.Marshal()
+	require.NoError(t, err)
+	block := &commonpb.Block{
+		Header: &commonpb.BlockHeader{ChainId: "chain_prune_evidence", BlockHeight: 12},
+		Txs: []*commonpb.Transaction{
+			{Payload: &commonpb.Payload{TxId: "tx1", ContractName: "contract1"}},
+			{Payload: &commonpb.Payload{
+				TxId:         recorded.TxId(),
+				ContractName: evidence.ContractName,
+				Method:       evidence.MethodRecord,
+				Parameters:   []*commonpb.KeyValuePair{{Key: evidence.ParamEvidence, Value: value}},
+			}},
+		},
+	}
+	// the evidence committed by any proposer is removed from pool
+	pruneEvidence(block, log)
+	require.Equal(t, []*evidence.Evidence{pending}, pool.Pending())
+}
@@ -14,7 +14,9 @@ import (
 	"sync"
 	"time"
 
+	"chainmaker.org/chainmaker-go/consensus/evidence"
 	"chainmaker.org/chainmaker-go/core/provider/conf"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
 	"chainmaker.org/chainmaker/localconf/v2"
 	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
 	"chainmaker.org/chainmaker/protocol/v2"
@@ -289,7 +291,7 @@ func (ts *TxScheduler) executeTx(tx *commonpb.Transaction, snapshot protocol.Sna
 	var txResult *commonpb.Result
 	var err error
 	var specialTxType protocol.ExecOrderTxType
-	if txResult, specialTxType, err = ts.runVM(tx, txSimContext); err != nil {
+	if txResult, specialTxType, err = ts.runVM(tx, txSimContext, block.Header.BlockHeight); err != nil {
 		runVmSuccess = false
 		ts.log.Errorf("failed to run vm for tx id:%s, tx result:%+v, error:%+v",
 			tx.Payload.GetTxId(), txResult, err)
@@ -380,7 +382,7 @@ func (ts *TxScheduler) Halt() {
 	ts.scheduleFinishC <- true
 }
 
-func (ts *TxScheduler) runVM(tx *commonpb.Transaction, txSimContext protocol.TxSimContext) (
+func (ts *TxScheduler) runVM(tx *commonpb.Transaction, txSimContext protocol.TxSimContext, height uint64) (
 	*commonpb.Result, protocol.ExecOrderTxType, error) {
 	var contractName string
 	var method string
@@ -413,6 +415,15 @@ func (ts *TxScheduler) runVM(tx *commonpb.Transaction, txSimContext protocol.TxS
 		)
 	}
 
+	if contractName == evidence.ContractName &&
+		activation.IsActive(ts.chainConf.ChainConfig(), activation.TBFTEvidence, height) {
+		if err = ts.recordEvidence(method, parameters, txSimContext); err != nil {
+			ts.log.Warnf("record evidence of tx[%s] error:%s", payload.TxId, err)
+			return errResult(result, err)
+		}
+		return result, protocol.ExecOrderTxTypeNormal, nil
+	}
+
 	contract, err := txSimContext.GetContractByName(contractName)
 	if err != nil {
 		ts.log.Errorf("Get contract info by name[%s] error:%s", contractName, err)
@@ -437,6 +448,41 @@ func (ts *TxScheduler) runVM(tx *commonpb.Transaction, txSimContext protocol.TxS
 	return result, specialTxType, errors.New(contractResultPayload.Message)
 }
 
+// recordEvidence executes the evidence tx, which records the verified equivocation evidence in state
+func (ts *TxScheduler) recordEvidence(method string, parameters map[string][]byte,
+	txSimContext protocol.TxSimContext) error {
+	if method != evidence.MethodRecord {
+		return fmt.Errorf("unknown method %s of contract %s", method, evidence.ContractName)
+	}
+	e, err := evidence.Unmarshal(parameters[evidence.ParamEvidence])
+	if err != nil {
+		return fmt.Errorf("invalid evidence: %s", err)
+	}
+	if err = e.Validate(); err != nil {
+		return err
+	}
+	ac, err := txSimContext.GetAccessControl()
+	if err != nil {
+		return err
+	}
+	if err = e.VerifySignatures(ac, ts.chainConf.ChainConfig()); err != nil {
+		return err
+	}
+	key := []byte(e.Key())
+	recorded, err := txSimContext.Get(evidence.ContractName, key)
+	if err != nil {
+		return err
+	}
+	if len(recorded) > 0 {
+		return fmt.Errorf("evidence %s is recorded already", e.Key())
+	}
+	value, err := e.Marshal()
+	if err != nil {
+		return err
+	}
+	return txSimContext.Put(evidence.ContractName, key, value)
+}
+
 func errResult(result *commonpb.Result, err error) (*commonpb.Result, protocol.ExecOrderTxType, error) {
 	result.ContractResult.Message = err.Error()
 	result.Code = commonpb.TxStatusCode_INVALID_PARAMETER
@@ -0,0 +1,122 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+
+	"chainmaker.org/chainmaker-go/core/statetree"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+// IsStateTreeMaintained returns true if the state tree should be kept up to date with the committed blocks,
+// which is when the activation height of activation.StateRoot is configured, before or after it.
+func IsStateTreeMaintained(chainConf protocol.ChainConf) bool {
+	if chainConf == nil || chainConf.ChainConfig() == nil {
+		return false
+	}
+	if _, ok := activation.ActivationHeight(chainConf.ChainConfig(), activation.StateRoot); !ok {
+		return false
+	}
+	// the write sets of sql contracts are sql statements, not states
+	return chainConf.ChainConfig().Contract == nil || !chainConf.ChainConfig().Contract.EnableSqlSupport
+}
+
+// IsStateRootEnabled returns true if the state root should be committed in the header of the block
+// at blockHeight, which is from the activation height of activation.StateRoot
+func IsStateRootEnabled(chainConf protocol.ChainConf, blockHeight uint64) bool {
+	return IsStateTreeMaintained(chainConf) &&
+		activation.IsActive(chainConf.ChainConfig(), activation.StateRoot, blockHeight)
+}
+
+// FinalizeStateRoot commits the state root of block into block.Header.RwSetRoot, which has been
+// finalized with the merkle root of tx rwsets, see statetree.CommitmentRoot.
+// Returns nil if state root is not enabled.
+func FinalizeStateRoot(stateTree *statetree.StateTree, lastBlock, block *commonpb.Block,
+	txRWSetMap map[string]*commonpb.TxRWSet, chainConf protocol.ChainConf) (*statetree.Version, error) {
+	if stateTree == nil || !IsStateRootEnabled(chainConf, block.Header.BlockHeight) {
+		return nil, nil
+	}
+	version, err := stateTree.Build(lastBlock, block, txRWSetMap)
+	if err != nil {
+		return nil, fmt.Errorf("build state tree error %s", err)
+	}
+	block.Header.RwSetRoot, err = statetree.CommitmentRoot(chainConf.ChainConfig().Crypto.Hash,
+		block.Header.RwSetRoot, version.Root())
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// IsStateRootValid, to check if the rwset root in block header commits to the rwsets of txs and
+// the state root built with txRWSetMap. Returns nil version if state root is not enabled.
+func IsStateRootValid(stateTree *statetree.StateTree, lastBlock, block *commonpb.Block,
+	txRWSetMap map[string]*commonpb.TxRWSet, chainConf protocol.ChainConf) (*statetree.Version, error) {
+	hashType := chainConf.ChainConfig().Crypto.Hash
+	if stateTree == nil || !IsStateRootEnabled(chainConf, block.Header.BlockHeight) {
+		return nil, IsRWSetHashValid(block, hashType)
+	}
+	rwSetTxRoot, err := utils.CalcRWSetRoot(hashType, block.Txs)
+	if err != nil {
+		return nil, fmt.Errorf("calc rwset error, %s", err)
+	}
+	version, err := stateTree.Build(lastBlock, block, txRWSetMap)
+	if err != nil {
+		return nil, fmt.Errorf("build state tree error %s", err)
+	}
+	rwSetRoot, err := statetree.CommitmentRoot(hashType, rwSetTxRoot, version.Root())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(rwSetRoot, block.Header.RwSetRoot) {
+		return nil, fmt.Errorf("rwset with state root expect %x, got %x (state root %x)",
+			block.Header.RwSetRoot, rwSetRoot, version.Root())
+	}
+	return version, nil
+}
+
+// RestorePendingStateTrees builds the trees of the proposed blocks restored from db after restart,
+// from the block after lastBlock, so that the blocks proposed on them can be built. A restored block
+// is skipped with its children if its parent is not found or its state root mismatches.
+func RestorePendingStateTrees(stateTree *statetree.StateTree, proposalCache protocol.ProposalCache,
+	lastBlock *commonpb.Block, chainConf protocol.ChainConf, log protocol.Logger) {
+	if stateTree == nil || lastBlock == nil {
+		return
+	}
+	parents := []*commonpb.Block{lastBlock}
+	for height := lastBlock.Header.BlockHeight + 1; len(parents) > 0; height++ {
+		var built []*commonpb.Block
+		for _, block := range proposalCache.GetProposedBlocksAt(height) {
+			var parent *commonpb.Block
+			for _, p := range parents {
+				if bytes.Equal(p.Header.BlockHash, block.Header.PreBlockHash) {
+					parent = p
+				}
+			}
+			if parent == nil {
+				continue
+			}
+			_, rwSetMap, _ := proposalCache.GetProposedBlock(block)
+			version, err := IsStateRootValid(stateTree, parent, block, rwSetMap, chainConf)
+			if err != nil {
+				log.Warnf("restore state tree of proposed block[%d](%x) failed, %s",
+					height, block.Header.BlockHash, err)
+				continue
+			}
+			if version != nil {
+				stateTree.AddPending(block, version)
+			}
+			built = append(built, block)
+		}
+		parents = built
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker-go/core/statetree"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	"chainmaker.org/chainmaker/logger/v2"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"chainmaker.org/chainmaker/utils/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// newStateRootTestBlock returns a block on parent with a tx writing key of contract c1
+func newStateRootTestBlock(t *testing.T, parent *commonpb.Block, key string) (
+	*commonpb.Block, map[string]*commonpb.TxRWSet) {
+	txId := "tx" + key
+	block := &commonpb.Block{
+		Header: &commonpb.BlockHeader{
+			BlockHeight:  parent.Header.BlockHeight + 1,
+			PreBlockHash: parent.Header.BlockHash,
+			BlockHash:    []byte("block" + key),
+		},
+		Txs: []*commonpb.Transaction{{
+			Payload: &commonpb.Payload{TxId: txId},
+			Result:  &commonpb.Result{RwSetHash: []byte(txId)},
+		}},
+	}
+	var err error
+	block.Header.RwSetRoot, err = utils.CalcRWSetRoot("SHA256", block.Txs)
+	require.NoError(t, err)
+	rwSetMap := map[string]*commonpb.TxRWSet{txId: {
+		TxId:     txId,
+		TxWrites: []*commonpb.TxWrite{{ContractName: "c1", Key: []byte(key), Value: []byte("v")}},
+	}}
+	return block, rwSetMap
+}
+
+func TestRestorePendingStateTrees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	chainConf := mock.NewMockChainConf(ctrl)
+	chainConf.EXPECT().ChainConfig().Return(&configpb.ChainConfig{
+		Crypto: &configpb.CryptoConfig{Hash: "SHA256"},
+		Consensus: &configpb.ConsensusConfig{ExtConfig: []*configpb.ConfigKeyValue{
+			{Key: activation.KeyPrefix + activation.StateRoot, Value: "0"},
+		}},
+	}).AnyTimes()
+	lastBlock := &commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: 0, BlockHash: []byte("block0")}}
+	store := mock.NewMockBlockchainStore(ctrl)
+	store.EXPECT().GetLastBlock().Return(lastBlock, nil).AnyTimes()
+	store.EXPECT().GetTxRWSetsByHeight(uint64(0)).Return(nil, nil).AnyTimes()
+	kvs := make(map[string][]byte)
+	db := mock.NewMockDBHandle(ctrl)
+	db.EXPECT().Get(gomock.Any()).DoAndReturn(func(key []byte) ([]byte, error) {
+		return kvs[string(key)], nil
+	}).AnyTimes()
+	db.EXPECT().Put(gomock.Any(), gomock.Any()).DoAndReturn(func(key, value []byte) error {
+		kvs[string(key)] = value
+		return nil
+	}).AnyTimes()
+	log := logger.GetLoggerByChain(logger.MODULE_CORE, "chain1")
+	newTree := func() *statetree.StateTree {
+		tree, err := statetree.NewStateTree("SHA256", store, db, log)
+		require.NoError(t, err)
+		require.NoError(t, tree.Load())
+		return tree
+	}
+
+	// two blocks are proposed above the committed block before restart
+	tree := newTree()
+	block1, rwSetMap1 := newStateRootTestBlock(t, lastBlock, "1")
+	version, err := FinalizeStateRoot(tree, lastBlock, block1, rwSetMap1, chainConf)
+	require.NoError(t, err)
+	tree.AddPending(block1, version)
+	block2, rwSetMap2 := newStateRootTestBlock(t, block1, "2")
+	version, err = FinalizeStateRoot(tree, block1, block2, rwSetMap2, chainConf)
+	require.NoError(t, err)
+	tree.AddPending(block2, version)
+	block3, rwSetMap3 := newStateRootTestBlock(t, block2, "3")
+	expected, err := tree.Build(block2, block3, rwSetMap3)
+	require.NoError(t, err)
+
+	// after restart, the proposed blocks are restored in proposal cache, but not their trees
+	tree = newTree()
+	_, err = tree.Build(block2, block3, rwSetMap3)
+	require.Error(t, err)
+
+	proposalCache := mock.NewMockProposalCache(ctrl)
+	proposalCache.EXPECT().GetProposedBlocksAt(gomock.Any()).DoAndReturn(func(height uint64) []*commonpb.Block {
+		switch height {
+		case 1:
+			return []*commonpb.Block{block1}
+		case 2:
+			return []*commonpb.Block{block2}
+		}
+		return nil
+	}).AnyTimes()
+	proposalCache.EXPECT().GetProposedBlock(block1).Return(block1, rwSetMap1, nil).AnyTimes()
+	proposalCache.EXPECT().GetProposedBlock(block2).Return(block2, rwSetMap2, nil).AnyTimes()
+	RestorePendingStateTrees(tree, proposalCache, lastBlock, chainConf, log)
+
+	// the child of the restored blocks is built on their trees
+	version, err = tree.Build(block2, block3, rwSetMap3)
+	require.NoError(t, err)
+	require.Equal(t, expected.Root(), version.Root())
+}
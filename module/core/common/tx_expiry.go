@@ -0,0 +1,94 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	commonextpb "chainmaker.org/chainmaker-go/pb/common"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+)
+
+// ErrTxExpired is returned when a tx is packed after its expiration height or time
+var ErrTxExpired = errors.New("tx expired")
+
+const (
+	// TxExpiryStatusCode is the status code reported for an expired tx
+	TxExpiryStatusCode = commonpb.TxStatusCode(commonextpb.TxStatusCodeExt_TX_EXPIRED)
+
+	// TxExpirationHeightKey is the reserved payload parameter of the expiration height of tx, in decimal.
+	// The tx is valid in the blocks up to the height. It is signed with the other parameters of payload.
+	TxExpirationHeightKey = "__EXPIRATION_HEIGHT__"
+)
+
+// IsTxExpiryEnabled, to check if the expiration height and Payload.ExpirationTime are enforced in the block
+// of blockHeight, which is from the activation height of activation.TxExpiry.
+func IsTxExpiryEnabled(chainConf protocol.ChainConf, blockHeight uint64) bool {
+	return chainConf != nil && activation.IsActive(chainConf.ChainConfig(), activation.TxExpiry, blockHeight)
+}
+
+// TxExpirationHeight returns the expiration height of tx in the parameter TxExpirationHeightKey,
+// 0 if it is not set. Returns an error if it is not a positive decimal.
+func TxExpirationHeight(tx *commonpb.Transaction) (uint64, error) {
+	if tx == nil || tx.Payload == nil {
+		return 0, nil
+	}
+	for _, kv := range tx.Payload.Parameters {
+		if kv.Key != TxExpirationHeightKey {
+			continue
+		}
+		height, err := strconv.ParseUint(string(kv.Value), 10, 64)
+		if err != nil || height == 0 {
+			return 0, fmt.Errorf("invalid %s of tx[%s]: %s", TxExpirationHeightKey, tx.Payload.TxId, kv.Value)
+		}
+		return height, nil
+	}
+	return 0, nil
+}
+
+// IsTxExpired, to check if tx is still valid in the block of blockHeight and blockTimestamp(seconds).
+// The expiration height in TxExpirationHeightKey and Payload.ExpirationTime(seconds) are optional,
+// 0 means no limit. Returns an error wrapping ErrTxExpired if tx expired, or the error of an invalid
+// expiration height.
+func IsTxExpired(tx *commonpb.Transaction, blockHeight uint64, blockTimestamp int64) error {
+	if tx == nil || tx.Payload == nil {
+		return nil
+	}
+	expirationHeight, err := TxExpirationHeight(tx)
+	if err != nil {
+		return err
+	}
+	if expirationHeight > 0 && blockHeight > expirationHeight {
+		return fmt.Errorf("%w (tx:%s), expiration height %d, block height %d",
+			ErrTxExpired, tx.Payload.TxId, expirationHeight, blockHeight)
+	}
+	expirationTime := tx.Payload.ExpirationTime
+	if expirationTime > 0 && blockTimestamp > expirationTime {
+		return fmt.Errorf("%w (tx:%s), expiration time %d, block timestamp %d",
+			ErrTxExpired, tx.Payload.TxId, expirationTime, blockTimestamp)
+	}
+	return nil
+}
+
+// SplitExpiredTxs splits txs into txs valid in the block of blockHeight and blockTimestamp,
+// and txs which expired or have an invalid expiration height, and should be removed from txpool.
+func SplitExpiredTxs(txs []*commonpb.Transaction, blockHeight uint64, blockTimestamp int64) (
+	valid []*commonpb.Transaction, expired []*commonpb.Transaction) {
+	valid = make([]*commonpb.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if IsTxExpired(tx, blockHeight, blockTimestamp) != nil {
+			expired = append(expired, tx)
+			continue
+		}
+		valid = append(valid, tx)
+	}
+	return valid, expired
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newExpiryTestTx(txId string, expirationTime int64) *commonpb.Transaction {
+	return &commonpb.Transaction{Payload: &commonpb.Payload{TxId: txId, ExpirationTime: expirationTime}}
+}
+
+func newExpiryHeightTestTx(txId string, expirationHeight string) *commonpb.Transaction {
+	tx := newExpiryTestTx(txId, 0)
+	tx.Payload.Parameters = []*commonpb.KeyValuePair{{Key: TxExpirationHeightKey, Value: []byte(expirationHeight)}}
+	return tx
+}
+
+func TestIsTxExpired(t *testing.T) {
+	require.NoError(t, IsTxExpired(newExpiryTestTx("tx1", 0), 10, 1000))
+	require.NoError(t, IsTxExpired(newExpiryTestTx("tx2", 1000), 10, 1000))
+
+	err := IsTxExpired(newExpiryTestTx("tx3", 999), 10, 1000)
+	require.True(t, errors.Is(err, ErrTxExpired))
+	require.NotEqual(t, commonpb.TxStatusCode_TIMEOUT, TxExpiryStatusCode)
+
+	// the tx is valid up to the expiration height
+	require.NoError(t, IsTxExpired(newExpiryHeightTestTx("tx4", "10"), 10, 1000))
+	err = IsTxExpired(newExpiryHeightTestTx("tx5", "9"), 10, 1000)
+	require.True(t, errors.Is(err, ErrTxExpired))
+
+	// an invalid expiration height is rejected
+	for _, height := range []string{"0", "-1", "ten"} {
+		err = IsTxExpired(newExpiryHeightTestTx("tx6", height), 10, 1000)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrTxExpired))
+	}
+}
+
+func TestIsTxExpiryEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	chainConf := mock.NewMockChainConf(ctrl)
+	chainConf.EXPECT().ChainConfig().Return(&configpb.ChainConfig{
+		Consensus: &configpb.ConsensusConfig{ExtConfig: []*configpb.ConfigKeyValue{
+			{Key: activation.KeyPrefix + activation.TxExpiry, Value: "100"},
+		}},
+	}).AnyTimes()
+	require.False(t, IsTxExpiryEnabled(nil, 100))
+	require.False(t, IsTxExpiryEnabled(chainConf, 99))
+	require.True(t, IsTxExpiryEnabled(chainConf, 100))
+}
+
+func TestSplitExpiredTxs(t *testing.T) {
+	txs := []*commonpb.Transaction{
+		newExpiryTestTx("tx1", 1000),
+		newExpiryTestTx("tx2", 999),
+		newExpiryTestTx("tx3", 0),
+		newExpiryHeightTestTx("tx4", "9"),
+		newExpiryHeightTestTx("tx5", "10"),
+	}
+	valid, expired := SplitExpiredTxs(txs, 10, 1000)
+	require.Equal(t, []*commonpb.Transaction{txs[0], txs[2], txs[4]}, valid)
+	require.Equal(t, []*commonpb.Transaction{txs[1], txs[3]}, expired)
+}
@@ -42,7 +42,12 @@ type VerifyStat struct {
 func ValidateTx(txsRet map[string]*commonpb.Transaction, tx *commonpb.Transaction, blockHeight uint64,
 	stat *VerifyStat, newAddTxs []*commonpb.Transaction, block *commonpb.Block,
 	consensusType consensuspb.ConsensusType, hashType string, store protocol.BlockchainStore,
-	chainId string, ac protocol.AccessControlProvider) error {
+	chainConf protocol.ChainConf, ac protocol.AccessControlProvider) error {
+	if IsTxExpiryEnabled(chainConf, block.Header.BlockHeight) {
+		if err := IsTxExpired(tx, block.Header.BlockHeight, block.Header.BlockTimestamp); err != nil {
+			return err
+		}
+	}
 	txInPool, existTx := txsRet[tx.Payload.TxId]
 	if existTx {
 		if consensuspb.ConsensusType_HOTSTUFF == consensusType &&
@@ -65,7 +70,8 @@ func ValidateTx(txsRet map[string]*commonpb.Transaction, tx *commonpb.Transactio
 	stat.SigCount++
 	startSigTicker := utils.CurrentTimeMillisSeconds()
 	// if tx in txpool, means tx has already validated. tx noIt in txpool, need to validate.
-	if err = utils.VerifyTxWithoutPayload(tx, chainId, ac); err != nil {
+	// the verified txs are cached, a tx verified on admission or in the block before is not verified again
+	if err = TxSigVerifierOf(chainConf.ChainConfig().ChainId).Verify(tx, chainConf, ac); err != nil {
 		err = fmt.Errorf("acl error (tx:%s), %s", tx.Payload.TxId, err.Error())
 		return err
 	}
@@ -209,6 +215,7 @@ func (vt *VerifierTx) verifierTxs(block *commonpb.Block) (txHashes [][]byte, txN
 	txsRet, txsHeightRet := vt.txPool.GetTxsByTxIds(txIds)
 
 	startTicker := utils.CurrentTimeMillisSeconds()
+	vt.verifySigs(block, txsRet)
 	for i := 0; i < waitCount; i++ {
 		index := i
 		go func() {
@@ -251,6 +258,24 @@ func (vt *VerifierTx) verifierTxs(block *commonpb.Block) (txHashes [][]byte, txN
 	return txHashes, txNewAdd, nil, nil
 }
 
+// verifySigs verifies the signatures of the txs not in txpool concurrently across the block,
+// the results are cached for ValidateTx, which reports the failed ones
+func (vt *VerifierTx) verifySigs(block *commonpb.Block, txsRet map[string]*commonpb.Transaction) {
+	txs := make([]*commonpb.Transaction, 0, len(block.Txs))
+	for _, tx := range block.Txs {
+		if _, ok := txsRet[tx.Payload.TxId]; !ok {
+			txs = append(txs, tx)
+		}
+	}
+	if len(txs) == 0 {
+		return
+	}
+	startTicker := utils.CurrentTimeMillisSeconds()
+	TxSigVerifierOf(vt.chainConf.ChainConfig().ChainId).VerifyTxs(txs, vt.chainConf, vt.ac)
+	vt.log.Debugf("verify signatures of %d txs not in txpool, used %d ms",
+		len(txs), utils.CurrentTimeMillisSeconds()-startTicker)
+}
+
 func (vt *VerifierTx) verifyTx(txs []*commonpb.Transaction, txsRet map[string]*commonpb.Transaction,
 	txsHeightRet map[string]uint64, stat *VerifyStat, block *commonpb.Block) (
 	[][]byte, []*commonpb.Transaction, error) {
@@ -260,7 +285,7 @@ func (vt *VerifierTx) verifyTx(txs []*commonpb.Transaction, txsRet map[string]*c
 		blockHeight := txsHeightRet[tx.Payload.TxId]
 		if err := ValidateTx(txsRet, tx, blockHeight, stat, newAddTxs, block,
 			vt.chainConf.ChainConfig().Consensus.Type, vt.chainConf.ChainConfig().Crypto.Hash, vt.store,
-			vt.chainConf.ChainConfig().ChainId, vt.ac); err != nil {
+			vt.chainConf, vt.ac); err != nil {
 			return nil, nil, err
 		}
 		startOthersTicker := utils.CurrentTimeMillisSeconds()
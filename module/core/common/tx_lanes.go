@@ -0,0 +1,212 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"strconv"
+	"strings"
+
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+const (
+	// SystemLaneRatioKey is the key in consensus ext config, max percent of block capacity for system lane
+	SystemLaneRatioKey = "system_lane_ratio"
+	// PriorityLaneRatioKey is the key in consensus ext config, max percent of block capacity for priority lane
+	PriorityLaneRatioKey = "priority_lane_ratio"
+	// PriorityLaneOrgsKey is the key in consensus ext config, comma separated org ids packed in priority lane
+	PriorityLaneOrgsKey = "priority_lane_orgs"
+	// PriorityLaneRolesKey is the key in consensus ext config, comma separated roles packed in priority lane
+	PriorityLaneRolesKey = "priority_lane_roles"
+	// LaneFetchRoundsKey is the key in consensus ext config, max tx batches fetched from txpool for a block
+	// to find the txs of system and priority lanes. It is 1 by default, which fetches one batch only, as
+	// the txs fetched but not packed are put back to txpool, which churns the pool of a busy chain.
+	LaneFetchRoundsKey = "lane_fetch_rounds"
+
+	defaultLaneFetchRounds = 1
+)
+
+// TxLane is the lane a tx is packed in, the smaller the earlier
+type TxLane int
+
+const (
+	TxLaneSystem   TxLane = iota // system contract txs and chain config txs
+	TxLanePriority               // txs from the configured orgs or roles
+	TxLaneNormal                 // all other txs
+	txLaneCount
+)
+
+// TxLanes classifies txs into lanes, and packs them by the capacity share of each lane
+type TxLanes struct {
+	ratios      [txLaneCount]int // max percent of block capacity for each lane, 100 by default
+	fetchRounds int              // max tx batches fetched from txpool for a block
+	orgs        map[string]struct{}
+	roles       map[protocol.Role]struct{}
+	ac          protocol.AccessControlProvider
+	log         protocol.Logger
+	// classified are the lanes of the txs classified, so a tx is classified once in fetching and packing
+	classified map[*commonpb.Transaction]TxLane
+}
+
+// NewTxLanes creates TxLanes from the consensus ext config in chain config
+func NewTxLanes(chainConf protocol.ChainConf, ac protocol.AccessControlProvider, log protocol.Logger) *TxLanes {
+	lanes := &TxLanes{
+		orgs:  make(map[string]struct{}),
+		roles: make(map[protocol.Role]struct{}),
+		ac:    ac,
+		log:   log,
+	}
+	lanes.ratios[TxLaneSystem] = parseLaneRatio(chainConf, SystemLaneRatioKey)
+	lanes.ratios[TxLanePriority] = parseLaneRatio(chainConf, PriorityLaneRatioKey)
+	lanes.ratios[TxLaneNormal] = 100
+	lanes.fetchRounds = defaultLaneFetchRounds
+	if value, ok := GetConsensusExtConfig(chainConf, LaneFetchRoundsKey); ok {
+		if rounds, err := strconv.Atoi(value); err == nil && rounds > 0 {
+			lanes.fetchRounds = rounds
+		}
+	}
+
+	if value, ok := GetConsensusExtConfig(chainConf, PriorityLaneOrgsKey); ok {
+		for _, org := range strings.Split(value, ",") {
+			if org = strings.TrimSpace(org); org != "" {
+				lanes.orgs[org] = struct{}{}
+			}
+		}
+	}
+	if value, ok := GetConsensusExtConfig(chainConf, PriorityLaneRolesKey); ok {
+		for _, role := range strings.Split(value, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				lanes.roles[protocol.Role(strings.ToUpper(role))] = struct{}{}
+			}
+		}
+	}
+	return lanes
+}
+
+func parseLaneRatio(chainConf protocol.ChainConf, key string) int {
+	value, ok := GetConsensusExtConfig(chainConf, key)
+	if !ok {
+		return 100
+	}
+	ratio, err := strconv.Atoi(value)
+	if err != nil || ratio < 0 || ratio > 100 {
+		return 100
+	}
+	return ratio
+}
+
+// Classify returns the lane of tx
+func (l *TxLanes) Classify(tx *commonpb.Transaction) TxLane {
+	if tx.Payload == nil {
+		return TxLaneNormal
+	}
+	if utils.IsConfigTx(tx) {
+		return TxLaneSystem
+	}
+	if _, ok := syscontract.SystemContract_value[tx.Payload.ContractName]; ok {
+		return TxLaneSystem
+	}
+	if tx.Sender == nil || tx.Sender.Signer == nil {
+		return TxLaneNormal
+	}
+	if _, ok := l.orgs[tx.Sender.Signer.OrgId]; ok {
+		return TxLanePriority
+	}
+	if len(l.roles) > 0 && l.ac != nil {
+		member, err := l.ac.NewMember(tx.Sender.Signer)
+		if err != nil {
+			l.log.Debugf("new member of tx[%s] failed, %s", tx.Payload.TxId, err)
+			return TxLaneNormal
+		}
+		if _, ok := l.roles[member.GetRole()]; ok {
+			return TxLanePriority
+		}
+	}
+	return TxLaneNormal
+}
+
+// laneOf returns the lane of tx, which is classified once
+func (l *TxLanes) laneOf(tx *commonpb.Transaction) TxLane {
+	if lane, ok := l.classified[tx]; ok {
+		return lane
+	}
+	if l.classified == nil {
+		l.classified = make(map[*commonpb.Transaction]TxLane)
+	}
+	lane := l.Classify(tx)
+	l.classified[tx] = lane
+	return lane
+}
+
+// FetchTxBatch fetches the txs to pack for the block at height from txPool. A tx batch of txpool holds the
+// earliest txs up to the block capacity, so the system and priority txs behind them would wait for later
+// blocks. While the batch is full and the system and priority lanes have not got their shares, more batches
+// are fetched, up to the fetch rounds. The txs fetched are packed by Pack, which gives the rest back.
+func (l *TxLanes) FetchTxBatch(txPool protocol.TxPool, height uint64, capacity int) []*commonpb.Transaction {
+	batch := txPool.FetchTxBatch(height)
+	last := batch
+	var laneCount [txLaneCount]int
+	for round := 1; round < l.fetchRounds && capacity > 0 && len(last) >= capacity; round++ {
+		for _, tx := range last {
+			laneCount[l.laneOf(tx)]++
+		}
+		if laneCount[TxLaneSystem] >= capacity*l.ratios[TxLaneSystem]/100 &&
+			laneCount[TxLanePriority] >= capacity*l.ratios[TxLanePriority]/100 {
+			break
+		}
+		last = txPool.FetchTxBatch(height)
+		batch = append(batch, last...)
+	}
+	return batch
+}
+
+// Pack selects at most capacity txs from batch. Lanes are packed by lane order, each up to its share of
+// the capacity, and then the capacity left is given to the other txs by lane order, so that the lower
+// lanes are not starved while the capacity is never wasted. The order of txs in the same lane is kept.
+// Returns the packed txs ordered by lane and the txs which should be put back to txpool.
+func (l *TxLanes) Pack(batch []*commonpb.Transaction, capacity int) (
+	packed []*commonpb.Transaction, rest []*commonpb.Transaction) {
+	var laneTxs [txLaneCount][]*commonpb.Transaction
+	for _, tx := range batch {
+		lane := l.laneOf(tx)
+		laneTxs[lane] = append(laneTxs[lane], tx)
+	}
+
+	// take the share of each lane
+	var taken [txLaneCount]int
+	left := capacity
+	for lane := TxLaneSystem; lane < txLaneCount; lane++ {
+		share := capacity * l.ratios[lane] / 100
+		if share > len(laneTxs[lane]) {
+			share = len(laneTxs[lane])
+		}
+		if share > left {
+			share = left
+		}
+		taken[lane] = share
+		left -= share
+	}
+	// give the unused capacity to the remaining txs by lane order
+	for lane := TxLaneSystem; lane < txLaneCount && left > 0; lane++ {
+		more := len(laneTxs[lane]) - taken[lane]
+		if more > left {
+			more = left
+		}
+		taken[lane] += more
+		left -= more
+	}
+
+	packed = make([]*commonpb.Transaction, 0, capacity-left)
+	for lane := TxLaneSystem; lane < txLaneCount; lane++ {
+		packed = append(packed, laneTxs[lane][:taken[lane]]...)
+		rest = append(rest, laneTxs[lane][taken[lane]:]...)
+	}
+	return packed, rest
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"testing"
+
+	pbac "chainmaker.org/chainmaker/pb-go/v2/accesscontrol"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newLaneTestTx(txId string, contractName string, orgId string) *commonpb.Transaction {
+	return &commonpb.Transaction{
+		Payload: &commonpb.Payload{TxId: txId, ContractName: contractName},
+		Sender:  &commonpb.EndorsementEntry{Signer: &pbac.Member{OrgId: orgId}},
+	}
+}
+
+func TestTxLanesPack(t *testing.T) {
+	lanes := &TxLanes{
+		ratios: [txLaneCount]int{50, 100, 100},
+		orgs:   map[string]struct{}{"org1": {}},
+		roles:  make(map[protocol.Role]struct{}),
+	}
+	sysContract := syscontract.SystemContract_CERT_MANAGE.String()
+	txs := []*commonpb.Transaction{
+		newLaneTestTx("normal1", "userContract", "org2"),
+		newLaneTestTx("prio1", "userContract", "org1"),
+		newLaneTestTx("sys1", sysContract, "org2"),
+		newLaneTestTx("sys2", sysContract, "org2"),
+		newLaneTestTx("sys3", sysContract, "org2"),
+	}
+	require.Equal(t, TxLaneNormal, lanes.Classify(txs[0]))
+	require.Equal(t, TxLanePriority, lanes.Classify(txs[1]))
+	require.Equal(t, TxLaneSystem, lanes.Classify(txs[2]))
+
+	// system lane is limited to 50% of the capacity
+	packed, rest := lanes.Pack(txs, 4)
+	require.Equal(t, []*commonpb.Transaction{txs[2], txs[3], txs[1], txs[0]}, packed)
+	require.Equal(t, []*commonpb.Transaction{txs[4]}, rest)
+
+	// unused capacity is given by lane order
+	packed, rest = lanes.Pack(txs[1:], 4)
+	require.Equal(t, []*commonpb.Transaction{txs[2], txs[3], txs[4], txs[1]}, packed)
+	require.Equal(t, 0, len(rest))
+}
+
+func TestTxLanesFetchTxBatch(t *testing.T) {
+	lanes := &TxLanes{
+		ratios:      [txLaneCount]int{100, 50, 100},
+		fetchRounds: 3,
+		orgs:        map[string]struct{}{"org1": {}},
+		roles:       make(map[protocol.Role]struct{}),
+	}
+	batches := [][]*commonpb.Transaction{
+		{newLaneTestTx("normal1", "userContract", "org2"), newLaneTestTx("normal2", "userContract", "org2")},
+		{newLaneTestTx("normal3", "userContract", "org2"), newLaneTestTx("prio1", "userContract", "org1")},
+		{newLaneTestTx("prio2", "userContract", "org1")},
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	txPool := mock.NewMockTxPool(ctrl)
+	fetched := 0
+	txPool.EXPECT().FetchTxBatch(uint64(10)).DoAndReturn(func(uint64) []*commonpb.Transaction {
+		fetched++
+		return batches[fetched-1]
+	}).AnyTimes()
+
+	// the priority tx in the second batch is fetched, and the system lane is short of its share
+	// until the last batch, which is not full
+	batch := lanes.FetchTxBatch(txPool, 10, 2)
+	require.Len(t, batch, 5)
+	require.Equal(t, 3, fetched)
+	packed, rest := lanes.Pack(batch, 2)
+	require.Equal(t, []*commonpb.Transaction{batches[1][1], batches[2][0]}, packed)
+	require.Len(t, rest, 3)
+
+	// one round only fetches the first batch
+	fetched = 0
+	lanes.fetchRounds = 1
+	require.Equal(t, batches[0], lanes.FetchTxBatch(txPool, 10, 2))
+	require.Equal(t, 1, fetched)
+}
+
+func TestTxLanesClassifyOnce(t *testing.T) {
+	// one batch is fetched by default
+	require.Equal(t, 1, NewTxLanes(nil, nil, nil).fetchRounds)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	member := mock.NewMockMember(ctrl)
+	member.EXPECT().GetRole().Return(protocol.RoleClient).AnyTimes()
+	ac := mock.NewMockAccessControlProvider(ctrl)
+	lanes := &TxLanes{
+		ratios:      [txLaneCount]int{100, 100, 100},
+		fetchRounds: 3,
+		orgs:        make(map[string]struct{}),
+		roles:       map[protocol.Role]struct{}{protocol.RoleAdmin: {}},
+		ac:          ac,
+	}
+	batches := [][]*commonpb.Transaction{
+		{newLaneTestTx("normal1", "userContract", "org2"), newLaneTestTx("normal2", "userContract", "org2")},
+		{newLaneTestTx("normal3", "userContract", "org2"), newLaneTestTx("normal4", "userContract", "org2")},
+		{newLaneTestTx("normal5", "userContract", "org2")},
+	}
+	fetched := 0
+	txPool := mock.NewMockTxPool(ctrl)
+	txPool.EXPECT().FetchTxBatch(uint64(10)).DoAndReturn(func(uint64) []*commonpb.Transaction {
+		fetched++
+		return batches[fetched-1]
+	}).Times(3)
+
+	// the member of each tx is created once in fetching and packing
+	ac.EXPECT().NewMember(gomock.Any()).Return(member, nil).Times(5)
+	batch := lanes.FetchTxBatch(txPool, 10, 2)
+	require.Len(t, batch, 5)
+	packed, rest := lanes.Pack(batch, 2)
+	require.Equal(t, batches[0], packed)
+	require.Len(t, rest, 3)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"bytes"
+
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+)
+
+const (
+	// pendingCheckDepth is the number of heights from the discarded block height,
+	// whose proposed blocks are checked for txs of the discarded block
+	pendingCheckDepth = 3
+)
+
+// SplitDiscardedTxs splits txs of a discarded self-proposed block into txs which should be re-injected into txpool,
+// and txs which should be dropped because they have been committed, are pending in other proposed blocks,
+// or are not from txpool by fromPool. Txs pending in other blocks are dropped from txpool as well, they are
+// committed with those blocks, or re-injected if those blocks are self-proposed and discarded too.
+func SplitDiscardedTxs(discarded *commonpb.Block, proposalCache protocol.ProposalCache,
+	store protocol.BlockchainStore, fromPool func(tx *commonpb.Transaction) bool, log protocol.Logger) (
+	reinject []*commonpb.Transaction, drop []*commonpb.Transaction) {
+	pending := make(map[string]struct{})
+	height := discarded.Header.BlockHeight
+	for h := height; h < height+pendingCheckDepth; h++ {
+		for _, b := range proposalCache.GetProposedBlocksAt(h) {
+			if bytes.Equal(b.Header.BlockHash, discarded.Header.BlockHash) {
+				continue
+			}
+			for _, tx := range b.Txs {
+				pending[tx.Payload.TxId] = struct{}{}
+			}
+		}
+	}
+
+	for _, tx := range discarded.Txs {
+		if fromPool != nil && !fromPool(tx) {
+			drop = append(drop, tx)
+			continue
+		}
+		if _, ok := pending[tx.Payload.TxId]; ok {
+			drop = append(drop, tx)
+			continue
+		}
+		exist, err := store.TxExists(tx.Payload.TxId)
+		if err != nil {
+			// can not decide, keep the former behavior to drop it
+			log.Warnf("check tx[%s] exists failed, %s", tx.Payload.TxId, err)
+			drop = append(drop, tx)
+			continue
+		}
+		if exist {
+			drop = append(drop, tx)
+			continue
+		}
+		reinject = append(reinject, tx)
+	}
+	return reinject, drop
+}
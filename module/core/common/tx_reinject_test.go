@@ -0,0 +1,56 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"chainmaker.org/chainmaker/logger/v2"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newReinjectTestBlock(height uint64, hash string, txIds ...string) *commonpb.Block {
+	block := &commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: height, BlockHash: []byte(hash)}}
+	for _, txId := range txIds {
+		block.Txs = append(block.Txs, &commonpb.Transaction{
+			Payload: &commonpb.Payload{TxId: txId, ContractName: "userContract"},
+		})
+	}
+	return block
+}
+
+func TestSplitDiscardedTxs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	discarded := newReinjectTestBlock(10, "discarded",
+		"committed", "pending", "pendingNext", "unknown", "new", "evidence")
+	discarded.Txs[5].Payload.ContractName = "evidenceContract"
+	proposalCache := mock.NewMockProposalCache(ctrl)
+	proposalCache.EXPECT().GetProposedBlocksAt(uint64(10)).Return([]*commonpb.Block{
+		discarded, newReinjectTestBlock(10, "competing", "pending"),
+	}).AnyTimes()
+	proposalCache.EXPECT().GetProposedBlocksAt(uint64(11)).Return([]*commonpb.Block{
+		newReinjectTestBlock(11, "next", "pendingNext"),
+	}).AnyTimes()
+	proposalCache.EXPECT().GetProposedBlocksAt(gomock.Any()).Return(nil).AnyTimes()
+	store := mock.NewMockBlockchainStore(ctrl)
+	store.EXPECT().TxExists("committed").Return(true, nil).AnyTimes()
+	store.EXPECT().TxExists("unknown").Return(false, errors.New("store error")).AnyTimes()
+	store.EXPECT().TxExists(gomock.Any()).Return(false, nil).AnyTimes()
+
+	reinject, drop := SplitDiscardedTxs(discarded, proposalCache, store, func(tx *commonpb.Transaction) bool {
+		return tx.Payload.ContractName != "evidenceContract"
+	}, logger.GetLoggerByChain(logger.MODULE_CORE, "chain1"))
+	require.Equal(t, []*commonpb.Transaction{discarded.Txs[4]}, reinject)
+	require.Equal(t, []*commonpb.Transaction{discarded.Txs[0], discarded.Txs[1], discarded.Txs[2],
+		discarded.Txs[3], discarded.Txs[5]}, drop)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"runtime"
+	"sync"
+
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+const defaultSigCacheSize = 100000 // max txs cached by TxSigVerifier
+
+var txSigVerifiers sync.Map // chain id => *TxSigVerifier
+
+// verifyTxSignatures verifies the signatures of tx through access control, replaced in tests
+var verifyTxSignatures = utils.VerifyTxWithoutPayload
+
+// TxSigVerifierOf returns the tx signature verifier of chain, which is shared by
+// the tx admission and the block verifier, so a tx is verified only once.
+func TxSigVerifierOf(chainId string) *TxSigVerifier {
+	if v, ok := txSigVerifiers.Load(chainId); ok {
+		return v.(*TxSigVerifier)
+	}
+	v, _ := txSigVerifiers.LoadOrStore(chainId, NewTxSigVerifier(defaultSigCacheSize))
+	return v.(*TxSigVerifier)
+}
+
+// TxSigVerifier verifies the sender and endorsement signatures of txs concurrently,
+// and caches the verified txs by tx request hash. The cache is bound to the sequence of
+// chain config, as a config update may revoke the members, and is discarded when a block
+// manages the certs or public keys, which revokes or freezes the members without a config update.
+// So the cached results are always the ones of the committed chain, and not of the history of node.
+// The signatures are verified one by one through access control, which has no batch api.
+type TxSigVerifier struct {
+	mu       sync.Mutex
+	size     int
+	sequence uint64              // chain config sequence of the cached results
+	version  uint64              // member version of the cached results, bumped by member management
+	verified map[string]struct{} // tx request hash => verified
+	keys     []string            // ring of keys in insertion order, for eviction
+	next     int
+}
+
+// NewTxSigVerifier creates a TxSigVerifier which caches at most size txs
+func NewTxSigVerifier(size int) *TxSigVerifier {
+	return &TxSigVerifier{
+		size:     size,
+		verified: make(map[string]struct{}, size),
+		keys:     make([]string, size),
+	}
+}
+
+// Verify verifies the signatures of tx, unless it has been verified under the same chain config
+func (v *TxSigVerifier) Verify(tx *commonpb.Transaction, chainConf protocol.ChainConf,
+	ac protocol.AccessControlProvider) error {
+	config := chainConf.ChainConfig()
+	key, err := utils.CalcTxRequestHash(config.Crypto.Hash, tx)
+	if err != nil {
+		return err
+	}
+	if v.isVerified(string(key), config.Sequence) {
+		return nil
+	}
+	// the result verified before a member management is not cached
+	version := v.memberVersion()
+	if err = verifyTxSignatures(tx, config.ChainId, ac); err != nil {
+		return err
+	}
+	v.add(string(key), config.Sequence, version)
+	return nil
+}
+
+// ContractNames returns the member management contracts, implements protocol.VmWatcher
+func (v *TxSigVerifier) ContractNames() []string {
+	return []string{syscontract.SystemContract_CERT_MANAGE.String(), syscontract.SystemContract_PUBKEY_MANAGE.String()}
+}
+
+// Callback discards the cached results after a block managed the members, implements protocol.VmWatcher.
+// It must be watched after access control, so the members are updated before the results are discarded.
+func (v *TxSigVerifier) Callback(_ string, _ []byte) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.version++
+	v.reset()
+	return nil
+}
+
+// VerifyTxs verifies the signatures of txs concurrently, returns the error of each tx in order
+func (v *TxSigVerifier) VerifyTxs(txs []*commonpb.Transaction, chainConf protocol.ChainConf,
+	ac protocol.AccessControlProvider) []error {
+	errs := make([]error, len(txs))
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	indexC := make(chan int, len(txs))
+	for i := range txs {
+		indexC <- i
+	}
+	close(indexC)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexC {
+				errs[i] = v.Verify(txs[i], chainConf, ac)
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func (v *TxSigVerifier) isVerified(key string, sequence uint64) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if sequence != v.sequence {
+		return false
+	}
+	_, ok := v.verified[key]
+	return ok
+}
+
+func (v *TxSigVerifier) memberVersion() uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.version
+}
+
+func (v *TxSigVerifier) add(key string, sequence, version uint64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.size <= 0 || version != v.version {
+		return
+	}
+	if sequence != v.sequence {
+		// chain config changed, the results verified before are discarded
+		v.sequence = sequence
+		v.reset()
+	}
+	if _, ok := v.verified[key]; ok {
+		return
+	}
+	delete(v.verified, v.keys[v.next])
+	v.keys[v.next] = key
+	v.verified[key] = struct{}{}
+	v.next = (v.next + 1) % v.size
+}
+
+func (v *TxSigVerifier) reset() {
+	v.verified = make(map[string]struct{}, v.size)
+	v.keys = make([]string, v.size)
+	v.next = 0
+}
@@ -0,0 +1,89 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"errors"
+	"testing"
+
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxSigVerifierCache(t *testing.T) {
+	v := NewTxSigVerifier(2)
+	v.add("tx1", 1, 0)
+	v.add("tx2", 1, 0)
+	require.True(t, v.isVerified("tx1", 1))
+	require.True(t, v.isVerified("tx2", 1))
+
+	// the oldest is evicted
+	v.add("tx3", 1, 0)
+	require.False(t, v.isVerified("tx1", 1))
+	require.True(t, v.isVerified("tx2", 1))
+	require.True(t, v.isVerified("tx3", 1))
+	v.add("tx3", 1, 0)
+	require.True(t, v.isVerified("tx2", 1))
+
+	// results are discarded after chain config changed
+	require.False(t, v.isVerified("tx3", 2))
+	v.add("tx4", 2, 0)
+	require.False(t, v.isVerified("tx3", 2))
+	require.True(t, v.isVerified("tx4", 2))
+
+	// results are discarded after the members changed, and the ones verified before are not cached
+	require.NoError(t, v.Callback(syscontract.SystemContract_CERT_MANAGE.String(), nil))
+	require.False(t, v.isVerified("tx4", 2))
+	v.add("tx4", 2, 0)
+	require.False(t, v.isVerified("tx4", 2))
+	v.add("tx4", 2, 1)
+	require.True(t, v.isVerified("tx4", 2))
+
+	require.Same(t, TxSigVerifierOf("chain1"), TxSigVerifierOf("chain1"))
+	require.NotSame(t, TxSigVerifierOf("chain1"), TxSigVerifierOf("chain2"))
+}
+
+func TestTxSigVerifierRevokedSigner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	chainConf := mock.NewMockChainConf(ctrl)
+	chainConf.EXPECT().ChainConfig().Return(&configpb.ChainConfig{
+		ChainId:  "chain1",
+		Sequence: 1,
+		Crypto:   &configpb.CryptoConfig{Hash: "SHA256"},
+	}).AnyTimes()
+
+	revoked := false
+	verified := 0
+	defer func(f func(*commonpb.Transaction, string, protocol.AccessControlProvider) error) {
+		verifyTxSignatures = f
+	}(verifyTxSignatures)
+	verifyTxSignatures = func(*commonpb.Transaction, string, protocol.AccessControlProvider) error {
+		verified++
+		if revoked {
+			return errors.New("cert revoked")
+		}
+		return nil
+	}
+
+	tx := &commonpb.Transaction{Payload: &commonpb.Payload{ChainId: "chain1", TxId: "tx1"}}
+	v := NewTxSigVerifier(10)
+	require.NoError(t, v.Verify(tx, chainConf, nil))
+	require.NoError(t, v.Verify(tx, chainConf, nil))
+	require.Equal(t, 1, verified)
+
+	// the signer is revoked by a cert management block, which does not change the chain config sequence
+	revoked = true
+	require.NoError(t, v.Callback(syscontract.SystemContract_CERT_MANAGE.String(), nil))
+	require.Error(t, v.Verify(tx, chainConf, nil))
+	require.Equal(t, 2, verified)
+}
@@ -4,7 +4,9 @@ go 1.15
 
 require (
 	chainmaker.org/chainmaker-go/consensus v0.0.0
+	chainmaker.org/chainmaker-go/pb v0.0.0
 	chainmaker.org/chainmaker-go/subscriber v0.0.0
+	chainmaker.org/chainmaker-go/upgrade v0.0.0
 	chainmaker.org/chainmaker/chainconf/v2 v2.1.1
 	chainmaker.org/chainmaker/common/v2 v2.1.0
 	chainmaker.org/chainmaker/localconf/v2 v2.1.0
@@ -16,6 +18,7 @@ require (
 	chainmaker.org/chainmaker/vm/v2 v2.1.1
 	github.com/ethereum/go-ethereum v1.10.3 // indirect
 	github.com/gogo/protobuf v1.3.2
+	github.com/golang/mock v1.6.0
 	github.com/panjf2000/ants/v2 v2.4.3
 	github.com/prometheus/client_golang v1.11.0
 	github.com/stretchr/testify v1.7.0
@@ -25,5 +28,7 @@ replace (
 	chainmaker.org/chainmaker-go/accesscontrol => ../accesscontrol
 	chainmaker.org/chainmaker-go/consensus => ../consensus
 	chainmaker.org/chainmaker-go/consensus/dpos => ./../consensus/dpos
+	chainmaker.org/chainmaker-go/pb => ../pb
 	chainmaker.org/chainmaker-go/subscriber => ../subscriber
+	chainmaker.org/chainmaker-go/upgrade => ../upgrade
 )
@@ -11,8 +11,11 @@ import (
 	"sync"
 	"time"
 
+	"chainmaker.org/chainmaker-go/consensus/evidence"
 	"chainmaker.org/chainmaker-go/core/common"
 	"chainmaker.org/chainmaker-go/core/provider/conf"
+	"chainmaker.org/chainmaker-go/core/statetree"
+	"chainmaker.org/chainmaker-go/core/txtimeline"
 	"chainmaker.org/chainmaker/common/v2/monitor"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
 	"chainmaker.org/chainmaker/localconf/v2"
@@ -57,8 +60,9 @@ type BlockProposerImpl struct {
 	log            protocol.Logger
 	finishProposeC chan bool // channel to receive signal to yield propose block
 
-	metricBlockPackageTime *prometheus.HistogramVec
-	proposer               *pbac.Member
+	metricBlockPackageTime   *prometheus.HistogramVec
+	metricDiscardedTxCounter *prometheus.CounterVec // txs of discarded self proposed blocks
+	proposer                 *pbac.Member
 
 	blockBuilder *common.BlockBuilder
 	storeHelper  conf.StoreHelper
@@ -77,6 +81,7 @@ type BlockProposerConfig struct {
 	AC              protocol.AccessControlProvider
 	BlockchainStore protocol.BlockchainStore
 	StoreHelper     conf.StoreHelper
+	StateTree       *statetree.StateTree
 }
 
 const (
@@ -127,6 +132,12 @@ func NewBlockProposer(config BlockProposerConfig, log protocol.Logger) (protocol
 			[]float64{0.005, 0.01, 0.015, 0.05, 0.1, 1, 10},
 			"chainId",
 		)
+		blockProposerImpl.metricDiscardedTxCounter = monitor.NewCounterVec(
+			monitor.SUBSYSTEM_CORE_PROPOSER,
+			"metric_discarded_tx_counter",
+			"discarded self proposed block txs counter, re-injected or dropped",
+			"chainId", "result",
+		)
 	}
 
 	bbConf := &common.BlockBuilderConf{
@@ -140,6 +151,7 @@ func NewBlockProposer(config BlockProposerConfig, log protocol.Logger) (protocol
 		ChainConf:       blockProposerImpl.chainConf,
 		Log:             blockProposerImpl.log,
 		StoreHelper:     blockProposerImpl.storeHelper,
+		StateTree:       config.StateTree,
 	}
 
 	blockProposerImpl.blockBuilder = common.NewBlockBuilder(bbConf)
@@ -258,24 +270,47 @@ func (bp *BlockProposerImpl) proposing(height uint64, preHash []byte) *commonpb.
 			return nil
 		}
 		bp.proposalCache.ClearTheBlock(selfProposedBlock)
-		// Note: It is not possible to re-add the transactions in the deleted block to txpool; because some transactions may
-		// be included in other blocks to be confirmed, and it is impossible to quickly exclude these pending transactions
-		// that have been entered into the block. Comprehensive considerations, directly discard this block is the optimal
-		// choice. This processing method may only cause partial transaction loss at the current node, but it can be solved
-		// by rebroadcasting on the client side.
-		bp.txPool.RetryAndRemoveTxs(nil, selfProposedBlock.Txs)
+		// Txs in the discarded block may be committed in the competing block, or included in other blocks to be
+		// confirmed. Only the txs neither committed nor pending are re-injected into txpool, others are dropped.
+		// evidence txs are not from txpool, they are proposed again from the evidence pool
+		reinjectTxs, dropTxs := common.SplitDiscardedTxs(selfProposedBlock, bp.proposalCache, bp.blockchainStore,
+			func(tx *commonpb.Transaction) bool {
+				return tx.Payload.ContractName != evidence.ContractName
+			}, bp.log)
+		bp.txPool.RetryAndRemoveTxs(reinjectTxs, dropTxs)
+		txtimeline.Of(bp.chainId).RecordTxs(dropTxs, txtimeline.Dropped, height, "in discarded self proposed block")
+		bp.log.Infof("discard self proposed block [%d](txs:%d), re-inject %d txs, drop %d txs",
+			height, len(selfProposedBlock.Txs), len(reinjectTxs), len(dropTxs))
+		if localconf.ChainMakerConfig.MonitorConfig.Enabled {
+			bp.metricDiscardedTxCounter.WithLabelValues(bp.chainId, "reinjected").Add(float64(len(reinjectTxs)))
+			bp.metricDiscardedTxCounter.WithLabelValues(bp.chainId, "dropped").Add(float64(len(dropTxs)))
+		}
 
 	}
 
 	// retrieve tx batch from tx pool
 	startFetchTick := utils.CurrentTimeMillisSeconds()
-	fetchBatch := bp.txPool.FetchTxBatch(height)
+	txCapacity := int(bp.chainConf.ChainConfig().Block.BlockTxCapacity)
+	lanes := common.NewTxLanes(bp.chainConf, bp.ac, bp.log)
+	fetchBatch := lanes.FetchTxBatch(bp.txPool, height, txCapacity)
 	fetchLasts := utils.CurrentTimeMillisSeconds() - startFetchTick
 	bp.log.Debugf("begin proposing block[%d], fetch tx num[%d]", height, len(fetchBatch))
 
 	startDupTick := utils.CurrentTimeMillisSeconds()
 	checkedBatch := bp.txDuplicateCheck(fetchBatch)
 	dupLasts := utils.CurrentTimeMillisSeconds() - startDupTick
+
+	// remove expired txs from txpool, the block timestamp is taken later than now,
+	// so txs expiring within one second are removed as well.
+	if common.IsTxExpiryEnabled(bp.chainConf, height) {
+		var txExpired []*commonpb.Transaction
+		checkedBatch, txExpired = common.SplitExpiredTxs(checkedBatch, height, utils.CurrentTimeSeconds()+1)
+		if len(txExpired) > 0 {
+			bp.txPool.RetryAndRemoveTxs(nil, txExpired)
+			txtimeline.Of(bp.chainId).RecordTxs(txExpired, txtimeline.Dropped, height, "expired")
+			bp.log.Infof("remove %d expired txs from txpool, block[%d]", len(txExpired), height)
+		}
+	}
 	if !utils.CanProposeEmptyBlock(bp.chainConf.ChainConfig().Consensus.Type) && len(checkedBatch) == 0 {
 		// can not propose empty block and tx batch is empty, then yield proposing.
 		bp.log.Debugf("no txs in tx pool, proposing block stoped")
@@ -283,15 +318,25 @@ func (bp *BlockProposerImpl) proposing(height uint64, preHash []byte) *commonpb.
 		return nil
 	}
 
-	txCapacity := int(bp.chainConf.ChainConfig().Block.BlockTxCapacity)
-	if len(checkedBatch) > txCapacity {
-		// check if checkedBatch > txCapacity, if so, strict block tx count according to  config,
-		// and put other txs back to txpool.
-		txRetry := checkedBatch[txCapacity:]
-		checkedBatch = checkedBatch[:txCapacity]
-		bp.txPool.RetryAndRemoveTxs(txRetry, nil)
-		bp.log.Warnf("txbatch oversize expect <= %d, got %d", txCapacity, len(checkedBatch))
+	// pack txs by priority lanes, strict block tx count according to config,
+	// and put other txs back to txpool.
+	checkedBatch, laneRetry := lanes.Pack(checkedBatch, txCapacity)
+	if len(laneRetry) > 0 {
+		bp.txPool.RetryAndRemoveTxs(laneRetry, nil)
+		bp.log.Warnf("txbatch oversize expect <= %d, got %d", txCapacity, len(checkedBatch)+len(laneRetry))
+	}
+
+	// check if checkedBatch exceeds block size limit, if so, put txs out of limit back to txpool,
+	// and remove txs which can never be packed into a block. Gas limit is checked after scheduling.
+	fitBatch, txRetry, txOversize := common.GetBlockLimit(bp.chainConf).Split(checkedBatch)
+	if len(txRetry) > 0 || len(txOversize) > 0 {
+		checkedBatch = fitBatch
+		bp.txPool.RetryAndRemoveTxs(txRetry, txOversize)
+		txtimeline.Of(bp.chainId).RecordTxs(txOversize, txtimeline.Dropped, height, "exceeds block limit")
+		bp.log.Warnf("txbatch exceeds block limit, keep %d, retry %d, remove %d",
+			len(fitBatch), len(txRetry), len(txOversize))
 	}
+	checkedBatch = common.AddEvidenceTxs(checkedBatch, height, bp.chainConf, bp.blockchainStore, bp.identity, bp.log)
 
 	block, timeLasts, err := bp.generateNewBlock(height, preHash, checkedBatch)
 	if err != nil {
@@ -300,7 +345,7 @@ func (bp *BlockProposerImpl) proposing(height uint64, preHash []byte) *commonpb.
 		if sqlErr := bp.storeHelper.RollBack(block, bp.blockchainStore); sqlErr != nil {
 			bp.log.Errorf("block [%d] rollback sql failed: %s", block.Header.BlockHeight, sqlErr)
 		}
-		bp.txPool.RetryAndRemoveTxs(checkedBatch, nil) // put txs back to txpool
+		bp.txPool.RetryAndRemoveTxs(common.TxsToRetry(checkedBatch, err), nil) // put txs back to txpool
 		return nil
 	}
 	_, txsRwSet, _ := bp.proposalCache.GetProposedBlock(block)
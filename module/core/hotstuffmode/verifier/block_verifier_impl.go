@@ -14,6 +14,7 @@ import (
 	"chainmaker.org/chainmaker-go/consensus"
 	"chainmaker.org/chainmaker-go/core/common"
 	"chainmaker.org/chainmaker-go/core/provider/conf"
+	"chainmaker.org/chainmaker-go/core/statetree"
 	commonErrors "chainmaker.org/chainmaker/common/v2/errors"
 	"chainmaker.org/chainmaker/common/v2/monitor"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
@@ -62,6 +63,7 @@ type BlockVerifierConfig struct {
 	TxPool          protocol.TxPool
 	VmMgr           protocol.VmManager
 	StoreHelper     conf.StoreHelper
+	StateTree       *statetree.StateTree
 }
 
 func NewBlockVerifier(config BlockVerifierConfig, log protocol.Logger) (protocol.BlockVerifier, error) {
@@ -95,6 +97,7 @@ func NewBlockVerifier(config BlockVerifierConfig, log protocol.Logger) (protocol
 		VmMgr:           config.VmMgr,
 		StoreHelper:     config.StoreHelper,
 		TxScheduler:     config.TxScheduler,
+		StateTree:       config.StateTree,
 	}
 	v.verifierBlock = common.NewVerifierBlock(conf)
 
@@ -123,7 +126,6 @@ func (v *BlockVerifierImpl) VerifyBlock(block *commonpb.Block, mode protocol.Ver
 	}
 	defer v.reentrantLocks.Unlock(string(block.Header.BlockHash))
 
-	var isValid bool
 	var contractEventMap map[string][]*commonpb.ContractEvent
 	// to check if the block has verified before
 	b, txRwSet, eventMap := v.proposalCache.GetProposedBlock(block)
@@ -136,10 +138,9 @@ func (v *BlockVerifierImpl) VerifyBlock(block *commonpb.Block, mode protocol.Ver
 			elapsed := utils.CurrentTimeMillisSeconds() - startTick
 			// the block has verified before
 			v.log.Infof("verify success repeat [%d](%x), total: %d", block.Header.BlockHeight, block.Header.BlockHash, elapsed)
-			isValid = true
 			if protocol.CONSENSUS_VERIFY == mode {
 				// consensus mode, publish verify result to message bus
-				v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(block, isValid, txRwSet))
+				v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(block, nil, txRwSet))
 			}
 			lastBlock, _ := v.proposalCache.GetProposedBlockByHashAndHeight(
 				block.Header.PreBlockHash, block.Header.BlockHeight-1)
@@ -184,7 +185,7 @@ func (v *BlockVerifierImpl) VerifyBlock(block *commonpb.Block, mode protocol.Ver
 		v.log.Warnf("verify failed [%d](%x),preBlockHash:%x, %s",
 			newBlock.Header.BlockHeight, newBlock.Header.BlockHash, newBlock.Header.PreBlockHash, err.Error())
 		if protocol.CONSENSUS_VERIFY == mode {
-			v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(newBlock, isValid, txRWSetMap))
+			v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(newBlock, err, txRWSetMap))
 		}
 
 		// rollback sql
@@ -216,9 +217,8 @@ func (v *BlockVerifierImpl) VerifyBlock(block *commonpb.Block, mode protocol.Ver
 	// mark transactions in block as pending status in txpool
 	v.txPool.AddTxsToPendingCache(newBlock.Txs, newBlock.Header.BlockHeight)
 
-	isValid = true
 	if protocol.CONSENSUS_VERIFY == mode {
-		v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(newBlock, isValid, txRWSetMap))
+		v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(newBlock, nil, txRWSetMap))
 	}
 	elapsed := utils.CurrentTimeMillisSeconds() - startTick
 	v.log.Infof("verify success [%d,%x](%v,pool: %d,consensusCheckUsed: %d, total: %d)", newBlock.Header.BlockHeight,
@@ -258,6 +258,7 @@ func (v *BlockVerifierImpl) validateBlock(block, lastBlock *commonpb.Block) (map
 func (v *BlockVerifierImpl) checkPreBlock_HOTSTUFF(block *commonpb.Block, lastBlock *commonpb.Block, err error,
 	lastBlockHash []byte, proposedHeight uint64) error {
 
+	parentBlock := lastBlock
 	if block.Header.BlockHeight == lastBlock.Header.BlockHeight+1 {
 		if err = common.IsPreHashValid(block, lastBlock.Header.BlockHash); err != nil {
 			return err
@@ -274,6 +275,11 @@ func (v *BlockVerifierImpl) checkPreBlock_HOTSTUFF(block *commonpb.Block, lastBl
 				err,
 			)
 		}
+		parentBlock = proposedBlock
+	}
+	// check if this block timestamp follows the timestamp rule
+	if err = common.CheckBlockTimestamp(v.chainConf, block, parentBlock); err != nil {
+		return err
 	}
 
 	// remove unconfirmed block from proposal cache and txpool
@@ -292,18 +298,19 @@ func (v *BlockVerifierImpl) verifyVoteSig(block *commonpb.Block) error {
 	return consensus.VerifyBlockSignatures(v.chainConf, v.ac, v.blockchainStore, block, v.ledgerCache)
 }
 
-func parseVerifyResult(block *commonpb.Block, isValid bool,
+// parseVerifyResult returns the verify result of block verified with err,
+// the code tells the reason of failure if it is known, such as common.InvalidBlockTimestampCode
+func parseVerifyResult(block *commonpb.Block, err error,
 	txsRwSet map[string]*commonpb.TxRWSet) *consensuspb.VerifyResult {
 	verifyResult := &consensuspb.VerifyResult{
 		VerifiedBlock: block,
 		TxsRwSet:      txsRwSet,
+		Code:          common.VerifyResultCode(err),
 	}
-	if isValid {
-		verifyResult.Code = consensuspb.VerifyResult_SUCCESS
+	if err == nil {
 		verifyResult.Msg = "OK"
 	} else {
 		verifyResult.Msg = "FAIL"
-		verifyResult.Code = consensuspb.VerifyResult_FAIL
 	}
 	return verifyResult
 }
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package conf
 
 import (
+	"chainmaker.org/chainmaker-go/core/statetree"
 	"chainmaker.org/chainmaker-go/subscriber"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
 	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
@@ -28,6 +29,7 @@ type CoreEngineConfig struct {
 	VmMgr           protocol.VmManager
 	Subscriber      *subscriber.EventSubscriber // block subsriber
 	StoreHelper     StoreHelper
+	StateTree       *statetree.StateTree // authenticated world state, nil if not supported
 }
 
 type StoreHelper interface {
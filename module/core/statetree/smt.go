@@ -0,0 +1,358 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statetree
+
+import (
+	"bytes"
+	"fmt"
+
+	"chainmaker.org/chainmaker/common/v2/crypto/hash"
+	"chainmaker.org/chainmaker/protocol/v2"
+)
+
+const (
+	leafPrefix   byte = 0x00
+	branchPrefix byte = 0x01
+
+	// nodeKeyPrefix is the key prefix of the persisted nodes in db, followed by the node hash
+	nodeKeyPrefix = "statetree/n/"
+)
+
+// node is a node of the compact sparse merkle tree. Nodes are immutable, an update creates new nodes
+// along the path and shares the others, so the trees of different blocks can be kept at the same time.
+// A subtree with only one leaf is represented by the leaf itself, an empty subtree is nil.
+// The nodes are persisted by hash, a persisted node is read as a stub with only the hash,
+// and loaded from db when it is visited.
+type node struct {
+	hash []byte
+
+	// branch
+	left  *node
+	right *node
+
+	// leaf
+	path  []byte // hash of the key
+	value []byte // hash of the value, or the root of the contract tree for leaves of the world tree
+	sub   *node  // the contract tree, only for leaves of the world tree
+
+	stub   bool // only the hash is known, the node is to be loaded from db
+	stored bool // the node and its children have been persisted
+}
+
+func (n *node) isLeaf() bool {
+	return n.path != nil
+}
+
+// hasher hashes the nodes with the hash type of chain, and reads the persisted nodes from db
+type hasher struct {
+	hashType string
+	empty    []byte            // hash of the empty subtree
+	db       protocol.DBHandle // the db of persisted nodes, nil if the nodes are not persisted
+}
+
+func newHasher(hashType string, db protocol.DBHandle) (*hasher, error) {
+	h, err := hash.GetByStrType(hashType, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported hash type %s, %s", hashType, err)
+	}
+	return &hasher{hashType: hashType, empty: make([]byte, len(h)), db: db}, nil
+}
+
+func (h *hasher) sum(data ...[]byte) []byte {
+	// hash type has been checked when the hasher created
+	result, _ := hash.GetByStrType(h.hashType, bytes.Join(data, nil))
+	return result
+}
+
+func (h *hasher) leafHash(path, value []byte) []byte {
+	return h.sum([]byte{leafPrefix}, path, value)
+}
+
+func (h *hasher) branchHash(left, right []byte) []byte {
+	return h.sum([]byte{branchPrefix}, left, right)
+}
+
+func (h *hasher) nodeHash(n *node) []byte {
+	if n == nil {
+		return h.empty
+	}
+	return n.hash
+}
+
+func (h *hasher) newLeaf(path, value []byte, sub *node) *node {
+	return &node{hash: h.leafHash(path, value), path: path, value: value, sub: sub}
+}
+
+// stub returns the persisted node of hash, nil for the empty subtree
+func (h *hasher) stub(hash []byte) *node {
+	if len(hash) == 0 || bytes.Equal(hash, h.empty) {
+		return nil
+	}
+	return &node{hash: hash, stub: true, stored: true}
+}
+
+// subOf returns the contract tree of a leaf of the world tree
+func (h *hasher) subOf(leaf *node) *node {
+	if leaf.sub != nil {
+		return leaf.sub
+	}
+	return h.stub(leaf.value)
+}
+
+func nodeKey(hash []byte) []byte {
+	return append([]byte(nodeKeyPrefix), hash...)
+}
+
+// encode returns the persisted form of n, the path and value of a leaf or the child hashes of a branch
+func (h *hasher) encode(n *node) []byte {
+	if n.isLeaf() {
+		return bytes.Join([][]byte{{leafPrefix}, n.path, n.value}, nil)
+	}
+	return bytes.Join([][]byte{{branchPrefix}, h.nodeHash(n.left), h.nodeHash(n.right)}, nil)
+}
+
+// load reads the stub n from db, the children of n are read as stubs
+func (h *hasher) load(n *node) error {
+	if n == nil || !n.stub {
+		return nil
+	}
+	if h.db == nil {
+		return fmt.Errorf("node %x is not in memory and no db to load it", n.hash)
+	}
+	data, err := h.db.Get(nodeKey(n.hash))
+	if err != nil {
+		return fmt.Errorf("get node %x failed, %s", n.hash, err)
+	}
+	size := len(h.empty)
+	if len(data) != 1+2*size {
+		return fmt.Errorf("node %x not found or corrupted, %d bytes", n.hash, len(data))
+	}
+	switch data[0] {
+	case leafPrefix:
+		n.path, n.value = data[1:1+size], data[1+size:]
+	case branchPrefix:
+		n.left, n.right = h.stub(data[1:1+size]), h.stub(data[1+size:])
+	default:
+		return fmt.Errorf("node %x has unknown type %d", n.hash, data[0])
+	}
+	n.stub = false
+	return nil
+}
+
+// store persists the nodes of the tree n which have not been persisted, children before parents,
+// so that a persisted node never refers to a missing one
+func (h *hasher) store(n *node) error {
+	if n == nil || n.stored {
+		return nil
+	}
+	for _, child := range []*node{n.left, n.right, n.sub} {
+		if err := h.store(child); err != nil {
+			return err
+		}
+	}
+	if err := h.db.Put(nodeKey(n.hash), h.encode(n)); err != nil {
+		return fmt.Errorf("put node %x failed, %s", n.hash, err)
+	}
+	n.stored = true
+	return nil
+}
+
+func (h *hasher) newBranch(left, right *node) (*node, error) {
+	if left == nil && right == nil {
+		return nil, nil
+	}
+	// collapse the branch with a single leaf
+	if left == nil {
+		if err := h.load(right); err != nil {
+			return nil, err
+		}
+		if right.isLeaf() {
+			return right, nil
+		}
+	}
+	if right == nil {
+		if err := h.load(left); err != nil {
+			return nil, err
+		}
+		if left.isLeaf() {
+			return left, nil
+		}
+	}
+	return &node{hash: h.branchHash(h.nodeHash(left), h.nodeHash(right)), left: left, right: right}, nil
+}
+
+// bitAt returns the bit of path at depth, from the most significant bit
+func bitAt(path []byte, depth int) byte {
+	return (path[depth/8] >> (7 - uint(depth%8))) & 1
+}
+
+// update returns the tree with leaf put at its path
+func (h *hasher) update(n *node, depth int, leaf *node) (*node, error) {
+	if n == nil {
+		return leaf, nil
+	}
+	if err := h.load(n); err != nil {
+		return nil, err
+	}
+	if n.isLeaf() {
+		if bytes.Equal(n.path, leaf.path) {
+			return leaf, nil
+		}
+		return h.split(n, leaf, depth), nil
+	}
+	if bitAt(leaf.path, depth) == 0 {
+		left, err := h.update(n.left, depth+1, leaf)
+		if err != nil {
+			return nil, err
+		}
+		return h.newBranch(left, n.right)
+	}
+	right, err := h.update(n.right, depth+1, leaf)
+	if err != nil {
+		return nil, err
+	}
+	return h.newBranch(n.left, right)
+}
+
+// split creates the branches to hold two leaves with different paths
+func (h *hasher) split(a, b *node, depth int) *node {
+	bitA, bitB := bitAt(a.path, depth), bitAt(b.path, depth)
+	if bitA != bitB {
+		if bitA == 0 {
+			return &node{hash: h.branchHash(a.hash, b.hash), left: a, right: b}
+		}
+		return &node{hash: h.branchHash(b.hash, a.hash), left: b, right: a}
+	}
+	child := h.split(a, b, depth+1)
+	if bitA == 0 {
+		return &node{hash: h.branchHash(child.hash, h.empty), left: child}
+	}
+	return &node{hash: h.branchHash(h.empty, child.hash), right: child}
+}
+
+// remove returns the tree without the leaf at path
+func (h *hasher) remove(n *node, depth int, path []byte) (*node, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if err := h.load(n); err != nil {
+		return nil, err
+	}
+	if n.isLeaf() {
+		if bytes.Equal(n.path, path) {
+			return nil, nil
+		}
+		return n, nil
+	}
+	if bitAt(path, depth) == 0 {
+		left, err := h.remove(n.left, depth+1, path)
+		if err != nil || left == n.left {
+			return n, err
+		}
+		return h.newBranch(left, n.right)
+	}
+	right, err := h.remove(n.right, depth+1, path)
+	if err != nil || right == n.right {
+		return n, err
+	}
+	return h.newBranch(n.left, right)
+}
+
+// get returns the leaf at path, nil if not exist
+func (h *hasher) get(n *node, path []byte) (*node, error) {
+	for depth := 0; n != nil; depth++ {
+		if err := h.load(n); err != nil {
+			return nil, err
+		}
+		if n.isLeaf() {
+			if bytes.Equal(n.path, path) {
+				return n, nil
+			}
+			return nil, nil
+		}
+		if bitAt(path, depth) == 0 {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return nil, nil
+}
+
+// Proof is the merkle proof of a path in the compact sparse merkle tree
+type Proof struct {
+	// Siblings are the hashes of the sibling subtrees along the path, from the root
+	Siblings [][]byte `json:"siblings"`
+	// LeafPath and LeafValue are the leaf where the path ends. For a proof of non-existence,
+	// they are empty if the path ends in an empty subtree, or another leaf sharing the prefix.
+	LeafPath  []byte `json:"leaf_path,omitempty"`
+	LeafValue []byte `json:"leaf_value,omitempty"`
+}
+
+// prove returns the proof of path
+func (h *hasher) prove(n *node, path []byte) (*Proof, error) {
+	proof := &Proof{}
+	for depth := 0; n != nil; depth++ {
+		if err := h.load(n); err != nil {
+			return nil, err
+		}
+		if n.isLeaf() {
+			proof.LeafPath = n.path
+			proof.LeafValue = n.value
+			break
+		}
+		if bitAt(path, depth) == 0 {
+			proof.Siblings = append(proof.Siblings, h.nodeHash(n.right))
+			n = n.left
+		} else {
+			proof.Siblings = append(proof.Siblings, h.nodeHash(n.left))
+			n = n.right
+		}
+	}
+	return proof, nil
+}
+
+// verify checks proof of path against root. value is the hash of the value, nil to verify non-existence.
+func (h *hasher) verify(root, path, value []byte, proof *Proof) error {
+	if proof == nil {
+		return fmt.Errorf("proof is nil")
+	}
+	if len(proof.Siblings) > len(path)*8 {
+		return fmt.Errorf("proof is too long, %d siblings", len(proof.Siblings))
+	}
+	var current []byte
+	switch {
+	case value != nil:
+		if !bytes.Equal(proof.LeafPath, path) || !bytes.Equal(proof.LeafValue, value) {
+			return fmt.Errorf("leaf of proof mismatch, path %x, value %x", proof.LeafPath, proof.LeafValue)
+		}
+		current = h.leafHash(path, value)
+	case proof.LeafPath == nil:
+		current = h.empty
+	default:
+		if bytes.Equal(proof.LeafPath, path) || len(proof.LeafPath) != len(path) {
+			return fmt.Errorf("leaf of proof is not another leaf, path %x", proof.LeafPath)
+		}
+		for depth := range proof.Siblings {
+			if bitAt(proof.LeafPath, depth) != bitAt(path, depth) {
+				return fmt.Errorf("leaf of proof is not on the path, path %x", proof.LeafPath)
+			}
+		}
+		current = h.leafHash(proof.LeafPath, proof.LeafValue)
+	}
+	for depth := len(proof.Siblings) - 1; depth >= 0; depth-- {
+		if bitAt(path, depth) == 0 {
+			current = h.branchHash(current, proof.Siblings[depth])
+		} else {
+			current = h.branchHash(proof.Siblings[depth], current)
+		}
+	}
+	if !bytes.Equal(current, root) {
+		return fmt.Errorf("root expect %x, got %x", root, current)
+	}
+	return nil
+}
@@ -0,0 +1,545 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package statetree maintains an authenticated structure of the world state, to prove the value of a key
+// at a height. The world state is a compact sparse merkle tree of contracts, each leaf of which holds
+// the root of a compact sparse merkle tree of the contract state.
+package statetree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+
+	commonextpb "chainmaker.org/chainmaker-go/pb/common"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// DBName is the name of the db handle in store for the persisted state tree
+	DBName = "statetree"
+
+	// heightKey is the key of the last height whose tree has been persisted
+	heightKey = "statetree/h"
+	// rootKeyPrefix is the key prefix of the state roots, followed by the height in big endian
+	rootKeyPrefix = "statetree/r/"
+	// loadLogInterval is the interval of heights to log the progress of loading
+	loadLogInterval = 1000
+)
+
+// StateTree is the authenticated world state of a chain. The nodes of the tree and the state root
+// of each height are persisted in db by the committed blocks, so the state of any committed height
+// can be proved. The tree is loaded by Load before consensus starts, which resumes from the last
+// persisted height and replays the write sets in store of the blocks committed after it, so the blocks
+// can be proposed and verified at once. When the tree falls behind the committed blocks later, such as
+// when the activation height is set by a config block, it is loaded again by the block committed.
+// The trees of proposed blocks are kept until committed, so that their children can be built on them.
+type StateTree struct {
+	hasher *hasher
+	store  protocol.BlockchainStore
+	log    protocol.Logger
+
+	lock   sync.Mutex
+	loaded bool   // the tree is up to date with the committed blocks
+	height uint64 // height of the last committed block
+	world  *node  // tree of the last committed block
+	// trees of the proposed blocks, keyed by the rwset root in block header,
+	// which commits to the state root of the block
+	pending map[string]*Version
+}
+
+// Version is the tree of a block
+type Version struct {
+	height uint64
+	world  *node
+	root   []byte
+}
+
+// Root returns the state root of the block
+func (v *Version) Root() []byte {
+	return v.root
+}
+
+// NewStateTree creates a StateTree of chain, which persists the tree in db
+func NewStateTree(hashType string, store protocol.BlockchainStore, db protocol.DBHandle,
+	log protocol.Logger) (*StateTree, error) {
+	h, err := newHasher(hashType, db)
+	if err != nil {
+		return nil, err
+	}
+	return &StateTree{
+		hasher:  h,
+		store:   store,
+		log:     log,
+		pending: make(map[string]*Version),
+	}, nil
+}
+
+func rootKey(height uint64) []byte {
+	key := make([]byte, len(rootKeyPrefix)+8)
+	copy(key, rootKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(rootKeyPrefix):], height)
+	return key
+}
+
+// persistedHeight returns the last height whose tree has been persisted, false if none
+func (t *StateTree) persistedHeight() (uint64, bool, error) {
+	data, err := t.hasher.db.Get([]byte(heightKey))
+	if err != nil {
+		return 0, false, fmt.Errorf("get state tree height failed, %s", err)
+	}
+	if len(data) == 0 {
+		return 0, false, nil
+	}
+	if len(data) != 8 {
+		return 0, false, fmt.Errorf("state tree height is corrupted, %d bytes", len(data))
+	}
+	return binary.BigEndian.Uint64(data), true, nil
+}
+
+// persistedRoot returns the persisted state root at height
+func (t *StateTree) persistedRoot(height uint64) ([]byte, error) {
+	root, err := t.hasher.db.Get(rootKey(height))
+	if err != nil {
+		return nil, fmt.Errorf("get state root of height %d failed, %s", height, err)
+	}
+	if len(root) != len(t.hasher.empty) {
+		return nil, fmt.Errorf("state root of height %d is not persisted", height)
+	}
+	return root, nil
+}
+
+// persist stores the nodes of world, and the state root of world at height
+func (t *StateTree) persist(world *node, height uint64) error {
+	if err := t.hasher.store(world); err != nil {
+		return err
+	}
+	if err := t.hasher.db.Put(rootKey(height), t.hasher.nodeHash(world)); err != nil {
+		return fmt.Errorf("put state root of height %d failed, %s", height, err)
+	}
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, height)
+	if err := t.hasher.db.Put([]byte(heightKey), heightBytes); err != nil {
+		return fmt.Errorf("put state tree height %d failed, %s", height, err)
+	}
+	return nil
+}
+
+// Load loads the tree up to the last committed block, see load
+func (t *StateTree) Load() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.ensureLoaded()
+}
+
+// ensureLoaded loads the tree if it is not up to date with the committed blocks.
+// It must be called with lock held.
+func (t *StateTree) ensureLoaded() error {
+	if t.loaded {
+		return nil
+	}
+	return t.load()
+}
+
+// load replays the write sets of the committed blocks after the persisted height, and marks the tree
+// loaded at the last committed block. It must be called with lock held.
+func (t *StateTree) load() error {
+	height, ok, err := t.persistedHeight()
+	if err != nil {
+		return err
+	}
+	var world *node
+	if ok {
+		root, err := t.persistedRoot(height)
+		if err != nil {
+			return err
+		}
+		world = t.hasher.stub(root)
+	}
+
+	lastBlock, err := t.store.GetLastBlock()
+	if err != nil {
+		return fmt.Errorf("get last block failed, %s", err)
+	}
+	if ok && height > lastBlock.Header.BlockHeight {
+		return fmt.Errorf("state tree height %d is higher than the last committed height %d",
+			height, lastBlock.Header.BlockHeight)
+	}
+	next := uint64(0)
+	if ok {
+		next = height + 1
+	}
+	if next <= lastBlock.Header.BlockHeight {
+		t.log.Infof("state tree loading from height %d to %d", next, lastBlock.Header.BlockHeight)
+	}
+	for h := next; h <= lastBlock.Header.BlockHeight; h++ {
+		rwSets, err := t.store.GetTxRWSetsByHeight(h)
+		if err != nil {
+			return fmt.Errorf("get rwsets of block[%d] failed, %s", h, err)
+		}
+		if world, err = t.apply(world, rwSets); err != nil {
+			return fmt.Errorf("apply rwsets of block[%d] failed, %s", h, err)
+		}
+		if err = t.persist(world, h); err != nil {
+			return err
+		}
+		if h%loadLogInterval == 0 {
+			t.log.Infof("state tree loading at height %d, last committed height %d",
+				h, lastBlock.Header.BlockHeight)
+			// drop the nodes in memory, they are loaded from db when visited again
+			world = t.hasher.stub(t.hasher.nodeHash(world))
+		}
+	}
+
+	t.world = t.hasher.stub(t.hasher.nodeHash(world))
+	t.height = lastBlock.Header.BlockHeight
+	t.loaded = true
+	t.log.Infof("state tree loaded at height %d, root %x", t.height, t.hasher.nodeHash(t.world))
+	return nil
+}
+
+// apply returns the tree with the writes of rwSets applied in order
+func (t *StateTree) apply(world *node, rwSets []*commonpb.TxRWSet) (*node, error) {
+	// the last write of a key wins
+	writes := make(map[string]map[string][]byte)
+	for _, rwSet := range rwSets {
+		if rwSet == nil {
+			continue
+		}
+		for _, w := range rwSet.TxWrites {
+			contract, ok := writes[w.ContractName]
+			if !ok {
+				contract = make(map[string][]byte)
+				writes[w.ContractName] = contract
+			}
+			contract[string(w.Key)] = w.Value
+		}
+	}
+	// the tree does not depend on the order of updates, sort to save the nodes created
+	contractNames := make([]string, 0, len(writes))
+	for name := range writes {
+		contractNames = append(contractNames, name)
+	}
+	sort.Strings(contractNames)
+	for _, name := range contractNames {
+		contractPath := t.hasher.sum([]byte(name))
+		leaf, err := t.hasher.get(world, contractPath)
+		if err != nil {
+			return nil, err
+		}
+		var contract *node
+		if leaf != nil {
+			contract = t.hasher.subOf(leaf)
+		}
+		for key, value := range writes[name] {
+			keyPath := t.hasher.sum([]byte(key))
+			if len(value) == 0 {
+				contract, err = t.hasher.remove(contract, 0, keyPath)
+			} else {
+				contract, err = t.hasher.update(contract, 0, t.hasher.newLeaf(keyPath, t.hasher.sum(value), nil))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if contract == nil {
+			world, err = t.hasher.remove(world, 0, contractPath)
+		} else {
+			world, err = t.hasher.update(world, 0, t.hasher.newLeaf(contractPath, contract.hash, contract))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return world, nil
+}
+
+// parentTree returns the tree of the parent block of a block at height
+func (t *StateTree) parentTree(lastBlock *commonpb.Block) (*node, error) {
+	if err := t.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	if lastBlock.Header.BlockHeight == t.height {
+		return t.world, nil
+	}
+	if p, ok := t.pending[string(lastBlock.Header.RwSetRoot)]; ok && p.height == lastBlock.Header.BlockHeight {
+		return p.world, nil
+	}
+	return nil, fmt.Errorf("state tree of block[%d](%x) not found, committed height %d",
+		lastBlock.Header.BlockHeight, lastBlock.Header.BlockHash, t.height)
+}
+
+// Build builds the tree of block, on the tree of lastBlock, with the write sets of block
+func (t *StateTree) Build(lastBlock, block *commonpb.Block,
+	txRWSetMap map[string]*commonpb.TxRWSet) (*Version, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	parent, err := t.parentTree(lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	world, err := t.apply(parent, orderedRWSets(block, txRWSetMap))
+	if err != nil {
+		return nil, err
+	}
+	return &Version{height: block.Header.BlockHeight, world: world, root: t.hasher.nodeHash(world)}, nil
+}
+
+// AddPending keeps the tree of a proposed or verified block, whose header has been finalized,
+// so that the blocks proposed on it can be built before it is committed
+func (t *StateTree) AddPending(block *commonpb.Block, v *Version) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if v.height <= t.height {
+		return
+	}
+	t.pending[string(block.Header.RwSetRoot)] = v
+}
+
+// Commit applies and persists the write sets of a committed block, which has been put into store.
+// If the tree is not up to date, it is loaded, which includes this block.
+func (t *StateTree) Commit(block *commonpb.Block, rwSets []*commonpb.TxRWSet) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.loaded {
+		if err := t.load(); err != nil {
+			t.log.Errorf("load state tree at block[%d] failed, %s", block.Header.BlockHeight, err)
+			return
+		}
+	}
+	if block.Header.BlockHeight <= t.height {
+		// included when loaded
+		return
+	}
+	if block.Header.BlockHeight != t.height+1 {
+		// the tree is out of date, load from the persisted height
+		t.log.Warnf("state tree at height %d, got block[%d], reload", t.height, block.Header.BlockHeight)
+		t.reset()
+		if err := t.load(); err != nil {
+			t.log.Errorf("load state tree at block[%d] failed, %s", block.Header.BlockHeight, err)
+		}
+		return
+	}
+	world := t.world
+	if p, ok := t.pending[string(block.Header.RwSetRoot)]; ok && p.height == block.Header.BlockHeight {
+		// the tree has been built when the block was proposed or verified
+		world = p.world
+	} else {
+		var err error
+		if world, err = t.apply(world, rwSets); err != nil {
+			t.log.Errorf("apply rwsets of block[%d] to state tree failed, %s", block.Header.BlockHeight, err)
+			t.reset()
+			return
+		}
+	}
+	if err := t.persist(world, block.Header.BlockHeight); err != nil {
+		t.log.Errorf("persist state tree of block[%d] failed, %s", block.Header.BlockHeight, err)
+		t.reset()
+		return
+	}
+	t.height = block.Header.BlockHeight
+	// drop the nodes in memory, they are loaded from db when visited again
+	t.world = t.hasher.stub(t.hasher.nodeHash(world))
+	for key, p := range t.pending {
+		if p.height <= t.height {
+			delete(t.pending, key)
+		}
+	}
+}
+
+// reset marks the tree out of date, it is loaded when used next time
+func (t *StateTree) reset() {
+	t.loaded = false
+	t.world = nil
+	t.pending = make(map[string]*Version)
+}
+
+// Prove returns the proof of key of contract at height, against the rwset root in the header of the block
+func (t *StateTree) Prove(contractName string, key []byte, height uint64) (*StateProof, error) {
+	// hold the lock until the value is read, so that the value is of the same height as the proof
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	proof, err := t.prove(contractName, key, height)
+	if err != nil {
+		return nil, err
+	}
+	block, err := t.store.GetBlock(height)
+	if err != nil || block == nil {
+		return nil, fmt.Errorf("get block[%d] failed, %v", height, err)
+	}
+	if proof.RwSetTxRoot, err = utils.CalcRWSetRoot(t.hasher.hashType, block.Txs); err != nil {
+		return nil, err
+	}
+	if proof.ContractProof == nil || !bytes.Equal(proof.ContractProof.LeafPath, t.hasher.sum(key)) {
+		// the key does not exist
+		return proof, nil
+	}
+	if proof.Value, err = t.readValue(contractName, key, height); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(t.hasher.sum(proof.Value), proof.ContractProof.LeafValue) {
+		return nil, fmt.Errorf("value of key %x at height %d mismatches with state tree", key, height)
+	}
+	return proof, nil
+}
+
+// prove returns the proof of key of contract at height without the value, it must be called with lock held
+func (t *StateTree) prove(contractName string, key []byte, height uint64) (*StateProof, error) {
+	if err := t.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	if height > t.height {
+		return nil, fmt.Errorf("state tree of height %d is not available, committed height %d", height, t.height)
+	}
+	world := t.world
+	if height != t.height {
+		root, err := t.persistedRoot(height)
+		if err != nil {
+			return nil, err
+		}
+		// read the tree of an earlier height from db, without caching the nodes
+		world = t.hasher.stub(root)
+	}
+	proof := &StateProof{
+		BlockHeight:  height,
+		ContractName: contractName,
+		Key:          key,
+		StateRoot:    t.hasher.nodeHash(world),
+	}
+	contractPath := t.hasher.sum([]byte(contractName))
+	var err error
+	if proof.WorldProof, err = t.hasher.prove(world, contractPath); err != nil {
+		return nil, err
+	}
+	leaf, err := t.hasher.get(world, contractPath)
+	if err != nil {
+		return nil, err
+	}
+	if leaf != nil {
+		proof.ContractRoot = leaf.value
+		if proof.ContractProof, err = t.hasher.prove(t.hasher.subOf(leaf), t.hasher.sum(key)); err != nil {
+			return nil, err
+		}
+	}
+	return proof, nil
+}
+
+// readValue reads the value of key at height, from the state db at the last committed height,
+// or from the history db otherwise. It must be called with lock held.
+func (t *StateTree) readValue(contractName string, key []byte, height uint64) ([]byte, error) {
+	if height == t.height {
+		return t.store.ReadObject(contractName, key)
+	}
+	iter, err := t.store.GetHistoryForKey(contractName, key)
+	if err != nil {
+		return nil, fmt.Errorf("get history of key %x failed, %s", key, err)
+	}
+	defer iter.Release()
+	var value []byte
+	var found bool
+	var foundHeight uint64
+	for iter.Next() {
+		km, err := iter.Value()
+		if err != nil {
+			return nil, fmt.Errorf("get history of key %x failed, %s", key, err)
+		}
+		if km.BlockHeight <= height && (!found || km.BlockHeight >= foundHeight) {
+			value, found, foundHeight = km.Value, true, km.BlockHeight
+			if km.IsDelete {
+				value = nil
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("value of key %x at height %d not found in history", key, height)
+	}
+	return value, nil
+}
+
+// orderedRWSets returns the write sets of block in the order of txs
+func orderedRWSets(block *commonpb.Block, txRWSetMap map[string]*commonpb.TxRWSet) []*commonpb.TxRWSet {
+	rwSets := make([]*commonpb.TxRWSet, 0, len(block.Txs))
+	for _, tx := range block.Txs {
+		if rwSet, ok := txRWSetMap[tx.Payload.TxId]; ok {
+			rwSets = append(rwSets, rwSet)
+		}
+	}
+	return rwSets
+}
+
+// CommitmentRoot returns the rwset root in block header from the activation height of state root,
+// the hash of the StateCommitment of the merkle root of the rwsets of txs and the state root
+func CommitmentRoot(hashType string, rwSetTxRoot, stateRoot []byte) ([]byte, error) {
+	h, err := newHasher(hashType, nil)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(&commonextpb.StateCommitment{RwSetTxRoot: rwSetTxRoot, StateRoot: stateRoot})
+	if err != nil {
+		return nil, err
+	}
+	return h.sum(data), nil
+}
+
+// StateProof proves the value of a key of a contract at a height
+type StateProof struct {
+	BlockHeight  uint64 `json:"block_height"`
+	ContractName string `json:"contract_name"`
+	Key          []byte `json:"key"`
+	Value        []byte `json:"value,omitempty"` // empty if the key does not exist
+	// RwSetTxRoot is the merkle root of the rwsets of txs in block, and with StateRoot
+	// they are committed by the rwset root of block header, see CommitmentRoot
+	RwSetTxRoot []byte `json:"rwset_tx_root"`
+	StateRoot   []byte `json:"state_root"`
+	// ContractRoot is the root of contract tree, empty if the contract has no state
+	ContractRoot  []byte `json:"contract_root,omitempty"`
+	WorldProof    *Proof `json:"world_proof"`
+	ContractProof *Proof `json:"contract_proof,omitempty"`
+}
+
+// VerifyStateProof checks proof against the block header of proof.BlockHeight
+func VerifyStateProof(hashType string, header *commonpb.BlockHeader, proof *StateProof) error {
+	h, err := newHasher(hashType, nil)
+	if err != nil {
+		return err
+	}
+	if header == nil || proof == nil {
+		return fmt.Errorf("header or proof is nil")
+	}
+	if header.BlockHeight != proof.BlockHeight {
+		return fmt.Errorf("height expect %d, got %d", header.BlockHeight, proof.BlockHeight)
+	}
+	root, err := CommitmentRoot(hashType, proof.RwSetTxRoot, proof.StateRoot)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, header.RwSetRoot) {
+		return fmt.Errorf("rwset root expect %x, got %x", header.RwSetRoot, root)
+	}
+	contractPath := h.sum([]byte(proof.ContractName))
+	if len(proof.ContractRoot) == 0 {
+		if len(proof.Value) > 0 {
+			return fmt.Errorf("contract %s has no state", proof.ContractName)
+		}
+		return h.verify(proof.StateRoot, contractPath, nil, proof.WorldProof)
+	}
+	if err = h.verify(proof.StateRoot, contractPath, proof.ContractRoot, proof.WorldProof); err != nil {
+		return fmt.Errorf("verify contract %s failed, %s", proof.ContractName, err)
+	}
+	var valueHash []byte
+	if len(proof.Value) > 0 {
+		valueHash = h.sum(proof.Value)
+	}
+	if err = h.verify(proof.ContractRoot, h.sum(proof.Key), valueHash, proof.ContractProof); err != nil {
+		return fmt.Errorf("verify key %x failed, %s", proof.Key, err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statetree
+
+import (
+	"fmt"
+	"testing"
+
+	"chainmaker.org/chainmaker/logger/v2"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRWSet(contractName string, kvs ...string) *commonpb.TxRWSet {
+	rwSet := &commonpb.TxRWSet{}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		rwSet.TxWrites = append(rwSet.TxWrites, &commonpb.TxWrite{
+			ContractName: contractName,
+			Key:          []byte(kvs[i]),
+			Value:        []byte(kvs[i+1]),
+		})
+	}
+	return rwSet
+}
+
+func mustApply(t *testing.T, tree *StateTree, world *node, rwSets ...*commonpb.TxRWSet) *node {
+	world, err := tree.apply(world, rwSets)
+	require.NoError(t, err)
+	return world
+}
+
+func TestStateTreeApply(t *testing.T) {
+	tree, err := NewStateTree("SHA256", nil, nil, nil)
+	require.NoError(t, err)
+
+	var kvs []string
+	for i := 0; i < 50; i++ {
+		kvs = append(kvs, fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	world := mustApply(t, tree, nil, newTestRWSet("c1", kvs...), newTestRWSet("c2", "k", "v"))
+
+	// the root does not depend on the order of writes
+	var reversed []string
+	for i := len(kvs) - 2; i >= 0; i -= 2 {
+		reversed = append(reversed, kvs[i], kvs[i+1])
+	}
+	world2 := mustApply(t, tree, nil, newTestRWSet("c2", "k", "v"), newTestRWSet("c1", reversed...))
+	require.Equal(t, world.hash, world2.hash)
+
+	// writing the same values keeps the root
+	world3 := mustApply(t, tree, world, newTestRWSet("c1", kvs[2:]...))
+	require.Equal(t, world.hash, world3.hash)
+
+	// deleting keys gives the same root as never writing them
+	deleted := newTestRWSet("c1", kvs[2:]...)
+	for _, w := range deleted.TxWrites {
+		w.Value = nil
+	}
+	world4 := mustApply(t, tree, world, deleted, newTestRWSet("c2", "k", ""))
+	world5 := mustApply(t, tree, nil, newTestRWSet("c1", kvs[:2]...))
+	require.Equal(t, world5.hash, world4.hash)
+}
+
+func TestStateTreeProof(t *testing.T) {
+	tree, err := NewStateTree("SHA256", nil, nil, nil)
+	require.NoError(t, err)
+	var kvs []string
+	for i := 0; i < 20; i++ {
+		kvs = append(kvs, fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	tree.world = mustApply(t, tree, nil, newTestRWSet("c1", kvs...))
+	tree.height = 1
+	tree.loaded = true
+
+	rwSetTxRoot := []byte("rwset tx root")
+	rwSetRoot, err := CommitmentRoot("SHA256", rwSetTxRoot, tree.world.hash)
+	require.NoError(t, err)
+	header := &commonpb.BlockHeader{BlockHeight: 1, RwSetRoot: rwSetRoot}
+
+	// existence
+	proof, err := tree.prove("c1", []byte("key3"), 1)
+	require.NoError(t, err)
+	proof.RwSetTxRoot = rwSetTxRoot
+	proof.Value = []byte("value3")
+	require.NoError(t, VerifyStateProof("SHA256", header, proof))
+	proof.Value = []byte("value4")
+	require.Error(t, VerifyStateProof("SHA256", header, proof))
+
+	// non-existence of key and contract
+	for _, c := range []struct{ contract, key string }{{"c1", "key100"}, {"c2", "key3"}} {
+		proof, err = tree.prove(c.contract, []byte(c.key), 1)
+		require.NoError(t, err)
+		proof.RwSetTxRoot = rwSetTxRoot
+		require.NoError(t, VerifyStateProof("SHA256", header, proof))
+		proof.Value = []byte("value3")
+		require.Error(t, VerifyStateProof("SHA256", header, proof))
+	}
+
+	_, err = tree.prove("c1", []byte("key3"), 2)
+	require.Error(t, err)
+}
+
+// newTestDB returns a db handle keeping the values in kvs
+func newTestDB(ctrl *gomock.Controller, kvs map[string][]byte) *mock.MockDBHandle {
+	db := mock.NewMockDBHandle(ctrl)
+	db.EXPECT().Get(gomock.Any()).DoAndReturn(func(key []byte) ([]byte, error) {
+		return kvs[string(key)], nil
+	}).AnyTimes()
+	db.EXPECT().Put(gomock.Any(), gomock.Any()).DoAndReturn(func(key, value []byte) error {
+		kvs[string(key)] = value
+		return nil
+	}).AnyTimes()
+	return db
+}
+
+func TestStateTreePersist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockRWSets := [][]*commonpb.TxRWSet{
+		{newTestRWSet("c1", "k1", "v1")},
+		{newTestRWSet("c1", "k2", "v2"), newTestRWSet("c2", "k1", "v1")},
+		{newTestRWSet("c1", "k1", "")},
+		{newTestRWSet("c2", "k2", "v2")},
+	}
+	lastHeight := uint64(2)
+	store := mock.NewMockBlockchainStore(ctrl)
+	store.EXPECT().GetLastBlock().DoAndReturn(func() (*commonpb.Block, error) {
+		return &commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: lastHeight}}, nil
+	}).AnyTimes()
+	rwSetsRead := make(map[uint64]int)
+	store.EXPECT().GetTxRWSetsByHeight(gomock.Any()).DoAndReturn(func(height uint64) ([]*commonpb.TxRWSet, error) {
+		rwSetsRead[height]++
+		return blockRWSets[height], nil
+	}).AnyTimes()
+	kvs := make(map[string][]byte)
+	tree, err := NewStateTree("SHA256", store, newTestDB(ctrl, kvs), logger.GetLoggerByChain(logger.MODULE_CORE, "chain1"))
+	require.NoError(t, err)
+
+	// the tree is loaded up to the committed blocks
+	require.NoError(t, tree.Load())
+	require.Equal(t, uint64(2), tree.height)
+
+	// the committed block is applied and persisted
+	lastHeight = 3
+	tree.Commit(&commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: 3}}, blockRWSets[3])
+	require.Equal(t, uint64(3), tree.height)
+
+	// the roots of all heights are persisted, and the proofs of earlier heights are read from db
+	memTree, err := NewStateTree("SHA256", nil, nil, nil)
+	require.NoError(t, err)
+	var world *node
+	for height, rwSets := range blockRWSets {
+		world = mustApply(t, memTree, world, rwSets...)
+		root, err := tree.persistedRoot(uint64(height))
+		require.NoError(t, err)
+		require.Equal(t, memTree.hasher.nodeHash(world), root)
+
+		tree.lock.Lock()
+		proof, err := tree.prove("c2", []byte("k1"), uint64(height))
+		tree.lock.Unlock()
+		require.NoError(t, err)
+		require.Equal(t, root, proof.StateRoot)
+		if height > 0 {
+			proof.Value = []byte("v1")
+		}
+		header := &commonpb.BlockHeader{BlockHeight: uint64(height)}
+		header.RwSetRoot, err = CommitmentRoot("SHA256", nil, root)
+		require.NoError(t, err)
+		require.NoError(t, VerifyStateProof("SHA256", header, proof))
+	}
+
+	// the tree is resumed from db after restart, without replaying the blocks
+	tree, err = NewStateTree("SHA256", store, newTestDB(ctrl, kvs), logger.GetLoggerByChain(logger.MODULE_CORE, "chain1"))
+	require.NoError(t, err)
+	require.NoError(t, tree.Load())
+	require.Equal(t, uint64(3), tree.height)
+	require.Equal(t, memTree.hasher.nodeHash(world), tree.hasher.nodeHash(tree.world))
+	for height := range blockRWSets {
+		require.LessOrEqual(t, rwSetsRead[uint64(height)], 1)
+	}
+}
+
+func TestStateTreeLoadOnCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockRWSets := [][]*commonpb.TxRWSet{
+		{newTestRWSet("c1", "k1", "v1")},
+		{newTestRWSet("c1", "k2", "v2")},
+		{newTestRWSet("c2", "k1", "v1")},
+	}
+	lastHeight := uint64(1)
+	store := mock.NewMockBlockchainStore(ctrl)
+	store.EXPECT().GetLastBlock().DoAndReturn(func() (*commonpb.Block, error) {
+		return &commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: lastHeight}}, nil
+	}).AnyTimes()
+	store.EXPECT().GetTxRWSetsByHeight(gomock.Any()).DoAndReturn(func(height uint64) ([]*commonpb.TxRWSet, error) {
+		return blockRWSets[height], nil
+	}).AnyTimes()
+	tree, err := NewStateTree("SHA256", store, newTestDB(ctrl, make(map[string][]byte)),
+		logger.GetLoggerByChain(logger.MODULE_CORE, "chain1"))
+	require.NoError(t, err)
+
+	// the tree is loaded synchronously by the block committed after the activation height is set,
+	// which includes the block, and the next block is applied on it
+	tree.Commit(&commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: 1}}, blockRWSets[1])
+	require.True(t, tree.loaded)
+	require.Equal(t, uint64(1), tree.height)
+	lastHeight = 2
+	tree.Commit(&commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: 2}}, blockRWSets[2])
+	require.Equal(t, uint64(2), tree.height)
+
+	memTree, err := NewStateTree("SHA256", nil, nil, nil)
+	require.NoError(t, err)
+	world := mustApply(t, memTree, nil, blockRWSets[0]...)
+	world = mustApply(t, memTree, world, blockRWSets[1]...)
+	world = mustApply(t, memTree, world, blockRWSets[2]...)
+	require.Equal(t, memTree.hasher.nodeHash(world), tree.hasher.nodeHash(tree.world))
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposer
+
+import (
+	"strconv"
+
+	"chainmaker.org/chainmaker-go/consensus/proposerhint"
+	"chainmaker.org/chainmaker-go/core/common"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+const (
+	// PipelineEnableKey is the key in consensus ext config, to enable pipelined proposing of the next height
+	PipelineEnableKey = "proposer_pipeline_enable"
+)
+
+// isPipelineEnabled, to check if the next height should be proposed speculatively on top of block.
+// Only the block on top of the last committed block is pipelined, config blocks and sql contracts are excluded.
+// The next height is only pipelined if this node is expected to be its proposer, or it would be discarded.
+func (bp *BlockProposerImpl) isPipelineEnabled(block *commonpb.Block) bool {
+	value, ok := common.GetConsensusExtConfig(bp.chainConf, PipelineEnableKey)
+	if !ok {
+		return false
+	}
+	if enable, err := strconv.ParseBool(value); err != nil || !enable {
+		return false
+	}
+	if bp.chainConf.ChainConfig().Contract.EnableSqlSupport || utils.IsConfBlock(block) {
+		return false
+	}
+	currentHeight, err := bp.ledgerCache.CurrentHeight()
+	if err != nil || currentHeight+1 != block.Header.BlockHeight {
+		return false
+	}
+	return proposerhint.IsProposer(bp.chainId, block.Header.BlockHeight+1)
+}
+
+// proposePipelined, build the block of the next height on top of the not-yet-committed parent block,
+// while the parent is in consensus. The snapshot of the block is linked to the snapshot of parent.
+// The block is cached as self proposed but not published, so it will be repeated by proposing
+// if parent is committed, or discarded and its txs re-injected if another block is committed instead.
+func (bp *BlockProposerImpl) proposePipelined(parent *commonpb.Block) {
+	defer bp.pipelineWg.Done()
+
+	startTick := utils.CurrentTimeMillisSeconds()
+	height := parent.Header.BlockHeight + 1
+	if bp.proposalCache.GetSelfProposedBlockAt(height) != nil {
+		return
+	}
+
+	checkedBatch, fetchLasts, dupLasts, ok := bp.fetchTxBatch(height)
+	if !ok {
+		return
+	}
+	block, timeLasts, err := bp.generateNewBlock(height, parent.Header.BlockHash, checkedBatch)
+	if err != nil {
+		if block != nil {
+			if sqlErr := bp.storeHelper.RollBack(block, bp.blockchainStore); sqlErr != nil {
+				bp.log.Errorf("block [%d] rollback sql failed: %s", block.Header.BlockHeight, sqlErr)
+			}
+		}
+		bp.txPool.RetryAndRemoveTxs(common.TxsToRetry(checkedBatch, err), nil) // put txs back to txpool
+		bp.log.Warnf("generate pipelined block failed, %s", err.Error())
+		return
+	}
+	// the block has not been proposed in consensus yet
+	bp.proposalCache.ResetProposedAt(height)
+
+	bp.log.Infof("proposer pipelined [%d](txs:%d) on top of [%x], time used(fetch:%d,dup:%d,vm:%v,total:%d)",
+		block.Header.BlockHeight, block.Header.TxCount, parent.Header.BlockHash,
+		fetchLasts, dupLasts, timeLasts, utils.CurrentTimeMillisSeconds()-startTick)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proposer
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker-go/consensus/proposerhint"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	configpb "chainmaker.org/chainmaker/pb-go/v2/config"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPipelineEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const chainId = "chain1"
+	chainConfig := &configpb.ChainConfig{
+		Consensus: &configpb.ConsensusConfig{},
+		Contract:  &configpb.ContractConfig{},
+	}
+	chainConf := mock.NewMockChainConf(ctrl)
+	chainConf.EXPECT().ChainConfig().Return(chainConfig).AnyTimes()
+	ledgerCache := mock.NewMockLedgerCache(ctrl)
+	ledgerCache.EXPECT().CurrentHeight().Return(uint64(9), nil).AnyTimes()
+	bp := &BlockProposerImpl{chainId: chainId, chainConf: chainConf, ledgerCache: ledgerCache}
+	block := &commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: 10}}
+
+	// not enabled
+	require.False(t, bp.isPipelineEnabled(block))
+
+	chainConfig.Consensus.ExtConfig = []*configpb.ConfigKeyValue{{Key: PipelineEnableKey, Value: "true"}}
+	// no proposer predictor registered
+	require.False(t, bp.isPipelineEnabled(block))
+
+	proposerhint.Register(chainId, func(height uint64) bool { return height == 11 })
+	defer proposerhint.Unregister(chainId)
+	require.True(t, bp.isPipelineEnabled(block))
+	// not the proposer of the next height
+	require.False(t, bp.isPipelineEnabled(&commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: 11}}))
+	// not on top of the last committed block
+	proposerhint.Register(chainId, func(uint64) bool { return true })
+	require.False(t, bp.isPipelineEnabled(&commonpb.Block{Header: &commonpb.BlockHeader{BlockHeight: 11}}))
+	require.True(t, bp.isPipelineEnabled(block))
+
+	chainConfig.Contract.EnableSqlSupport = true
+	require.False(t, bp.isPipelineEnabled(block))
+}
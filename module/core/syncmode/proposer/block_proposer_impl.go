@@ -11,8 +11,11 @@ import (
 	"sync"
 	"time"
 
+	"chainmaker.org/chainmaker-go/consensus/evidence"
 	"chainmaker.org/chainmaker-go/core/common"
 	"chainmaker.org/chainmaker-go/core/provider/conf"
+	"chainmaker.org/chainmaker-go/core/statetree"
+	"chainmaker.org/chainmaker-go/core/txtimeline"
 	"chainmaker.org/chainmaker/common/v2/monitor"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
 	"chainmaker.org/chainmaker/localconf/v2"
@@ -58,11 +61,14 @@ type BlockProposerImpl struct {
 	log            protocol.Logger
 	finishProposeC chan bool // channel to receive signal to yield propose block
 
-	metricBlockPackageTime *prometheus.HistogramVec
-	proposer               *pbac.Member
+	metricBlockPackageTime   *prometheus.HistogramVec
+	metricDiscardedTxCounter *prometheus.CounterVec // txs of discarded self proposed blocks
+	proposer                 *pbac.Member
 
 	blockBuilder *common.BlockBuilder
 	storeHelper  conf.StoreHelper
+
+	pipelineWg sync.WaitGroup // wait group of speculative proposing of the next height
 }
 
 type BlockProposerConfig struct {
@@ -78,6 +84,7 @@ type BlockProposerConfig struct {
 	AC              protocol.AccessControlProvider
 	BlockchainStore protocol.BlockchainStore
 	StoreHelper     conf.StoreHelper
+	StateTree       *statetree.StateTree
 }
 
 const (
@@ -129,6 +136,12 @@ func NewBlockProposer(config BlockProposerConfig, log protocol.Logger) (protocol
 			[]float64{0.005, 0.01, 0.015, 0.05, 0.1, 1, 10},
 			"chainId",
 		)
+		blockProposerImpl.metricDiscardedTxCounter = monitor.NewCounterVec(
+			monitor.SUBSYSTEM_CORE_PROPOSER,
+			"metric_discarded_tx_counter",
+			"discarded self proposed block txs counter, re-injected or dropped",
+			"chainId", "result",
+		)
 	}
 
 	blockProposerImpl.storeHelper = config.StoreHelper
@@ -143,6 +156,7 @@ func NewBlockProposer(config BlockProposerConfig, log protocol.Logger) (protocol
 		ChainConf:       blockProposerImpl.chainConf,
 		Log:             blockProposerImpl.log,
 		StoreHelper:     config.StoreHelper,
+		StateTree:       config.StateTree,
 	}
 
 	blockProposerImpl.blockBuilder = common.NewBlockBuilder(bbConf)
@@ -249,6 +263,8 @@ func (bp *BlockProposerImpl) proposing(height uint64, preHash []byte) *commonpb.
 	startTick := utils.CurrentTimeMillisSeconds()
 	defer bp.yieldProposing()
 
+	// wait for the speculative proposing in pipelined mode, the block built is repeated if it's on top of preHash
+	bp.pipelineWg.Wait()
 	selfProposedBlock := bp.proposalCache.GetSelfProposedBlockAt(height)
 	if selfProposedBlock != nil {
 		if bytes.Equal(selfProposedBlock.Header.PreBlockHash, preHash) {
@@ -261,39 +277,29 @@ func (bp *BlockProposerImpl) proposing(height uint64, preHash []byte) *commonpb.
 			return nil
 		}
 		bp.proposalCache.ClearTheBlock(selfProposedBlock)
-		// Note: It is not possible to re-add the transactions in the deleted block to txpool; because some transactions may
-		// be included in other blocks to be confirmed, and it is impossible to quickly exclude these pending transactions
-		// that have been entered into the block. Comprehensive considerations, directly discard this block is the optimal
-		// choice. This processing method may only cause partial transaction loss at the current node, but it can be solved
-		// by rebroadcasting on the client side.
-		bp.txPool.RetryAndRemoveTxs(nil, selfProposedBlock.Txs)
+		// Txs in the discarded block may be committed in the competing block, or included in other blocks to be
+		// confirmed. Only the txs neither committed nor pending are re-injected into txpool, others are dropped.
+		// evidence txs are not from txpool, they are proposed again from the evidence pool
+		reinjectTxs, dropTxs := common.SplitDiscardedTxs(selfProposedBlock, bp.proposalCache, bp.blockchainStore,
+			func(tx *commonpb.Transaction) bool {
+				return tx.Payload.ContractName != evidence.ContractName
+			}, bp.log)
+		bp.txPool.RetryAndRemoveTxs(reinjectTxs, dropTxs)
+		txtimeline.Of(bp.chainId).RecordTxs(dropTxs, txtimeline.Dropped, height, "in discarded self proposed block")
+		bp.log.Infof("discard self proposed block [%d](txs:%d), re-inject %d txs, drop %d txs",
+			height, len(selfProposedBlock.Txs), len(reinjectTxs), len(dropTxs))
+		if localconf.ChainMakerConfig.MonitorConfig.Enabled {
+			bp.metricDiscardedTxCounter.WithLabelValues(bp.chainId, "reinjected").Add(float64(len(reinjectTxs)))
+			bp.metricDiscardedTxCounter.WithLabelValues(bp.chainId, "dropped").Add(float64(len(dropTxs)))
+		}
 	}
 
-	// retrieve tx batch from tx pool
-	startFetchTick := utils.CurrentTimeMillisSeconds()
-	fetchBatch := bp.txPool.FetchTxBatch(height)
-	fetchLasts := utils.CurrentTimeMillisSeconds() - startFetchTick
-	bp.log.Debugf("begin proposing block[%d], fetch tx num[%d]", height, len(fetchBatch))
-
-	startDupTick := utils.CurrentTimeMillisSeconds()
-	checkedBatch := bp.txDuplicateCheck(fetchBatch)
-	dupLasts := utils.CurrentTimeMillisSeconds() - startDupTick
-	if !utils.CanProposeEmptyBlock(bp.chainConf.ChainConfig().Consensus.Type) && len(checkedBatch) == 0 {
-		// can not propose empty block and tx batch is empty, then yield proposing.
-		bp.log.Debugf("no txs in tx pool, proposing block stoped")
-		bp.txPool.RetryAndRemoveTxs(nil, fetchBatch)
+	checkedBatch, fetchLasts, dupLasts, ok := bp.fetchTxBatch(height)
+	if !ok {
 		return nil
 	}
-
-	txCapacity := int(bp.chainConf.ChainConfig().Block.BlockTxCapacity)
-	if len(checkedBatch) > txCapacity {
-		// check if checkedBatch > txCapacity, if so, strict block tx count according to  config,
-		// and put other txs back to txpool.
-		txRetry := checkedBatch[txCapacity:]
-		checkedBatch = checkedBatch[:txCapacity]
-		bp.txPool.RetryAndRemoveTxs(txRetry, nil)
-		bp.log.Warnf("txbatch oversize expect <= %d, got %d", txCapacity, len(checkedBatch))
-	}
+	// evidence txs are not proposed in pipelined mode, they would repeat the ones in the parent block
+	checkedBatch = common.AddEvidenceTxs(checkedBatch, height, bp.chainConf, bp.blockchainStore, bp.identity, bp.log)
 
 	block, timeLasts, err := bp.generateNewBlock(height, preHash, checkedBatch)
 	if err != nil {
@@ -301,7 +307,7 @@ func (bp *BlockProposerImpl) proposing(height uint64, preHash []byte) *commonpb.
 		if sqlErr := bp.storeHelper.RollBack(block, bp.blockchainStore); sqlErr != nil {
 			bp.log.Errorf("block [%d] rollback sql failed: %s", block.Header.BlockHeight, sqlErr)
 		}
-		bp.txPool.RetryAndRemoveTxs(checkedBatch, nil) // put txs back to txpool
+		bp.txPool.RetryAndRemoveTxs(common.TxsToRetry(checkedBatch, err), nil) // put txs back to txpool
 		bp.log.Warnf("generate new block failed, %s", err.Error())
 		return nil
 	}
@@ -339,9 +345,67 @@ func (bp *BlockProposerImpl) proposing(height uint64, preHash []byte) *commonpb.
 	if localconf.ChainMakerConfig.MonitorConfig.Enabled {
 		bp.metricBlockPackageTime.WithLabelValues(bp.chainId).Observe(float64(elapsed) / 1000)
 	}
+	if bp.isPipelineEnabled(block) {
+		bp.pipelineWg.Add(1)
+		go bp.proposePipelined(block)
+	}
 	return block
 }
 
+// fetchTxBatch, retrieve tx batch from tx pool, then check duplicate, pack by lanes and strict by block limit.
+// Returns false if there are no txs to propose.
+func (bp *BlockProposerImpl) fetchTxBatch(height uint64) ([]*commonpb.Transaction, int64, int64, bool) {
+	// retrieve tx batch from tx pool
+	startFetchTick := utils.CurrentTimeMillisSeconds()
+	txCapacity := int(bp.chainConf.ChainConfig().Block.BlockTxCapacity)
+	lanes := common.NewTxLanes(bp.chainConf, bp.ac, bp.log)
+	fetchBatch := lanes.FetchTxBatch(bp.txPool, height, txCapacity)
+	fetchLasts := utils.CurrentTimeMillisSeconds() - startFetchTick
+	bp.log.Debugf("begin proposing block[%d], fetch tx num[%d]", height, len(fetchBatch))
+
+	startDupTick := utils.CurrentTimeMillisSeconds()
+	checkedBatch := bp.txDuplicateCheck(fetchBatch)
+	dupLasts := utils.CurrentTimeMillisSeconds() - startDupTick
+
+	// remove expired txs from txpool, the block timestamp is taken later than now,
+	// so txs expiring within one second are removed as well.
+	if common.IsTxExpiryEnabled(bp.chainConf, height) {
+		var txExpired []*commonpb.Transaction
+		checkedBatch, txExpired = common.SplitExpiredTxs(checkedBatch, height, utils.CurrentTimeSeconds()+1)
+		if len(txExpired) > 0 {
+			bp.txPool.RetryAndRemoveTxs(nil, txExpired)
+			txtimeline.Of(bp.chainId).RecordTxs(txExpired, txtimeline.Dropped, height, "expired")
+			bp.log.Infof("remove %d expired txs from txpool, block[%d]", len(txExpired), height)
+		}
+	}
+	if !utils.CanProposeEmptyBlock(bp.chainConf.ChainConfig().Consensus.Type) && len(checkedBatch) == 0 {
+		// can not propose empty block and tx batch is empty, then yield proposing.
+		bp.log.Debugf("no txs in tx pool, proposing block stoped")
+		bp.txPool.RetryAndRemoveTxs(nil, fetchBatch)
+		return nil, fetchLasts, dupLasts, false
+	}
+
+	// pack txs by priority lanes, strict block tx count according to config,
+	// and put other txs back to txpool.
+	checkedBatch, laneRetry := lanes.Pack(checkedBatch, txCapacity)
+	if len(laneRetry) > 0 {
+		bp.txPool.RetryAndRemoveTxs(laneRetry, nil)
+		bp.log.Warnf("txbatch oversize expect <= %d, got %d", txCapacity, len(checkedBatch)+len(laneRetry))
+	}
+
+	// check if checkedBatch exceeds block size limit, if so, put txs out of limit back to txpool,
+	// and remove txs which can never be packed into a block. Gas limit is checked after scheduling.
+	fitBatch, txRetry, txOversize := common.GetBlockLimit(bp.chainConf).Split(checkedBatch)
+	if len(txRetry) > 0 || len(txOversize) > 0 {
+		checkedBatch = fitBatch
+		bp.txPool.RetryAndRemoveTxs(txRetry, txOversize)
+		txtimeline.Of(bp.chainId).RecordTxs(txOversize, txtimeline.Dropped, height, "exceeds block limit")
+		bp.log.Warnf("txbatch exceeds block limit, keep %d, retry %d, remove %d",
+			len(fitBatch), len(txRetry), len(txOversize))
+	}
+	return checkedBatch, fetchLasts, dupLasts, true
+}
+
 // txDuplicateCheck, to check if transactions that are about to proposing are double spenting.
 func (bp *BlockProposerImpl) txDuplicateCheck(batch []*commonpb.Transaction) []*commonpb.Transaction {
 	if len(batch) == 0 {
@@ -13,6 +13,7 @@ import (
 
 	"chainmaker.org/chainmaker-go/core/common"
 	"chainmaker.org/chainmaker-go/core/provider/conf"
+	"chainmaker.org/chainmaker-go/core/statetree"
 
 	"chainmaker.org/chainmaker-go/consensus"
 	commonErrors "chainmaker.org/chainmaker/common/v2/errors"
@@ -63,6 +64,7 @@ type BlockVerifierConfig struct {
 	TxPool          protocol.TxPool
 	VmMgr           protocol.VmManager
 	StoreHelper     conf.StoreHelper
+	StateTree       *statetree.StateTree
 }
 
 func NewBlockVerifier(config BlockVerifierConfig, log protocol.Logger) (protocol.BlockVerifier, error) {
@@ -96,6 +98,7 @@ func NewBlockVerifier(config BlockVerifierConfig, log protocol.Logger) (protocol
 		ProposalCache:   config.ProposedCache,
 		StoreHelper:     config.StoreHelper,
 		TxScheduler:     config.TxScheduler,
+		StateTree:       config.StateTree,
 	}
 	v.verifierBlock = common.NewVerifierBlock(conf)
 
@@ -128,7 +131,6 @@ func (v *BlockVerifierImpl) VerifyBlock(block *commonpb.Block, mode protocol.Ver
 	}
 	defer v.reentrantLocks.Unlock(string(block.Header.BlockHash))
 
-	var isValid bool
 	var contractEventMap map[string][]*commonpb.ContractEvent
 	// to check if the block has verified before
 	b, txRwSet, eventMap := v.proposalCache.GetProposedBlock(block)
@@ -141,10 +143,9 @@ func (v *BlockVerifierImpl) VerifyBlock(block *commonpb.Block, mode protocol.Ver
 			elapsed := utils.CurrentTimeMillisSeconds() - startTick
 			// the block has verified before
 			v.log.Infof("verify success repeat [%d](%x), total: %d", block.Header.BlockHeight, block.Header.BlockHash, elapsed)
-			isValid = true
 			if protocol.CONSENSUS_VERIFY == mode {
 				// consensus mode, publish verify result to message bus
-				v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(block, isValid, txRwSet))
+				v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(block, nil, txRwSet))
 			}
 			lastBlock, _ := v.proposalCache.GetProposedBlockByHashAndHeight(
 				block.Header.PreBlockHash, block.Header.BlockHeight-1)
@@ -192,7 +193,7 @@ func (v *BlockVerifierImpl) VerifyBlock(block *commonpb.Block, mode protocol.Ver
 		v.log.Warnf("verify failed [%d](%x),preBlockHash:%x, %s",
 			newBlock.Header.BlockHeight, newBlock.Header.BlockHash, newBlock.Header.PreBlockHash, err.Error())
 		if protocol.CONSENSUS_VERIFY == mode {
-			v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(newBlock, isValid, txRWSetMap))
+			v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(newBlock, err, txRWSetMap))
 		}
 
 		// rollback sql
@@ -224,9 +225,8 @@ func (v *BlockVerifierImpl) VerifyBlock(block *commonpb.Block, mode protocol.Ver
 	// mark transactions in block as pending status in txpool
 	v.txPool.AddTxsToPendingCache(newBlock.Txs, newBlock.Header.BlockHeight)
 
-	isValid = true
 	if protocol.CONSENSUS_VERIFY == mode {
-		v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(newBlock, isValid, txRWSetMap))
+		v.msgBus.Publish(msgbus.VerifyResult, parseVerifyResult(newBlock, nil, txRWSetMap))
 	}
 	elapsed := utils.CurrentTimeMillisSeconds() - startTick
 	v.log.Infof("verify success [%d,%x](%v,pool: %d,consensusCheckUsed: %d, total: %d)", newBlock.Header.BlockHeight,
@@ -255,7 +255,7 @@ func (v *BlockVerifierImpl) validateBlock(block, lastBlock *commonpb.Block) (
 	proposedHeight := lastBlock.Header.BlockHeight
 	// check if this block height is 1 bigger than last block height
 	lastBlockHash := lastBlock.Header.BlockHash
-	err = common.CheckPreBlock(block, lastBlock, err, lastBlockHash, proposedHeight)
+	err = common.CheckPreBlock(block, lastBlock, err, lastBlockHash, proposedHeight, v.chainConf)
 	if err != nil {
 		return nil, nil, timeLasts, err
 	}
@@ -267,18 +267,19 @@ func (v *BlockVerifierImpl) verifyVoteSig(block *commonpb.Block) error {
 	return consensus.VerifyBlockSignatures(v.chainConf, v.ac, v.blockchainStore, block, v.ledgerCache)
 }
 
-func parseVerifyResult(block *commonpb.Block, isValid bool,
+// parseVerifyResult returns the verify result of block verified with err,
+// the code tells the reason of failure if it is known, such as common.InvalidBlockTimestampCode
+func parseVerifyResult(block *commonpb.Block, err error,
 	txsRwSet map[string]*commonpb.TxRWSet) *consensuspb.VerifyResult {
 	verifyResult := &consensuspb.VerifyResult{
 		VerifiedBlock: block,
 		TxsRwSet:      txsRwSet,
+		Code:          common.VerifyResultCode(err),
 	}
-	if isValid {
-		verifyResult.Code = consensuspb.VerifyResult_SUCCESS
+	if err == nil {
 		verifyResult.Msg = "OK"
 	} else {
 		verifyResult.Msg = "FAIL"
-		verifyResult.Code = consensuspb.VerifyResult_FAIL
 	}
 	return verifyResult
 }
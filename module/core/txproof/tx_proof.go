@@ -0,0 +1,171 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package txproof builds and verifies the proof that a tx is committed in a block. A proof holds
+// the block header, the merkle path from the tx hash to the tx root of header, and the consensus
+// commit certificate of the block, so it can be checked with only a trusted chain config.
+package txproof
+
+import (
+	"bytes"
+	"fmt"
+
+	"chainmaker.org/chainmaker-go/consensus/tbft"
+	"chainmaker.org/chainmaker-go/core/common"
+	"chainmaker.org/chainmaker/common/v2/crypto/hash"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+// certificateKeys are the keys in block additional data of the commit certificate, the precommits
+// or the aggregate commit of TBFT and DPoS consensus
+var certificateKeys = []string{protocol.TBFTAddtionalDataKey, tbft.TBFTAggregateCommitKey}
+
+// TxProof proves that Tx is committed in the block of Header
+type TxProof struct {
+	Header *commonpb.BlockHeader `json:"header"`
+	Tx     *commonpb.Transaction `json:"tx"`
+	// TxIndex is the index of tx in block, which decides the side of each sibling in MerklePath
+	TxIndex uint32 `json:"tx_index"`
+	// MerklePath are the sibling hashes from the tx hash up to the tx root
+	MerklePath [][]byte `json:"merkle_path"`
+	// AdditionalData holds the commit certificate of the block, only for TBFT and DPoS consensus
+	AdditionalData *commonpb.AdditionalData `json:"additional_data,omitempty"`
+}
+
+// BuildTxProof builds the proof of a committed tx from store
+func BuildTxProof(store protocol.BlockchainStore, txId string, hashType string) (*TxProof, error) {
+	block, err := store.GetBlockByTx(txId)
+	if err != nil {
+		return nil, fmt.Errorf("get block of tx[%s] failed, %s", txId, err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("tx[%s] not found", txId)
+	}
+
+	proof := &TxProof{Header: block.Header}
+	txHashes := make([][]byte, len(block.Txs))
+	found := false
+	for i, tx := range block.Txs {
+		if txHashes[i], err = utils.CalcTxHash(hashType, tx); err != nil {
+			return nil, err
+		}
+		if tx.Payload.TxId == txId {
+			proof.Tx = tx
+			proof.TxIndex = uint32(i)
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("tx[%s] not found in block[%d]", txId, block.Header.BlockHeight)
+	}
+	if err = common.IsMerkleRootValid(block, txHashes, hashType); err != nil {
+		return nil, err
+	}
+	if proof.MerklePath, err = merklePath(hashType, txHashes, int(proof.TxIndex)); err != nil {
+		return nil, err
+	}
+
+	if block.AdditionalData != nil {
+		for _, key := range certificateKeys {
+			if data, ok := block.AdditionalData.ExtraData[key]; ok {
+				if proof.AdditionalData == nil {
+					proof.AdditionalData = &commonpb.AdditionalData{ExtraData: make(map[string][]byte)}
+				}
+				proof.AdditionalData.ExtraData[key] = data
+			}
+		}
+	}
+	return proof, nil
+}
+
+// VerifyTxProof checks that the tx of proof is committed by the validators of the trusted chain config,
+// with the same checks as the nodes run on the synced blocks. The validators and weights are read from
+// chainConf, ac verifies the signatures of the certificate, store is only needed by DPoS consensus.
+func VerifyTxProof(proof *TxProof, chainConf protocol.ChainConf, ac protocol.AccessControlProvider,
+	store protocol.BlockchainStore) error {
+	if proof == nil || proof.Header == nil || proof.Tx == nil || proof.Tx.Payload == nil {
+		return fmt.Errorf("invalid proof")
+	}
+	hashType := chainConf.ChainConfig().Crypto.Hash
+	header := proof.Header
+	if proof.TxIndex >= header.TxCount {
+		return fmt.Errorf("tx index expect < %d, got %d", header.TxCount, proof.TxIndex)
+	}
+	txHash, err := utils.CalcTxHash(hashType, proof.Tx)
+	if err != nil {
+		return err
+	}
+	if err = verifyMerklePath(hashType, txHash, proof.TxIndex, proof.MerklePath, header.TxRoot); err != nil {
+		return err
+	}
+	blockHash, err := utils.CalcBlockHash(hashType, &commonpb.Block{Header: header})
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(blockHash, header.BlockHash) {
+		return fmt.Errorf("block hash expect %x, got %x", header.BlockHash, blockHash)
+	}
+	return tbft.VerifyBlockSignatures(chainConf, ac,
+		&commonpb.Block{Header: header, AdditionalData: proof.AdditionalData}, store)
+}
+
+// merklePath returns the sibling hashes of the leaf at index, in the merkle tree of hash.GetMerkleRoot,
+// where an entry without right sibling is hashed with itself.
+func merklePath(hashType string, hashes [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(hashes) {
+		return nil, fmt.Errorf("index expect < %d, got %d", len(hashes), index)
+	}
+	var path [][]byte
+	level := hashes
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			path = append(path, level[sibling])
+		} else {
+			path = append(path, level[index])
+		}
+		next := make([][]byte, (len(level)+1)/2)
+		for i := range next {
+			left := level[2*i]
+			right := left
+			if 2*i+1 < len(level) {
+				right = level[2*i+1]
+			}
+			var err error
+			if next[i], err = hash.GetByStrType(hashType, append(append([]byte{}, left...), right...)); err != nil {
+				return nil, err
+			}
+		}
+		level = next
+		index /= 2
+	}
+	return path, nil
+}
+
+// verifyMerklePath checks the merkle path from leaf at index to root, the tree of IsMerkleRootValid
+// walked along one tx
+func verifyMerklePath(hashType string, leaf []byte, index uint32, path [][]byte, root []byte) error {
+	current := leaf
+	for _, sibling := range path {
+		data := make([]byte, 0, len(current)+len(sibling))
+		if index%2 == 0 {
+			data = append(append(data, current...), sibling...)
+		} else {
+			data = append(append(data, sibling...), current...)
+		}
+		var err error
+		if current, err = hash.GetByStrType(hashType, data); err != nil {
+			return err
+		}
+		index /= 2
+	}
+	if !bytes.Equal(current, root) {
+		return fmt.Errorf("txroot expect %x, got %x", root, current)
+	}
+	return nil
+}
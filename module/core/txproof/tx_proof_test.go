@@ -0,0 +1,95 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txproof
+
+import (
+	"fmt"
+	"testing"
+
+	"chainmaker.org/chainmaker-go/consensus/tbft"
+	"chainmaker.org/chainmaker/common/v2/crypto/hash"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"chainmaker.org/chainmaker/utils/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerklePath(t *testing.T) {
+	for _, count := range []int{1, 2, 3, 5, 8, 11} {
+		hashes := make([][]byte, count)
+		for i := range hashes {
+			var err error
+			hashes[i], err = hash.GetByStrType("SHA256", []byte(fmt.Sprintf("tx%d", i)))
+			require.NoError(t, err)
+		}
+		root, err := hash.GetMerkleRoot("SHA256", hashes)
+		require.NoError(t, err)
+
+		for i := range hashes {
+			path, err := merklePath("SHA256", hashes, i)
+			require.NoError(t, err)
+			require.NoError(t, verifyMerklePath("SHA256", hashes[i], uint32(i), path, root),
+				"count %d index %d", count, i)
+			if i^1 < count {
+				require.Error(t, verifyMerklePath("SHA256", hashes[i], uint32(i^1), path, root))
+			}
+		}
+	}
+
+	_, err := merklePath("SHA256", make([][]byte, 2), 2)
+	require.Error(t, err)
+}
+
+func TestBuildTxProof(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	block := &commonpb.Block{
+		Header: &commonpb.BlockHeader{BlockHeight: 3, TxCount: 3},
+		AdditionalData: &commonpb.AdditionalData{ExtraData: map[string][]byte{
+			protocol.TBFTAddtionalDataKey: []byte("votes"),
+			"other":                       []byte("other"),
+		}},
+	}
+	var txHashes [][]byte
+	for i := 0; i < 3; i++ {
+		tx := &commonpb.Transaction{Payload: &commonpb.Payload{TxId: fmt.Sprintf("tx%d", i)}}
+		txHash, err := utils.CalcTxHash("SHA256", tx)
+		require.NoError(t, err)
+		block.Txs = append(block.Txs, tx)
+		txHashes = append(txHashes, txHash)
+	}
+	var err error
+	block.Header.TxRoot, err = hash.GetMerkleRoot("SHA256", txHashes)
+	require.NoError(t, err)
+
+	store := mock.NewMockBlockchainStore(ctrl)
+	store.EXPECT().GetBlockByTx(gomock.Any()).Return(block, nil).AnyTimes()
+
+	proof, err := BuildTxProof(store, "tx2", "SHA256")
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), proof.TxIndex)
+	require.NoError(t, verifyMerklePath("SHA256", txHashes[2], 2, proof.MerklePath, block.Header.TxRoot))
+	// only the commit certificate is kept
+	require.Equal(t, map[string][]byte{protocol.TBFTAddtionalDataKey: []byte("votes")}, proof.AdditionalData.ExtraData)
+	// the aggregate commit instead of the precommits
+	delete(block.AdditionalData.ExtraData, protocol.TBFTAddtionalDataKey)
+	block.AdditionalData.ExtraData[tbft.TBFTAggregateCommitKey] = []byte("aggregate")
+	proof, err = BuildTxProof(store, "tx1", "SHA256")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{tbft.TBFTAggregateCommitKey: []byte("aggregate")}, proof.AdditionalData.ExtraData)
+
+	_, err = BuildTxProof(store, "tx3", "SHA256")
+	require.Error(t, err)
+
+	// the tx root of block mismatches its txs
+	block.Header.TxRoot = txHashes[0]
+	_, err = BuildTxProof(store, "tx2", "SHA256")
+	require.Error(t, err)
+}
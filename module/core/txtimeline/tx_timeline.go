@@ -0,0 +1,153 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package txtimeline records the lifecycle events of txs seen by this node, from rpc received
+// to committed or dropped, in a bounded memory store per chain.
+package txtimeline
+
+import (
+	"sync"
+
+	"chainmaker.org/chainmaker/localconf/v2"
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+// EventType is the type of tx lifecycle event
+type EventType string
+
+const (
+	RpcReceived  EventType = "RPC_RECEIVED"  // tx received by rpc server
+	P2pReceived  EventType = "P2P_RECEIVED"  // tx received by broadcast from other nodes
+	PoolAdmitted EventType = "POOL_ADMITTED" // tx added into txpool
+	PoolRejected EventType = "POOL_REJECTED" // tx rejected before or by txpool
+	Proposed     EventType = "PROPOSED"      // tx fetched into a block proposed by this node
+	Verified     EventType = "VERIFIED"      // the block of tx verified by this node
+	Committed    EventType = "COMMITTED"     // the block of tx committed
+	Dropped      EventType = "DROPPED"       // tx removed from txpool without committed
+)
+
+const (
+	defaultMaxTxs      = 100000 // max txs kept, the oldest are evicted
+	defaultMaxTxEvents = 32     // max events kept for one tx
+)
+
+var timelines sync.Map // chain id => *Timeline
+
+// Register creates the timeline of chain if it does not exist, and returns it. It is called when the
+// tx pool of chain is created, so there is no timeline of the chains not on this node.
+func Register(chainId string) *Timeline {
+	if t := Of(chainId); t != nil {
+		return t
+	}
+	t, _ := timelines.LoadOrStore(chainId, NewTimeline(defaultMaxTxs))
+	return t.(*Timeline)
+}
+
+// Of returns the timeline of chain, nil if it is not registered, on which the events are not recorded
+func Of(chainId string) *Timeline {
+	if t, ok := timelines.Load(chainId); ok {
+		return t.(*Timeline)
+	}
+	return nil
+}
+
+// Event is a lifecycle event of tx
+type Event struct {
+	Type      EventType `json:"type"`
+	Timestamp int64     `json:"timestamp"` // unix millis
+	NodeId    string    `json:"node_id"`
+	Height    uint64    `json:"height,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// Timeline keeps the events of the latest txs
+type Timeline struct {
+	mu     sync.RWMutex
+	size   int
+	events map[string][]*Event // tx id => events in order
+	txIds  []string            // ring of tx ids in first seen order, for eviction
+	next   int
+}
+
+// NewTimeline creates a timeline which keeps the events of at most size txs
+func NewTimeline(size int) *Timeline {
+	return &Timeline{
+		size:   size,
+		events: make(map[string][]*Event),
+		txIds:  make([]string, size),
+	}
+}
+
+// Record records an event of tx, height is 0 if it's not in a block
+func (t *Timeline) Record(txId string, eventType EventType, height uint64, reason string) {
+	if t == nil {
+		return
+	}
+	event := &Event{
+		Type:      eventType,
+		Timestamp: utils.CurrentTimeMillisSeconds(),
+		NodeId:    localconf.ChainMakerConfig.NodeConfig.NodeId,
+		Height:    height,
+		Reason:    reason,
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.add(txId, event)
+}
+
+// RecordTxs records the same event of txs
+func (t *Timeline) RecordTxs(txs []*commonpb.Transaction, eventType EventType, height uint64, reason string) {
+	if t == nil || len(txs) == 0 {
+		return
+	}
+	timestamp := utils.CurrentTimeMillisSeconds()
+	nodeId := localconf.ChainMakerConfig.NodeConfig.NodeId
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tx := range txs {
+		t.add(tx.Payload.TxId, &Event{
+			Type:      eventType,
+			Timestamp: timestamp,
+			NodeId:    nodeId,
+			Height:    height,
+			Reason:    reason,
+		})
+	}
+}
+
+// Get returns the events of tx in order, nil if tx is not seen or evicted
+func (t *Timeline) Get(txId string) []*Event {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	events := t.events[txId]
+	if events == nil {
+		return nil
+	}
+	result := make([]*Event, len(events))
+	copy(result, events)
+	return result
+}
+
+func (t *Timeline) add(txId string, event *Event) {
+	if t.size <= 0 {
+		return
+	}
+	events, ok := t.events[txId]
+	if !ok {
+		delete(t.events, t.txIds[t.next])
+		t.txIds[t.next] = txId
+		t.next = (t.next + 1) % t.size
+	}
+	if len(events) >= defaultMaxTxEvents {
+		// keep the first event, which tells where the tx comes from
+		events = append(events[:1], events[2:]...)
+	}
+	t.events[txId] = append(events, event)
+}
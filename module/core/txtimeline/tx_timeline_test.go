@@ -0,0 +1,48 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txtimeline
+
+import (
+	"testing"
+
+	commonpb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline(t *testing.T) {
+	timeline := NewTimeline(2)
+	timeline.Record("tx1", RpcReceived, 0, "")
+	timeline.Record("tx1", PoolAdmitted, 0, "")
+	timeline.RecordTxs([]*commonpb.Transaction{{Payload: &commonpb.Payload{TxId: "tx1"}}}, Proposed, 5, "")
+	events := timeline.Get("tx1")
+	require.Equal(t, 3, len(events))
+	require.Equal(t, RpcReceived, events[0].Type)
+	require.Equal(t, Proposed, events[2].Type)
+	require.Equal(t, uint64(5), events[2].Height)
+
+	// the first seen tx is evicted
+	timeline.Record("tx2", PoolRejected, 0, "tx duplicate")
+	timeline.Record("tx3", RpcReceived, 0, "")
+	require.Nil(t, timeline.Get("tx1"))
+	require.Equal(t, "tx duplicate", timeline.Get("tx2")[0].Reason)
+
+	// the first event is kept when the events of tx exceed the limit
+	for i := 0; i < defaultMaxTxEvents+5; i++ {
+		timeline.Record("tx3", Verified, uint64(i), "")
+	}
+	events = timeline.Get("tx3")
+	require.Equal(t, defaultMaxTxEvents, len(events))
+	require.Equal(t, RpcReceived, events[0].Type)
+	require.Equal(t, uint64(defaultMaxTxEvents+4), events[len(events)-1].Height)
+
+	// the events of an unregistered chain are not recorded
+	require.Nil(t, Of("chain1"))
+	Of("chain1").Record("tx1", RpcReceived, 0, "")
+	require.Nil(t, Of("chain1").Get("tx1"))
+	require.Same(t, Register("chain1"), Of("chain1"))
+	require.Same(t, Register("chain1"), Register("chain1"))
+}
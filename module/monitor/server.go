@@ -12,6 +12,8 @@ import (
 	"net"
 	"net/http"
 
+	"chainmaker.org/chainmaker-go/consensus/raft"
+	"chainmaker.org/chainmaker-go/consensus/tbft"
 	"chainmaker.org/chainmaker/localconf/v2"
 	"chainmaker.org/chainmaker/logger/v2"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -28,6 +30,8 @@ func NewMonitorServer() *MonitorServer {
 	if localconf.ChainMakerConfig.MonitorConfig.Enabled {
 		mux := http.NewServeMux()
 		mux.Handle("/metrics", promhttp.Handler())
+		mux.Handle("/debug/tbft", tbft.DebugHandler())
+		mux.Handle("/debug/raft", raft.StatusHandler())
 		return &MonitorServer{
 			httpServer: &http.Server{
 				Handler: mux,
@@ -3,6 +3,7 @@ module chainmaker.org/chainmaker-go/core
 go 1.15
 
 require (
+	chainmaker.org/chainmaker-go/upgrade v0.0.0
 	chainmaker.org/chainmaker/common/v2 v2.1.0
 	chainmaker.org/chainmaker/logger/v2 v2.1.0
 	chainmaker.org/chainmaker/net-common v1.0.1
@@ -22,4 +23,7 @@ require (
 	honnef.co/go/tools v0.1.3 // indirect
 )
 
-replace github.com/libp2p/go-libp2p-core => chainmaker.org/chainmaker/libp2p-core v1.0.0
+replace (
+	chainmaker.org/chainmaker-go/upgrade => ../upgrade
+	github.com/libp2p/go-libp2p-core => chainmaker.org/chainmaker/libp2p-core v1.0.0
+)
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 
+	"chainmaker.org/chainmaker-go/upgrade/compat"
 	"chainmaker.org/chainmaker/common/v2/msgbus"
 	rootLog "chainmaker.org/chainmaker/logger/v2"
 	"chainmaker.org/chainmaker/net-common/common/priorityblocker"
@@ -62,6 +63,7 @@ type NetService struct {
 	ac            protocol.AccessControlProvider
 	revokeNodeIds sync.Map // nolint: structcheck,unused // node id of node cert revoked , map[string]struct{}
 	vmWatcher     *VmWatcher
+	chainConf     protocol.ChainConf // chain config, nil if not set by NetServiceFactory
 }
 
 // NewNetService create a new net service instance.
@@ -576,12 +578,30 @@ func CreateMsgHandlerForMsgBus(
 			)
 			return nil
 		}
+		if topic == msgbus.RecvConsensusMsg && !netService.isConsensusPeerAllowed(node) {
+			netService.logger.Warnf("[NetService/%s handler for msg-bus] drop msg of incompatible peer %s",
+				logMsgDescription,
+				node,
+			)
+			return nil
+		}
 		pbMsg := NewNetMsg(data, msgType, node)
 		netService.msgBus.Publish(topic, pbMsg)
 		return nil
 	}
 }
 
+// isConsensusPeerAllowed, to check if the consensus msgs from node can be accepted.
+// Under compat.PolicyReject, the msgs of the peers incompatible with this node are dropped here for all
+// consensus engines. Each node drops by its own view of the peer versions, which is an omission as if
+// the peer were offline, so the nodes never count the votes of a block differently.
+func (ns *NetService) isConsensusPeerAllowed(node string) bool {
+	if ns.chainConf == nil {
+		return true
+	}
+	return compat.Of(ns.chainId).IsAllowed(ns.chainConf.ChainConfig(), node)
+}
+
 // bindMsgBus bind a msgbus.MessageBus.
 func (ns *NetService) bindMsgBus(bus msgbus.MessageBus) error {
 	if ns.msgBus != nil {
@@ -27,6 +27,7 @@ func (nsf *NetServiceFactory) NewNetService(
 		return nil, err
 	}
 	if chainConf != nil {
+		ns.chainConf = chainConf
 		if err := nsf.setAllConsensusNodeIds(ns, chainConf); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,70 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: common/result_ext.proto
+
+package common
+
+import (
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// TxStatusCodeExt are the tx status codes of chainmaker-go beyond common.TxStatusCode of pb-go,
+// reported as common.TxStatusCode(value). The values start from 100 to keep out of the pb-go ones.
+type TxStatusCodeExt int32
+
+const (
+	TxStatusCodeExt_TX_STATUS_CODE_EXT_NONE TxStatusCodeExt = 0
+	// the tx is packed or submitted after its expiration time
+	TxStatusCodeExt_TX_EXPIRED TxStatusCodeExt = 100
+)
+
+var TxStatusCodeExt_name = map[int32]string{
+	0:   "TX_STATUS_CODE_EXT_NONE",
+	100: "TX_EXPIRED",
+}
+
+var TxStatusCodeExt_value = map[string]int32{
+	"TX_STATUS_CODE_EXT_NONE": 0,
+	"TX_EXPIRED":              100,
+}
+
+func (x TxStatusCodeExt) String() string {
+	return proto.EnumName(TxStatusCodeExt_name, int32(x))
+}
+
+func (TxStatusCodeExt) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_772ce0dc7ef75ef1, []int{0}
+}
+
+func init() {
+	proto.RegisterEnum("common.TxStatusCodeExt", TxStatusCodeExt_name, TxStatusCodeExt_value)
+}
+
+func init() { proto.RegisterFile("common/result_ext.proto", fileDescriptor_772ce0dc7ef75ef1) }
+
+var fileDescriptor_772ce0dc7ef75ef1 = []byte{
+	// 175 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x4f, 0xce, 0xcf, 0xcd,
+	0xcd, 0xcf, 0xd3, 0x2f, 0x4a, 0x2d, 0x2e, 0xcd, 0x29, 0x89, 0x4f, 0xad, 0x28, 0xd1, 0x2b, 0x28,
+	0xca, 0x2f, 0xc9, 0x17, 0x62, 0x83, 0x48, 0x68, 0xd9, 0x71, 0xf1, 0x87, 0x54, 0x04, 0x97, 0x24,
+	0x96, 0x94, 0x16, 0x3b, 0xe7, 0xa7, 0xa4, 0xba, 0x56, 0x94, 0x08, 0x49, 0x73, 0x89, 0x87, 0x44,
+	0xc4, 0x07, 0x87, 0x38, 0x86, 0x84, 0x06, 0xc7, 0x3b, 0xfb, 0xbb, 0xb8, 0xc6, 0xbb, 0x46, 0x84,
+	0xc4, 0xfb, 0xf9, 0xfb, 0xb9, 0x0a, 0x30, 0x08, 0xf1, 0x71, 0x71, 0x85, 0x44, 0xc4, 0xbb, 0x46,
+	0x04, 0x78, 0x06, 0xb9, 0xba, 0x08, 0xa4, 0x38, 0x39, 0x9c, 0x78, 0x24, 0xc7, 0x78, 0xe1, 0x91,
+	0x1c, 0xe3, 0x83, 0x47, 0x72, 0x8c, 0x13, 0x1e, 0xcb, 0x31, 0x5c, 0x78, 0x2c, 0xc7, 0x70, 0xe3,
+	0xb1, 0x1c, 0x43, 0x94, 0x5a, 0x72, 0x46, 0x62, 0x66, 0x5e, 0x6e, 0x62, 0x76, 0x6a, 0x91, 0x5e,
+	0x7e, 0x51, 0xba, 0x3e, 0x82, 0xab, 0x9b, 0x9e, 0xaf, 0x5f, 0x90, 0xa4, 0x0f, 0x71, 0x41, 0x12,
+	0x1b, 0xd8, 0x41, 0xc6, 0x80, 0x01, 0x00, 0x5a, 0x6a, 0x23, 0x60, 0xab, 0x00, 0x00, 0x00,
+}
@@ -0,0 +1,376 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: common/state_ext.proto
+
+package common
+
+import (
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// StateCommitment commits a block to its write sets and the world state after it. From the activation
+// height of state_root, the rwset root in block header is the hash of the marshaled StateCommitment,
+// instead of the merkle root of the write sets of txs.
+type StateCommitment struct {
+	// the merkle root of the write sets of txs in block
+	RwSetTxRoot []byte `protobuf:"bytes,1,opt,name=rw_set_tx_root,json=rwSetTxRoot,proto3" json:"rw_set_tx_root,omitempty"`
+	// the root of the state tree after the block
+	StateRoot []byte `protobuf:"bytes,2,opt,name=state_root,json=stateRoot,proto3" json:"state_root,omitempty"`
+}
+
+func (m *StateCommitment) Reset()         { *m = StateCommitment{} }
+func (m *StateCommitment) String() string { return proto.CompactTextString(m) }
+func (*StateCommitment) ProtoMessage()    {}
+func (*StateCommitment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_001c62684b712156, []int{0}
+}
+func (m *StateCommitment) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *StateCommitment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StateCommitment.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *StateCommitment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateCommitment.Merge(m, src)
+}
+func (m *StateCommitment) XXX_Size() int {
+	return m.Size()
+}
+func (m *StateCommitment) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateCommitment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateCommitment proto.InternalMessageInfo
+
+func (m *StateCommitment) GetRwSetTxRoot() []byte {
+	if m != nil {
+		return m.RwSetTxRoot
+	}
+	return nil
+}
+
+func (m *StateCommitment) GetStateRoot() []byte {
+	if m != nil {
+		return m.StateRoot
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*StateCommitment)(nil), "common.StateCommitment")
+}
+
+func init() { proto.RegisterFile("common/state_ext.proto", fileDescriptor_001c62684b712156) }
+
+var fileDescriptor_001c62684b712156 = []byte{
+	// 179 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x4b, 0xce, 0xcf, 0xcd,
+	0xcd, 0xcf, 0xd3, 0x2f, 0x2e, 0x49, 0x2c, 0x49, 0x8d, 0x4f, 0xad, 0x28, 0xd1, 0x2b, 0x28, 0xca,
+	0x2f, 0xc9, 0x17, 0x62, 0x83, 0x88, 0x2b, 0x85, 0x72, 0xf1, 0x07, 0x83, 0xa4, 0x9c, 0xf3, 0x73,
+	0x73, 0x33, 0x4b, 0x72, 0x53, 0xf3, 0x4a, 0x84, 0x94, 0xb9, 0xf8, 0x8a, 0xca, 0xe3, 0x8b, 0x53,
+	0x4b, 0xe2, 0x4b, 0x2a, 0xe2, 0x8b, 0xf2, 0xf3, 0x4b, 0x24, 0x18, 0x15, 0x18, 0x35, 0x78, 0x82,
+	0xb8, 0x8b, 0xca, 0x83, 0x53, 0x4b, 0x42, 0x2a, 0x82, 0xf2, 0xf3, 0x4b, 0x84, 0x64, 0xb9, 0xb8,
+	0x20, 0x46, 0x82, 0x15, 0x30, 0x81, 0x15, 0x70, 0x82, 0x45, 0x40, 0xd2, 0x4e, 0x0e, 0x27, 0x1e,
+	0xc9, 0x31, 0x5e, 0x78, 0x24, 0xc7, 0xf8, 0xe0, 0x91, 0x1c, 0xe3, 0x84, 0xc7, 0x72, 0x0c, 0x17,
+	0x1e, 0xcb, 0x31, 0xdc, 0x78, 0x2c, 0xc7, 0x10, 0xa5, 0x96, 0x9c, 0x91, 0x98, 0x99, 0x97, 0x9b,
+	0x98, 0x9d, 0x5a, 0xa4, 0x97, 0x5f, 0x94, 0xae, 0x8f, 0xe0, 0xea, 0xa6, 0xe7, 0xeb, 0x17, 0x24,
+	0xe9, 0x43, 0x1c, 0x96, 0xc4, 0x06, 0x76, 0xa7, 0x31, 0x60, 0x00, 0x52, 0x15, 0x04, 0x97, 0xc1,
+	0x00, 0x00, 0x00,
+}
+
+func (m *StateCommitment) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StateCommitment) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StateCommitment) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.StateRoot) > 0 {
+		i -= len(m.StateRoot)
+		copy(dAtA[i:], m.StateRoot)
+		i = encodeVarintStateExt(dAtA, i, uint64(len(m.StateRoot)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.RwSetTxRoot) > 0 {
+		i -= len(m.RwSetTxRoot)
+		copy(dAtA[i:], m.RwSetTxRoot)
+		i = encodeVarintStateExt(dAtA, i, uint64(len(m.RwSetTxRoot)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintStateExt(dAtA []byte, offset int, v uint64) int {
+	offset -= sovStateExt(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *StateCommitment) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.RwSetTxRoot)
+	if l > 0 {
+		n += 1 + l + sovStateExt(uint64(l))
+	}
+	l = len(m.StateRoot)
+	if l > 0 {
+		n += 1 + l + sovStateExt(uint64(l))
+	}
+	return n
+}
+
+func sovStateExt(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozStateExt(x uint64) (n int) {
+	return sovStateExt(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *StateCommitment) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStateExt
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StateCommitment: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StateCommitment: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RwSetTxRoot", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStateExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStateExt
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStateExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RwSetTxRoot = append(m.RwSetTxRoot[:0], dAtA[iNdEx:postIndex]...)
+			if m.RwSetTxRoot == nil {
+				m.RwSetTxRoot = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StateRoot", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStateExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStateExt
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStateExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StateRoot = append(m.StateRoot[:0], dAtA[iNdEx:postIndex]...)
+			if m.StateRoot == nil {
+				m.StateRoot = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStateExt(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStateExt
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipStateExt(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowStateExt
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowStateExt
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowStateExt
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthStateExt
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupStateExt
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthStateExt
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthStateExt        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowStateExt          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupStateExt = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,1021 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: consensus/chainedbft/chainedbft_ext.proto
+
+package chainedbft
+
+import (
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// MessageTypeExt are the chained-BFT message types of chainmaker-go beyond chainedbft.MessageType of pb-go,
+// sent as chainedbft.MessageType(value) in a chainedbft.ConsensusMsg followed by ConsensusMsgExt.
+// The nodes which do not know a type ignore the message.
+type MessageTypeExt int32
+
+const (
+	MessageTypeExt_MESSAGE_TYPE_EXT_NONE MessageTypeExt = 0
+	// the msg carries a QCSignature
+	MessageTypeExt_QC_SIGNATURE_MESSAGE MessageTypeExt = 100
+)
+
+var MessageTypeExt_name = map[int32]string{
+	0:   "MESSAGE_TYPE_EXT_NONE",
+	100: "QC_SIGNATURE_MESSAGE",
+}
+
+var MessageTypeExt_value = map[string]int32{
+	"MESSAGE_TYPE_EXT_NONE": 0,
+	"QC_SIGNATURE_MESSAGE":  100,
+}
+
+func (x MessageTypeExt) String() string {
+	return proto.EnumName(MessageTypeExt_name, int32(x))
+}
+
+func (MessageTypeExt) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_520d19f3091214e9, []int{0}
+}
+
+// ConsensusMsgExt are the fields of a consensus msg beyond chainedbft.ConsensusMsg of pb-go. The msg is sent as
+// chainedbft.ConsensusMsg in protobuf followed by ConsensusMsgExt in protobuf, which is still
+// a chainedbft.ConsensusMsg on the wire as the field numbers are out of the ones of chainedbft.ConsensusMsg.
+type ConsensusMsgExt struct {
+	QcSignature *QCSignature `protobuf:"bytes,100,opt,name=qc_signature,json=qcSignature,proto3" json:"qc_signature,omitempty"`
+}
+
+func (m *ConsensusMsgExt) Reset()         { *m = ConsensusMsgExt{} }
+func (m *ConsensusMsgExt) String() string { return proto.CompactTextString(m) }
+func (*ConsensusMsgExt) ProtoMessage()    {}
+func (*ConsensusMsgExt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_520d19f3091214e9, []int{0}
+}
+func (m *ConsensusMsgExt) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ConsensusMsgExt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ConsensusMsgExt.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ConsensusMsgExt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConsensusMsgExt.Merge(m, src)
+}
+func (m *ConsensusMsgExt) XXX_Size() int {
+	return m.Size()
+}
+func (m *ConsensusMsgExt) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConsensusMsgExt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConsensusMsgExt proto.InternalMessageInfo
+
+func (m *ConsensusMsgExt) GetQcSignature() *QCSignature {
+	if m != nil {
+		return m.QcSignature
+	}
+	return nil
+}
+
+// QCSignature is the BLS signature of author on the QC of the block of block_id, sent to the other validators
+// besides its vote
+type QCSignature struct {
+	AuthorIdx uint64 `protobuf:"varint,1,opt,name=author_idx,json=authorIdx,proto3" json:"author_idx,omitempty"`
+	Author    string `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	Height    uint64 `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Level     uint64 `protobuf:"varint,4,opt,name=level,proto3" json:"level,omitempty"`
+	EpochId   uint64 `protobuf:"varint,5,opt,name=epoch_id,json=epochId,proto3" json:"epoch_id,omitempty"`
+	BlockId   []byte `protobuf:"bytes,6,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Signature []byte `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *QCSignature) Reset()         { *m = QCSignature{} }
+func (m *QCSignature) String() string { return proto.CompactTextString(m) }
+func (*QCSignature) ProtoMessage()    {}
+func (*QCSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_520d19f3091214e9, []int{1}
+}
+func (m *QCSignature) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *QCSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_QCSignature.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *QCSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QCSignature.Merge(m, src)
+}
+func (m *QCSignature) XXX_Size() int {
+	return m.Size()
+}
+func (m *QCSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_QCSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QCSignature proto.InternalMessageInfo
+
+func (m *QCSignature) GetAuthorIdx() uint64 {
+	if m != nil {
+		return m.AuthorIdx
+	}
+	return 0
+}
+
+func (m *QCSignature) GetAuthor() string {
+	if m != nil {
+		return m.Author
+	}
+	return ""
+}
+
+func (m *QCSignature) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *QCSignature) GetLevel() uint64 {
+	if m != nil {
+		return m.Level
+	}
+	return 0
+}
+
+func (m *QCSignature) GetEpochId() uint64 {
+	if m != nil {
+		return m.EpochId
+	}
+	return 0
+}
+
+func (m *QCSignature) GetBlockId() []byte {
+	if m != nil {
+		return m.BlockId
+	}
+	return nil
+}
+
+func (m *QCSignature) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// AggregateQC certifies the QC of a block, stored in the block with the QC without votes
+type AggregateQC struct {
+	// the bitmap of the signers by their indexes in the validators of the epoch
+	Signers []byte `protobuf:"bytes,1,opt,name=signers,proto3" json:"signers,omitempty"`
+	// the aggregate of the QC signatures of signers
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *AggregateQC) Reset()         { *m = AggregateQC{} }
+func (m *AggregateQC) String() string { return proto.CompactTextString(m) }
+func (*AggregateQC) ProtoMessage()    {}
+func (*AggregateQC) Descriptor() ([]byte, []int) {
+	return fileDescriptor_520d19f3091214e9, []int{2}
+}
+func (m *AggregateQC) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AggregateQC) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AggregateQC.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AggregateQC) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AggregateQC.Merge(m, src)
+}
+func (m *AggregateQC) XXX_Size() int {
+	return m.Size()
+}
+func (m *AggregateQC) XXX_DiscardUnknown() {
+	xxx_messageInfo_AggregateQC.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AggregateQC proto.InternalMessageInfo
+
+func (m *AggregateQC) GetSigners() []byte {
+	if m != nil {
+		return m.Signers
+	}
+	return nil
+}
+
+func (m *AggregateQC) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("chainedbft.MessageTypeExt", MessageTypeExt_name, MessageTypeExt_value)
+	proto.RegisterType((*ConsensusMsgExt)(nil), "chainedbft.ConsensusMsgExt")
+	proto.RegisterType((*QCSignature)(nil), "chainedbft.QCSignature")
+	proto.RegisterType((*AggregateQC)(nil), "chainedbft.AggregateQC")
+}
+
+func init() {
+	proto.RegisterFile("consensus/chainedbft/chainedbft_ext.proto", fileDescriptor_520d19f3091214e9)
+}
+
+var fileDescriptor_520d19f3091214e9 = []byte{
+	// 386 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0x41, 0x6f, 0xd3, 0x30,
+	0x14, 0xc7, 0xeb, 0xb1, 0xb5, 0xf4, 0xb5, 0x82, 0xc9, 0x1a, 0xe0, 0x49, 0x10, 0x55, 0x3d, 0x15,
+	0x24, 0x5a, 0x09, 0x38, 0x71, 0x2b, 0x95, 0x35, 0xe5, 0x90, 0x40, 0x93, 0x20, 0x01, 0x17, 0x2b,
+	0x89, 0x8d, 0x13, 0xad, 0xc4, 0x59, 0xec, 0xa2, 0xf0, 0x2d, 0xf8, 0x54, 0x88, 0xe3, 0x8e, 0x1c,
+	0x51, 0xfb, 0x45, 0x50, 0xdc, 0x96, 0x0c, 0xb4, 0x9b, 0x7f, 0xff, 0x9f, 0xdf, 0x93, 0x9e, 0xfd,
+	0xe0, 0x69, 0xaa, 0x0a, 0x2d, 0x0a, 0xbd, 0xd6, 0xb3, 0x34, 0x8b, 0xf3, 0x42, 0xf0, 0xe4, 0xb3,
+	0xb9, 0x71, 0x64, 0xa2, 0x36, 0xd3, 0xb2, 0x52, 0x46, 0x61, 0x68, 0xd3, 0xb1, 0x07, 0xf7, 0x17,
+	0x87, 0x42, 0x4f, 0x4b, 0x5a, 0x1b, 0xfc, 0x1a, 0x86, 0x57, 0x29, 0xd3, 0xb9, 0x2c, 0x62, 0xb3,
+	0xae, 0x04, 0xe1, 0x23, 0x34, 0x19, 0xbc, 0x78, 0x34, 0x6d, 0xab, 0xa6, 0xcb, 0x45, 0x78, 0xd0,
+	0xc1, 0xe0, 0x2a, 0xfd, 0x0b, 0xe3, 0x1f, 0x08, 0x06, 0x37, 0x24, 0x7e, 0x02, 0x10, 0xaf, 0x4d,
+	0xa6, 0x2a, 0x96, 0xf3, 0x9a, 0xa0, 0x11, 0x9a, 0x1c, 0x07, 0xfd, 0x5d, 0xe2, 0xf2, 0x1a, 0x3f,
+	0x84, 0xee, 0x0e, 0xc8, 0xd1, 0x08, 0x4d, 0xfa, 0xc1, 0x9e, 0x9a, 0x3c, 0x13, 0xb9, 0xcc, 0x0c,
+	0xb9, 0x63, 0x4b, 0xf6, 0x84, 0xcf, 0xe0, 0x64, 0x25, 0xbe, 0x8a, 0x15, 0x39, 0xb6, 0xf1, 0x0e,
+	0xf0, 0x39, 0xdc, 0x15, 0xa5, 0x4a, 0x33, 0x96, 0x73, 0x72, 0x62, 0x45, 0xcf, 0xb2, 0xcb, 0x1b,
+	0x95, 0xac, 0x54, 0x7a, 0xd9, 0xa8, 0xee, 0x08, 0x4d, 0x86, 0x41, 0xcf, 0xb2, 0xcb, 0xf1, 0x63,
+	0xe8, 0xb7, 0x33, 0xf6, 0xac, 0x6b, 0x83, 0x31, 0x85, 0xc1, 0x5c, 0xca, 0x4a, 0xc8, 0xd8, 0x88,
+	0xe5, 0x02, 0x13, 0xe8, 0x35, 0x4e, 0x54, 0xda, 0x0e, 0x31, 0x0c, 0x0e, 0xf8, 0x6f, 0x9b, 0xa3,
+	0xff, 0xda, 0x3c, 0xa3, 0x70, 0xcf, 0x13, 0x5a, 0xc7, 0x52, 0x44, 0xdf, 0x4a, 0xd1, 0xbc, 0xee,
+	0x39, 0x3c, 0xf0, 0x68, 0x18, 0xce, 0x2f, 0x28, 0x8b, 0x3e, 0xbe, 0xa3, 0x8c, 0x7e, 0x88, 0x98,
+	0xff, 0xd6, 0xa7, 0xa7, 0x1d, 0x4c, 0xe0, 0x6c, 0xb9, 0x60, 0xa1, 0x7b, 0xe1, 0xcf, 0xa3, 0xf7,
+	0x01, 0x65, 0xfb, 0x7b, 0xa7, 0xfc, 0x8d, 0xff, 0x73, 0xe3, 0xa0, 0xeb, 0x8d, 0x83, 0x7e, 0x6f,
+	0x1c, 0xf4, 0x7d, 0xeb, 0x74, 0xae, 0xb7, 0x4e, 0xe7, 0xd7, 0xd6, 0xe9, 0x7c, 0x7a, 0x65, 0x7f,
+	0xe5, 0x4b, 0x7c, 0x29, 0xaa, 0xa9, 0xaa, 0xe4, 0xac, 0xc5, 0xe7, 0x52, 0xcd, 0xca, 0x64, 0x76,
+	0xdb, 0x5a, 0x24, 0x5d, 0xbb, 0x08, 0x2f, 0xff, 0x0c, 0x00, 0xba, 0x78, 0xab, 0x1b, 0x35, 0x02,
+	0x00, 0x00,
+}
+
+func (m *ConsensusMsgExt) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ConsensusMsgExt) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ConsensusMsgExt) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.QcSignature != nil {
+		{
+			size, err := m.QcSignature.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintChainedbftExt(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x6
+		i--
+		dAtA[i] = 0xa2
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *QCSignature) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *QCSignature) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *QCSignature) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Signature) > 0 {
+		i -= len(m.Signature)
+		copy(dAtA[i:], m.Signature)
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(len(m.Signature)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if len(m.BlockId) > 0 {
+		i -= len(m.BlockId)
+		copy(dAtA[i:], m.BlockId)
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(len(m.BlockId)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.EpochId != 0 {
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(m.EpochId))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Level != 0 {
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(m.Level))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Height != 0 {
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Author) > 0 {
+		i -= len(m.Author)
+		copy(dAtA[i:], m.Author)
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(len(m.Author)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.AuthorIdx != 0 {
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(m.AuthorIdx))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AggregateQC) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AggregateQC) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AggregateQC) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Signature) > 0 {
+		i -= len(m.Signature)
+		copy(dAtA[i:], m.Signature)
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(len(m.Signature)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Signers) > 0 {
+		i -= len(m.Signers)
+		copy(dAtA[i:], m.Signers)
+		i = encodeVarintChainedbftExt(dAtA, i, uint64(len(m.Signers)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintChainedbftExt(dAtA []byte, offset int, v uint64) int {
+	offset -= sovChainedbftExt(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *ConsensusMsgExt) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.QcSignature != nil {
+		l = m.QcSignature.Size()
+		n += 2 + l + sovChainedbftExt(uint64(l))
+	}
+	return n
+}
+
+func (m *QCSignature) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.AuthorIdx != 0 {
+		n += 1 + sovChainedbftExt(uint64(m.AuthorIdx))
+	}
+	l = len(m.Author)
+	if l > 0 {
+		n += 1 + l + sovChainedbftExt(uint64(l))
+	}
+	if m.Height != 0 {
+		n += 1 + sovChainedbftExt(uint64(m.Height))
+	}
+	if m.Level != 0 {
+		n += 1 + sovChainedbftExt(uint64(m.Level))
+	}
+	if m.EpochId != 0 {
+		n += 1 + sovChainedbftExt(uint64(m.EpochId))
+	}
+	l = len(m.BlockId)
+	if l > 0 {
+		n += 1 + l + sovChainedbftExt(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovChainedbftExt(uint64(l))
+	}
+	return n
+}
+
+func (m *AggregateQC) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Signers)
+	if l > 0 {
+		n += 1 + l + sovChainedbftExt(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovChainedbftExt(uint64(l))
+	}
+	return n
+}
+
+func sovChainedbftExt(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozChainedbftExt(x uint64) (n int) {
+	return sovChainedbftExt(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *ConsensusMsgExt) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChainedbftExt
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ConsensusMsgExt: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ConsensusMsgExt: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 100:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QcSignature", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.QcSignature == nil {
+				m.QcSignature = &QCSignature{}
+			}
+			if err := m.QcSignature.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChainedbftExt(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *QCSignature) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChainedbftExt
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: QCSignature: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: QCSignature: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthorIdx", wireType)
+			}
+			m.AuthorIdx = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AuthorIdx |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Author", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Author = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Level", wireType)
+			}
+			m.Level = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Level |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EpochId", wireType)
+			}
+			m.EpochId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.EpochId |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockId", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlockId = append(m.BlockId[:0], dAtA[iNdEx:postIndex]...)
+			if m.BlockId == nil {
+				m.BlockId = []byte{}
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChainedbftExt(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AggregateQC) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowChainedbftExt
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AggregateQC: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AggregateQC: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signers", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signers = append(m.Signers[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signers == nil {
+				m.Signers = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipChainedbftExt(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthChainedbftExt
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipChainedbftExt(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowChainedbftExt
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowChainedbftExt
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthChainedbftExt
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupChainedbftExt
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthChainedbftExt
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthChainedbftExt        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowChainedbftExt          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupChainedbftExt = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,77 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: consensus/raft/raft_ext.proto
+
+package raft
+
+import (
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// MessageTypeExt are the raft message types of chainmaker-go beyond raftpb.MessageType of etcd raft,
+// sent as raftpb.MessageType(value) in a raftpb.Message. They are handled by chainmaker-go before
+// the message is stepped into etcd raft.
+type MessageTypeExt int32
+
+const (
+	MessageTypeExt_MESSAGE_TYPE_EXT_NONE MessageTypeExt = 0
+	// requests the committed blocks from Index to install a snapshot
+	MessageTypeExt_MSG_SNAP_CHUNK_REQUEST MessageTypeExt = 100
+	// responds a MSG_SNAP_CHUNK_REQUEST with the blocks in Entries, Reject if there is none
+	MessageTypeExt_MSG_SNAP_CHUNK MessageTypeExt = 101
+)
+
+var MessageTypeExt_name = map[int32]string{
+	0:   "MESSAGE_TYPE_EXT_NONE",
+	100: "MSG_SNAP_CHUNK_REQUEST",
+	101: "MSG_SNAP_CHUNK",
+}
+
+var MessageTypeExt_value = map[string]int32{
+	"MESSAGE_TYPE_EXT_NONE":  0,
+	"MSG_SNAP_CHUNK_REQUEST": 100,
+	"MSG_SNAP_CHUNK":         101,
+}
+
+func (x MessageTypeExt) String() string {
+	return proto.EnumName(MessageTypeExt_name, int32(x))
+}
+
+func (MessageTypeExt) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_4f3716dbfc4c719c, []int{0}
+}
+
+func init() {
+	proto.RegisterEnum("raft.MessageTypeExt", MessageTypeExt_name, MessageTypeExt_value)
+}
+
+func init() { proto.RegisterFile("consensus/raft/raft_ext.proto", fileDescriptor_4f3716dbfc4c719c) }
+
+var fileDescriptor_4f3716dbfc4c719c = []byte{
+	// 194 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0x4d, 0xce, 0xcf, 0x2b,
+	0x4e, 0xcd, 0x2b, 0x2e, 0x2d, 0xd6, 0x2f, 0x4a, 0x4c, 0x2b, 0x01, 0x13, 0xf1, 0xa9, 0x15, 0x25,
+	0x7a, 0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42, 0x2c, 0x20, 0xbe, 0x56, 0x34, 0x17, 0x9f, 0x6f, 0x6a,
+	0x71, 0x71, 0x62, 0x7a, 0x6a, 0x48, 0x65, 0x41, 0xaa, 0x6b, 0x45, 0x89, 0x90, 0x24, 0x97, 0xa8,
+	0xaf, 0x6b, 0x70, 0xb0, 0xa3, 0xbb, 0x6b, 0x7c, 0x48, 0x64, 0x80, 0x6b, 0xbc, 0x6b, 0x44, 0x48,
+	0xbc, 0x9f, 0xbf, 0x9f, 0xab, 0x00, 0x83, 0x90, 0x14, 0x97, 0x98, 0x6f, 0xb0, 0x7b, 0x7c, 0xb0,
+	0x9f, 0x63, 0x40, 0xbc, 0xb3, 0x47, 0xa8, 0x9f, 0x77, 0x7c, 0x90, 0x6b, 0x60, 0xa8, 0x6b, 0x70,
+	0x88, 0x40, 0x8a, 0x90, 0x10, 0x17, 0x1f, 0xaa, 0x9c, 0x40, 0xaa, 0x93, 0xc7, 0x89, 0x47, 0x72,
+	0x8c, 0x17, 0x1e, 0xc9, 0x31, 0x3e, 0x78, 0x24, 0xc7, 0x38, 0xe1, 0xb1, 0x1c, 0xc3, 0x85, 0xc7,
+	0x72, 0x0c, 0x37, 0x1e, 0xcb, 0x31, 0x44, 0xe9, 0x25, 0x67, 0x24, 0x66, 0xe6, 0xe5, 0x26, 0x66,
+	0xa7, 0x16, 0xe9, 0xe5, 0x17, 0xa5, 0xeb, 0x23, 0xb8, 0xba, 0xe9, 0xf9, 0xfa, 0x05, 0x49, 0xfa,
+	0xa8, 0x6e, 0x4f, 0x62, 0x03, 0xbb, 0xd9, 0x18, 0x30, 0x00, 0xcc, 0xaf, 0x23, 0x66, 0xd4, 0x00,
+	0x00, 0x00,
+}
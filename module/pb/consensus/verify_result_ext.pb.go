@@ -0,0 +1,72 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: consensus/verify_result_ext.proto
+
+package consensus
+
+import (
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// VerifyResultCodeExt are the block verify result codes of chainmaker-go beyond consensus.VerifyResult.Code
+// of pb-go, reported as consensus.VerifyResult_Code(value). Any code other than SUCCESS rejects the block.
+type VerifyResultCodeExt int32
+
+const (
+	VerifyResultCodeExt_VERIFY_RESULT_CODE_EXT_NONE VerifyResultCodeExt = 0
+	// the block timestamp breaks the block timestamp rule of chain config
+	VerifyResultCodeExt_INVALID_BLOCK_TIMESTAMP VerifyResultCodeExt = 100
+)
+
+var VerifyResultCodeExt_name = map[int32]string{
+	0:   "VERIFY_RESULT_CODE_EXT_NONE",
+	100: "INVALID_BLOCK_TIMESTAMP",
+}
+
+var VerifyResultCodeExt_value = map[string]int32{
+	"VERIFY_RESULT_CODE_EXT_NONE": 0,
+	"INVALID_BLOCK_TIMESTAMP":     100,
+}
+
+func (x VerifyResultCodeExt) String() string {
+	return proto.EnumName(VerifyResultCodeExt_name, int32(x))
+}
+
+func (VerifyResultCodeExt) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_bf7590f69ec9f734, []int{0}
+}
+
+func init() {
+	proto.RegisterEnum("consensus.VerifyResultCodeExt", VerifyResultCodeExt_name, VerifyResultCodeExt_value)
+}
+
+func init() { proto.RegisterFile("consensus/verify_result_ext.proto", fileDescriptor_bf7590f69ec9f734) }
+
+var fileDescriptor_bf7590f69ec9f734 = []byte{
+	// 200 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x52, 0x4c, 0xce, 0xcf, 0x2b,
+	0x4e, 0xcd, 0x2b, 0x2e, 0x2d, 0xd6, 0x2f, 0x4b, 0x2d, 0xca, 0x4c, 0xab, 0x8c, 0x2f, 0x4a, 0x2d,
+	0x2e, 0xcd, 0x29, 0x89, 0x4f, 0xad, 0x28, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x84,
+	0x2b, 0xd1, 0x0a, 0xe6, 0x12, 0x0e, 0x03, 0xab, 0x0a, 0x02, 0x2b, 0x72, 0xce, 0x4f, 0x49, 0x75,
+	0xad, 0x28, 0x11, 0x92, 0xe7, 0x92, 0x0e, 0x73, 0x0d, 0xf2, 0x74, 0x8b, 0x8c, 0x0f, 0x72, 0x0d,
+	0x0e, 0xf5, 0x09, 0x89, 0x77, 0xf6, 0x77, 0x71, 0x8d, 0x77, 0x8d, 0x08, 0x89, 0xf7, 0xf3, 0xf7,
+	0x73, 0x15, 0x60, 0x10, 0x92, 0xe6, 0x12, 0xf7, 0xf4, 0x0b, 0x73, 0xf4, 0xf1, 0x74, 0x89, 0x77,
+	0xf2, 0xf1, 0x77, 0xf6, 0x8e, 0x0f, 0xf1, 0xf4, 0x75, 0x0d, 0x0e, 0x71, 0xf4, 0x0d, 0x10, 0x48,
+	0x71, 0x72, 0x3e, 0xf1, 0x48, 0x8e, 0xf1, 0xc2, 0x23, 0x39, 0xc6, 0x07, 0x8f, 0xe4, 0x18, 0x27,
+	0x3c, 0x96, 0x63, 0xb8, 0xf0, 0x58, 0x8e, 0xe1, 0xc6, 0x63, 0x39, 0x86, 0x28, 0xcd, 0xe4, 0x8c,
+	0xc4, 0xcc, 0xbc, 0xdc, 0xc4, 0xec, 0xd4, 0x22, 0xbd, 0xfc, 0xa2, 0x74, 0x7d, 0x04, 0x57, 0x37,
+	0x3d, 0x5f, 0xbf, 0x20, 0x49, 0x1f, 0xee, 0xb2, 0x24, 0x36, 0xb0, 0x5b, 0x8d, 0x01, 0x03, 0x00,
+	0x2d, 0x46, 0x2d, 0xe2, 0xd0, 0x00, 0x00, 0x00,
+}
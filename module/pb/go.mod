@@ -0,0 +1,5 @@
+module chainmaker.org/chainmaker-go/pb
+
+go 1.15
+
+require github.com/gogo/protobuf v1.3.2
@@ -0,0 +1,654 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: sync/node_status_ext.proto
+
+package sync
+
+import (
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// BlockHeightBCMExt is sync.BlockHeightBCM of pb-go with the version of node, sent as the node status.
+// Both messages are compatible on the wire, the nodes before version exchange skip node_version as an
+// unknown field, and node_version is absent in the status from those nodes.
+type BlockHeightBCMExt struct {
+	BlockHeight    uint64       `protobuf:"varint,1,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	ArchivedHeight uint64       `protobuf:"varint,2,opt,name=archived_height,json=archivedHeight,proto3" json:"archived_height,omitempty"`
+	NodeVersion    *NodeVersion `protobuf:"bytes,1000,opt,name=node_version,json=nodeVersion,proto3" json:"node_version,omitempty"`
+}
+
+func (m *BlockHeightBCMExt) Reset()         { *m = BlockHeightBCMExt{} }
+func (m *BlockHeightBCMExt) String() string { return proto.CompactTextString(m) }
+func (*BlockHeightBCMExt) ProtoMessage()    {}
+func (*BlockHeightBCMExt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a95361731ad43992, []int{0}
+}
+func (m *BlockHeightBCMExt) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BlockHeightBCMExt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BlockHeightBCMExt.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BlockHeightBCMExt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockHeightBCMExt.Merge(m, src)
+}
+func (m *BlockHeightBCMExt) XXX_Size() int {
+	return m.Size()
+}
+func (m *BlockHeightBCMExt) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockHeightBCMExt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockHeightBCMExt proto.InternalMessageInfo
+
+func (m *BlockHeightBCMExt) GetBlockHeight() uint64 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *BlockHeightBCMExt) GetArchivedHeight() uint64 {
+	if m != nil {
+		return m.ArchivedHeight
+	}
+	return 0
+}
+
+func (m *BlockHeightBCMExt) GetNodeVersion() *NodeVersion {
+	if m != nil {
+		return m.NodeVersion
+	}
+	return nil
+}
+
+// NodeVersion is the version of the binary of a node
+type NodeVersion struct {
+	// the protocol version of the binary
+	Protocol uint32 `protobuf:"varint,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// the lowest protocol version of peers compatible with the binary
+	MinProtocol uint32 `protobuf:"varint,2,opt,name=min_protocol,json=minProtocol,proto3" json:"min_protocol,omitempty"`
+	// names of the activation features known by the binary, sorted
+	Features []string `protobuf:"bytes,3,rep,name=features,proto3" json:"features,omitempty"`
+}
+
+func (m *NodeVersion) Reset()         { *m = NodeVersion{} }
+func (m *NodeVersion) String() string { return proto.CompactTextString(m) }
+func (*NodeVersion) ProtoMessage()    {}
+func (*NodeVersion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a95361731ad43992, []int{1}
+}
+func (m *NodeVersion) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *NodeVersion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_NodeVersion.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *NodeVersion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NodeVersion.Merge(m, src)
+}
+func (m *NodeVersion) XXX_Size() int {
+	return m.Size()
+}
+func (m *NodeVersion) XXX_DiscardUnknown() {
+	xxx_messageInfo_NodeVersion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NodeVersion proto.InternalMessageInfo
+
+func (m *NodeVersion) GetProtocol() uint32 {
+	if m != nil {
+		return m.Protocol
+	}
+	return 0
+}
+
+func (m *NodeVersion) GetMinProtocol() uint32 {
+	if m != nil {
+		return m.MinProtocol
+	}
+	return 0
+}
+
+func (m *NodeVersion) GetFeatures() []string {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*BlockHeightBCMExt)(nil), "sync.BlockHeightBCMExt")
+	proto.RegisterType((*NodeVersion)(nil), "sync.NodeVersion")
+}
+
+func init() { proto.RegisterFile("sync/node_status_ext.proto", fileDescriptor_a95361731ad43992) }
+
+var fileDescriptor_a95361731ad43992 = []byte{
+	// 275 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0x2a, 0xae, 0xcc, 0x4b,
+	0xd6, 0xcf, 0xcb, 0x4f, 0x49, 0x8d, 0x2f, 0x2e, 0x49, 0x2c, 0x29, 0x2d, 0x8e, 0x4f, 0xad, 0x28,
+	0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x01, 0xc9, 0x29, 0x4d, 0x63, 0xe4, 0x12, 0x74,
+	0xca, 0xc9, 0x4f, 0xce, 0xf6, 0x48, 0xcd, 0x4c, 0xcf, 0x28, 0x71, 0x72, 0xf6, 0x75, 0xad, 0x28,
+	0x11, 0x52, 0xe4, 0xe2, 0x49, 0x02, 0x09, 0xc6, 0x67, 0x80, 0x45, 0x25, 0x18, 0x15, 0x18, 0x35,
+	0x58, 0x82, 0xb8, 0x93, 0x10, 0x0a, 0x85, 0xd4, 0xb9, 0xf8, 0x13, 0x8b, 0x92, 0x33, 0x32, 0xcb,
+	0x52, 0x53, 0x60, 0xaa, 0x98, 0xc0, 0xaa, 0xf8, 0x60, 0xc2, 0x50, 0x85, 0xa6, 0x5c, 0x3c, 0x60,
+	0x07, 0x94, 0xa5, 0x16, 0x15, 0x67, 0xe6, 0xe7, 0x49, 0xbc, 0x60, 0x57, 0x60, 0xd4, 0xe0, 0x36,
+	0x12, 0xd4, 0x03, 0xd9, 0xaf, 0xe7, 0x97, 0x9f, 0x92, 0x1a, 0x06, 0x91, 0x09, 0xe2, 0xce, 0x43,
+	0x70, 0x94, 0x32, 0xb8, 0xb8, 0x91, 0xe4, 0x84, 0xa4, 0xb8, 0x38, 0xc0, 0xce, 0x4e, 0xce, 0xcf,
+	0x01, 0xbb, 0x86, 0x37, 0x08, 0xce, 0x07, 0xb9, 0x36, 0x37, 0x33, 0x2f, 0x1e, 0x2e, 0xcf, 0x04,
+	0x96, 0xe7, 0xce, 0xcd, 0xcc, 0x0b, 0x80, 0x29, 0x91, 0xe2, 0xe2, 0x48, 0x4b, 0x4d, 0x2c, 0x29,
+	0x2d, 0x4a, 0x2d, 0x96, 0x60, 0x56, 0x60, 0xd6, 0xe0, 0x0c, 0x82, 0xf3, 0x9d, 0xec, 0x4e, 0x3c,
+	0x92, 0x63, 0xbc, 0xf0, 0x48, 0x8e, 0xf1, 0xc1, 0x23, 0x39, 0xc6, 0x09, 0x8f, 0xe5, 0x18, 0x2e,
+	0x3c, 0x96, 0x63, 0xb8, 0xf1, 0x58, 0x8e, 0x21, 0x4a, 0x25, 0x39, 0x23, 0x31, 0x33, 0x2f, 0x37,
+	0x31, 0x3b, 0xb5, 0x48, 0x2f, 0xbf, 0x28, 0x5d, 0x1f, 0xc1, 0xd5, 0x4d, 0xcf, 0xd7, 0x2f, 0x48,
+	0xd2, 0x07, 0x79, 0x21, 0x89, 0x0d, 0x6c, 0xb1, 0x31, 0x60, 0x00, 0xd7, 0x9a, 0x8b, 0x41, 0x6d,
+	0x01, 0x00, 0x00,
+}
+
+func (m *BlockHeightBCMExt) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BlockHeightBCMExt) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BlockHeightBCMExt) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.NodeVersion != nil {
+		{
+			size, err := m.NodeVersion.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintNodeStatusExt(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x3e
+		i--
+		dAtA[i] = 0xc2
+	}
+	if m.ArchivedHeight != 0 {
+		i = encodeVarintNodeStatusExt(dAtA, i, uint64(m.ArchivedHeight))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.BlockHeight != 0 {
+		i = encodeVarintNodeStatusExt(dAtA, i, uint64(m.BlockHeight))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *NodeVersion) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *NodeVersion) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *NodeVersion) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Features) > 0 {
+		for iNdEx := len(m.Features) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Features[iNdEx])
+			copy(dAtA[i:], m.Features[iNdEx])
+			i = encodeVarintNodeStatusExt(dAtA, i, uint64(len(m.Features[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.MinProtocol != 0 {
+		i = encodeVarintNodeStatusExt(dAtA, i, uint64(m.MinProtocol))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Protocol != 0 {
+		i = encodeVarintNodeStatusExt(dAtA, i, uint64(m.Protocol))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintNodeStatusExt(dAtA []byte, offset int, v uint64) int {
+	offset -= sovNodeStatusExt(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *BlockHeightBCMExt) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.BlockHeight != 0 {
+		n += 1 + sovNodeStatusExt(uint64(m.BlockHeight))
+	}
+	if m.ArchivedHeight != 0 {
+		n += 1 + sovNodeStatusExt(uint64(m.ArchivedHeight))
+	}
+	if m.NodeVersion != nil {
+		l = m.NodeVersion.Size()
+		n += 2 + l + sovNodeStatusExt(uint64(l))
+	}
+	return n
+}
+
+func (m *NodeVersion) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Protocol != 0 {
+		n += 1 + sovNodeStatusExt(uint64(m.Protocol))
+	}
+	if m.MinProtocol != 0 {
+		n += 1 + sovNodeStatusExt(uint64(m.MinProtocol))
+	}
+	if len(m.Features) > 0 {
+		for _, s := range m.Features {
+			l = len(s)
+			n += 1 + l + sovNodeStatusExt(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovNodeStatusExt(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozNodeStatusExt(x uint64) (n int) {
+	return sovNodeStatusExt(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *BlockHeightBCMExt) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNodeStatusExt
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BlockHeightBCMExt: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BlockHeightBCMExt: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockHeight", wireType)
+			}
+			m.BlockHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNodeStatusExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BlockHeight |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ArchivedHeight", wireType)
+			}
+			m.ArchivedHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNodeStatusExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ArchivedHeight |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 1000:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NodeVersion", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNodeStatusExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthNodeStatusExt
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthNodeStatusExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.NodeVersion == nil {
+				m.NodeVersion = &NodeVersion{}
+			}
+			if err := m.NodeVersion.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNodeStatusExt(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthNodeStatusExt
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *NodeVersion) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowNodeStatusExt
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: NodeVersion: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: NodeVersion: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocol", wireType)
+			}
+			m.Protocol = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNodeStatusExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Protocol |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinProtocol", wireType)
+			}
+			m.MinProtocol = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNodeStatusExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinProtocol |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Features", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowNodeStatusExt
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthNodeStatusExt
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthNodeStatusExt
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Features = append(m.Features, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipNodeStatusExt(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthNodeStatusExt
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipNodeStatusExt(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowNodeStatusExt
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowNodeStatusExt
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowNodeStatusExt
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthNodeStatusExt
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupNodeStatusExt
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthNodeStatusExt
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthNodeStatusExt        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowNodeStatusExt          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupNodeStatusExt = fmt.Errorf("proto: unexpected end of group")
+)
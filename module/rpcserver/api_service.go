@@ -14,6 +14,8 @@ import (
 	"fmt"
 
 	"chainmaker.org/chainmaker-go/blockchain"
+	"chainmaker.org/chainmaker-go/core/common"
+	"chainmaker.org/chainmaker-go/core/txtimeline"
 	commonErr "chainmaker.org/chainmaker/common/v2/errors"
 	"chainmaker.org/chainmaker/common/v2/monitor"
 	"chainmaker.org/chainmaker/localconf/v2"
@@ -110,11 +112,12 @@ func (s *ApiService) SendRequest(ctx context.Context, req *commonPb.TxRequest) (
 // validate tx
 func (s *ApiService) validate(tx *commonPb.Transaction) (errCode commonErr.ErrCode, errMsg string) {
 	var (
-		err error
-		bc  *blockchain.Blockchain
+		err       error
+		bc        *blockchain.Blockchain
+		chainConf protocol.ChainConf
 	)
 
-	_, err = s.chainMakerServer.GetChainConf(tx.Payload.ChainId)
+	chainConf, err = s.chainMakerServer.GetChainConf(tx.Payload.ChainId)
 	if err != nil {
 		errCode = commonErr.ERR_CODE_GET_CHAIN_CONF
 		errMsg = s.getErrMsg(errCode, err)
@@ -130,7 +133,8 @@ func (s *ApiService) validate(tx *commonPb.Transaction) (errCode commonErr.ErrCo
 		return
 	}
 
-	if err = utils.VerifyTxWithoutPayload(tx, tx.Payload.ChainId, bc.GetAccessControl()); err != nil {
+	// cache the verified tx, so it is not verified again in block verification
+	if err = common.TxSigVerifierOf(tx.Payload.ChainId).Verify(tx, chainConf, bc.GetAccessControl()); err != nil {
 		errCode = commonErr.ERR_CODE_TX_VERIFY_FAILED
 		errMsg = fmt.Sprintf("%s, %s, txId:%s, sender:%s", errCode.String(), err.Error(), tx.Payload.TxId,
 			hex.EncodeToString(tx.Sender.Signer.MemberInfo))
@@ -167,6 +171,9 @@ func (s *ApiService) invoke(tx *commonPb.Transaction, source protocol.TxSource)
 	case commonPb.TxType_QUERY_CONTRACT:
 		return s.dealQuery(tx, source)
 	case commonPb.TxType_INVOKE_CONTRACT:
+		// recorded after the tx is validated, so the txs of unknown chains or with invalid signatures,
+		// which anyone can send, do not evict the events of the others
+		txtimeline.Of(tx.Payload.ChainId).Record(tx.Payload.TxId, txtimeline.RpcReceived, 0, "")
 		return s.dealTransact(tx, source)
 	case commonPb.TxType_ARCHIVE:
 		return s.doArchive(tx)
@@ -215,6 +222,26 @@ func (s *ApiService) dealQuery(tx *commonPb.Transaction, source protocol.TxSourc
 		return s.dealSystemChainQuery(tx, vmMgr)
 	}
 
+	if isStateProofQuery(tx) {
+		return s.dealStateProofQuery(tx)
+	}
+
+	if isTxProofQuery(tx) {
+		return s.dealTxProofQuery(tx)
+	}
+
+	if isTxTimelineQuery(tx) {
+		return s.dealTxTimelineQuery(tx)
+	}
+
+	if isRaftTransferRequest(tx) {
+		return s.dealRaftTransferRequest(tx)
+	}
+
+	if isEvidenceQuery(tx) {
+		return s.dealEvidenceQuery(tx)
+	}
+
 	ctx := &txQuerySimContextImpl{
 		tx:               tx,
 		txReadKeyMap:     map[string]*commonPb.TxRead{},
@@ -368,6 +395,14 @@ func (s *ApiService) dealTransact(tx *commonPb.Transaction, source protocol.TxSo
 		resp    = &commonPb.TxResponse{TxId: tx.Payload.TxId}
 	)
 
+	if err = s.checkTxExpiry(tx); err != nil {
+		s.log.Warnf("reject tx, %s", err.Error())
+		txtimeline.Of(tx.Payload.ChainId).Record(tx.Payload.TxId, txtimeline.PoolRejected, 0, err.Error())
+		resp.Code = common.TxExpiryStatusCode
+		resp.Message = err.Error()
+		return resp
+	}
+
 	err = s.chainMakerServer.AddTx(tx.Payload.ChainId, tx, source)
 
 	s.incInvokeCounter(tx.Payload.ChainId, err)
@@ -394,6 +429,23 @@ func (s *ApiService) dealTransact(tx *commonPb.Transaction, source protocol.TxSo
 	return resp
 }
 
+// checkTxExpiry, to check if tx has expired before the next block of chain
+func (s *ApiService) checkTxExpiry(tx *commonPb.Transaction) error {
+	store, err := s.chainMakerServer.GetStore(tx.Payload.ChainId)
+	if err != nil {
+		return nil
+	}
+	chainConf, err := s.chainMakerServer.GetChainConf(tx.Payload.ChainId)
+	if err != nil {
+		return nil
+	}
+	lastBlock, err := store.GetLastBlock()
+	if err != nil || !common.IsTxExpiryEnabled(chainConf, lastBlock.Header.BlockHeight+1) {
+		return nil
+	}
+	return common.IsTxExpired(tx, lastBlock.Header.BlockHeight+1, utils.CurrentTimeSeconds())
+}
+
 func (s *ApiService) incInvokeCounter(chainId string, err error) {
 	if localconf.ChainMakerConfig.MonitorConfig.Enabled {
 		if err == nil {
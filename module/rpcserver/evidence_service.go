@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) BABEC. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package rpcserver
+
+import (
+	"encoding/json"
+
+	"chainmaker.org/chainmaker-go/consensus/evidence"
+	commonErr "chainmaker.org/chainmaker/common/v2/errors"
+	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+)
+
+const (
+	// GET_EVIDENCE is the method of CHAIN_QUERY contract, which returns the equivocation evidence
+	// of TBFT validators recorded on chain, in json
+	GET_EVIDENCE = "GET_EVIDENCE"
+
+	// evidence query parameter, "true" to return the evidence pending in this node instead
+	evidenceParamPending = "PENDING"
+)
+
+// isEvidenceQuery returns true if tx queries the equivocation evidence
+func isEvidenceQuery(tx *commonPb.Transaction) bool {
+	return tx.Payload.ContractName == syscontract.SystemContract_CHAIN_QUERY.String() &&
+		tx.Payload.Method == GET_EVIDENCE
+}
+
+// dealEvidenceQuery - deal equivocation evidence query
+func (s *ApiService) dealEvidenceQuery(tx *commonPb.Transaction) *commonPb.TxResponse {
+	resp := &commonPb.TxResponse{TxId: tx.Payload.TxId}
+	result, err := s.getEvidence(tx)
+	if err != nil {
+		errMsg := s.getErrMsg(commonErr.ERR_CODE_INVOKE_CONTRACT, err)
+		s.log.Warn(errMsg)
+		resp.Code = commonPb.TxStatusCode_CONTRACT_FAIL
+		resp.Message = errMsg
+		resp.ContractResult = &commonPb.ContractResult{Code: 1, Message: err.Error()}
+		return resp
+	}
+
+	resp.Code = commonPb.TxStatusCode_SUCCESS
+	resp.Message = commonPb.TxStatusCode_SUCCESS.String()
+	resp.ContractResult = &commonPb.ContractResult{Result: result}
+	return resp
+}
+
+func (s *ApiService) getEvidence(tx *commonPb.Transaction) ([]byte, error) {
+	params := s.kvPair2Map(tx.Payload.Parameters)
+	if string(params[evidenceParamPending]) == "true" {
+		return json.Marshal(evidence.Of(tx.Payload.ChainId).Pending())
+	}
+
+	store, err := s.chainMakerServer.GetStore(tx.Payload.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	list, err := evidence.List(store)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(list)
+}
@@ -4,6 +4,8 @@ go 1.15
 
 require (
 	chainmaker.org/chainmaker-go/blockchain v0.0.0
+	chainmaker.org/chainmaker-go/consensus v0.0.0
+	chainmaker.org/chainmaker-go/core v0.0.0
 	chainmaker.org/chainmaker-go/subscriber v0.0.0
 	chainmaker.org/chainmaker/common/v2 v2.1.0
 	chainmaker.org/chainmaker/localconf/v2 v2.1.0
@@ -26,10 +28,12 @@ replace (
 	chainmaker.org/chainmaker-go/consensus => ../consensus
 	chainmaker.org/chainmaker-go/core => ../core
 	chainmaker.org/chainmaker-go/net => ../net
+	chainmaker.org/chainmaker-go/pb => ../pb
 	chainmaker.org/chainmaker-go/snapshot => ../snapshot
 	chainmaker.org/chainmaker-go/subscriber => ../subscriber
 	chainmaker.org/chainmaker-go/sync => ../sync
 	chainmaker.org/chainmaker-go/txpool => ../txpool
+	chainmaker.org/chainmaker-go/upgrade => ../upgrade
 	chainmaker.org/chainmaker-go/vm => ../vm
 	github.com/libp2p/go-libp2p-core => chainmaker.org/chainmaker/libp2p-core v1.0.0
 	google.golang.org/grpc v1.40.0 => google.golang.org/grpc v1.26.0
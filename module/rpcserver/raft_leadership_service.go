@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) BABEC. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package rpcserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"chainmaker.org/chainmaker-go/consensus/raft"
+	commonErr "chainmaker.org/chainmaker/common/v2/errors"
+	"chainmaker.org/chainmaker/localconf/v2"
+	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/utils/v2"
+)
+
+const (
+	// TRANSFER_RAFT_LEADERSHIP is the method of CHAIN_QUERY contract, which transfers the raft leadership
+	// of the chain away from this node, and returns the transferee in json. It is sent to the leader by an
+	// admin of the org of the node, to drain the leader for maintenance.
+	TRANSFER_RAFT_LEADERSHIP = "TRANSFER_RAFT_LEADERSHIP"
+
+	// raft leadership transfer parameter, the node id of the transferee, the best follower if it is empty
+	raftTransferParamTo = "TO"
+
+	// defaultRaftTransferWindow is the time window of the transfer requests in seconds, if the tx_timeout
+	// of chain is not set
+	defaultRaftTransferWindow = 600
+)
+
+// raftTransferTxIds is the transfer requests in the time window. A transfer request is a query, which is
+// not deduplicated by the tx pool, so the replayed ones are rejected by it.
+var raftTransferTxIds = &seenTxIds{txIds: make(map[string]int64)}
+
+// seenTxIds is the ids of the txs seen in a time window
+type seenTxIds struct {
+	sync.Mutex
+	txIds map[string]int64 // tx id => timestamp
+}
+
+// add adds the tx of txId and timestamp, it returns an error if the timestamp is not in window seconds
+// around now or the tx is seen already. The txs out of the window are removed.
+func (s *seenTxIds) add(txId string, timestamp, now, window int64) error {
+	if timestamp < now-window || timestamp > now+window {
+		return fmt.Errorf("timestamp %d of tx %s is out of the window of %d seconds", timestamp, txId, window)
+	}
+	s.Lock()
+	defer s.Unlock()
+	for id, ts := range s.txIds {
+		if ts < now-window {
+			delete(s.txIds, id)
+		}
+	}
+	if _, ok := s.txIds[txId]; ok {
+		return fmt.Errorf("tx %s is duplicated", txId)
+	}
+	s.txIds[txId] = timestamp
+	return nil
+}
+
+// raftTransferResult is the result of the raft leadership transfer
+type raftTransferResult struct {
+	Transferee string `json:"transferee"`
+}
+
+// isRaftTransferRequest returns true if tx transfers the raft leadership
+func isRaftTransferRequest(tx *commonPb.Transaction) bool {
+	return tx.Payload.ContractName == syscontract.SystemContract_CHAIN_QUERY.String() &&
+		tx.Payload.Method == TRANSFER_RAFT_LEADERSHIP
+}
+
+// dealRaftTransferRequest - deal raft leadership transfer request
+func (s *ApiService) dealRaftTransferRequest(tx *commonPb.Transaction) *commonPb.TxResponse {
+	resp := &commonPb.TxResponse{TxId: tx.Payload.TxId}
+	result, err := s.transferRaftLeadership(tx)
+	if err != nil {
+		errMsg := s.getErrMsg(commonErr.ERR_CODE_INVOKE_CONTRACT, err)
+		s.log.Warn(errMsg)
+		resp.Code = commonPb.TxStatusCode_CONTRACT_FAIL
+		resp.Message = errMsg
+		resp.ContractResult = &commonPb.ContractResult{Code: 1, Message: err.Error()}
+		return resp
+	}
+
+	resp.Code = commonPb.TxStatusCode_SUCCESS
+	resp.Message = commonPb.TxStatusCode_SUCCESS.String()
+	resp.ContractResult = &commonPb.ContractResult{Result: result}
+	return resp
+}
+
+// transferRaftLeadership transfers the raft leadership if the sender, whose signature is verified already,
+// is an admin of the org of this node, and the request is in the tx time window and not replayed
+func (s *ApiService) transferRaftLeadership(tx *commonPb.Transaction) ([]byte, error) {
+	role, err := s.getRoleFromTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	if role != protocol.RoleAdmin || tx.Sender.Signer.OrgId != localconf.ChainMakerConfig.NodeConfig.OrgId {
+		return nil, fmt.Errorf("%s is only allowed to the admin of org %s", TRANSFER_RAFT_LEADERSHIP,
+			localconf.ChainMakerConfig.NodeConfig.OrgId)
+	}
+
+	chainConf, err := s.chainMakerServer.GetChainConf(tx.Payload.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	window := int64(chainConf.ChainConfig().Block.TxTimeout)
+	if window <= 0 {
+		window = defaultRaftTransferWindow
+	}
+	if err = raftTransferTxIds.add(tx.Payload.TxId, tx.Payload.Timestamp, utils.CurrentTimeSeconds(),
+		window); err != nil {
+		return nil, err
+	}
+
+	params := s.kvPair2Map(tx.Payload.Parameters)
+	transferee, err := raft.TransferLeadership(tx.Payload.ChainId, string(params[raftTransferParamTo]))
+	if err != nil {
+		return nil, err
+	}
+	s.log.Infof("raft leadership of chain %s is transferred to %s by %s", tx.Payload.ChainId, transferee,
+		tx.Sender.Signer.OrgId)
+	return json.Marshal(&raftTransferResult{Transferee: transferee})
+}
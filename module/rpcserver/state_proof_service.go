@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) BABEC. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package rpcserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"chainmaker.org/chainmaker-go/core/common"
+	commonErr "chainmaker.org/chainmaker/common/v2/errors"
+	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+)
+
+const (
+	// GET_STATE_PROOF is the method of CHAIN_QUERY contract, which returns the value of a key
+	// at a height with a merkle proof against the header of that block, in json
+	GET_STATE_PROOF = "GET_STATE_PROOF"
+
+	// state proof query parameters, BLOCK_HEIGHT is optional and the last committed height by default
+	stateProofParamContractName = "CONTRACT_NAME"
+	stateProofParamKey          = "KEY"
+	stateProofParamBlockHeight  = "BLOCK_HEIGHT"
+)
+
+// isStateProofQuery returns true if tx queries the state proof
+func isStateProofQuery(tx *commonPb.Transaction) bool {
+	return tx.Payload.ContractName == syscontract.SystemContract_CHAIN_QUERY.String() &&
+		tx.Payload.Method == GET_STATE_PROOF
+}
+
+// dealStateProofQuery - deal state proof query
+func (s *ApiService) dealStateProofQuery(tx *commonPb.Transaction) *commonPb.TxResponse {
+	resp := &commonPb.TxResponse{TxId: tx.Payload.TxId}
+	result, err := s.getStateProof(tx)
+	if err != nil {
+		errMsg := s.getErrMsg(commonErr.ERR_CODE_INVOKE_CONTRACT, err)
+		s.log.Warn(errMsg)
+		resp.Code = commonPb.TxStatusCode_CONTRACT_FAIL
+		resp.Message = errMsg
+		resp.ContractResult = &commonPb.ContractResult{Code: 1, Message: err.Error()}
+		return resp
+	}
+
+	resp.Code = commonPb.TxStatusCode_SUCCESS
+	resp.Message = commonPb.TxStatusCode_SUCCESS.String()
+	resp.ContractResult = &commonPb.ContractResult{Result: result}
+	return resp
+}
+
+func (s *ApiService) getStateProof(tx *commonPb.Transaction) ([]byte, error) {
+	params := s.kvPair2Map(tx.Payload.Parameters)
+	contractName := string(params[stateProofParamContractName])
+	if contractName == "" {
+		return nil, errors.New("contract name is empty")
+	}
+	key, ok := params[stateProofParamKey]
+	if !ok {
+		return nil, errors.New("key is empty")
+	}
+
+	bc, err := s.chainMakerServer.GetBlockchain(tx.Payload.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	if bc.GetStateTree() == nil {
+		return nil, errors.New("state tree is not supported")
+	}
+
+	chainConf, err := s.chainMakerServer.GetChainConf(tx.Payload.ChainId)
+	if err != nil {
+		return nil, err
+	}
+
+	var height uint64
+	if value, ok := params[stateProofParamBlockHeight]; ok && len(value) > 0 {
+		if height, err = strconv.ParseUint(string(value), 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid block height %s", string(value))
+		}
+	} else {
+		store, err := s.chainMakerServer.GetStore(tx.Payload.ChainId)
+		if err != nil {
+			return nil, err
+		}
+		lastBlock, err := store.GetLastBlock()
+		if err != nil {
+			return nil, err
+		}
+		height = lastBlock.Header.BlockHeight
+	}
+	// the blocks before the activation of state root do not commit to the state
+	if !common.IsStateRootEnabled(chainConf, height) {
+		return nil, fmt.Errorf("state root is not enabled at height %d", height)
+	}
+
+	proof, err := bc.GetStateTree().Prove(contractName, key, height)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(proof)
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) BABEC. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package rpcserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"chainmaker.org/chainmaker-go/core/txproof"
+	commonErr "chainmaker.org/chainmaker/common/v2/errors"
+	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+)
+
+const (
+	// GET_TX_PROOF is the method of CHAIN_QUERY contract, which returns the inclusion proof of a tx
+	// with the block header, merkle path and commit signatures, in json
+	GET_TX_PROOF = "GET_TX_PROOF"
+
+	// tx proof query parameter
+	txProofParamTxId = "TX_ID"
+)
+
+// isTxProofQuery returns true if tx queries the tx inclusion proof
+func isTxProofQuery(tx *commonPb.Transaction) bool {
+	return tx.Payload.ContractName == syscontract.SystemContract_CHAIN_QUERY.String() &&
+		tx.Payload.Method == GET_TX_PROOF
+}
+
+// dealTxProofQuery - deal tx inclusion proof query
+func (s *ApiService) dealTxProofQuery(tx *commonPb.Transaction) *commonPb.TxResponse {
+	resp := &commonPb.TxResponse{TxId: tx.Payload.TxId}
+	result, err := s.getTxProof(tx)
+	if err != nil {
+		errMsg := s.getErrMsg(commonErr.ERR_CODE_INVOKE_CONTRACT, err)
+		s.log.Warn(errMsg)
+		resp.Code = commonPb.TxStatusCode_CONTRACT_FAIL
+		resp.Message = errMsg
+		resp.ContractResult = &commonPb.ContractResult{Code: 1, Message: err.Error()}
+		return resp
+	}
+
+	resp.Code = commonPb.TxStatusCode_SUCCESS
+	resp.Message = commonPb.TxStatusCode_SUCCESS.String()
+	resp.ContractResult = &commonPb.ContractResult{Result: result}
+	return resp
+}
+
+func (s *ApiService) getTxProof(tx *commonPb.Transaction) ([]byte, error) {
+	params := s.kvPair2Map(tx.Payload.Parameters)
+	txId := string(params[txProofParamTxId])
+	if txId == "" {
+		return nil, errors.New("tx id is empty")
+	}
+
+	chainConf, err := s.chainMakerServer.GetChainConf(tx.Payload.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	store, err := s.chainMakerServer.GetStore(tx.Payload.ChainId)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := txproof.BuildTxProof(store, txId, chainConf.ChainConfig().Crypto.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(proof)
+}
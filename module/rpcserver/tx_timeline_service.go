@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) BABEC. All rights reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package rpcserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"chainmaker.org/chainmaker-go/core/txtimeline"
+	commonErr "chainmaker.org/chainmaker/common/v2/errors"
+	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+)
+
+const (
+	// GET_TX_TIMELINE is the method of CHAIN_QUERY contract, which returns the lifecycle events
+	// of a tx seen by this node, in json
+	GET_TX_TIMELINE = "GET_TX_TIMELINE"
+
+	// tx timeline query parameter
+	txTimelineParamTxId = "TX_ID"
+)
+
+// isTxTimelineQuery returns true if tx queries the tx timeline
+func isTxTimelineQuery(tx *commonPb.Transaction) bool {
+	return tx.Payload.ContractName == syscontract.SystemContract_CHAIN_QUERY.String() &&
+		tx.Payload.Method == GET_TX_TIMELINE
+}
+
+// dealTxTimelineQuery - deal tx timeline query
+func (s *ApiService) dealTxTimelineQuery(tx *commonPb.Transaction) *commonPb.TxResponse {
+	resp := &commonPb.TxResponse{TxId: tx.Payload.TxId}
+	result, err := s.getTxTimeline(tx)
+	if err != nil {
+		errMsg := s.getErrMsg(commonErr.ERR_CODE_INVOKE_CONTRACT, err)
+		s.log.Warn(errMsg)
+		resp.Code = commonPb.TxStatusCode_CONTRACT_FAIL
+		resp.Message = errMsg
+		resp.ContractResult = &commonPb.ContractResult{Code: 1, Message: err.Error()}
+		return resp
+	}
+
+	resp.Code = commonPb.TxStatusCode_SUCCESS
+	resp.Message = commonPb.TxStatusCode_SUCCESS.String()
+	resp.ContractResult = &commonPb.ContractResult{Result: result}
+	return resp
+}
+
+func (s *ApiService) getTxTimeline(tx *commonPb.Transaction) ([]byte, error) {
+	params := s.kvPair2Map(tx.Payload.Parameters)
+	txId := string(params[txTimelineParamTxId])
+	if txId == "" {
+		return nil, errors.New("tx id is empty")
+	}
+	events := txtimeline.Of(tx.Payload.ChainId).Get(txId)
+	if events == nil {
+		return nil, fmt.Errorf("tx[%s] not seen by this node", txId)
+	}
+	return json.Marshal(events)
+}
@@ -9,6 +9,7 @@ require (
 	chainmaker.org/chainmaker/pb-go/v2 v2.1.0
 	chainmaker.org/chainmaker/protocol/v2 v2.1.1
 	chainmaker.org/chainmaker/utils/v2 v2.1.0
+	github.com/prometheus/client_golang v1.11.0
 	github.com/stretchr/testify v1.7.0
 	go.uber.org/atomic v1.9.0
 )
@@ -24,6 +24,7 @@ func (f *Factory) NewSnapshotManager(blockchainStore protocol.BlockchainStore) p
 		snapshots: make(map[utils.BlockFingerPrint]*SnapshotImpl, 1024),
 		delegate: &ManagerDelegate{
 			blockchainStore: blockchainStore,
+			stateCache:      newStateCache(defaultStateCacheSize),
 		},
 	}
 }
@@ -42,6 +42,8 @@ type SnapshotImpl struct {
 	preBlockHash   []byte
 
 	preSnapshot protocol.Snapshot
+	// committed state shared by all snapshots of the chain, nil if disabled
+	stateCache *stateCache
 
 	txRWSetTable   []*commonPb.TxRWSet
 	txTable        []*commonPb.Transaction
@@ -152,7 +154,28 @@ func (s *SnapshotImpl) GetKey(txExecSeq int, contractName string, key []byte) ([
 		iter = iter.GetPreSnapshot()
 	}
 
-	return s.blockchainStore.ReadObject(contractName, key)
+	return s.readCommittedState(contractName, key)
+}
+
+// readCommittedState reads the committed value of key, from the state cache if possible
+func (s *SnapshotImpl) readCommittedState(contractName string, key []byte) ([]byte, error) {
+	if s.stateCache == nil {
+		return s.blockchainStore.ReadObject(contractName, key)
+	}
+
+	finalKey := constructKey(contractName, key)
+	value, ok, readHeight := s.stateCache.get(finalKey)
+	reportStateCacheHit(s.chainId, ok)
+	if ok {
+		return value, nil
+	}
+
+	value, err := s.blockchainStore.ReadObject(contractName, key)
+	if err != nil {
+		return nil, err
+	}
+	s.stateCache.put(finalKey, value, readHeight)
+	return value, nil
 }
 
 // ApplyTxSimContext After the read-write set is generated, add TxSimContext to the snapshot
@@ -29,6 +29,17 @@ func (m *ManagerImpl) storeAndLinkSnapshotImpl(snapshotImpl *SnapshotImpl,
 	}
 }
 
+// link to the snapshot of a self proposed block, which is stored before the block is finalized,
+// so that the next height can be built on top of the not-yet-committed block
+func (m *ManagerImpl) linkProposedSnapshotImpl(snapshotImpl *SnapshotImpl, prevBlock *commonPb.Block) {
+	if snapshotImpl.GetPreSnapshot() != nil || prevBlock == nil || prevBlock.Header == nil {
+		return
+	}
+	if prevSnapshot, ok := m.snapshots[calcNotConsensusFingerPrint(prevBlock)]; ok {
+		snapshotImpl.SetPreSnapshot(prevSnapshot)
+	}
+}
+
 // When generating blocks, generate a Snapshot for each block, which is used as read-write set cache
 func (m *ManagerImpl) NewSnapshot(prevBlock *commonPb.Block, block *commonPb.Block) protocol.Snapshot {
 	m.delegate.lock.Lock()
@@ -40,6 +51,7 @@ func (m *ManagerImpl) NewSnapshot(prevBlock *commonPb.Block, block *commonPb.Blo
 	prevFingerPrint := utils.CalcBlockFingerPrint(prevBlock)
 	fingerPrint := utils.CalcBlockFingerPrint(block)
 	m.storeAndLinkSnapshotImpl(snapshotImpl, &prevFingerPrint, &fingerPrint)
+	m.linkProposedSnapshotImpl(snapshotImpl, prevBlock)
 
 	log.Infof(
 		"create snapshot@%s at height %d, fingerPrint[%v] -> prevFingerPrint[%v]",
@@ -60,13 +72,15 @@ func (m *ManagerImpl) NotifyBlockCommitted(block *commonPb.Block) error {
 	// 计算刚落块的区块指纹
 	deleteFp := utils.CalcBlockFingerPrint(block)
 	deleteFpEx := calcNotConsensusFingerPrint(block)
+	m.refreshStateCache(block, deleteFp, deleteFpEx)
+
 	// 如果有snapshot对应的前序snapshot的指纹, 等于刚落块的区块指纹
 	for _, snapshot := range m.snapshots {
 		if snapshot == nil || snapshot.GetPreSnapshot() == nil {
 			continue
 		}
 		prevFp := m.delegate.calcSnapshotFingerPrint(snapshot.GetPreSnapshot().(*SnapshotImpl))
-		if deleteFp == prevFp {
+		if deleteFp == prevFp || deleteFpEx == prevFp {
 			snapshot.SetPreSnapshot(nil)
 		}
 	}
@@ -98,6 +112,26 @@ func (m *ManagerImpl) NotifyBlockCommitted(block *commonPb.Block) error {
 	return nil
 }
 
+// refresh the shared state cache with the writes of the committed block,
+// drop all cached state if the snapshot of the block is unknown
+func (m *ManagerImpl) refreshStateCache(block *commonPb.Block, fps ...utils.BlockFingerPrint) {
+	cache := m.delegate.stateCache
+	if cache == nil {
+		return
+	}
+	for _, fp := range fps {
+		if snapshot, ok := m.snapshots[fp]; ok && snapshot != nil {
+			snapshot.lock.Lock()
+			cache.applyCommitted(block.Header.BlockHeight, snapshot.writeTable)
+			snapshot.lock.Unlock()
+			return
+		}
+	}
+	log.Infof("snapshot@%s of block %d not found, purge state cache",
+		block.Header.ChainId, block.Header.BlockHeight)
+	cache.purge(block.Header.BlockHeight)
+}
+
 func calcNotConsensusFingerPrint(block *commonPb.Block) utils.BlockFingerPrint {
 	if block == nil {
 		return ""
@@ -20,6 +20,7 @@ import (
 type ManagerDelegate struct {
 	lock            sync.Mutex
 	blockchainStore protocol.BlockchainStore
+	stateCache      *stateCache
 }
 
 func (m *ManagerDelegate) calcSnapshotFingerPrint(snapshot *SnapshotImpl) utils.BlockFingerPrint {
@@ -41,6 +42,7 @@ func (m *ManagerDelegate) makeSnapshotImpl(block *commonPb.Block, blockHeight ui
 	txCount := len(block.Txs) // as map init size
 	snapshotImpl := &SnapshotImpl{
 		blockchainStore: m.blockchainStore,
+		stateCache:      m.stateCache,
 		sealed:          atomic.NewBool(false),
 		preSnapshot:     nil,
 
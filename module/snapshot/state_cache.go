@@ -0,0 +1,157 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+Copyright (C) THL A29 Limited, a Tencent company. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package snapshot
+
+import (
+	"container/list"
+	"sync"
+
+	"chainmaker.org/chainmaker/common/v2/monitor"
+	"chainmaker.org/chainmaker/localconf/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// defaultStateCacheSize is the max number of committed keys kept in the state cache
+	defaultStateCacheSize = 100000
+)
+
+var (
+	metricStateCacheHit  *prometheus.CounterVec
+	metricStateCacheMiss *prometheus.CounterVec
+	metricOnce           sync.Once
+)
+
+// cachedState is a committed value of a key, together with the block height it is valid from
+type cachedState struct {
+	key    string
+	value  []byte
+	height uint64
+}
+
+// stateCache is a bounded LRU cache of committed world state, shared by all snapshots of a chain.
+// It only holds values which are consistent with the blockchain store at committedHeight,
+// so it can be used as the last level before reading the store.
+type stateCache struct {
+	lock            sync.Mutex
+	capacity        int
+	committedHeight uint64
+	lru             *list.List
+	items           map[string]*list.Element
+}
+
+func newStateCache(capacity int) *stateCache {
+	if capacity <= 0 {
+		capacity = defaultStateCacheSize
+	}
+	if localconf.ChainMakerConfig.MonitorConfig.Enabled {
+		metricOnce.Do(func() {
+			metricStateCacheHit = monitor.NewCounterVec(monitor.SUBSYSTEM_CORE_COMMITTER,
+				"metric_state_cache_hit", "state cache hit counts metric", "chainId")
+			metricStateCacheMiss = monitor.NewCounterVec(monitor.SUBSYSTEM_CORE_COMMITTER,
+				"metric_state_cache_miss", "state cache miss counts metric", "chainId")
+		})
+	}
+	return &stateCache{
+		capacity: capacity,
+		lru:      list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+// get returns the cached committed value of finalKey and the height the cache is consistent with.
+// The returned height must be passed to put if the value is loaded from the store on a miss.
+func (c *stateCache) get(finalKey string) ([]byte, bool, uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[finalKey]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*cachedState).value, true, c.committedHeight
+	}
+	return nil, false, c.committedHeight
+}
+
+// put adds a value read from the store. readHeight is the committed height returned by get before reading,
+// if some block has been committed in between, the value may be stale and is dropped.
+func (c *stateCache) put(finalKey string, value []byte, readHeight uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if readHeight != c.committedHeight {
+		return
+	}
+	if _, ok := c.items[finalKey]; ok {
+		return
+	}
+	c.add(finalKey, value, readHeight)
+}
+
+// applyCommitted refreshes the cache with the writes of a committed block,
+// so that entries overwritten by the block are never served again.
+func (c *stateCache) applyCommitted(height uint64, writeTable map[string]*sv) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for finalKey, w := range writeTable {
+		if elem, ok := c.items[finalKey]; ok {
+			state, _ := elem.Value.(*cachedState)
+			state.value = w.value
+			state.height = height
+			c.lru.MoveToFront(elem)
+			continue
+		}
+		c.add(finalKey, w.value, height)
+	}
+	if height > c.committedHeight {
+		c.committedHeight = height
+	}
+}
+
+// purge drops all entries, used when the writes of a committed block are unknown
+func (c *stateCache) purge(height uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.lru.Init()
+	c.items = make(map[string]*list.Element, c.capacity)
+	if height > c.committedHeight {
+		c.committedHeight = height
+	}
+}
+
+func (c *stateCache) size() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+func (c *stateCache) add(finalKey string, value []byte, height uint64) {
+	c.items[finalKey] = c.lru.PushFront(&cachedState{
+		key:    finalKey,
+		value:  value,
+		height: height,
+	})
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*cachedState).key)
+	}
+}
+
+// reportStateCacheHit records a hit or a miss of the state cache for chainId
+func reportStateCacheHit(chainId string, hit bool) {
+	if !localconf.ChainMakerConfig.MonitorConfig.Enabled || metricStateCacheHit == nil {
+		return
+	}
+	if hit {
+		metricStateCacheHit.WithLabelValues(chainId).Inc()
+	} else {
+		metricStateCacheMiss.WithLabelValues(chainId).Inc()
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+Copyright (C) THL A29 Limited, a Tencent company. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateCache(t *testing.T) {
+	cache := newStateCache(2)
+
+	_, ok, readHeight := cache.get("c1k1")
+	require.False(t, ok)
+	cache.put("c1k1", []byte("v1"), readHeight)
+	value, ok, _ := cache.get("c1k1")
+	require.True(t, ok)
+	require.Equal(t, []byte("v1"), value)
+
+	// value read before a commit must not be cached
+	_, _, readHeight = cache.get("c1k2")
+	cache.applyCommitted(1, map[string]*sv{"c1k1": {seq: 0, value: []byte("v2")}})
+	cache.put("c1k2", []byte("stale"), readHeight)
+	_, ok, _ = cache.get("c1k2")
+	require.False(t, ok)
+
+	// committed writes invalidate stale entries
+	value, ok, _ = cache.get("c1k1")
+	require.True(t, ok)
+	require.Equal(t, []byte("v2"), value)
+
+	// bounded by capacity
+	cache.applyCommitted(2, map[string]*sv{
+		"c1k3": {seq: 0, value: []byte("v3")},
+		"c1k4": {seq: 1, value: []byte("v4")},
+	})
+	require.Equal(t, 2, cache.size())
+	_, ok, _ = cache.get("c1k1")
+	require.False(t, ok)
+
+	cache.purge(3)
+	require.Equal(t, 0, cache.size())
+	_, _, readHeight = cache.get("c1k3")
+	require.Equal(t, uint64(3), readHeight)
+}
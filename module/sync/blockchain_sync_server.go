@@ -35,6 +35,7 @@ type BlockChainSyncServer struct {
 	ledgerCache     protocol.LedgerCache     // Provides the latest chain state for the node
 	blockVerifier   protocol.BlockVerifier   // Verify Block Validity
 	blockCommitter  protocol.BlockCommitter  // Adds a validated block to the chain to update the state of the chain
+	chainConf       protocol.ChainConf       // Checks the compatibility of peers
 
 	log   *logger.CMLogger
 	conf  *BlockSyncServerConf // The configuration in sync module
@@ -51,7 +52,8 @@ func NewBlockChainSyncServer(chainId string,
 	blockchainStore protocol.BlockchainStore,
 	ledgerCache protocol.LedgerCache,
 	blockVerifier protocol.BlockVerifier,
-	blockCommitter protocol.BlockCommitter) protocol.SyncService {
+	blockCommitter protocol.BlockCommitter,
+	chainConf protocol.ChainConf) protocol.SyncService {
 
 	syncServer := &BlockChainSyncServer{
 		chainId:         chainId,
@@ -61,6 +63,7 @@ func NewBlockChainSyncServer(chainId string,
 		ledgerCache:     ledgerCache,
 		blockVerifier:   blockVerifier,
 		blockCommitter:  blockCommitter,
+		chainConf:       chainConf,
 		close:           make(chan bool),
 		log:             logger.GetLoggerByChain(logger.MODULE_SYNC, chainId),
 	}
@@ -183,7 +186,8 @@ func (sync *BlockChainSyncServer) handleNodeStatusReq(from string) error {
 	}
 	archivedHeight := sync.blockChainStore.GetArchivedPivot()
 	sync.log.Debugf("receive node status request from node [%s]", from)
-	if bz, err = proto.Marshal(&syncPb.BlockHeightBCM{BlockHeight: height, ArchivedHeight: archivedHeight}); err != nil {
+	bz, err = marshalNodeStatus(&syncPb.BlockHeightBCM{BlockHeight: height, ArchivedHeight: archivedHeight})
+	if err != nil {
 		return err
 	}
 	return sync.sendMsg(syncPb.SyncMsg_NODE_STATUS_RESP, bz, from)
@@ -196,6 +200,12 @@ func (sync *BlockChainSyncServer) handleNodeStatusResp(syncMsg *syncPb.SyncMsg,
 	}
 	sync.log.Debugf("receive node[%s] status, height [%d], archived height [%d]", from, msg.BlockHeight,
 		msg.ArchivedHeight)
+	version, err := unmarshalNodeVersion(syncMsg.Payload)
+	if err != nil {
+		sync.log.Warnf("unmarshal node[%s] version failed, %s", from, err)
+	} else {
+		sync.checkPeerVersion(from, version)
+	}
 	return sync.scheduler.addTask(NodeStatusMsg{msg: msg, from: from})
 }
 
@@ -419,7 +429,7 @@ func (sync *BlockChainSyncServer) OnMessage(message *msgbus.Message) {
 		if height%3 != 0 {
 			return
 		}
-		bz, err := proto.Marshal(&syncPb.BlockHeightBCM{BlockHeight: height})
+		bz, err := marshalNodeStatus(&syncPb.BlockHeightBCM{BlockHeight: height})
 		if err != nil {
 			sync.log.Errorf("marshal BlockHeightBCM failed, reason: %s", err)
 			return
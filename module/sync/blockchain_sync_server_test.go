@@ -69,7 +69,7 @@ func initTestSync(t *testing.T) (protocol.SyncService, func()) {
 	mockStore := newMockBlockChainStore(ctrl)
 	mockLedger := newMockLedgerCache(ctrl, &commonPb.Block{Header: &commonPb.BlockHeader{BlockHeight: 10}})
 	mockCommit := newMockCommitter(ctrl, mockLedger)
-	sync := NewBlockChainSyncServer("chain1", mockNet, mockMsgBus, mockStore, mockLedger, mockVerify, mockCommit)
+	sync := NewBlockChainSyncServer("chain1", mockNet, mockMsgBus, mockStore, mockLedger, mockVerify, mockCommit, nil)
 	require.NoError(t, sync.Start())
 	return sync, func() {
 		sync.Stop()
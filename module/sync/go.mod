@@ -3,6 +3,8 @@ module chainmaker.org/chainmaker-go/sync
 go 1.15
 
 require (
+	chainmaker.org/chainmaker-go/pb v0.0.0
+	chainmaker.org/chainmaker-go/upgrade v0.0.0
 	chainmaker.org/chainmaker/common/v2 v2.1.0
 	chainmaker.org/chainmaker/localconf/v2 v2.1.0
 	chainmaker.org/chainmaker/logger/v2 v2.1.0
@@ -13,3 +15,8 @@ require (
 	github.com/golang/mock v1.6.0
 	github.com/stretchr/testify v1.7.0
 )
+
+replace (
+	chainmaker.org/chainmaker-go/pb => ../pb
+	chainmaker.org/chainmaker-go/upgrade => ../upgrade
+)
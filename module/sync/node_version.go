@@ -0,0 +1,64 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sync
+
+import (
+	syncextpb "chainmaker.org/chainmaker-go/pb/sync"
+	"chainmaker.org/chainmaker-go/upgrade/compat"
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	syncPb "chainmaker.org/chainmaker/pb-go/v2/sync"
+)
+
+// marshalNodeStatus marshals the node status with the version of this node, see syncextpb.BlockHeightBCMExt
+func marshalNodeStatus(msg *syncPb.BlockHeightBCM) ([]byte, error) {
+	local := compat.LocalVersion()
+	status := &syncextpb.BlockHeightBCMExt{
+		BlockHeight:    msg.BlockHeight,
+		ArchivedHeight: msg.ArchivedHeight,
+		NodeVersion: &syncextpb.NodeVersion{
+			Protocol:    local.Protocol,
+			MinProtocol: local.MinProtocol,
+			Features:    local.Features,
+		},
+	}
+	return status.Marshal()
+}
+
+// unmarshalNodeVersion returns the node version in the payload of node status,
+// which is the zero version for the nodes before version exchange
+func unmarshalNodeVersion(bz []byte) (*compat.NodeVersion, error) {
+	status := &syncextpb.BlockHeightBCMExt{}
+	if err := status.Unmarshal(bz); err != nil {
+		return nil, err
+	}
+	version := &compat.NodeVersion{}
+	if status.NodeVersion != nil {
+		version.Protocol = status.NodeVersion.Protocol
+		version.MinProtocol = status.NodeVersion.MinProtocol
+		version.Features = status.NodeVersion.Features
+	}
+	return version, nil
+}
+
+// checkPeerVersion records the version of peer, and logs when its compatibility changes
+func (sync *BlockChainSyncServer) checkPeerVersion(from string, version *compat.NodeVersion) {
+	var chainConfig *config.ChainConfig
+	if sync.chainConf != nil {
+		chainConfig = sync.chainConf.ChainConfig()
+	}
+	reason, changed := compat.Of(sync.chainId).Update(chainConfig, from, version)
+	if !changed {
+		return
+	}
+	if reason != nil {
+		sync.log.Warnf("peer [%s] at version %s is INCOMPATIBLE with local version %s: %s, policy: %s",
+			from, version, compat.LocalVersion(), reason, compat.GetPolicy(chainConfig))
+		return
+	}
+	sync.log.Infof("peer [%s] at version %s is compatible with local version %s",
+		from, version, compat.LocalVersion())
+}
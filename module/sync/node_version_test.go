@@ -0,0 +1,38 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sync
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker-go/upgrade/compat"
+	syncPb "chainmaker.org/chainmaker/pb-go/v2/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeVersion(t *testing.T) {
+	status := &syncPb.BlockHeightBCM{BlockHeight: 100, ArchivedHeight: 10}
+	bz, err := marshalNodeStatus(status)
+	require.NoError(t, err)
+
+	// the version is skipped by the nodes before version exchange
+	msg := syncPb.BlockHeightBCM{}
+	require.NoError(t, msg.Unmarshal(bz))
+	require.EqualValues(t, 100, msg.BlockHeight)
+	require.EqualValues(t, 10, msg.ArchivedHeight)
+
+	version, err := unmarshalNodeVersion(bz)
+	require.NoError(t, err)
+	require.Equal(t, compat.LocalVersion(), version)
+
+	// the status from the nodes before version exchange
+	bz, err = status.Marshal()
+	require.NoError(t, err)
+	version, err = unmarshalNodeVersion(bz)
+	require.NoError(t, err)
+	require.Equal(t, &compat.NodeVersion{}, version)
+}
@@ -3,12 +3,18 @@ module chainmaker.org/chainmaker-go/txpool
 go 1.15
 
 require (
+	chainmaker.org/chainmaker-go/core v0.0.0
+	chainmaker.org/chainmaker-go/upgrade v0.0.0
 	chainmaker.org/chainmaker/common/v2 v2.1.0
+	chainmaker.org/chainmaker/logger/v2 v2.1.0
+	chainmaker.org/chainmaker/pb-go/v2 v2.1.0
 	chainmaker.org/chainmaker/protocol/v2 v2.1.1
 	chainmaker.org/chainmaker/txpool-single/v2 v2.1.0
 	github.com/StackExchange/wmi v1.2.1 // indirect
 	github.com/btcsuite/btcd v0.22.0-beta // indirect
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
+	github.com/gogo/protobuf v1.3.2
+	github.com/golang/mock v1.6.0
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/mitchellh/mapstructure v1.4.2 // indirect
@@ -17,6 +23,7 @@ require (
 	github.com/prometheus/common v0.31.1 // indirect
 	github.com/prometheus/procfs v0.7.3 // indirect
 	github.com/shirou/gopsutil v3.21.9+incompatible // indirect
+	github.com/stretchr/testify v1.7.0
 	github.com/studyzy/sqlparse v0.0.0-20210525032257-e7b9574609c3 // indirect
 	go.uber.org/zap v1.19.1 // indirect
 	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519 // indirect
@@ -26,3 +33,14 @@ require (
 	google.golang.org/genproto v0.0.0-20211013025323-ce878158c4d4 // indirect
 	google.golang.org/grpc v1.41.0 // indirect
 )
+
+replace (
+	chainmaker.org/chainmaker-go/accesscontrol => ../accesscontrol
+	chainmaker.org/chainmaker-go/consensus => ../consensus
+	chainmaker.org/chainmaker-go/consensus/dpos => ./../consensus/dpos
+	chainmaker.org/chainmaker-go/core => ../core
+	chainmaker.org/chainmaker-go/pb => ../pb
+	chainmaker.org/chainmaker-go/subscriber => ../subscriber
+	chainmaker.org/chainmaker-go/upgrade => ../upgrade
+	github.com/libp2p/go-libp2p-core => chainmaker.org/chainmaker/libp2p-core v1.0.0
+)
@@ -0,0 +1,71 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txpool
+
+import (
+	"sync"
+
+	"chainmaker.org/chainmaker-go/core/common"
+	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/protocol/v2"
+)
+
+// txExpiry tracks the txs admitted into the pool with an expiration height or time, so they are evicted
+// from the pool once expired, as they can not be packed anymore. Without it the expired txs are removed
+// only by the proposer which fetches them, and stay in the pools of the other nodes.
+type txExpiry struct {
+	chainConf protocol.ChainConf
+
+	mu  sync.Mutex
+	txs map[string]*commonPb.Transaction // tx id => tx with an expiration
+}
+
+func newTxExpiry(chainConf protocol.ChainConf) *txExpiry {
+	return &txExpiry{
+		chainConf: chainConf,
+		txs:       make(map[string]*commonPb.Transaction),
+	}
+}
+
+// track tracks tx if it has an expiration height or time
+func (e *txExpiry) track(tx *commonPb.Transaction) {
+	if tx.Payload.ExpirationTime <= 0 {
+		if height, err := common.TxExpirationHeight(tx); err == nil && height == 0 {
+			return
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.txs[tx.Payload.TxId] = tx
+}
+
+// expired returns the txs tracked which expire for the block after the committed block, and stops tracking
+// them, the txs committed, and the txs no longer in pool by exists.
+func (e *txExpiry) expired(block *commonPb.Block, exists func(txId string) bool) []*commonPb.Transaction {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, tx := range block.Txs {
+		delete(e.txs, tx.Payload.TxId)
+	}
+	if len(e.txs) == 0 {
+		return nil
+	}
+	height := block.Header.BlockHeight + 1
+	enabled := common.IsTxExpiryEnabled(e.chainConf, height)
+	var expired []*commonPb.Transaction
+	for txId, tx := range e.txs {
+		if !exists(txId) {
+			delete(e.txs, txId)
+			continue
+		}
+		if enabled && common.IsTxExpired(tx, height, block.Header.BlockTimestamp) != nil {
+			expired = append(expired, tx)
+			delete(e.txs, txId)
+		}
+	}
+	return expired
+}
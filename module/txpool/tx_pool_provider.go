@@ -44,5 +44,5 @@ func GetTxPoolProvider(t string) Provider {
 	if !ok {
 		return nil
 	}
-	return provider
+	return wrap(provider)
 }
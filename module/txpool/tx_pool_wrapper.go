@@ -0,0 +1,140 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txpool
+
+import (
+	"chainmaker.org/chainmaker-go/core/txtimeline"
+	"chainmaker.org/chainmaker/common/v2/msgbus"
+	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
+	netPb "chainmaker.org/chainmaker/pb-go/v2/net"
+	txpoolPb "chainmaker.org/chainmaker/pb-go/v2/txpool"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+)
+
+// poolWrapper wraps the tx pool of a provider. It records the lifecycle events of txs at the pool in the tx
+// timeline of chain: the txs received by broadcast, admitted or rejected by the pool from any source, and
+// dropped by the pool on retry. And it evicts the expired txs from the pool, see txExpiry.
+type poolWrapper struct {
+	protocol.TxPool
+	timeline *txtimeline.Timeline
+	expiry   *txExpiry
+	log      protocol.Logger
+	// subscriber is the subscriber of the broadcast txs registered by the pool
+	subscriber msgbus.Subscriber
+}
+
+// wrap returns the provider creating the tx pools of provider wrapped by poolWrapper
+func wrap(provider Provider) Provider {
+	return func(nodeId string, chainId string, blockStore protocol.BlockchainStore, msgBus msgbus.MessageBus,
+		conf protocol.ChainConf, ac protocol.AccessControlProvider, log protocol.Logger, monitorEnabled bool,
+		poolConfig map[string]interface{}) (protocol.TxPool, error) {
+		pool := &poolWrapper{timeline: txtimeline.Register(chainId), expiry: newTxExpiry(conf), log: log}
+		inner, err := provider(nodeId, chainId, blockStore, &wrapperMsgBus{MessageBus: msgBus, pool: pool},
+			conf, ac, log, monitorEnabled, poolConfig)
+		if err != nil {
+			return nil, err
+		}
+		pool.TxPool = inner
+		msgBus.Register(msgbus.BlockInfo, pool)
+		return pool, nil
+	}
+}
+
+// AddTx adds tx into the pool, and records whether it is admitted
+func (pool *poolWrapper) AddTx(tx *commonPb.Transaction, source protocol.TxSource) error {
+	if err := pool.TxPool.AddTx(tx, source); err != nil {
+		pool.timeline.Record(tx.Payload.TxId, txtimeline.PoolRejected, 0, err.Error())
+		return err
+	}
+	pool.timeline.Record(tx.Payload.TxId, txtimeline.PoolAdmitted, 0, "")
+	pool.expiry.track(tx)
+	return nil
+}
+
+// RetryAndRemoveTxs puts retryTxs back and removes removeTxs, and records the retried txs which are not
+// put back, as the pool is full
+func (pool *poolWrapper) RetryAndRemoveTxs(retryTxs []*commonPb.Transaction, removeTxs []*commonPb.Transaction) {
+	pool.TxPool.RetryAndRemoveTxs(retryTxs, removeTxs)
+	for _, tx := range retryTxs {
+		if !pool.TxPool.IsTxExistInPool(tx.Payload.TxId) {
+			pool.timeline.Record(tx.Payload.TxId, txtimeline.Dropped, 0, "evicted by txpool on retry")
+		}
+	}
+}
+
+// OnMessage receives the committed blocks and the txs broadcast by other nodes. The expired txs are evicted
+// once a block is committed. A single tx is added through AddTx, so whether it is admitted is recorded,
+// and the others are passed to the pool after recorded as received.
+func (pool *poolWrapper) OnMessage(msg *msgbus.Message) {
+	if msg != nil && msg.Topic == msgbus.BlockInfo {
+		if blockInfo, ok := msg.Payload.(*commonPb.BlockInfo); ok && blockInfo.Block != nil {
+			pool.evictExpired(blockInfo.Block)
+		}
+		return
+	}
+	if msg == nil || msg.Topic != msgbus.RecvTxPoolMsg {
+		return
+	}
+	netMsg, ok := msg.Payload.(*netPb.NetMsg)
+	txPoolMsg := new(txpoolPb.TxPoolMsg)
+	if !ok || proto.Unmarshal(netMsg.Payload, txPoolMsg) != nil {
+		pool.subscriber.OnMessage(msg)
+		return
+	}
+	switch txPoolMsg.Type {
+	case txpoolPb.TxPoolMsgType_SINGLE_TX:
+		tx := new(commonPb.Transaction)
+		if proto.Unmarshal(txPoolMsg.Payload, tx) != nil || tx.Payload == nil {
+			pool.subscriber.OnMessage(msg)
+			return
+		}
+		pool.timeline.Record(tx.Payload.TxId, txtimeline.P2pReceived, 0, "")
+		_ = pool.AddTx(tx, protocol.P2P)
+	case txpoolPb.TxPoolMsgType_BATCH_TX:
+		batch := new(txpoolPb.TxBatch)
+		if proto.Unmarshal(txPoolMsg.Payload, batch) == nil {
+			pool.timeline.RecordTxs(batch.Txs, txtimeline.P2pReceived, 0, "")
+		}
+		pool.subscriber.OnMessage(msg)
+	default:
+		pool.subscriber.OnMessage(msg)
+	}
+}
+
+// OnQuit is called when the msgbus quits
+func (pool *poolWrapper) OnQuit() {
+	if pool.subscriber != nil {
+		pool.subscriber.OnQuit()
+	}
+}
+
+// evictExpired removes the txs expired for the block after block from the pool
+func (pool *poolWrapper) evictExpired(block *commonPb.Block) {
+	expired := pool.expiry.expired(block, pool.TxPool.IsTxExistInPool)
+	if len(expired) == 0 {
+		return
+	}
+	pool.TxPool.RetryAndRemoveTxs(nil, expired)
+	pool.timeline.RecordTxs(expired, txtimeline.Dropped, block.Header.BlockHeight, "expired")
+	pool.log.Infof("evict %d expired txs from txpool at block[%d]", len(expired), block.Header.BlockHeight)
+}
+
+// wrapperMsgBus registers the poolWrapper instead of the pool created, for the broadcast txs
+type wrapperMsgBus struct {
+	msgbus.MessageBus
+	pool *poolWrapper
+}
+
+// Register registers sub to topic, with the broadcast txs received by the poolWrapper first
+func (b *wrapperMsgBus) Register(topic msgbus.Topic, sub msgbus.Subscriber) {
+	if topic == msgbus.RecvTxPoolMsg {
+		b.pool.subscriber = sub
+		sub = b.pool
+	}
+	b.MessageBus.Register(topic, sub)
+}
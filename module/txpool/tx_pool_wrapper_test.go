@@ -0,0 +1,119 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txpool
+
+import (
+	"errors"
+	"testing"
+
+	"chainmaker.org/chainmaker-go/core/common"
+	"chainmaker.org/chainmaker-go/core/txtimeline"
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	"chainmaker.org/chainmaker/common/v2/msgbus"
+	"chainmaker.org/chainmaker/logger/v2"
+	commonPb "chainmaker.org/chainmaker/pb-go/v2/common"
+	configPb "chainmaker.org/chainmaker/pb-go/v2/config"
+	netPb "chainmaker.org/chainmaker/pb-go/v2/net"
+	txpoolPb "chainmaker.org/chainmaker/pb-go/v2/txpool"
+	"chainmaker.org/chainmaker/protocol/v2"
+	"chainmaker.org/chainmaker/protocol/v2/mock"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTimelineTestTx(txId string) *commonPb.Transaction {
+	return &commonPb.Transaction{Payload: &commonPb.Payload{TxId: txId}}
+}
+
+func TestPoolWrapperTimeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	inner := mock.NewMockTxPool(ctrl)
+	pool := &poolWrapper{TxPool: inner, timeline: txtimeline.NewTimeline(10), expiry: newTxExpiry(nil)}
+
+	inner.EXPECT().AddTx(gomock.Any(), protocol.RPC).Return(nil)
+	require.NoError(t, pool.AddTx(newTimelineTestTx("tx1"), protocol.RPC))
+	require.Equal(t, txtimeline.PoolAdmitted, pool.timeline.Get("tx1")[0].Type)
+
+	inner.EXPECT().AddTx(gomock.Any(), protocol.RPC).Return(errors.New("tx pool is full"))
+	require.Error(t, pool.AddTx(newTimelineTestTx("tx2"), protocol.RPC))
+	require.Equal(t, txtimeline.PoolRejected, pool.timeline.Get("tx2")[0].Type)
+	require.Equal(t, "tx pool is full", pool.timeline.Get("tx2")[0].Reason)
+
+	// a retried tx not put back is dropped
+	inner.EXPECT().RetryAndRemoveTxs(gomock.Any(), gomock.Any())
+	inner.EXPECT().IsTxExistInPool("tx1").Return(true)
+	inner.EXPECT().IsTxExistInPool("tx3").Return(false)
+	pool.RetryAndRemoveTxs([]*commonPb.Transaction{newTimelineTestTx("tx1"), newTimelineTestTx("tx3")}, nil)
+	require.Len(t, pool.timeline.Get("tx1"), 1)
+	require.Equal(t, txtimeline.Dropped, pool.timeline.Get("tx3")[0].Type)
+
+	// a broadcast tx is recorded as received, and then admitted
+	txBytes, err := proto.Marshal(newTimelineTestTx("tx4"))
+	require.NoError(t, err)
+	msgBytes, err := proto.Marshal(&txpoolPb.TxPoolMsg{Type: txpoolPb.TxPoolMsgType_SINGLE_TX, Payload: txBytes})
+	require.NoError(t, err)
+	inner.EXPECT().AddTx(gomock.Any(), protocol.P2P).Return(nil)
+	pool.OnMessage(&msgbus.Message{Topic: msgbus.RecvTxPoolMsg, Payload: &netPb.NetMsg{Payload: msgBytes}})
+	events := pool.timeline.Get("tx4")
+	require.Len(t, events, 2)
+	require.Equal(t, txtimeline.P2pReceived, events[0].Type)
+	require.Equal(t, txtimeline.PoolAdmitted, events[1].Type)
+}
+
+func TestPoolWrapperExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	chainConf := mock.NewMockChainConf(ctrl)
+	chainConf.EXPECT().ChainConfig().Return(&configPb.ChainConfig{
+		Consensus: &configPb.ConsensusConfig{ExtConfig: []*configPb.ConfigKeyValue{
+			{Key: activation.KeyPrefix + activation.TxExpiry, Value: "0"},
+		}},
+	}).AnyTimes()
+	inner := mock.NewMockTxPool(ctrl)
+	pool := &poolWrapper{
+		TxPool:   inner,
+		timeline: txtimeline.NewTimeline(10),
+		expiry:   newTxExpiry(chainConf),
+		log:      logger.GetLoggerByChain(logger.MODULE_TXPOOL, "chain1"),
+	}
+
+	byTime := newTimelineTestTx("tx1")
+	byTime.Payload.ExpirationTime = 1000
+	byHeight := newTimelineTestTx("tx2")
+	byHeight.Payload.Parameters = []*commonPb.KeyValuePair{{Key: common.TxExpirationHeightKey, Value: []byte("10")}}
+	committed := newTimelineTestTx("tx3")
+	committed.Payload.ExpirationTime = 1000
+	inner.EXPECT().AddTx(gomock.Any(), protocol.RPC).Return(nil).Times(4)
+	for _, tx := range []*commonPb.Transaction{byTime, byHeight, committed, newTimelineTestTx("tx4")} {
+		require.NoError(t, pool.AddTx(tx, protocol.RPC))
+	}
+	require.Len(t, pool.expiry.txs, 3)
+
+	newBlockInfo := func(height uint64, timestamp int64, txs ...*commonPb.Transaction) *msgbus.Message {
+		return &msgbus.Message{Topic: msgbus.BlockInfo, Payload: &commonPb.BlockInfo{Block: &commonPb.Block{
+			Header: &commonPb.BlockHeader{BlockHeight: height, BlockTimestamp: timestamp},
+			Txs:    txs,
+		}}}
+	}
+	inner.EXPECT().IsTxExistInPool(gomock.Any()).Return(true).AnyTimes()
+
+	// nothing expires yet, and the committed tx is not tracked anymore
+	pool.OnMessage(newBlockInfo(8, 1000, committed))
+	require.Len(t, pool.expiry.txs, 2)
+
+	// the tx expired by time is evicted
+	inner.EXPECT().RetryAndRemoveTxs(nil, []*commonPb.Transaction{byTime})
+	pool.OnMessage(newBlockInfo(9, 1001))
+	require.Equal(t, txtimeline.Dropped, pool.timeline.Get("tx1")[1].Type)
+
+	// the tx expired by height is evicted, which can not be packed after block 10
+	inner.EXPECT().RetryAndRemoveTxs(nil, []*commonPb.Transaction{byHeight})
+	pool.OnMessage(newBlockInfo(10, 1002))
+	require.Empty(t, pool.expiry.txs)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package activation is the registry of features which become active at a block height set in chain config,
+// so that a new rule can be shipped ahead of time and every node switches to it at the same height.
+//
+// The activation height of a feature is set in the ext config of consensus config, with the key
+// "activation.<feature name>" and the height in decimal. A feature is active for the blocks at and
+// above its activation height. The activation height must not be changed once the chain has passed it,
+// otherwise the blocks committed before can not be verified again.
+package activation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+)
+
+// KeyPrefix is the prefix of the activation height keys in consensus ext config
+const KeyPrefix = "activation."
+
+// Feature is a rule which changes the blocks accepted by the chain
+type Feature struct {
+	Name        string
+	Description string
+	// DefaultActive tells if the feature is active when no activation height is configured.
+	// It is true for the rules shipped before this registry, which are enabled by their own config,
+	// and false for the new rules, which must be activated explicitly.
+	DefaultActive bool
+}
+
+var features = make(map[string]*Feature)
+
+// Register registers a feature, it panics if the name is registered already
+func Register(feature *Feature) {
+	if _, ok := features[feature.Name]; ok {
+		panic(fmt.Sprintf("activation feature %s registered twice", feature.Name))
+	}
+	features[feature.Name] = feature
+}
+
+// Features returns all the registered features sorted by name
+func Features() []*Feature {
+	list := make([]*Feature, 0, len(features))
+	for _, feature := range features {
+		list = append(list, feature)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// ActivationHeight returns the activation height of feature configured in chain config,
+// false if it is not configured or invalid
+func ActivationHeight(chainConfig *config.ChainConfig, name string) (uint64, bool) {
+	if chainConfig == nil || chainConfig.Consensus == nil {
+		return 0, false
+	}
+	for _, kv := range chainConfig.Consensus.ExtConfig {
+		if kv.Key != KeyPrefix+name {
+			continue
+		}
+		height, err := strconv.ParseUint(string(kv.Value), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return height, true
+	}
+	return 0, false
+}
+
+// IsActive returns true if feature is active for the block at height, an unregistered feature is never active
+func IsActive(chainConfig *config.ChainConfig, name string, height uint64) bool {
+	feature, ok := features[name]
+	if !ok {
+		return false
+	}
+	activationHeight, ok := ActivationHeight(chainConfig, name)
+	if !ok {
+		return feature.DefaultActive
+	}
+	return height >= activationHeight
+}
+
+// ActivatedAt returns the names of the features whose activation height is exactly height
+func ActivatedAt(chainConfig *config.ChainConfig, height uint64) []string {
+	var names []string
+	for _, feature := range Features() {
+		if activationHeight, ok := ActivationHeight(chainConfig, feature.Name); ok && activationHeight == height {
+			names = append(names, feature.Name)
+		}
+	}
+	return names
+}
+
+// CheckConfig checks the activation heights in chain config, which must be registered features
+// with a valid height
+func CheckConfig(chainConfig *config.ChainConfig) error {
+	if chainConfig == nil || chainConfig.Consensus == nil {
+		return nil
+	}
+	for _, kv := range chainConfig.Consensus.ExtConfig {
+		if !strings.HasPrefix(kv.Key, KeyPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(kv.Key, KeyPrefix)
+		if _, ok := features[name]; !ok {
+			return fmt.Errorf("unknown activation feature: %s", name)
+		}
+		if _, err := strconv.ParseUint(string(kv.Value), 10, 64); err != nil {
+			return fmt.Errorf("invalid activation height of feature %s: %s", name, kv.Value)
+		}
+	}
+	return nil
+}
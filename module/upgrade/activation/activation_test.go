@@ -0,0 +1,46 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package activation
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newChainConfig(kvs ...string) *config.ChainConfig {
+	consensus := &config.ConsensusConfig{}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		consensus.ExtConfig = append(consensus.ExtConfig, &config.ConfigKeyValue{
+			Key:   kvs[i],
+			Value: kvs[i+1],
+		})
+	}
+	return &config.ChainConfig{Consensus: consensus}
+}
+
+func TestIsActive(t *testing.T) {
+	chainConfig := newChainConfig()
+	require.True(t, IsActive(chainConfig, BlockTimestampRule, 1))
+	require.False(t, IsActive(chainConfig, TxExpiry, 1))
+	require.False(t, IsActive(chainConfig, "not_registered", 1))
+
+	chainConfig = newChainConfig(KeyPrefix+BlockTimestampRule, "100", KeyPrefix+TxExpiry, "200")
+	require.False(t, IsActive(chainConfig, BlockTimestampRule, 99))
+	require.True(t, IsActive(chainConfig, BlockTimestampRule, 100))
+	require.False(t, IsActive(chainConfig, TxExpiry, 199))
+	require.True(t, IsActive(chainConfig, TxExpiry, 200))
+	require.Equal(t, []string{TxExpiry}, ActivatedAt(chainConfig, 200))
+	require.Empty(t, ActivatedAt(chainConfig, 150))
+}
+
+func TestCheckConfig(t *testing.T) {
+	require.NoError(t, CheckConfig(newChainConfig(KeyPrefix+BlockLimit, "10", "TBFT_propose_timeout", "1s")))
+	require.Error(t, CheckConfig(newChainConfig(KeyPrefix+"not_registered", "10")))
+	require.Error(t, CheckConfig(newChainConfig(KeyPrefix+BlockLimit, "-1")))
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package activation
+
+// names of the features known by this version
+const (
+	// BlockLimit rejects the blocks whose txs exceed block.block_size or block_gas_limit
+	BlockLimit = "block_limit"
+	// BlockTimestampRule is the block timestamp rule of block_timestamp_monotonic and block_timestamp_max_drift
+	BlockTimestampRule = "block_timestamp_rule"
+	// StateRoot commits the state root in the rwset root of block header, see statetree.CommitmentRoot
+	StateRoot = "state_root"
+	// TxExpiry rejects the txs packed after their expiration height or Payload.ExpirationTime
+	TxExpiry = "tx_expiry"
+	// TBFTWeightedVoting weights the votes and proposers of TBFT by the validator weights
+	TBFTWeightedVoting = "tbft_weighted_voting"
+	// TBFTEvidence records the equivocation evidence txs of TBFT validators in state
+	TBFTEvidence = "tbft_evidence"
+	// TBFTBlockParts sends the large TBFT proposals in merkle-rooted parts of TBFT_block_part_size
+	TBFTBlockParts = "tbft_block_parts"
+	// TBFTAggregateSignatures certifies the TBFT commits by the BLS aggregate signatures of TBFT_aggregate_keys
+	TBFTAggregateSignatures = "tbft_aggregate_signatures"
+	// HotStuffAggregateSignatures certifies the chained-BFT QCs by the BLS aggregate signatures of
+	// HotstuffAggregateKeys
+	HotStuffAggregateSignatures = "hotstuff_aggregate_signatures"
+)
+
+func init() {
+	Register(&Feature{
+		Name:        BlockLimit,
+		Description: "reject the blocks whose txs exceed the block size or gas limit",
+	})
+	Register(&Feature{
+		Name:          BlockTimestampRule,
+		Description:   "check block timestamps against the parent and the local clock",
+		DefaultActive: true,
+	})
+	Register(&Feature{
+		Name:        StateRoot,
+		Description: "commit the world state root in the block header",
+	})
+	Register(&Feature{
+		Name:        TxExpiry,
+		Description: "reject the txs packed after their expiration height or time",
+	})
+	Register(&Feature{
+		Name:        TBFTWeightedVoting,
+		Description: "weight the TBFT votes and proposers by TBFT_validator_weights or DPoS tokens",
+	})
+	Register(&Feature{
+		Name:        TBFTEvidence,
+		Description: "record the conflicting votes of TBFT validators as evidence txs",
+	})
+	Register(&Feature{
+		Name:        TBFTBlockParts,
+		Description: "send the TBFT proposals larger than TBFT_block_part_size in parts",
+	})
+	Register(&Feature{
+		Name:        TBFTAggregateSignatures,
+		Description: "certify the TBFT commits by one BLS aggregate signature instead of the precommits",
+	})
+	Register(&Feature{
+		Name:        HotStuffAggregateSignatures,
+		Description: "certify the chained-BFT QCs of committed blocks by one BLS aggregate signature instead of the votes",
+	})
+}
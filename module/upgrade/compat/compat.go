@@ -0,0 +1,208 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package compat checks the protocol compatibility between this node and its peers.
+// The version of a peer is the protocol version and the features known by its binary, exchanged
+// in the node status messages of sync module. A peer is incompatible if its protocol version is out of
+// the range supported here, or if it does not know a feature which is scheduled for activation by chain config.
+package compat
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	"chainmaker.org/chainmaker/common/v2/monitor"
+	"chainmaker.org/chainmaker/localconf/v2"
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ProtocolVersion is the protocol version of this binary, increased on the changes which break
+	// the compatibility with the binaries before
+	ProtocolVersion uint32 = 1
+	// MinProtocolVersion is the lowest protocol version of peers compatible with this binary,
+	// the binaries before version exchange are at version 0
+	MinProtocolVersion uint32 = 0
+
+	// PolicyKey is the key in consensus ext config of the policy on incompatible peers
+	PolicyKey = "peer_version_policy"
+)
+
+// Policy is the policy on incompatible peers
+type Policy string
+
+const (
+	// PolicyWarn logs warnings on incompatible peers, it's the default
+	PolicyWarn Policy = "warn"
+	// PolicyReject drops the consensus msgs of incompatible peers in net service, for all consensus engines
+	PolicyReject Policy = "reject"
+)
+
+// GetPolicy reads the policy on incompatible peers from consensus ext config
+func GetPolicy(chainConfig *config.ChainConfig) Policy {
+	if chainConfig == nil || chainConfig.Consensus == nil {
+		return PolicyWarn
+	}
+	for _, kv := range chainConfig.Consensus.ExtConfig {
+		if kv.Key == PolicyKey && Policy(kv.Value) == PolicyReject {
+			return PolicyReject
+		}
+	}
+	return PolicyWarn
+}
+
+// NodeVersion is the version of the binary of a node
+type NodeVersion struct {
+	Protocol    uint32   `json:"protocol"`
+	MinProtocol uint32   `json:"min_protocol"`
+	Features    []string `json:"features"` // names of features known by the binary, sorted
+}
+
+// LocalVersion returns the version of this binary
+func LocalVersion() *NodeVersion {
+	version := &NodeVersion{
+		Protocol:    ProtocolVersion,
+		MinProtocol: MinProtocolVersion,
+	}
+	for _, feature := range activation.Features() {
+		version.Features = append(version.Features, feature.Name)
+	}
+	return version
+}
+
+// String returns the version as "<protocol>/<feature count>", the label of version distribution metrics
+func (v *NodeVersion) String() string {
+	return fmt.Sprintf("%d/%d", v.Protocol, len(v.Features))
+}
+
+// Check checks if the peer at version is compatible with this node under chain config
+func Check(chainConfig *config.ChainConfig, version *NodeVersion) error {
+	if version.Protocol < MinProtocolVersion {
+		return fmt.Errorf("protocol version %d is lower than %d", version.Protocol, MinProtocolVersion)
+	}
+	if version.MinProtocol > ProtocolVersion {
+		return fmt.Errorf("protocol version %d is lower than %d required by peer", ProtocolVersion,
+			version.MinProtocol)
+	}
+	known := make(map[string]struct{}, len(version.Features))
+	for _, name := range version.Features {
+		known[name] = struct{}{}
+	}
+	for _, feature := range activation.Features() {
+		height, ok := activation.ActivationHeight(chainConfig, feature.Name)
+		if !ok {
+			continue
+		}
+		if _, ok = known[feature.Name]; !ok {
+			return fmt.Errorf("feature %s activated at height %d is unknown to peer", feature.Name, height)
+		}
+	}
+	return nil
+}
+
+var registries sync.Map // chain id => *Registry
+
+// Of returns the peer version registry of chain
+func Of(chainId string) *Registry {
+	if r, ok := registries.Load(chainId); ok {
+		return r.(*Registry)
+	}
+	r, _ := registries.LoadOrStore(chainId, NewRegistry(chainId))
+	return r.(*Registry)
+}
+
+// Registry keeps the latest versions of peers and their compatibility
+type Registry struct {
+	mu           sync.RWMutex
+	chainId      string
+	peers        map[string]*NodeVersion // node id => version
+	incompatible map[string]error        // node id => reason
+
+	metricPeerVersions *prometheus.GaugeVec
+}
+
+// NewRegistry creates a peer version registry of chain
+func NewRegistry(chainId string) *Registry {
+	r := &Registry{
+		chainId:      chainId,
+		peers:        make(map[string]*NodeVersion),
+		incompatible: make(map[string]error),
+	}
+	if localconf.ChainMakerConfig.MonitorConfig.Enabled {
+		r.metricPeerVersions = monitor.NewGaugeVec(monitor.SUBSYSTEM_CONSENSUS, "metric_peer_versions",
+			"peer count by version and compatibility", "chainId", "version", "compatible")
+	}
+	return r
+}
+
+// Update records the version of peer, returns the reason if it is incompatible, and if its
+// compatibility is changed by this update
+func (r *Registry) Update(chainConfig *config.ChainConfig, nodeId string, version *NodeVersion) (
+	reason error, changed bool) {
+	reason = Check(chainConfig, version)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lastReason, wasIncompatible := r.incompatible[nodeId]
+	_, known := r.peers[nodeId]
+	changed = !known || wasIncompatible != (reason != nil)
+	if !changed && reason != nil {
+		changed = lastReason.Error() != reason.Error()
+	}
+	r.peers[nodeId] = version
+	if reason != nil {
+		r.incompatible[nodeId] = reason
+	} else {
+		delete(r.incompatible, nodeId)
+	}
+	r.observe()
+	return reason, changed
+}
+
+// IsAllowed returns false if peer is known to be incompatible and policy is PolicyReject,
+// the peers whose version is not received yet are allowed
+func (r *Registry) IsAllowed(chainConfig *config.ChainConfig, nodeId string) bool {
+	if GetPolicy(chainConfig) != PolicyReject {
+		return true
+	}
+	r.mu.RLock()
+	version, ok := r.peers[nodeId]
+	r.mu.RUnlock()
+	if !ok {
+		return true
+	}
+	// check again, as chain config may have changed since the version was received
+	return Check(chainConfig, version) == nil
+}
+
+// Versions returns the versions of peers by node id
+func (r *Registry) Versions() map[string]*NodeVersion {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := make(map[string]*NodeVersion, len(r.peers))
+	for nodeId, version := range r.peers {
+		versions[nodeId] = version
+	}
+	return versions
+}
+
+// observe sets the metrics of version distribution, under lock
+func (r *Registry) observe() {
+	if r.metricPeerVersions == nil {
+		return
+	}
+	counts := make(map[[2]string]int)
+	for nodeId, version := range r.peers {
+		_, incompatible := r.incompatible[nodeId]
+		counts[[2]string{version.String(), strconv.FormatBool(!incompatible)}]++
+	}
+	r.metricPeerVersions.Reset()
+	for key, count := range counts {
+		r.metricPeerVersions.WithLabelValues(r.chainId, key[0], key[1]).Set(float64(count))
+	}
+}
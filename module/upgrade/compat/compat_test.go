@@ -0,0 +1,57 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compat
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker-go/upgrade/activation"
+	"chainmaker.org/chainmaker/pb-go/v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	chainConfig := &config.ChainConfig{Consensus: &config.ConsensusConfig{}}
+	legacy := &NodeVersion{}
+	require.NoError(t, Check(chainConfig, LocalVersion()))
+	require.NoError(t, Check(chainConfig, legacy))
+	require.Error(t, Check(chainConfig, &NodeVersion{Protocol: ProtocolVersion + 1, MinProtocol: ProtocolVersion + 1}))
+
+	// the legacy peer does not know the feature scheduled
+	chainConfig.Consensus.ExtConfig = []*config.ConfigKeyValue{
+		{Key: activation.KeyPrefix + activation.TxExpiry, Value: "100"},
+	}
+	require.NoError(t, Check(chainConfig, LocalVersion()))
+	require.Error(t, Check(chainConfig, legacy))
+}
+
+func TestRegistry(t *testing.T) {
+	chainConfig := &config.ChainConfig{Consensus: &config.ConsensusConfig{
+		ExtConfig: []*config.ConfigKeyValue{
+			{Key: activation.KeyPrefix + activation.TxExpiry, Value: "100"},
+		},
+	}}
+	r := NewRegistry("chain1")
+
+	reason, changed := r.Update(chainConfig, "node1", LocalVersion())
+	require.NoError(t, reason)
+	require.True(t, changed)
+	_, changed = r.Update(chainConfig, "node1", LocalVersion())
+	require.False(t, changed)
+	reason, changed = r.Update(chainConfig, "node2", &NodeVersion{})
+	require.Error(t, reason)
+	require.True(t, changed)
+	require.Len(t, r.Versions(), 2)
+
+	// incompatible peers are refused only under reject policy
+	require.True(t, r.IsAllowed(chainConfig, "node2"))
+	chainConfig.Consensus.ExtConfig = append(chainConfig.Consensus.ExtConfig,
+		&config.ConfigKeyValue{Key: PolicyKey, Value: string(PolicyReject)})
+	require.True(t, r.IsAllowed(chainConfig, "node1"))
+	require.False(t, r.IsAllowed(chainConfig, "node2"))
+	require.True(t, r.IsAllowed(chainConfig, "node3"))
+}
@@ -0,0 +1,11 @@
+module chainmaker.org/chainmaker-go/upgrade
+
+go 1.15
+
+require (
+	chainmaker.org/chainmaker/common/v2 v2.1.0
+	chainmaker.org/chainmaker/localconf/v2 v2.1.0
+	chainmaker.org/chainmaker/pb-go/v2 v2.1.0
+	github.com/prometheus/client_golang v1.11.0
+	github.com/stretchr/testify v1.7.0
+)
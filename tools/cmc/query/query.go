@@ -37,6 +37,8 @@ func NewQueryOnChainCMD() *cobra.Command {
 	cmd.AddCommand(newQueryBlockByHashOnChainCMD())
 	cmd.AddCommand(newQueryBlockByTxIdOnChainCMD())
 	cmd.AddCommand(newQueryArchivedHeightOnChainCMD())
+	cmd.AddCommand(newQueryTxTimelineCMD())
+	cmd.AddCommand(newQueryEvidenceCMD())
 
 	return cmd
 }
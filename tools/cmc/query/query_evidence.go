@@ -0,0 +1,70 @@
+// Copyright (C) BABEC. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hokaccha/go-prettyjson"
+	"github.com/spf13/cobra"
+
+	"chainmaker.org/chainmaker-go/tools/cmc/util"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+)
+
+const (
+	// the method of CHAIN_QUERY contract served by node, see rpcserver
+	methodGetEvidence = "GET_EVIDENCE"
+	paramPending      = "PENDING"
+)
+
+// newQueryEvidenceCMD `query evidence` command implementation
+func newQueryEvidenceCMD() *cobra.Command {
+	var pending bool
+	cmd := &cobra.Command{
+		Use:   "evidence",
+		Short: "query equivocation evidence of TBFT validators",
+		Long: "query equivocation evidence of TBFT validators recorded on chain, " +
+			"or pending in the node connected with --pending",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			//// 1.Chain Client
+			cc, err := util.CreateChainClient(sdkConfPath, chainId, "", "", "", "", "")
+			if err != nil {
+				return err
+			}
+			defer cc.Stop()
+
+			//// 2.Query evidence
+			resp, err := cc.QuerySystemContract(syscontract.SystemContract_CHAIN_QUERY.String(), methodGetEvidence,
+				util.ConvertParameters(map[string]string{paramPending: strconv.FormatBool(pending)}), -1)
+			if err != nil {
+				return err
+			}
+			if resp.Code != common.TxStatusCode_SUCCESS {
+				return fmt.Errorf("query evidence failed, %s", resp.Message)
+			}
+
+			var list []interface{}
+			if err = json.Unmarshal(resp.ContractResult.Result, &list); err != nil {
+				return err
+			}
+			output, err := prettyjson.Marshal(list)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+
+	util.AttachAndRequiredFlags(cmd, flags, []string{
+		flagSdkConfPath, flagChainId,
+	})
+	cmd.Flags().BoolVar(&pending, "pending", false, "query the evidence pending in the node instead")
+	return cmd
+}
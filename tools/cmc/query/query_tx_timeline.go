@@ -0,0 +1,68 @@
+// Copyright (C) BABEC. All rights reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hokaccha/go-prettyjson"
+	"github.com/spf13/cobra"
+
+	"chainmaker.org/chainmaker-go/tools/cmc/util"
+	"chainmaker.org/chainmaker/pb-go/v2/common"
+	"chainmaker.org/chainmaker/pb-go/v2/syscontract"
+)
+
+const (
+	// the method of CHAIN_QUERY contract served by node, see rpcserver
+	methodGetTxTimeline = "GET_TX_TIMELINE"
+	paramTxId           = "TX_ID"
+)
+
+// newQueryTxTimelineCMD `query tx-timeline` command implementation
+func newQueryTxTimelineCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tx-timeline [txid]",
+		Short: "query lifecycle events of tx seen by the node",
+		Long: "query lifecycle events of tx seen by the node connected, " +
+			"such as rpc received, rejected by txpool, proposed, committed or dropped",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			//// 1.Chain Client
+			cc, err := util.CreateChainClient(sdkConfPath, chainId, "", "", "", "", "")
+			if err != nil {
+				return err
+			}
+			defer cc.Stop()
+
+			//// 2.Query tx timeline of node
+			resp, err := cc.QuerySystemContract(syscontract.SystemContract_CHAIN_QUERY.String(), methodGetTxTimeline,
+				util.ConvertParameters(map[string]string{paramTxId: args[0]}), -1)
+			if err != nil {
+				return err
+			}
+			if resp.Code != common.TxStatusCode_SUCCESS {
+				return fmt.Errorf("query tx timeline failed, %s", resp.Message)
+			}
+
+			var events []interface{}
+			if err = json.Unmarshal(resp.ContractResult.Result, &events); err != nil {
+				return err
+			}
+			output, err := prettyjson.Marshal(events)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+
+	util.AttachAndRequiredFlags(cmd, flags, []string{
+		flagSdkConfPath, flagChainId,
+	})
+	return cmd
+}